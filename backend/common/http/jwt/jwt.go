@@ -17,6 +17,7 @@ type options struct {
 	ttl     time.Duration
 	subject string
 	issuer  string
+	id      string
 }
 
 type option = func(o *options)
@@ -39,6 +40,13 @@ func WithIssuer(issuer string) option {
 	}
 }
 
+// token id (jti claim), e.g. session the token belongs to
+func WithID(id string) option {
+	return func(o *options) {
+		o.id = id
+	}
+}
+
 func GenerateToken(sec []byte, opts ...option) (string, error) {
 	o := options{
 		ttl:     defaultTTL,
@@ -57,6 +65,7 @@ func GenerateToken(sec []byte, opts ...option) (string, error) {
 		ExpiresAt: jwt.NewNumericDate(exp),
 		Issuer:    o.issuer,
 		Subject:   o.subject,
+		ID:        o.id,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -70,8 +79,10 @@ func GenerateToken(sec []byte, opts ...option) (string, error) {
 }
 
 type checks struct {
-	subject *string
-	issuer  *string
+	subject    *string
+	issuer     *string
+	subjectOut *string
+	idOut      *string
 }
 
 type check = func(c *checks)
@@ -87,6 +98,21 @@ func WithIssuerCheck(issuer *string) check {
 		c.issuer = issuer
 	}
 }
+
+// store subject of valid token
+func WithSubjectOut(subject *string) check {
+	return func(c *checks) {
+		c.subjectOut = subject
+	}
+}
+
+// store token id of valid token
+func WithIDOut(id *string) check {
+	return func(c *checks) {
+		c.idOut = id
+	}
+}
+
 func ValidateToken(tok string, sec []byte, chks ...check) error {
 	c := checks{}
 	for _, chk := range chks {
@@ -123,6 +149,21 @@ func ValidateToken(tok string, sec []byte, chks ...check) error {
 			return err
 		}
 	}
+	if c.subjectOut != nil {
+		claimSubj, err := token.Claims.GetSubject()
+		if err != nil {
+			return xerr.WrapWithStack(err)
+		}
+		*c.subjectOut = claimSubj
+	}
+	if c.idOut != nil {
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return xerr.New("invalid claims type")
+		}
+		id, _ := claims["jti"].(string)
+		*c.idOut = id
+	}
 
 	return nil
 }
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// HTTPMetrics receives one observation per served request
+type HTTPMetrics interface {
+	ObserveRequest(method string, route string, status int, d time.Duration)
+}
+
+// create middleware for requests metrics, route is chi route pattern
+// to keep labels cardinality low
+func Metrics(m HTTPMetrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if m == nil {
+			return next
+		}
+
+		metricsFn := func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww, ok := w.(chimw.WrapResponseWriter)
+			if !ok {
+				ww = chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+			}
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				m.ObserveRequest(r.Method, routePattern(r), status, time.Since(start))
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(metricsFn)
+	}
+}
+
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides if one more request with the key is allowed,
+// returns time to wait otherwise
+type RateLimiter interface {
+	Allow(key string) (bool, time.Duration)
+}
+
+// create middleware limiting requests per client ip,
+// only given paths are limited, all requests if no paths
+func RateLimit(l RateLimiter, paths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l == nil {
+			return next
+		}
+
+		limitFn := func(w http.ResponseWriter, r *http.Request) {
+			if len(paths) > 0 && !slices.Contains(paths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := ClientIP(r.Context())
+			if key == "" {
+				key = r.RemoteAddr
+			}
+			if ok, wait := l.Allow(key); !ok {
+				retryAfter := int(math.Ceil(wait.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests),
+					http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(limitFn)
+	}
+}
+
+// idle buckets are refilled, so they are dropped to keep memory bounded
+const sweepInterval = time.Minute
+
+// KeyLimiter is token bucket rate limiter per key
+type KeyLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var _ RateLimiter = (*KeyLimiter)(nil)
+
+// allow limit requests per period with bursts up to burst requests
+func NewKeyLimiter(limit int, per time.Duration, burst int) *KeyLimiter {
+	return &KeyLimiter{
+		rate:    float64(limit) / per.Seconds(),
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *KeyLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+func (l *KeyLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewKeyLimiter(6, time.Minute, 2)
+	l.now = func() time.Time { return now }
+
+	// burst
+	ok, _ := l.Allow("a")
+	require.True(t, ok)
+	ok, _ = l.Allow("a")
+	require.True(t, ok)
+	ok, wait := l.Allow("a")
+	require.False(t, ok)
+	require.Equal(t, 10*time.Second, wait)
+
+	// other key has own bucket
+	ok, _ = l.Allow("b")
+	require.True(t, ok)
+
+	// refill
+	now = now.Add(10 * time.Second)
+	ok, _ = l.Allow("a")
+	require.True(t, ok)
+	ok, _ = l.Allow("a")
+	require.False(t, ok)
+
+	// idle buckets are dropped
+	now = now.Add(time.Hour)
+	l.Allow("c")
+	require.Len(t, l.buckets, 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	l := NewKeyLimiter(1, time.Hour, 1)
+	h := RealIP(nil)(RateLimit(l, "/api/auth")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	call := func(path, remote string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		r.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, call("/api/auth", "1.1.1.1:100").Code)
+	// other port of the same client
+	w := call("/api/auth", "1.1.1.1:200")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	// other client
+	require.Equal(t, http.StatusOK, call("/api/auth", "2.2.2.2:100").Code)
+	// not limited path
+	require.Equal(t, http.StatusOK, call("/api/users", "1.1.1.1:100").Code)
+}
+
+func TestRealIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+	}
+	tests := []struct {
+		name    string
+		remote  string
+		xff     []string
+		realIP  string
+		visible string
+	}{
+		{"direct", "1.1.1.1:100", nil, "", "1.1.1.1"},
+		{"untrusted proxy headers ignored", "1.1.1.1:100", []string{"2.2.2.2"}, "3.3.3.3", "1.1.1.1"},
+		{"trusted proxy", "127.0.0.1:100", []string{"2.2.2.2"}, "", "2.2.2.2"},
+		{"forged leftmost entry", "127.0.0.1:100", []string{"6.6.6.6, 2.2.2.2"}, "", "2.2.2.2"},
+		{"proxy chain", "127.0.0.1:100", []string{"2.2.2.2, 10.1.1.1"}, "", "2.2.2.2"},
+		{"multiple headers", "127.0.0.1:100", []string{"2.2.2.2", "10.1.1.1"}, "", "2.2.2.2"},
+		{"x-real-ip", "127.0.0.1:100", nil, "3.3.3.3", "3.3.3.3"},
+		{"invalid forwarded", "127.0.0.1:100", []string{"garbage"}, "", "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, gotRemote string
+			h := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r.Context())
+				gotRemote = r.RemoteAddr
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			require.Equal(t, tt.visible, got)
+			require.Equal(t, tt.visible, gotRemote)
+		})
+	}
+}
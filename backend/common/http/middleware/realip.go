@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKeyType struct{}
+
+var clientIPKey = clientIPKeyType{}
+
+// create middleware resolving client ip. forwarded headers are honoured
+// only for requests from trusted proxies, resolved ip replaces RemoteAddr
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		realIPFn := func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			if ip.IsValid() {
+				r.RemoteAddr = ip.String()
+				r = r.WithContext(context.WithValue(r.Context(), clientIPKey, ip.String()))
+			}
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(realIPFn)
+	}
+}
+
+// client ip resolved by RealIP middleware, empty if unknown
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	remote := parseAddr(r.RemoteAddr)
+	if !remote.IsValid() || !isTrusted(remote, trusted) {
+		return remote
+	}
+
+	// proxies append to X-Forwarded-For, so the rightmost
+	// untrusted address is the first one we can't trust to be forged
+	forwarded := forwardedFor(r)
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := parseAddr(forwarded[i])
+		if !ip.IsValid() {
+			break
+		}
+		if !isTrusted(ip, trusted) || i == 0 {
+			return ip
+		}
+	}
+
+	if ip := parseAddr(r.Header.Get("X-Real-IP")); ip.IsValid() {
+		return ip
+	}
+	return remote
+}
+
+func forwardedFor(r *http.Request) []string {
+	var addrs []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for _, a := range strings.Split(h, ",") {
+			addrs = append(addrs, strings.TrimSpace(a))
+		}
+	}
+	return addrs
+}
+
+// parse ip with or without port
+func parseAddr(s string) netip.Addr {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
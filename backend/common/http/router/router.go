@@ -3,6 +3,7 @@ package router
 import (
 	"fmt"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
@@ -69,6 +70,30 @@ func WithLogger(log *zap.Logger) Option {
 	}
 }
 
+func WithMetrics(m mw.HTTPMetrics) Option {
+	return func(r *routerOptions) {
+		r.metrics = m
+	}
+}
+
+// forwarded headers are honoured for requests from these addresses
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(r *routerOptions) {
+		r.trustedProxies = append(r.trustedProxies, proxies...)
+	}
+}
+
+// limit requests per client ip, only given paths are limited,
+// all requests if no paths
+func WithRateLimit(l mw.RateLimiter, paths ...string) Option {
+	return func(r *routerOptions) {
+		r.rateLimits = append(r.rateLimits, rateLimit{
+			limiter: l,
+			paths:   paths,
+		})
+	}
+}
+
 func New(options ...Option) (http.Handler, error) {
 	ro := &routerOptions{
 		requestTimeout: DefaultRequestTimeout,
@@ -82,10 +107,15 @@ func New(options ...Option) (http.Handler, error) {
 	// add middleware from chi
 	r := chi.NewRouter()
 	r.Use(chimw.RequestID)
+	r.Use(mw.RealIP(ro.trustedProxies))
 	r.Use(mw.Headers())
 	r.Use(chimw.Timeout(ro.requestTimeout))
 	r.Use(mw.Logger(ro.log))
+	r.Use(mw.Metrics(ro.metrics))
 	r.Use(chimw.Recoverer)
+	for _, rl := range ro.rateLimits {
+		r.Use(mw.RateLimit(rl.limiter, rl.paths...))
+	}
 	r.Use(chimw.NewCompressor(ro.compressionLvl).Handler)
 
 	// allow cross-origin
@@ -131,6 +161,11 @@ type spaItem struct {
 	page SPA
 }
 
+type rateLimit struct {
+	limiter mw.RateLimiter
+	paths   []string
+}
+
 type routerOptions struct {
 	handlers       []handler
 	spas           []spaItem
@@ -138,6 +173,9 @@ type routerOptions struct {
 	requestTimeout time.Duration
 	compressionLvl int
 	log            *zap.Logger
+	metrics        mw.HTTPMetrics
+	trustedProxies []netip.Prefix
+	rateLimits     []rateLimit
 }
 
 type Option func(*routerOptions)
@@ -0,0 +1,67 @@
+// Package xraysecret derives per-user secrets of xray protocols
+// from user vless uuid, so one user can be served by nodes
+// with mixed protocol inbounds.
+package xraysecret
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"text/template"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
+const (
+	ss2022Prefix = "2022-"
+	ss2022Salt   = "xrayman-ss2022:"
+
+	// client config template function name, method is raw string
+	// to keep template valid json, usage:
+	// "password": "<server psk>:{{ss2022key .uuid `2022-blake3-aes-128-gcm`}}"
+	SS2022KeyFunc = "ss2022key"
+
+	// client config template variable always set to user vless uuid,
+	// templates without vless users have no other uuid field
+	UUIDVar = "uuid"
+)
+
+func VmessID(vlessUUID string) string {
+	return vlessUUID
+}
+
+func TrojanPassword(vlessUUID string) string {
+	return vlessUUID
+}
+
+func IsShadowsocks2022(method string) bool {
+	return strings.HasPrefix(method, ss2022Prefix)
+}
+
+// base64 user psk with length required by shadowsocks 2022 method
+func Shadowsocks2022Key(vlessUUID, method string) (string, error) {
+	keyLen, err := ss2022KeyLen(method)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(ss2022Salt + vlessUUID))
+	return base64.StdEncoding.EncodeToString(hash[:keyLen]), nil
+}
+
+// functions available in client config templates
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		SS2022KeyFunc: Shadowsocks2022Key,
+	}
+}
+
+func ss2022KeyLen(method string) (int, error) {
+	switch method {
+	case "2022-blake3-aes-128-gcm":
+		return 16, nil
+	case "2022-blake3-aes-256-gcm", "2022-blake3-chacha20-poly1305":
+		return 32, nil
+	default:
+		return 0, xerr.Newf("unsupported shadowsocks 2022 method: %s", method)
+	}
+}
@@ -0,0 +1,56 @@
+package xraysecret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testUUID = "1a2b3c4d-0000-1111-2222-333344445555"
+
+func TestShadowsocks2022Key(t *testing.T) {
+	tests := []struct {
+		method string
+		keyLen int
+	}{
+		{method: "2022-blake3-aes-128-gcm", keyLen: 16},
+		{method: "2022-blake3-aes-256-gcm", keyLen: 32},
+		{method: "2022-blake3-chacha20-poly1305", keyLen: 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			key, err := Shadowsocks2022Key(testUUID, tt.method)
+			require.NoError(t, err)
+
+			raw, err := base64.StdEncoding.DecodeString(key)
+			require.NoError(t, err)
+			require.Len(t, raw, tt.keyLen)
+
+			// derivation is stable
+			again, err := Shadowsocks2022Key(testUUID, tt.method)
+			require.NoError(t, err)
+			require.Equal(t, key, again)
+		})
+	}
+
+	_, err := Shadowsocks2022Key(testUUID, "aes-128-gcm")
+	require.Error(t, err)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := template.New("test").
+		Funcs(TemplateFuncs()).
+		Parse(`psk:{{ss2022key .uuid "2022-blake3-aes-128-gcm"}}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]string{"uuid": testUUID})
+	require.NoError(t, err)
+
+	key, err := Shadowsocks2022Key(testUUID, "2022-blake3-aes-128-gcm")
+	require.NoError(t, err)
+	require.Equal(t, "psk:"+key, buf.String())
+}
@@ -5,17 +5,19 @@ import (
 
 	"github.com/XRay-Addons/xrayman/common/gx"
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/servercfg"
 	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/xrayapi"
-	"github.com/XRay-Addons/xrayman/node/internal/models"
+	xs "github.com/XRay-Addons/xrayman/node/internal/infra/xray/xrayservice"
 	"github.com/XRay-Addons/xrayman/node/internal/service"
 	"go.uber.org/zap"
 )
 
 type CheckXRayParams struct {
 	gx.In
-	Service *service.Service
-	XRayApi *xrayapi.XRayApi
-	Log     *zap.Logger
+	ServerCfg   *servercfg.Config
+	XRayService *xs.XRayService
+	XRayApi     *xrayapi.XRayApi
+	Log         *zap.Logger
 }
 
 var checkXRay = gx.Invoke(
@@ -23,12 +25,17 @@ var checkXRay = gx.Invoke(
 		lc.AppendBootstrap(gx.Bootstrap{
 			Name: "check xray",
 			Fn: func(ctx context.Context) (err error) {
-				// start service
-				if _, err = p.Service.Start(ctx, models.StartParams{}); err != nil {
+				// start xray without users, bypass service
+				// to keep users snapshot untouched
+				cfg, err := p.ServerCfg.GetUsersCfg(nil)
+				if err != nil {
+					return
+				}
+				if err = p.XRayService.Start(ctx, cfg); err != nil {
 					return
 				}
 				defer func() {
-					closeErr := p.Service.Stop(ctx)
+					closeErr := p.XRayService.Stop(ctx)
 					err = xerr.Join(err, closeErr)
 				}()
 
@@ -47,6 +54,36 @@ var checkXRay = gx.Invoke(
 	},
 )
 
+type RestoreXRayParams struct {
+	gx.In
+	Service   *service.Service
+	AutoStart bool `name:"autostart"`
+	Log       *zap.Logger
+}
+
+var restoreXRay = gx.Invoke(
+	func(lc gx.Lifecycle, p RestoreXRayParams) {
+		if !p.AutoStart {
+			return
+		}
+		lc.AppendBootstrap(gx.Bootstrap{
+			Name: "restore xray",
+			Fn: func(ctx context.Context) error {
+				// bad snapshot or xray start failure must not stop node,
+				// it stays reachable with xray stopped to get fixed config
+				if err := p.Service.Restore(ctx); err != nil {
+					p.Log.Error("restore xray, left stopped", zap.Error(err))
+				}
+				return nil
+			},
+			Retry: func(err error) bool {
+				return false
+			},
+		})
+	},
+)
+
 var Bootstrap = gx.Module("bootstrap",
 	checkXRay,
+	restoreXRay,
 )
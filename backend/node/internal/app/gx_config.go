@@ -5,6 +5,7 @@ import (
 	"github.com/XRay-Addons/xrayman/node/internal/config"
 	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/clientcfg"
 	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/servercfg"
+	"github.com/XRay-Addons/xrayman/node/internal/models"
 	"github.com/XRay-Addons/xrayman/node/internal/service"
 )
 
@@ -27,6 +28,11 @@ var Config = gx.Module("config",
 		},
 		"xray-client",
 	),
+	gx.Provide(
+		func(cfg *config.Config) ([]models.InboundRule, error) {
+			return servercfg.LoadInboundRules(cfg.InboundRules)
+		},
+	),
 	gx.ProvideNamed(
 		func(cfg *config.Config) string {
 			return cfg.XRayDataDir
@@ -39,11 +45,23 @@ var Config = gx.Module("config",
 		},
 		"endpoint",
 	),
+	gx.ProvideNamed(
+		func(cfg *config.Config) string {
+			return cfg.MetricsEndpoint
+		},
+		"metrics-endpoint",
+	),
+	gx.ProvideNamed(
+		func(cfg *config.Config) bool {
+			return cfg.AutoStart
+		},
+		"autostart",
+	),
 	gx.ProvideAnnotated(
 		servercfg.New,
 		gx.As(new(service.ServerCfg)),
 		gx.As(gx.Self()),
-		gx.ParamTags(`name:"xray-server"`),
+		gx.ParamTags(`name:"xray-server"`, ``),
 	),
 	gx.ProvideAnnotated(
 		clientcfg.New,
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/gx"
+	"github.com/XRay-Addons/xrayman/common/http/router"
+	"github.com/XRay-Addons/xrayman/common/http/server"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/metrics"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/xrayapi"
+	"go.uber.org/zap"
+)
+
+const (
+	metricsPath          = "/metrics"
+	metricsScrapeTimeout = 5 * time.Second
+)
+
+var metricsProvider = gx.Provide(
+	func(api *xrayapi.XRayApi, log *zap.Logger) (*metrics.Metrics, error) {
+		return metrics.New(api, metricsScrapeTimeout, log)
+	},
+)
+
+type MetricsServerParams struct {
+	gx.In
+	Endpoint string `name:"metrics-endpoint"`
+	Metrics  *metrics.Metrics
+	Lc       gx.Lifecycle
+}
+
+// metrics listener is separate from api one,
+// so metrics are available without nodeman jwt
+var metricsServerJob = gx.Invoke(
+	func(p MetricsServerParams) error {
+		if p.Endpoint == "" {
+			return nil
+		}
+		r, err := router.New(
+			router.WithHandler(metricsPath, p.Metrics.Handler()))
+		if err != nil {
+			return err
+		}
+		s, err := server.New(p.Endpoint, r)
+		if err != nil {
+			return err
+		}
+		p.Lc.AppendJob(gx.Job{
+			Name: "metrics server",
+			OnStart: func(context.Context) error {
+				return s.Listen()
+			},
+			OnStop: func(ctx context.Context) error {
+				return s.Shutdown(ctx)
+			},
+		})
+		return nil
+	},
+)
+
+var Metrics = gx.Module("metrics",
+	metricsProvider,
+	metricsServerJob,
+)
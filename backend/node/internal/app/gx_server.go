@@ -10,14 +10,17 @@ import (
 	"github.com/XRay-Addons/xrayman/node/internal/http/api"
 	"github.com/XRay-Addons/xrayman/node/internal/http/handler"
 	"github.com/XRay-Addons/xrayman/node/internal/http/security"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/metrics"
 	"github.com/XRay-Addons/xrayman/node/internal/service"
 	genapi "github.com/XRay-Addons/xrayman/node/pkg/api/http/openapi-gen"
 	"go.uber.org/zap"
 )
 
 var httpHandler = gx.ProvideAnnotated(
-	func(s *service.Service, l *zap.Logger) (*handler.Handler, error) {
-		return handler.New(s, handler.WithLogger(l))
+	func(s *service.Service, m *metrics.Metrics, l *zap.Logger) (*handler.Handler, error) {
+		return handler.New(s,
+			handler.WithMetrics(m),
+			handler.WithLogger(l))
 	},
 	gx.As(new(genapi.Handler)),
 )
@@ -35,6 +38,7 @@ var apiHandler = gx.ProvideAnnotated(
 type RouterParams struct {
 	gx.In
 	ApiHandler http.Handler `name:"api-handler"`
+	Metrics    *metrics.Metrics
 	Log        *zap.Logger
 }
 
@@ -42,6 +46,7 @@ var r = gx.ProvideNamed(
 	func(p RouterParams) (http.Handler, error) {
 		return router.New(
 			router.WithHandler("/", p.ApiHandler),
+			router.WithMetrics(p.Metrics),
 			router.WithLogger(p.Log))
 	},
 	"router",
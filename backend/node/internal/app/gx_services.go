@@ -2,10 +2,18 @@ package app
 
 import (
 	"github.com/XRay-Addons/xrayman/common/gx"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/snapshot"
 	"github.com/XRay-Addons/xrayman/node/internal/service"
 	"go.uber.org/fx"
 )
 
+var usersSnapshot = gx.ProvideAnnotated(
+	snapshot.New,
+	gx.As(new(service.UsersSnapshot)),
+	gx.ParamTags(`name:"persistent-dir"`),
+)
+
 var Services = gx.Module("service",
+	usersSnapshot,
 	fx.Provide(service.New),
 )
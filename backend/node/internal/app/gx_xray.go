@@ -33,6 +33,7 @@ var xrayService = gx.ProvideAnnotated(
 		return s, err
 	},
 	gx.As(new(service.XRayService)),
+	gx.As(gx.Self()),
 )
 
 type XRayApiParams struct {
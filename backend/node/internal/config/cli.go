@@ -18,9 +18,21 @@ supported template params:
   - {{ .VlessEmail }}
   - {{ .VlessUUID }}`,
 
+	"inboundRulesHelp": `inbound rules json file, maps server inbounds to user fields,
+matched in order before built-in rules, empty network or security matches any:
+  [{"protocol": "vless", "network": "grpc", "security": "tls",
+    "user": {"flow": "", "encryption": "none", "extra": {"level": 0}}}]`,
+
 	"persistentHelp": `persistent config dir. persistent objects
-(certs, secrets, config to connect to node)
+(certs, secrets, config to connect to node, users snapshot)
 should be generated on-demand`,
+
+	"autostartHelp": `start xray on node start with users snapshot
+from persistent dir if xray was running before node stop`,
+
+	"metricsHelp": `prometheus metrics endpoint tcp address, like 127.0.0.1:9100.
+metrics are served on /metrics without auth and tls,
+bind it to private address. empty for disable`,
 }
 
 type CLI struct {
@@ -28,7 +40,10 @@ type CLI struct {
 	Endpoint      string        `short:"a" default:"localhost:8080" env:"ENDPOINT" help:"${endpointHelp}"`
 	XRayDataDir   string        `short:"d" env:"XRAY_DATA_DIR" help:"${xrayDataHelp}"`
 	XRayConfigDir string        `short:"c" env:"XRAY_CONFIG_DIR" help:"${xrayConfigHelp}"`
+	InboundRules  string        `name:"inbound-rules" env:"INBOUND_RULES" help:"${inboundRulesHelp}"`
 	PersistentDir string        `short:"p" env:"PERSISTENT_DIR" help:"${persistentHelp}"`
+	AutoStart     bool          `name:"autostart" env:"AUTOSTART" help:"${autostartHelp}"`
+	Metrics       string        `name:"metrics" env:"METRICS_ENDPOINT" help:"${metricsHelp}"`
 	LogLevel      zapcore.Level `name:"log-lvl" default:"info" env:"LOG_LEVEL" help:"zap log level"`
 }
 
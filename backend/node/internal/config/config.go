@@ -11,8 +11,12 @@ type Config struct {
 	Endpoint      string
 	XRayDataDir   string
 	XRayConfigDir string
+	InboundRules  string
 	PersistentDir string
-	LogLevel      zapcore.Level
+	AutoStart     bool
+	// empty if metrics are disabled
+	MetricsEndpoint string
+	LogLevel        zapcore.Level
 }
 
 func (c *Config) XRayServer() string {
@@ -28,8 +32,12 @@ func NewConfig(cli *CLI) (*Config, error) {
 		Endpoint:      cli.Endpoint,
 		XRayDataDir:   cli.XRayDataDir,
 		XRayConfigDir: cli.XRayConfigDir,
+		InboundRules:  cli.InboundRules,
 		PersistentDir: cli.PersistentDir,
-		LogLevel:      cli.LogLevel,
+		AutoStart:     cli.AutoStart,
+
+		MetricsEndpoint: cli.Metrics,
+		LogLevel:        cli.LogLevel,
 	}
 
 	if err := Validate(cfg); err != nil {
@@ -14,6 +14,13 @@ func Validate(c *Config) error {
 			xerr.WithStack(),
 			xerr.WithInfof("invalid endpoint %s", c.Endpoint))
 	}
+	if c.MetricsEndpoint != "" {
+		if _, err := net.ResolveTCPAddr("tcp", c.MetricsEndpoint); err != nil {
+			return xerr.Wrap(err,
+				xerr.WithStack(),
+				xerr.WithInfof("invalid metrics endpoint %s", c.MetricsEndpoint))
+		}
+	}
 	if err := checkDir(c.XRayDataDir); err != nil {
 		return xerr.WrapWithInfof(err, "arg: %s", c.XRayDataDir)
 	}
@@ -29,7 +36,19 @@ func Validate(c *Config) error {
 	if err := checkJson(c.XRayClient()); err != nil {
 		return xerr.WrapWithInfo(err, "xray client cfg")
 	}
-	// don't check c.PersistentDir, it could be created later
+	if c.InboundRules != "" {
+		if err := checkFile(c.InboundRules); err != nil {
+			return xerr.WrapWithInfo(err, "inbound rules")
+		}
+		if err := checkJson(c.InboundRules); err != nil {
+			return xerr.WrapWithInfo(err, "inbound rules")
+		}
+	}
+	// don't check c.PersistentDir, it could be created later,
+	// without it users snapshot is not stored
+	if c.AutoStart && c.PersistentDir == "" {
+		return xerr.New("autostart requires persistent dir for users snapshot")
+	}
 
 	return nil
 }
@@ -10,6 +10,7 @@ var (
 	ErrConnection           = xerr.Define("connection")
 	ErrTemporaryUnavailable = xerr.Define("temporary unavailable")
 	ErrAccessDenied         = xerr.Define("access denied")
+	ErrInvalidConfig        = xerr.Define("invalid config")
 )
 
 func NilCall() error {
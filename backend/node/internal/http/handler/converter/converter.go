@@ -9,13 +9,21 @@ import (
 // goverter:output:format function
 // goverter:output:file ./converter_generated.go
 // goverter:enum:unknown @panic
+// goverter:extend ConvertEmail
 //
 //go:generate goverter gen .
 type Converter interface {
 	ConvertStartRequest(source *api.StartRequest) *models.StartParams
 	ConvertStartResult(source *models.StartResult) *api.StartResponse
+	ConvertReloadResult(source *models.ReloadResult) *api.ReloadResponse
+	ConvertPushConfigsRequest(source *api.PushConfigsRequest) *models.PushConfigsParams
 	ConvertEditUsersRequest(source *api.EditUsersRequest) *models.EditUsersParams
 	ConvertStatusResult(source *models.StatusResult) *api.StatusResponse
 	ConvertStatus(source models.ServiceStatus) api.ServiceStatus
 	ConvertStatsResult(source *models.StatsResult) *api.StatsResponse
 }
+
+// unset email means default one
+func ConvertEmail(s api.OptString) string {
+	return s.Or("")
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/http/middleware"
 	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
@@ -15,6 +16,7 @@ import (
 
 type Handler struct {
 	service Service
+	metrics Metrics
 	log     *zap.Logger
 }
 
@@ -27,6 +29,12 @@ func WithLogger(log *zap.Logger) option {
 	}
 }
 
+func WithMetrics(m Metrics) option {
+	return func(h *Handler) {
+		h.metrics = m
+	}
+}
+
 type option = func(h *Handler)
 
 var _ api.Handler = (*Handler)(nil)
@@ -50,6 +58,8 @@ func (h *Handler) Start(ctx context.Context, req *api.StartRequest) (_ *api.Star
 		return nil, errdefs.NilCall()
 	}
 
+	defer h.observeCall("start", time.Now(), &err)
+
 	p := converter.ConvertStartRequest(req)
 	res, err := h.service.Start(ctx, *p)
 	if err != nil {
@@ -68,6 +78,37 @@ func (h *Handler) Stop(ctx context.Context) error {
 	return nil
 }
 
+func (h *Handler) Reload(ctx context.Context) (*api.ReloadResponse, error) {
+	if h == nil || h.service == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.service.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertReloadResult(res), nil
+}
+
+func (h *Handler) PushConfigs(ctx context.Context, req *api.PushConfigsRequest) (*api.ReloadResponse, error) {
+	if h == nil || h.service == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertPushConfigsRequest(req)
+	res, err := h.service.PushConfigs(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertReloadResult(res), nil
+}
+
+func (h *Handler) ValidateConfigs(ctx context.Context, req *api.PushConfigsRequest) error {
+	if h == nil || h.service == nil {
+		return errdefs.NilCall()
+	}
+	p := converter.ConvertPushConfigsRequest(req)
+	return h.service.ValidateConfigs(ctx, *p)
+}
+
 func (h *Handler) GetStatus(ctx context.Context) (*api.StatusResponse, error) {
 	if h == nil || h.service == nil {
 		return nil, errdefs.NilCall()
@@ -79,10 +120,12 @@ func (h *Handler) GetStatus(ctx context.Context) (*api.StatusResponse, error) {
 	return converter.ConvertStatusResult(status), nil
 }
 
-func (h *Handler) EditUsers(ctx context.Context, req *api.EditUsersRequest) error {
+func (h *Handler) EditUsers(ctx context.Context, req *api.EditUsersRequest) (err error) {
 	if h == nil || h.service == nil {
 		return errdefs.NilCall()
 	}
+	defer h.observeCall("edit_users", time.Now(), &err)
+
 	p := converter.ConvertEditUsersRequest(req)
 	if err := h.service.EditUsers(ctx, *p); err != nil {
 		return err
@@ -98,9 +141,18 @@ func (h *Handler) GetStats(ctx context.Context) (*api.StatsResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	if h.metrics != nil {
+		h.metrics.ObserveStats(*stats)
+	}
 	return converter.ConvertStatsResult(stats), nil
 }
 
+func (h *Handler) observeCall(op string, start time.Time, err *error) {
+	if h.metrics != nil {
+		h.metrics.ObserveCall(op, time.Since(start), *err)
+	}
+}
+
 func (h *Handler) NewError(ctx context.Context, err error) *api.ErrorStatusCode {
 	// log error
 	h.logError(ctx, err)
@@ -128,6 +180,9 @@ func (h *Handler) translateError(err error) *api.ErrorStatusCode {
 	if errors.Is(err, errdefs.ErrConnection) {
 		return httperrdefs.ErrConnection
 	}
+	if errors.Is(err, errdefs.ErrInvalidConfig) {
+		return httperrdefs.InvalidConfig(err)
+	}
 	return httperrdefs.ErrUnknown
 }
 
@@ -9,6 +9,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/node/internal/http/handler/mocks"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 	api "github.com/XRay-Addons/xrayman/node/pkg/api/http/openapi-gen"
@@ -78,7 +80,51 @@ func TestHandler(t *testing.T) {
 					Return(&models.StartResult{}, nil)
 			},
 			expectedCode: http.StatusOK,
-			expectedBody: `{"clientConfigTemplate": {"template":[], "vlessEmailField":"", "vlessUUIDField":""}}`,
+			expectedBody: `{"clientConfigTemplate": {"template":[], "vlessEmailField":"", "vlessUUIDField":""}, "version":""}`,
+		},
+		{
+			name:   "Reload OK",
+			method: http.MethodPost,
+			path:   "/reload",
+			body:   nil,
+			mockSetup: func(m *mocks.MockService) {
+				m.EXPECT().
+					Reload(gomock.Any()).
+					Return(&models.ReloadResult{Version: "v1"}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"clientConfigTemplate": {"template":[], "vlessEmailField":"", "vlessUUIDField":""}, "version":"v1"}`,
+		},
+		{
+			name:   "PushConfigs Invalid Config",
+			method: http.MethodPost,
+			path:   "/configs",
+			body:   []byte(`{"serverConfig":"{}","clientConfig":"[]"}`),
+			mockSetup: func(m *mocks.MockService) {
+				m.EXPECT().
+					PushConfigs(gomock.Any(), models.PushConfigsParams{
+						ServerConfig: "{}",
+						ClientConfig: "[]",
+					}).
+					Return(nil, xerr.Wrap(xerr.New("no inbounds"), xerr.WithType(errdefs.ErrInvalidConfig)))
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"Invalid config","details":"no inbounds"}`,
+		},
+		{
+			name:   "ValidateConfigs OK",
+			method: http.MethodPost,
+			path:   "/configs/validate",
+			body:   []byte(`{"serverConfig":"{}","clientConfig":"[]"}`),
+			mockSetup: func(m *mocks.MockService) {
+				m.EXPECT().
+					ValidateConfigs(gomock.Any(), models.PushConfigsParams{
+						ServerConfig: "{}",
+						ClientConfig: "[]",
+					}).
+					Return(nil)
+			},
+			expectedCode: http.StatusOK,
 		},
 		{
 			name:         "Post Validation Error",
@@ -122,6 +168,11 @@ func TestHandler(t *testing.T) {
 				tt.expectedCode == http.StatusInternalServerError {
 				return
 			}
+			// responses without body
+			if tt.expectedBody == "" {
+				require.Empty(t, rr.Body.String())
+				return
+			}
 
 			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
 
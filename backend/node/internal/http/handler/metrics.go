@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+)
+
+type Metrics interface {
+	// called for every users changing call
+	ObserveCall(op string, d time.Duration, err error)
+	// called for every stats batch returned to nodeman
+	ObserveStats(stats models.StatsResult)
+}
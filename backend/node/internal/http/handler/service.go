@@ -10,6 +10,9 @@ import (
 type Service interface {
 	Start(ctx context.Context, params models.StartParams) (*models.StartResult, error)
 	Stop(ctx context.Context) error
+	Reload(ctx context.Context) (*models.ReloadResult, error)
+	PushConfigs(ctx context.Context, params models.PushConfigsParams) (*models.ReloadResult, error)
+	ValidateConfigs(ctx context.Context, params models.PushConfigsParams) error
 	Status(ctx context.Context) (*models.StatusResult, error)
 	EditUsers(ctx context.Context, params models.EditUsersParams) error
 	GetStats(ctx context.Context) (*models.StatsResult, error)
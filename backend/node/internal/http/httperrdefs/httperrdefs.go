@@ -22,6 +22,12 @@ var (
 		"unknown error", "we really don't know")
 )
 
+// invalid config error details describe what's wrong,
+// so config pusher could fix it
+func InvalidConfig(err error) *api.ErrorStatusCode {
+	return new(http.StatusBadRequest, "Invalid config", err.Error())
+}
+
 func new(statusCode int, message string, details ...string) *api.ErrorStatusCode {
 	he := api.Error{Message: message}
 
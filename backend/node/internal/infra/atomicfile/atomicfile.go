@@ -0,0 +1,20 @@
+package atomicfile
+
+import (
+	"os"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
+// Write replaces file content via temp file rename,
+// so file is never left partially written
+func Write(path string, data []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, perm); err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	return nil
+}
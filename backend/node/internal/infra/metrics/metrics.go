@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/http/middleware"
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/node/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "node"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.HistogramVec
+
+	calls      *prometheus.HistogramVec
+	callErrors *prometheus.CounterVec
+
+	userTraffic *prometheus.CounterVec
+}
+
+var _ middleware.HTTPMetrics = (*Metrics)(nil)
+var _ handler.Metrics = (*Metrics)(nil)
+
+func New(xray XRayAPI, timeout time.Duration, log *zap.Logger) (*Metrics, error) {
+	if xray == nil {
+		return nil, errdefs.NilArg("xray")
+	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP requests duration by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+
+		calls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "call_duration_seconds",
+			Help:      "Users changing calls (start, edit users) duration.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"op"}),
+		callErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "api",
+			Name:      "call_errors_total",
+			Help:      "Failed users changing calls (start, edit users).",
+		}, []string{"op"}),
+
+		userTraffic: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "traffic",
+			Name:      "user_bytes_total",
+			Help:      "User traffic reported to nodeman since node start.",
+		}, []string{"user", "direction"}),
+	}
+
+	err := registerAll(m.registry,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newXRayCollector(xray, timeout, log),
+		m.httpRequests,
+		m.calls,
+		m.callErrors,
+		m.userTraffic,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Handler serves metrics in prometheus format
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveRequest(method string, route string,
+	status int, d time.Duration,
+) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.
+		WithLabelValues(method, route, strconv.Itoa(status)).
+		Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveCall(op string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.calls.WithLabelValues(op).Observe(d.Seconds())
+	if err != nil {
+		m.callErrors.WithLabelValues(op).Inc()
+	}
+}
+
+func (m *Metrics) ObserveStats(stats models.StatsResult) {
+	if m == nil {
+		return
+	}
+	for _, u := range stats.Users {
+		user := strconv.Itoa(u.ID)
+		m.userTraffic.WithLabelValues(user, "uplink").Add(float64(max(u.Uplink, 0)))
+		m.userTraffic.WithLabelValues(user, "downlink").Add(float64(max(u.Downlink, 0)))
+	}
+}
+
+func registerAll(r prometheus.Registerer, cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			return xerr.WrapWithStack(err)
+		}
+	}
+	return nil
+}
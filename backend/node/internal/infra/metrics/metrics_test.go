@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type xrayAPI struct {
+	stats *models.RuntimeStats
+}
+
+func (a *xrayAPI) GetRuntimeStats(ctx context.Context) (*models.RuntimeStats, error) {
+	if a.stats == nil {
+		return nil, xerr.New("xray stopped")
+	}
+	return a.stats, nil
+}
+
+func TestMetrics_XRay(t *testing.T) {
+	api := &xrayAPI{}
+	c := newXRayCollector(api, time.Second, zap.NewNop())
+
+	// stopped xray reported as down
+	err := testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP node_xray_up 1 if xray api responds, 0 otherwise.
+# TYPE node_xray_up gauge
+node_xray_up 0
+`), "node_xray_up")
+	require.NoError(t, err)
+
+	api.stats = &models.RuntimeStats{
+		Sys: models.SysStats{NumGoroutine: 10, Uptime: time.Minute},
+		Inbounds: []models.InboundUsers{
+			{Tag: "vless", Count: 3},
+			{Tag: "trojan", Count: 3},
+		},
+	}
+	err = testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP node_xray_inbound_users Number of users in xray inbound.
+# TYPE node_xray_inbound_users gauge
+node_xray_inbound_users{inbound="trojan"} 3
+node_xray_inbound_users{inbound="vless"} 3
+# HELP node_xray_uptime_seconds Xray process uptime.
+# TYPE node_xray_uptime_seconds gauge
+node_xray_uptime_seconds 60
+`), "node_xray_inbound_users", "node_xray_uptime_seconds")
+	require.NoError(t, err)
+}
+
+func TestMetrics_Calls(t *testing.T) {
+	m, err := New(&xrayAPI{}, time.Second, zap.NewNop())
+	require.NoError(t, err)
+
+	m.ObserveCall("edit_users", time.Millisecond, nil)
+	m.ObserveCall("edit_users", time.Millisecond, xerr.New("xray stopped"))
+	m.ObserveStats(models.StatsResult{Users: []models.UserStats{
+		{ID: 1, Uplink: 10, Downlink: 100},
+	}})
+
+	require.Equal(t, 1., testutil.ToFloat64(m.callErrors.WithLabelValues("edit_users")))
+	require.Equal(t, 100., testutil.ToFloat64(m.userTraffic.WithLabelValues("1", "downlink")))
+}
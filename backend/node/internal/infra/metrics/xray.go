@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// xray collector reads xray runtime stats on every scrape
+type xrayCollector struct {
+	xray    XRayAPI
+	timeout time.Duration
+	log     *zap.Logger
+
+	up           *prometheus.Desc
+	goroutines   *prometheus.Desc
+	gcs          *prometheus.Desc
+	allocBytes   *prometheus.Desc
+	sysBytes     *prometheus.Desc
+	uptime       *prometheus.Desc
+	inboundUsers *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*xrayCollector)(nil)
+
+func newXRayCollector(xray XRayAPI, timeout time.Duration,
+	log *zap.Logger,
+) *xrayCollector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "xray", name),
+			help, labels, nil)
+	}
+	return &xrayCollector{
+		xray:    xray,
+		timeout: timeout,
+		log:     log,
+
+		up:           desc("up", "1 if xray api responds, 0 otherwise."),
+		goroutines:   desc("goroutines", "Number of xray goroutines."),
+		gcs:          desc("gc_total", "Number of xray completed GC cycles."),
+		allocBytes:   desc("alloc_bytes", "Bytes of xray allocated heap objects."),
+		sysBytes:     desc("sys_bytes", "Bytes of memory xray obtained from OS."),
+		uptime:       desc("uptime_seconds", "Xray process uptime."),
+		inboundUsers: desc("inbound_users", "Number of users in xray inbound.", "inbound"),
+	}
+}
+
+func (c *xrayCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.goroutines
+	ch <- c.gcs
+	ch <- c.allocBytes
+	ch <- c.sysBytes
+	ch <- c.uptime
+	ch <- c.inboundUsers
+}
+
+func (c *xrayCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	// stopped xray is a regular state, report it as down
+	stats, err := c.xray.GetRuntimeStats(ctx)
+	if err != nil {
+		c.log.Debug("collect xray metrics", zap.Error(err))
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+	c.collectSys(ch, stats.Sys)
+
+	for _, in := range stats.Inbounds {
+		ch <- prometheus.MustNewConstMetric(c.inboundUsers,
+			prometheus.GaugeValue, float64(in.Count), in.Tag)
+	}
+}
+
+func (c *xrayCollector) collectSys(ch chan<- prometheus.Metric, s models.SysStats) {
+	ch <- prometheus.MustNewConstMetric(c.goroutines,
+		prometheus.GaugeValue, float64(s.NumGoroutine))
+	ch <- prometheus.MustNewConstMetric(c.gcs,
+		prometheus.CounterValue, float64(s.NumGC))
+	ch <- prometheus.MustNewConstMetric(c.allocBytes,
+		prometheus.GaugeValue, float64(s.Alloc))
+	ch <- prometheus.MustNewConstMetric(c.sysBytes,
+		prometheus.GaugeValue, float64(s.Sys))
+	ch <- prometheus.MustNewConstMetric(c.uptime,
+		prometheus.GaugeValue, s.Uptime.Seconds())
+}
@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+)
+
+type XRayAPI interface {
+	GetRuntimeStats(ctx context.Context) (*models.RuntimeStats, error)
+}
@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/atomicfile"
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+)
+
+const UsersFile = "users.json"
+
+// Store keeps last users passed to node in persistent dir
+type Store struct {
+	// empty if persistent dir is not set, nothing is stored then
+	path string
+	mu   sync.Mutex
+}
+
+type userWrapper struct {
+	ID        models.UserID `json:"id"`
+	Name      string        `json:"name"`
+	VlessUUID string        `json:"vless_uuid"`
+	Email     string        `json:"email,omitempty"`
+}
+
+type snapshotWrapper struct {
+	Running bool          `json:"running"`
+	Users   []userWrapper `json:"users"`
+}
+
+// New creates store in dir, empty dir disables store:
+// empty snapshot is loaded and saving is no-op
+func New(dir string) (*Store, error) {
+	if dir == "" {
+		return &Store{}, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	return &Store{
+		path: filepath.Join(dir, UsersFile),
+	}, nil
+}
+
+// Load returns stored snapshot, or empty one if nothing stored yet
+func (s *Store) Load() (*models.UsersSnapshot, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if s.path == "" {
+		return &models.UsersSnapshot{}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &models.UsersSnapshot{}, nil
+	}
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+
+	var wrapper snapshotWrapper
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, xerr.Wrap(err, xerr.WithStack(), xerr.WithFile(s.path))
+	}
+
+	users := make([]models.User, 0, len(wrapper.Users))
+	for _, u := range wrapper.Users {
+		users = append(users, models.User{
+			ID:        u.ID,
+			Name:      u.Name,
+			VlessUUID: u.VlessUUID,
+			Email:     u.Email,
+		})
+	}
+	return &models.UsersSnapshot{
+		Running: wrapper.Running,
+		Users:   users,
+	}, nil
+}
+
+// Save replaces stored snapshot, file is replaced atomically
+// to not lose snapshot on node crash while writing
+func (s *Store) Save(snapshot models.UsersSnapshot) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wrapper := snapshotWrapper{
+		Running: snapshot.Running,
+		Users:   make([]userWrapper, 0, len(snapshot.Users)),
+	}
+	for _, u := range snapshot.Users {
+		wrapper.Users = append(wrapper.Users, userWrapper{
+			ID:        u.ID,
+			Name:      u.Name,
+			VlessUUID: u.VlessUUID,
+			Email:     u.Email,
+		})
+	}
+
+	data, err := json.Marshal(&wrapper)
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+
+	return atomicfile.Write(s.path, data, 0o600)
+}
@@ -0,0 +1,57 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "persistent")
+
+	store, err := New(dir)
+	require.NoError(t, err)
+
+	// nothing stored yet
+	snapshot, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, models.UsersSnapshot{}, *snapshot)
+
+	stored := models.UsersSnapshot{
+		Running: true,
+		Users: []models.User{
+			{ID: 1, Name: "user1", VlessUUID: "uuid1"},
+			{ID: 2, Name: "user2", VlessUUID: "uuid2", Email: "user2@example.com"},
+		},
+	}
+	require.NoError(t, store.Save(stored))
+
+	// snapshot survives store recreation
+	store, err = New(dir)
+	require.NoError(t, err)
+	snapshot, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, stored, *snapshot)
+
+	// corrupted snapshot
+	require.NoError(t, os.WriteFile(filepath.Join(dir, UsersFile), []byte("{"), 0o600))
+	_, err = store.Load()
+	require.Error(t, err)
+}
+
+func TestStore_NoDir(t *testing.T) {
+	store, err := New("")
+	require.NoError(t, err)
+
+	// nothing is stored without persistent dir
+	require.NoError(t, store.Save(models.UsersSnapshot{
+		Running: true,
+		Users:   []models.User{{ID: 1, Name: "user1", VlessUUID: "uuid1"}},
+	}))
+	snapshot, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, models.UsersSnapshot{}, *snapshot)
+}
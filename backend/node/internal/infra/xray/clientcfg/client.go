@@ -1,20 +1,36 @@
 package clientcfg
 
 import (
+	"io"
 	"os"
+	"sync"
 	"text/template"
 
 	"github.com/XRay-Addons/xrayman/common/jsonval"
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/common/xraysecret"
 	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/atomicfile"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 )
 
+const testUUID = "00000000-0000-0000-0000-000000000000"
+
 type Config struct {
-	cfg models.ClientConfigTemplate
+	path string
+	cfg  models.ClientConfigTemplate
+	mu   sync.RWMutex
+}
+
+func New(path string) (*Config, error) {
+	clientCfg, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{path: path, cfg: *clientCfg}, nil
 }
 
-func New(path string) (cfg *Config, err error) {
+func load(path string) (cfg *models.ClientConfigTemplate, err error) {
 	defer func() {
 		if err != nil {
 			err = xerr.WrapWithFile(err, path)
@@ -25,12 +41,19 @@ func New(path string) (cfg *Config, err error) {
 	if err != nil {
 		return nil, xerr.WrapWithStack(err)
 	}
+	return parse(rawTemplate)
+}
+
+func parse(rawTemplate []byte) (*models.ClientConfigTemplate, error) {
 	if err := jsonval.ValidateJsonData(rawTemplate); err != nil {
 		return nil, err
 	}
 	rawTemplateStr := string(rawTemplate)
 
-	_, err = template.New("validate").Parse(rawTemplateStr)
+	tmpl, err := template.New("validate").
+		Funcs(xraysecret.TemplateFuncs()).
+		Option("missingkey=error").
+		Parse(rawTemplateStr)
 	if err != nil {
 		return nil, xerr.WrapWithStack(err)
 	}
@@ -48,18 +71,97 @@ func New(path string) (cfg *Config, err error) {
 		return nil, err
 	}
 
-	clientCfg := models.ClientConfigTemplate{
+	// template may use only variables known to renderer,
+	// e.g. shadowsocks only template must derive keys from .uuid
+	testData := map[string]string{
+		emailField:         "1-test",
+		vlessUUIdField:     testUUID,
+		xraysecret.UUIDVar: testUUID,
+	}
+	if err := tmpl.Execute(io.Discard, testData); err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+
+	return &models.ClientConfigTemplate{
 		Template:        cfgTemplate,
 		VlessEmailField: emailField,
 		VlessUUIDField:  vlessUUIdField,
+	}, nil
+}
+
+// Validate checks template could replace current one
+func (cfg *Config) Validate(rawTemplate string) error {
+	if cfg == nil {
+		return errdefs.NilCall()
+	}
+	_, err := parse([]byte(rawTemplate))
+	return err
+}
+
+// Write replaces template file, Reload applies it
+func (cfg *Config) Write(rawTemplate string) error {
+	if cfg == nil {
+		return errdefs.NilCall()
+	}
+	return atomicfile.Write(cfg.path, []byte(rawTemplate), 0o600)
+}
+
+// Read returns template file content, it's written back
+// if replacing template fails
+func (cfg *Config) Read() (string, error) {
+	if cfg == nil {
+		return "", errdefs.NilCall()
+	}
+	rawTemplate, err := os.ReadFile(cfg.path)
+	if err != nil {
+		return "", xerr.WrapWithFile(xerr.WrapWithStack(err), cfg.path)
+	}
+	return string(rawTemplate), nil
+}
+
+// Reload re-reads template file, keeps current template if new one is invalid
+func (cfg *Config) Reload() error {
+	apply, err := cfg.PrepareReload()
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// PrepareReload re-reads and validates template file,
+// returned apply replaces current template,
+// revert returned by apply restores replaced one
+func (cfg *Config) PrepareReload() (apply func() (revert func()), err error) {
+	if cfg == nil {
+		return nil, errdefs.NilCall()
+	}
+
+	clientCfg, err := load(cfg.path)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Config{cfg: clientCfg}, nil
+	return func() func() {
+		cfg.mu.Lock()
+		defer cfg.mu.Unlock()
+		prev := cfg.cfg
+		cfg.cfg = *clientCfg
+
+		return func() {
+			cfg.mu.Lock()
+			defer cfg.mu.Unlock()
+			cfg.cfg = prev
+		}
+	}, nil
 }
 
 func (cfg *Config) GetTemplate() (*models.ClientConfigTemplate, error) {
 	if cfg == nil {
 		return nil, errdefs.NilCall()
 	}
-	return &cfg.cfg, nil
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	clientCfg := cfg.cfg
+	return &clientCfg, nil
 }
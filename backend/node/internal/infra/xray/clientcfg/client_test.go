@@ -1,6 +1,7 @@
 package clientcfg
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -153,3 +154,30 @@ func TestInvalidClientConfig(t *testing.T) {
 	_, err = New(filePath)
 	require.Error(t, err)
 }
+
+func TestShadowsocksClientConfig(t *testing.T) {
+	const ssTemplate = `[{
+  "outbounds": [
+    {
+      "protocol": "shadowsocks",
+      "settings": {
+        "servers": [
+          {
+            "method": "2022-blake3-aes-128-gcm",
+            "password": "psk:{{ss2022key .%s ` + "`2022-blake3-aes-128-gcm`" + `}}"
+          }
+        ]
+      }
+    }
+  ]
+}]`
+
+	// shadowsocks only template has no vless fields, key is derived from uuid
+	cfg, err := parse([]byte(fmt.Sprintf(ssTemplate, "uuid")))
+	require.NoError(t, err)
+	require.Empty(t, cfg.VlessUUIDField)
+
+	// unknown variable would be rendered empty
+	_, err = parse([]byte(fmt.Sprintf(ssTemplate, "VlessUUID")))
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package clientcfg
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
@@ -25,36 +26,48 @@ func parseClientConfig(in string) ([]models.ClientConfigTemplateItem, error) {
 }
 
 func extractVlessEmailField(cfg string) (string, error) {
-	users := getUsers(gjson.Parse(cfg))
-	userIDs, err := extractFields(users, "email")
+	parsed := gjson.Parse(cfg)
+	userEmails, err := extractFields(getUsers(parsed), "email")
 	if err != nil {
 		return "", err
 	}
-	userID, err := getSingleValue(userIDs)
+	serverEmails, err := extractFields(getTrojanServers(parsed), "email")
 	if err != nil {
 		return "", err
 	}
-	return userID, nil
+	userEmail, err := getSingleValue(append(userEmails, serverEmails...))
+	if err != nil {
+		return "", err
+	}
+	return userEmail, nil
 }
 
+// vless uuid is used as vmess id and trojan password
 func extractVlessUUIDField(cfg string) (string, error) {
-	users := getUsers(gjson.Parse(cfg))
-	userIDs, err := extractFields(users, "id")
+	parsed := gjson.Parse(cfg)
+	userIDs, err := extractFields(getUsers(parsed), "id")
 	if err != nil {
 		return "", err
 	}
-	userID, err := getSingleValue(userIDs)
+	serverIDs, err := extractFields(getTrojanServers(parsed), "password")
+	if err != nil {
+		return "", err
+	}
+	userID, err := getSingleValue(append(userIDs, serverIDs...))
 	if err != nil {
 		return "", err
 	}
 	return userID, nil
 }
 
-// get outbounds users
+// get vless and vmess outbounds users
 func getUsers(cfgs gjson.Result) []gjson.Result {
 	users := make([]gjson.Result, 0)
 	cfgs.ForEach(func(_, cfg gjson.Result) bool {
-		cfg.Get(`outbounds.#(protocol=="vless")#`).ForEach(func(_, o gjson.Result) bool {
+		cfg.Get("outbounds").ForEach(func(_, o gjson.Result) bool {
+			if p := o.Get("protocol").String(); p != "vless" && p != "vmess" {
+				return true
+			}
 			o.Get("settings.vnext").ForEach(func(_, v gjson.Result) bool {
 				v.Get("users").ForEach(func(_, u gjson.Result) bool {
 					users = append(users, u)
@@ -69,6 +82,22 @@ func getUsers(cfgs gjson.Result) []gjson.Result {
 	return users
 }
 
+// get trojan outbounds servers
+func getTrojanServers(cfgs gjson.Result) []gjson.Result {
+	servers := make([]gjson.Result, 0)
+	cfgs.ForEach(func(_, cfg gjson.Result) bool {
+		cfg.Get(`outbounds.#(protocol=="trojan")#`).ForEach(func(_, o gjson.Result) bool {
+			o.Get("settings.servers").ForEach(func(_, s gjson.Result) bool {
+				servers = append(servers, s)
+				return true
+			})
+			return true
+		})
+		return true
+	})
+	return servers
+}
+
 // extract fields by names
 func extractFields(items []gjson.Result, name string) ([]string, error) {
 	uniqueFields := make(map[string]struct{}, 0)
@@ -122,6 +151,7 @@ func extractTemplateVar(s string) (string, error) {
 }
 
 func getSingleValue(values []string) (string, error) {
+	values = slices.Compact(slices.Sorted(slices.Values(values)))
 	if len(values) > 1 {
 		return "", xerr.Newf("multiple name field templates found: %v", values)
 	}
@@ -11,7 +11,7 @@ import (
 func addSrvUsers(cfg string, ins []models.Inbound, us []models.User) (string, error) {
 	usersCfg := cfg
 	for _, inbound := range ins {
-		sectionUsers, err := makeSectionUsers(inbound.Type, us)
+		sectionUsers, err := makeSectionUsers(inbound, us)
 		if err != nil {
 			return "", err
 		}
@@ -23,13 +23,31 @@ func addSrvUsers(cfg string, ins []models.Inbound, us []models.User) (string, er
 		}
 	}
 
-	return usersCfg, nil
+	return enableUsersOnline(usersCfg, ins)
 }
 
-func makeSectionUsers(it models.InboundType, us []models.User) ([]map[string]string, error) {
-	sectionUsers := make([]map[string]string, 0, len(us))
+// xray tracks users online ips only with "statsUserOnline" policy
+// of users level, it's enabled for all levels of managed inbounds
+func enableUsersOnline(cfg string, ins []models.Inbound) (string, error) {
+	levels := make(map[uint32]struct{})
+	for _, inbound := range ins {
+		levels[inbound.User.Level()] = struct{}{}
+	}
+	for level := range levels {
+		// colon forces numeric object key instead of array index
+		path := fmt.Sprintf("policy.levels.:%d.statsUserOnline", level)
+		var err error
+		if cfg, err = sjson.Set(cfg, path, true); err != nil {
+			return "", xerr.WrapWithStack(err)
+		}
+	}
+	return cfg, nil
+}
+
+func makeSectionUsers(in models.Inbound, us []models.User) ([]map[string]any, error) {
+	sectionUsers := make([]map[string]any, 0, len(us))
 	for _, u := range us {
-		su, err := makeSectionUser(it, u)
+		su, err := makeSectionUser(in, u)
 		if err != nil {
 			return nil, err
 		}
@@ -38,20 +56,31 @@ func makeSectionUsers(it models.InboundType, us []models.User) ([]map[string]str
 	return sectionUsers, nil
 }
 
-func makeSectionUser(it models.InboundType, u models.User) (map[string]string, error) {
-	switch it {
-	case models.VlessTcpReality:
-		return map[string]string{
-			"email": u.VlessEmail(),
-			"flow":  "xtls-rprx-vision",
-			"id":    u.VlessUUID,
-		}, nil
-	case models.VlessXHTTP:
-		return map[string]string{
-			"email": u.VlessEmail(),
-			"id":    u.VlessUUID,
-		}, nil
+func makeSectionUser(in models.Inbound, u models.User) (map[string]any, error) {
+	su := make(map[string]any, len(in.User.Extra)+3)
+	for k, v := range in.User.Extra {
+		su[k] = v
+	}
+	su["email"] = u.VlessEmail()
+
+	switch in.Type {
+	case models.Vless:
+		su["id"] = u.VlessUUID
+		if in.User.Flow != "" {
+			su["flow"] = in.User.Flow
+		}
+	case models.Vmess:
+		su["id"] = u.VmessID()
+	case models.Trojan:
+		su["password"] = u.TrojanPassword()
+	case models.Shadowsocks2022:
+		key, err := u.Shadowsocks2022Key(in.Method)
+		if err != nil {
+			return nil, err
+		}
+		su["password"] = key
 	default:
-		return nil, xerr.Newf("unsupported inbound type: %v", it)
+		return nil, xerr.Newf("unsupported inbound type: %v", in.Type)
 	}
+	return su, nil
 }
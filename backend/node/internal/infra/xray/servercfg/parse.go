@@ -1,6 +1,7 @@
 package servercfg
 
 import (
+	"github.com/XRay-Addons/xrayman/common/xraysecret"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 	"github.com/tidwall/gjson"
 )
@@ -11,43 +12,76 @@ const (
 	inboundTagPath = "tag"
 	networkPath    = "streamSettings.network"
 	securityPath   = "streamSettings.security"
+	methodPath     = "settings.method"
 	apiUrlPath     = "api.listen"
 )
 
-func parseSrvInbounds(cfg string) []models.Inbound {
+var inboundProtocols = map[string]models.InboundType{
+	"vless":       models.Vless,
+	"vmess":       models.Vmess,
+	"trojan":      models.Trojan,
+	"shadowsocks": models.Shadowsocks2022,
+}
+
+func parseSrvInbounds(cfg string, rules []models.InboundRule) []models.Inbound {
 	inboundSections := gjson.Get(cfg, inboundsPath).Array()
 
 	inbounds := make([]models.Inbound, 0, len(inboundSections))
 	for _, inbound := range inboundSections {
 		tag := inbound.Get(inboundTagPath).String()
 		protocol := inbound.Get(protocolPath).String()
-		network := inbound.Get(networkPath).String()
-		security := inbound.Get(securityPath).String()
+		network := getInboundNetwork(inbound)
+		security := getInboundSecurity(inbound)
+		method := inbound.Get(methodPath).String()
 
-		inboundType := getInboundType(protocol, network, security)
+		inboundType := getInboundType(protocol, method)
 		if inboundType == models.UnsupportedInbound {
 			continue
 		}
+		rule, ok := matchInboundRule(rules, protocol, network, security)
+		if !ok {
+			continue
+		}
 		inbounds = append(inbounds, models.Inbound{
-			Tag:  tag,
-			Type: inboundType,
+			Tag:    tag,
+			Type:   inboundType,
+			Method: method,
+			User:   rule.User,
 		})
 	}
 
 	return inbounds
 }
 
-func getInboundType(protocol, network, security string) models.InboundType {
-	if protocol != "vless" {
+func getInboundType(protocol, method string) models.InboundType {
+	inboundType, ok := inboundProtocols[protocol]
+	if !ok {
+		return models.UnsupportedInbound
+	}
+	// only 2022 methods support multiple users with own keys
+	if inboundType == models.Shadowsocks2022 &&
+		!xraysecret.IsShadowsocks2022(method) {
 		return models.UnsupportedInbound
 	}
-	if network == "tcp" && security == "reality" {
-		return models.VlessTcpReality
+	return inboundType
+}
+
+// xray defaults: no network means tcp, "raw" is new name of tcp
+func getInboundNetwork(inbound gjson.Result) string {
+	network := inbound.Get(networkPath).String()
+	if network == "" || network == "raw" {
+		return "tcp"
 	}
-	if network == "xhttp" {
-		return models.VlessXHTTP
+	return network
+}
+
+// xray defaults: no security means none
+func getInboundSecurity(inbound gjson.Result) string {
+	security := inbound.Get(securityPath).String()
+	if security == "" {
+		return "none"
 	}
-	return models.UnsupportedInbound
+	return security
 }
 
 func parseSrvApiURL(srvCfg string) string {
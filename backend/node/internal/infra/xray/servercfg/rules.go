@@ -0,0 +1,101 @@
+package servercfg
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+)
+
+var defaultInboundRules = []models.InboundRule{
+	{
+		Protocol: "vless", Network: "tcp", Security: "reality",
+		User: models.UserTemplate{Flow: "xtls-rprx-vision", Encryption: "none"},
+	},
+	{
+		Protocol: "vless", Network: "xhttp",
+		User: models.UserTemplate{Encryption: "none"},
+	},
+	{
+		Protocol: "vless", Network: "grpc",
+		User: models.UserTemplate{Encryption: "none"},
+	},
+	{
+		Protocol: "vless", Network: "ws",
+		User: models.UserTemplate{Encryption: "none"},
+	},
+	{
+		Protocol: "vless", Network: "httpupgrade",
+		User: models.UserTemplate{Encryption: "none"},
+	},
+	{Protocol: "vmess"},
+	{Protocol: "trojan"},
+	{Protocol: "shadowsocks"},
+}
+
+// rules file item, like
+// {"protocol": "vless", "network": "grpc", "security": "tls",
+// "user": {"encryption": "none", "extra": {"level": 1}}}
+type inboundRuleJSON struct {
+	Protocol string `json:"protocol"`
+	Network  string `json:"network,omitempty"`
+	Security string `json:"security,omitempty"`
+	User     struct {
+		Flow       string         `json:"flow,omitempty"`
+		Encryption string         `json:"encryption,omitempty"`
+		Extra      map[string]any `json:"extra,omitempty"`
+	} `json:"user"`
+}
+
+func DefaultInboundRules() []models.InboundRule {
+	return defaultInboundRules
+}
+
+// LoadInboundRules reads rules from json file,
+// they are matched before default ones
+func LoadInboundRules(path string) ([]models.InboundRule, error) {
+	if path == "" {
+		return DefaultInboundRules(), nil
+	}
+
+	data, err := os.ReadFile(path) // #nosec
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+
+	var items []inboundRuleJSON
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, xerr.Wrap(err, xerr.WithStack(), xerr.WithFile(path))
+	}
+
+	rules := make([]models.InboundRule, 0, len(items)+len(defaultInboundRules))
+	for i, item := range items {
+		if _, ok := inboundProtocols[item.Protocol]; !ok {
+			return nil, xerr.Newf("rule %d: unsupported protocol %q", i, item.Protocol)
+		}
+		rules = append(rules, models.InboundRule{
+			Protocol: item.Protocol,
+			Network:  item.Network,
+			Security: item.Security,
+			User: models.UserTemplate{
+				Flow:       item.User.Flow,
+				Encryption: item.User.Encryption,
+				Extra:      item.User.Extra,
+			},
+		})
+	}
+
+	return append(rules, defaultInboundRules...), nil
+}
+
+func matchInboundRule(rules []models.InboundRule,
+	protocol, network, security string,
+) (models.InboundRule, bool) {
+	for _, r := range rules {
+		if r.Match(protocol, network, security) {
+			return r, true
+		}
+	}
+	return models.InboundRule{}, false
+}
@@ -2,26 +2,61 @@ package servercfg
 
 import (
 	"os"
+	"sync"
 
+	"github.com/XRay-Addons/xrayman/common/jsonval"
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/atomicfile"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 )
 
 type Config struct {
+	path  string
+	rules []models.InboundRule
+
 	config   string
 	inbounds []models.Inbound
 	apiURL   string
+	mu       sync.RWMutex
+}
+
+type parsedConfig struct {
+	config   string
+	inbounds []models.Inbound
+	apiURL   string
+}
+
+func New(path string, rules []models.InboundRule) (*Config, error) {
+	parsed, err := load(path, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		path:     path,
+		rules:    rules,
+		config:   parsed.config,
+		inbounds: parsed.inbounds,
+		apiURL:   parsed.apiURL,
+	}, nil
 }
 
-func New(path string) (*Config, error) {
+func load(path string, rules []models.InboundRule) (*parsedConfig, error) {
 	srvCfg, err := os.ReadFile(path)
 	if err != nil {
 		return nil, xerr.WrapWithStack(err)
 	}
+	return parse(srvCfg, rules)
+}
+
+func parse(srvCfg []byte, rules []models.InboundRule) (*parsedConfig, error) {
+	if err := jsonval.ValidateJsonData(srvCfg); err != nil {
+		return nil, err
+	}
 	srvCfgStr := string(srvCfg)
 
-	inbounds := parseSrvInbounds(srvCfgStr)
+	inbounds := parseSrvInbounds(srvCfgStr, rules)
 	if len(inbounds) == 0 {
 		return nil, xerr.New("no supported inbounds in server cfg")
 	}
@@ -31,17 +66,107 @@ func New(path string) (*Config, error) {
 		return nil, xerr.New("no api url in server cfg")
 	}
 
-	return &Config{
+	return &parsedConfig{
 		config:   srvCfgStr,
 		inbounds: inbounds,
 		apiURL:   apiURL,
 	}, nil
 }
 
+// Validate checks config could replace current one
+func (cfg *Config) Validate(srvCfg string) error {
+	if cfg == nil {
+		return errdefs.NilCall()
+	}
+	parsed, err := parse([]byte(srvCfg), cfg.rules)
+	if err != nil {
+		return err
+	}
+	return cfg.checkApiURL(parsed.apiURL)
+}
+
+// Write replaces config file, Reload applies it
+func (cfg *Config) Write(srvCfg string) error {
+	if cfg == nil {
+		return errdefs.NilCall()
+	}
+	return atomicfile.Write(cfg.path, []byte(srvCfg), 0o600)
+}
+
+// Read returns config file content, it's written back
+// if replacing config fails
+func (cfg *Config) Read() (string, error) {
+	if cfg == nil {
+		return "", errdefs.NilCall()
+	}
+	srvCfg, err := os.ReadFile(cfg.path)
+	if err != nil {
+		return "", xerr.WrapWithFile(xerr.WrapWithStack(err), cfg.path)
+	}
+	return string(srvCfg), nil
+}
+
+// Reload re-reads config file, keeps current config if new one is invalid.
+// api url can't be changed without node restart.
+func (cfg *Config) Reload() error {
+	apply, err := cfg.PrepareReload()
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// PrepareReload re-reads and validates config file, returned apply
+// replaces current config, so reload could be aborted before apply.
+// revert returned by apply restores replaced config
+func (cfg *Config) PrepareReload() (apply func() (revert func()), err error) {
+	if cfg == nil {
+		return nil, errdefs.NilCall()
+	}
+
+	parsed, err := load(cfg.path, cfg.rules)
+	if err != nil {
+		return nil, xerr.WrapWithFile(err, cfg.path)
+	}
+
+	if err := cfg.checkApiURL(parsed.apiURL); err != nil {
+		return nil, err
+	}
+
+	return func() func() {
+		cfg.mu.Lock()
+		defer cfg.mu.Unlock()
+
+		prevConfig, prevInbounds := cfg.config, cfg.inbounds
+		cfg.config = parsed.config
+		cfg.inbounds = parsed.inbounds
+
+		return func() {
+			cfg.mu.Lock()
+			defer cfg.mu.Unlock()
+
+			cfg.config = prevConfig
+			cfg.inbounds = prevInbounds
+		}
+	}, nil
+}
+
+// api url is fixed for xray api client lifetime
+func (cfg *Config) checkApiURL(apiURL string) error {
+	if currURL := cfg.GetApiURL(); apiURL != currURL {
+		return xerr.Newf("api url changed from %s to %s, restart required",
+			currURL, apiURL)
+	}
+	return nil
+}
+
 func (cfg *Config) GetInbounds() []models.Inbound {
 	if cfg == nil {
 		return []models.Inbound{}
 	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
 	return cfg.inbounds
 }
 
@@ -49,6 +174,8 @@ func (cfg *Config) GetApiURL() string {
 	if cfg == nil {
 		return ""
 	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
 	return cfg.apiURL
 }
 
@@ -56,6 +183,8 @@ func (cfg *Config) GetUsersCfg(users []models.User) (string, error) {
 	if cfg == nil {
 		return "", errdefs.NilCall()
 	}
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
 
 	usersCfg, err := addSrvUsers(cfg.config, cfg.inbounds, users)
 	if err != nil {
@@ -3,10 +3,13 @@ package servercfg
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 const testServerCfg = `{
@@ -54,6 +57,87 @@ const testServerCfg = `{
           "acceptProxyProtocol": true
         }
       }
+    },
+    {
+      "tag": "grpc-in",
+      "port": 10005,
+      "protocol": "vless",
+      "settings": {
+        "clients": [],
+        "decryption": "none"
+      },
+      "streamSettings": {
+        "network": "grpc",
+        "security": "tls"
+      }
+    },
+    {
+      "tag": "ws-in",
+      "port": 10006,
+      "protocol": "vless",
+      "settings": {
+        "clients": [],
+        "decryption": "none"
+      },
+      "streamSettings": {
+        "network": "ws"
+      }
+    },
+    {
+      "tag": "httpupgrade-in",
+      "port": 10007,
+      "protocol": "vless",
+      "settings": {
+        "clients": [],
+        "decryption": "none"
+      },
+      "streamSettings": {
+        "network": "httpupgrade"
+      }
+    },
+    {
+      "tag": "vmess-in",
+      "port": 10001,
+      "protocol": "vmess",
+      "settings": {
+        "clients": []
+      },
+      "streamSettings": {
+        "network": "ws"
+      }
+    },
+    {
+      "tag": "trojan-in",
+      "port": 10002,
+      "protocol": "trojan",
+      "settings": {
+        "clients": []
+      },
+      "streamSettings": {
+        "network": "tcp",
+        "security": "tls"
+      }
+    },
+    {
+      "tag": "ss2022-in",
+      "port": 10003,
+      "protocol": "shadowsocks",
+      "settings": {
+        "method": "2022-blake3-aes-128-gcm",
+        "password": "server-psk",
+        "clients": [],
+        "network": "tcp,udp"
+      }
+    },
+    {
+      "tag": "ss-legacy-in",
+      "port": 10004,
+      "protocol": "shadowsocks",
+      "settings": {
+        "method": "aes-128-gcm",
+        "password": "legacy",
+        "network": "tcp,udp"
+      }
     }
   ]
 }`
@@ -61,8 +145,16 @@ const testServerCfg = `{
 const testApiURL = "127.0.0.1:32999"
 
 var testInbounds = []models.Inbound{
-	{Tag: "reality-in", Type: models.VlessTcpReality},
-	{Tag: "xhttp-in", Type: models.VlessXHTTP},
+	{Tag: "reality-in", Type: models.Vless, User: models.UserTemplate{
+		Flow: "xtls-rprx-vision", Encryption: "none",
+	}},
+	{Tag: "xhttp-in", Type: models.Vless, User: models.UserTemplate{Encryption: "none"}},
+	{Tag: "grpc-in", Type: models.Vless, User: models.UserTemplate{Encryption: "none"}},
+	{Tag: "ws-in", Type: models.Vless, User: models.UserTemplate{Encryption: "none"}},
+	{Tag: "httpupgrade-in", Type: models.Vless, User: models.UserTemplate{Encryption: "none"}},
+	{Tag: "vmess-in", Type: models.Vmess},
+	{Tag: "trojan-in", Type: models.Trojan},
+	{Tag: "ss2022-in", Type: models.Shadowsocks2022, Method: "2022-blake3-aes-128-gcm"},
 }
 
 var testUser = models.User{
@@ -77,7 +169,7 @@ func TestServiceCfg(t *testing.T) {
 	err := os.WriteFile(filePath, []byte(testServerCfg), 0o600)
 	require.NoError(t, err)
 
-	serviceCfg, err := New(filePath)
+	serviceCfg, err := New(filePath, DefaultInboundRules())
 	require.NoError(t, err)
 
 	apiURL := serviceCfg.GetApiURL()
@@ -86,6 +178,98 @@ func TestServiceCfg(t *testing.T) {
 	inbounds := serviceCfg.GetInbounds()
 	require.Equal(t, testInbounds, inbounds)
 
-	_, err = serviceCfg.GetUsersCfg([]models.User{testUser})
+	usersCfg, err := serviceCfg.GetUsersCfg([]models.User{testUser})
+	require.NoError(t, err)
+	require.True(t, gjson.Get(usersCfg, "policy.levels.0.statsUserOnline").Bool())
+}
+
+const testInboundRules = `[
+  {"protocol": "vless", "network": "ws", "user": {"encryption": "none", "extra": {"level": 1}}},
+  {"protocol": "vless", "network": "grpc", "security": "none"}
+]`
+
+func TestInboundRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "service_config.json")
+	rulesPath := filepath.Join(tmpDir, "inbound_rules.json")
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte(testServerCfg), 0o600))
+	require.NoError(t, os.WriteFile(rulesPath, []byte(testInboundRules), 0o600))
+
+	rules, err := LoadInboundRules(rulesPath)
+	require.NoError(t, err)
+	require.Len(t, rules, 2+len(DefaultInboundRules()))
+
+	serviceCfg, err := New(cfgPath, rules)
 	require.NoError(t, err)
+
+	// custom rule overrides default one
+	inbounds := serviceCfg.GetInbounds()
+	wsIdx := slices.IndexFunc(inbounds, func(in models.Inbound) bool {
+		return in.Tag == "ws-in"
+	})
+	require.NotEqual(t, -1, wsIdx)
+	require.Equal(t, map[string]any{"level": float64(1)}, inbounds[wsIdx].User.Extra)
+
+	// tls grpc inbound doesn't match custom rule, falls to default
+	grpcIdx := slices.IndexFunc(inbounds, func(in models.Inbound) bool {
+		return in.Tag == "grpc-in"
+	})
+	require.NotEqual(t, -1, grpcIdx)
+	require.Equal(t, models.UserTemplate{Encryption: "none"}, inbounds[grpcIdx].User)
+
+	usersCfg, err := serviceCfg.GetUsersCfg([]models.User{testUser})
+	require.NoError(t, err)
+	wsUser := gjson.Get(usersCfg, "inbounds.#(tag=ws-in).settings.clients.0")
+	require.Equal(t, int64(1), wsUser.Get("level").Int())
+	require.Equal(t, testUser.VlessUUID, wsUser.Get("id").String())
+	require.False(t, wsUser.Get("encryption").Exists())
+	// online stats are enabled for custom level too
+	require.True(t, gjson.Get(usersCfg, "policy.levels.0.statsUserOnline").Bool())
+	require.True(t, gjson.Get(usersCfg, "policy.levels.1.statsUserOnline").Bool())
+	require.False(t, gjson.Get(usersCfg, "policy.levels.0.0").Exists())
+
+	_, err = LoadInboundRules(filepath.Join(tmpDir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestServiceCfgReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "service_config.json")
+
+	require.NoError(t, os.WriteFile(filePath, []byte(testServerCfg), 0o600))
+
+	serviceCfg, err := New(filePath, DefaultInboundRules())
+	require.NoError(t, err)
+
+	// invalid config keeps current one
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"inbounds": []}`), 0o600))
+	require.Error(t, serviceCfg.Reload())
+	require.Equal(t, testInbounds, serviceCfg.GetInbounds())
+
+	// api url can't be changed
+	changedURL, err := sjson.Set(testServerCfg, "api.listen", "127.0.0.1:33000")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filePath, []byte(changedURL), 0o600))
+	require.Error(t, serviceCfg.Reload())
+
+	// inbounds changes applied
+	changedInbounds, err := sjson.Delete(testServerCfg, "inbounds.0")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filePath, []byte(changedInbounds), 0o600))
+	require.NoError(t, serviceCfg.Reload())
+	require.Equal(t, testInbounds[1:], serviceCfg.GetInbounds())
+	require.Equal(t, testApiURL, serviceCfg.GetApiURL())
+
+	// prepared reload is not applied until apply call
+	require.NoError(t, os.WriteFile(filePath, []byte(testServerCfg), 0o600))
+	apply, err := serviceCfg.PrepareReload()
+	require.NoError(t, err)
+	require.Equal(t, testInbounds[1:], serviceCfg.GetInbounds())
+	revert := apply()
+	require.Equal(t, testInbounds, serviceCfg.GetInbounds())
+
+	// reverted reload restores previous config
+	revert()
+	require.Equal(t, testInbounds[1:], serviceCfg.GetInbounds())
 }
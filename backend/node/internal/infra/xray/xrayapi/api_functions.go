@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
@@ -12,6 +13,8 @@ import (
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/serial"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func addUser(
@@ -63,11 +66,45 @@ func ping(
 	return nil
 }
 
+func getSysStats(
+	ctx context.Context,
+	ssClient statsService.StatsServiceClient,
+) (*models.SysStats, error) {
+	resp, err := ssClient.GetSysStats(ctx, &statsService.SysStatsRequest{})
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+
+	return &models.SysStats{
+		NumGoroutine: resp.GetNumGoroutine(),
+		NumGC:        resp.GetNumGC(),
+		Alloc:        resp.GetAlloc(),
+		Sys:          resp.GetSys(),
+		Uptime:       time.Duration(resp.GetUptime()) * time.Second,
+	}, nil
+}
+
+func getInboundUsersCount(
+	ctx context.Context,
+	hs handlerService.HandlerServiceClient,
+	inboundTag string,
+) (int64, error) {
+	resp, err := hs.GetInboundUsersCount(ctx, &handlerService.GetInboundUserRequest{
+		Tag: inboundTag,
+	})
+	if err != nil {
+		return 0, xerr.WrapWithStack(err)
+	}
+
+	return resp.GetCount(), nil
+}
+
 const (
 	userPattern = "user>>>"
 	splitTag    = ">>>"
 	userTag     = "user"
 	trafficTag  = "traffic"
+	onlineTag   = "online"
 	uplinkTag   = "uplink"
 	downlinkTag = "downlink"
 )
@@ -75,6 +112,7 @@ const (
 func getStats(
 	ctx context.Context,
 	ssClient statsService.StatsServiceClient,
+	emails map[string]models.UserID,
 	log *zap.Logger,
 ) (*models.StatsResult, error) {
 	resp, err := ssClient.QueryStats(context.Background(), &statsService.QueryStatsRequest{
@@ -88,13 +126,16 @@ func getStats(
 	// Get traffic data
 	stat := resp.GetStat()
 	userStatsMap := make(map[int]models.UserStats)
+	deviceStatsMap := make(map[models.DeviceID]models.DeviceStats)
 	for _, s := range stat {
 		parts := strings.Split(s.Name, splitTag)
 		if len(parts) != 4 || parts[0] != userTag || parts[2] != trafficTag {
 			log.Warn("unparsed stat", zap.String("name", s.Name))
 			continue
 		}
-		userID, _, err := models.ParseVlessEmail(parts[1])
+		// user could have several identities (devices, previous
+		// credentials during rotation), their stats are summed
+		userID, deviceID, err := parseEmail(parts[1], emails)
 		if err != nil {
 			log.Warn("unparsed user", zap.String("name", s.Name))
 			continue
@@ -102,25 +143,118 @@ func getStats(
 
 		userStat := userStatsMap[userID]
 		userStat.ID = userID
+		deviceStat := deviceStatsMap[deviceID]
+		deviceStat.ID = deviceID
+		deviceStat.UserID = userID
 
 		switch parts[3] {
 		case uplinkTag:
-			userStat.Uplink = s.Value
+			userStat.Uplink += s.Value
+			deviceStat.Uplink += s.Value
 		case downlinkTag:
-			userStat.Downlink = s.Value
+			userStat.Downlink += s.Value
+			deviceStat.Downlink += s.Value
 		default:
 			log.Warn("unparsed direction", zap.String("tag", parts[3]))
 		}
 
 		userStatsMap[userID] = userStat
+		if deviceID != 0 {
+			deviceStatsMap[deviceID] = deviceStat
+		}
+	}
+
+	// add online ips, user could be online without traffic
+	onlineIPs, err := getOnlineIPs(ctx, ssClient, emails, log)
+	if err != nil {
+		return nil, err
+	}
+	for userID, ips := range onlineIPs {
+		userStat := userStatsMap[userID]
+		userStat.ID = userID
+		userStat.OnlineIPs = make([]string, 0, len(ips))
+		for ip := range ips {
+			userStat.OnlineIPs = append(userStat.OnlineIPs, ip)
+		}
+		userStatsMap[userID] = userStat
 	}
 
 	usersStats := make([]models.UserStats, 0, len(userStatsMap))
 	for _, v := range userStatsMap {
 		usersStats = append(usersStats, v)
 	}
+	devicesStats := make([]models.DeviceStats, 0, len(deviceStatsMap))
+	for _, v := range deviceStatsMap {
+		devicesStats = append(devicesStats, v)
+	}
 
 	return &models.StatsResult{
-		Users: usersStats,
+		Users:   usersStats,
+		Devices: devicesStats,
 	}, nil
 }
+
+// online ips are tracked by xray only with "statsUserOnline" policy,
+// servercfg enables it for levels of managed inbounds users
+func getOnlineIPs(
+	ctx context.Context,
+	ssClient statsService.StatsServiceClient,
+	emails map[string]models.UserID,
+	log *zap.Logger,
+) (map[models.UserID]map[string]struct{}, error) {
+	resp, err := ssClient.GetAllOnlineUsers(ctx, &statsService.GetAllOnlineUsersRequest{})
+	// older xray versions have no online stats
+	if status.Code(err) == codes.Unimplemented {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+
+	// user and its devices could be online from the same ips
+	onlineIPs := make(map[models.UserID]map[string]struct{})
+	for _, name := range resp.GetUsers() {
+		parts := strings.Split(name, splitTag)
+		if len(parts) != 3 || parts[0] != userTag || parts[2] != onlineTag {
+			log.Warn("unparsed online stat", zap.String("name", name))
+			continue
+		}
+		userID, _, err := parseEmail(parts[1], emails)
+		if err != nil {
+			log.Warn("unparsed user", zap.String("name", name))
+			continue
+		}
+
+		ipsResp, err := ssClient.GetStatsOnlineIpList(ctx, &statsService.GetStatsRequest{
+			Name: name,
+		})
+		// user went offline after users list request
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, xerr.WrapWithStack(err)
+		}
+		ips, ok := onlineIPs[userID]
+		if !ok {
+			ips = make(map[string]struct{})
+			onlineIPs[userID] = ips
+		}
+		for ip := range ipsResp.GetIps() {
+			ips[ip] = struct{}{}
+		}
+	}
+
+	return onlineIPs, nil
+}
+
+// custom emails of migrated users are matched by running users,
+// other ones are "<id>-<name>" emails of users and their devices
+func parseEmail(email string, emails map[string]models.UserID,
+) (models.UserID, models.DeviceID, error) {
+	if id, ok := emails[email]; ok {
+		return id, 0, nil
+	}
+	id, device, _, err := models.ParseVlessEmail(email)
+	return id, device, err
+}
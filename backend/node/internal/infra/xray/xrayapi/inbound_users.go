@@ -1,43 +1,71 @@
 package xrayapi
 
 import (
+	"cmp"
+
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 	"github.com/xtls/xray-core/common/protocol"
 	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/proxy/shadowsocks_2022"
+	"github.com/xtls/xray-core/proxy/trojan"
 	"github.com/xtls/xray-core/proxy/vless"
+	"github.com/xtls/xray-core/proxy/vmess"
 )
 
-func getInboundUser(u models.User, in models.InboundType) (*protocol.User, error) {
-	switch in {
-	case models.VlessTcpReality, models.VlessXHTTP:
-		vlessAccunt, err := getVlessAccount(u, in)
+func getInboundUser(u models.User, in models.Inbound) (*protocol.User, error) {
+	var account *serial.TypedMessage
+	switch in.Type {
+	case models.Vless:
+		account = serial.ToTypedMessage(getVlessAccount(u, in.User))
+	case models.Vmess:
+		account = serial.ToTypedMessage(getVmessAccount(u))
+	case models.Trojan:
+		account = serial.ToTypedMessage(getTrojanAccount(u))
+	case models.Shadowsocks2022:
+		ssAccount, err := getShadowsocks2022Account(u, in.Method)
 		if err != nil {
 			return nil, err
 		}
-		return &protocol.User{
-			Email:   u.VlessEmail(),
-			Account: serial.ToTypedMessage(vlessAccunt),
-		}, nil
+		account = serial.ToTypedMessage(ssAccount)
 	default:
-		return nil, xerr.Newf("unsupported inbound: %v", in)
+		return nil, xerr.Newf("unsupported inbound: %v", in.Type)
 	}
+	// same level as server config clients, so level policy
+	// applies to users added in runtime too
+	return &protocol.User{
+		Level:   in.User.Level(),
+		Email:   u.VlessEmail(),
+		Account: account,
+	}, nil
 }
 
-func getVlessAccount(u models.User, in models.InboundType) (*vless.Account, error) {
-	switch in {
-	case models.VlessTcpReality:
-		return &vless.Account{
-			Id:         u.VlessUUID,
-			Encryption: "none",
-			Flow:       "xtls-rprx-vision",
-		}, nil
-	case models.VlessXHTTP:
-		return &vless.Account{
-			Id:         u.VlessUUID,
-			Encryption: "none",
-		}, nil
-	default:
-		return nil, xerr.Newf("unsupported inbound: %v", in)
+func getVlessAccount(u models.User, tmpl models.UserTemplate) *vless.Account {
+	return &vless.Account{
+		Id:         u.VlessUUID,
+		Encryption: cmp.Or(tmpl.Encryption, "none"),
+		Flow:       tmpl.Flow,
+	}
+}
+
+func getVmessAccount(u models.User) *vmess.Account {
+	return &vmess.Account{
+		Id: u.VmessID(),
+	}
+}
+
+func getTrojanAccount(u models.User) *trojan.Account {
+	return &trojan.Account{
+		Password: u.TrojanPassword(),
+	}
+}
+
+func getShadowsocks2022Account(u models.User, method string) (*shadowsocks_2022.Account, error) {
+	key, err := u.Shadowsocks2022Key(method)
+	if err != nil {
+		return nil, err
 	}
+	return &shadowsocks_2022.Account{
+		Key: key,
+	}, nil
 }
@@ -104,6 +104,17 @@ func (api *XRayApi) Close(ctx context.Context) error {
 	return nil
 }
 
+// SetInbounds replaces inbounds users are edited in,
+// used after server config reload
+func (api *XRayApi) SetInbounds(inbounds []models.Inbound) {
+	if api == nil {
+		return
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.inbounds = inbounds
+}
+
 func (api *XRayApi) EditUsers(
 	ctx context.Context,
 	add, remove []models.User,
@@ -118,7 +129,7 @@ func (api *XRayApi) EditUsers(
 	var editUsersTx tx.Tx
 	for _, in := range api.inbounds {
 		for _, u := range add {
-			inUser, err := getInboundUser(u, in.Type)
+			inUser, err := getInboundUser(u, in)
 			if err != nil {
 				return err
 			}
@@ -128,7 +139,7 @@ func (api *XRayApi) EditUsers(
 			)
 		}
 		for _, u := range remove {
-			inUser, err := getInboundUser(u, in.Type)
+			inUser, err := getInboundUser(u, in)
 			if err != nil {
 				return err
 			}
@@ -175,8 +186,9 @@ func (api *XRayApi) Ping(ctx context.Context) error {
 	return ping(ctx, api.ssClient)
 }
 
-func (api *XRayApi) GetStats(ctx context.Context) (*models.StatsResult, error) {
-	if api == nil || api.ssClient == nil {
+// GetRuntimeStats returns xray process stats and users count per inbound
+func (api *XRayApi) GetRuntimeStats(ctx context.Context) (*models.RuntimeStats, error) {
+	if api == nil || api.ssClient == nil || api.hsClient == nil {
 		return nil, errdefs.NilCall()
 	}
 
@@ -186,6 +198,42 @@ func (api *XRayApi) GetStats(ctx context.Context) (*models.StatsResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, api.timeout)
 	defer cancel()
 
-	return getStats(ctx, api.ssClient, api.log)
+	sys, err := getSysStats(ctx, api.ssClient)
+	if err != nil {
+		return nil, err
+	}
+	inbounds := make([]models.InboundUsers, 0, len(api.inbounds))
+	for _, in := range api.inbounds {
+		count, err := getInboundUsersCount(ctx, api.hsClient, in.Tag)
+		if err != nil {
+			return nil, err
+		}
+		inbounds = append(inbounds, models.InboundUsers{
+			Tag:   in.Tag,
+			Count: count,
+		})
+	}
+
+	return &models.RuntimeStats{
+		Sys:      *sys,
+		Inbounds: inbounds,
+	}, nil
+}
+
+// GetStats returns users traffic and online ips, users with
+// custom emails are matched by emails map
+func (api *XRayApi) GetStats(ctx context.Context,
+	emails map[string]models.UserID,
+) (*models.StatsResult, error) {
+	if api == nil || api.ssClient == nil {
+		return nil, errdefs.NilCall()
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, api.timeout)
+	defer cancel()
 
+	return getStats(ctx, api.ssClient, emails, api.log)
 }
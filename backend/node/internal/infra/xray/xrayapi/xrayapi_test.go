@@ -61,7 +61,9 @@ var testXRayUser = models.User{
 }
 
 var testXRayInbounds = []models.Inbound{
-	{Tag: "vlesstcp-reality", Type: models.VlessTcpReality},
+	{Tag: "vlesstcp-reality", Type: models.Vless, User: models.UserTemplate{
+		Flow: "xtls-rprx-vision", Encryption: "none",
+	}},
 }
 
 // test service ctl
@@ -115,3 +117,35 @@ func TestXRayAPI(t *testing.T) {
 	)
 	assert.NoError(t, err)
 }
+
+func TestGetInboundUser(t *testing.T) {
+	in := testXRayInbounds[0]
+	in.User.Extra = map[string]any{"level": float64(2)}
+
+	user, err := getInboundUser(testXRayUser, in)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), user.Level)
+	require.Equal(t, testXRayUser.VlessEmail(), user.Email)
+
+	// no level extra field is default level
+	user, err = getInboundUser(testXRayUser, testXRayInbounds[0])
+	require.NoError(t, err)
+	require.Zero(t, user.Level)
+}
+
+func TestParseEmail(t *testing.T) {
+	emails := map[string]models.UserID{"alice@example.com": 5}
+
+	id, device, err := parseEmail("alice@example.com", emails)
+	require.NoError(t, err)
+	require.Equal(t, 5, id)
+	require.Equal(t, 0, device)
+
+	id, device, err = parseEmail("5-alice.3", emails)
+	require.NoError(t, err)
+	require.Equal(t, 5, id)
+	require.Equal(t, 3, device)
+
+	_, _, err = parseEmail("bob@example.com", emails)
+	require.Error(t, err)
+}
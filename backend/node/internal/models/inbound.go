@@ -4,11 +4,59 @@ type InboundType int
 
 const (
 	UnsupportedInbound = iota
-	VlessTcpReality
-	VlessXHTTP
+	Vless
+	Vmess
+	Trojan
+	Shadowsocks2022
 )
 
+// UserTemplate is inbound client fields besides user credentials
+type UserTemplate struct {
+	// vless only
+	Flow string
+	// vless only, used in xray api account only,
+	// server config clients must not contain encryption
+	Encryption string
+	// additional server config client fields, like "level"
+	Extra map[string]any
+}
+
+// user level set by "level" extra field, xray default is 0
+func (t UserTemplate) Level() uint32 {
+	var level int64
+	switch v := t.Extra["level"].(type) {
+	case float64:
+		level = int64(v)
+	case int:
+		level = int64(v)
+	case int64:
+		level = v
+	}
+	if level < 0 {
+		return 0
+	}
+	return uint32(level)
+}
+
+// InboundRule maps server inbound to user template,
+// empty Network or Security matches any value
+type InboundRule struct {
+	Protocol string
+	Network  string
+	Security string
+	User     UserTemplate
+}
+
+func (r InboundRule) Match(protocol, network, security string) bool {
+	return r.Protocol == protocol &&
+		(r.Network == "" || r.Network == network) &&
+		(r.Security == "" || r.Security == security)
+}
+
 type Inbound struct {
 	Tag  string
 	Type InboundType
+	// cipher method, shadowsocks only
+	Method string
+	User   UserTemplate
 }
@@ -9,6 +9,16 @@ type StartResult struct {
 	Version              string
 }
 
+type PushConfigsParams struct {
+	ServerConfig string
+	ClientConfig string
+}
+
+type ReloadResult struct {
+	ClientConfigTemplate ClientConfigTemplate
+	Version              string
+}
+
 type StatusResult struct {
 	ServiceStatus ServiceStatus
 }
@@ -19,5 +29,12 @@ type EditUsersParams struct {
 }
 
 type StatsResult struct {
-	Users []UserStats
+	Users   []UserStats
+	Devices []DeviceStats
+}
+
+// UsersSnapshot is node state persisted to restore xray after node restart
+type UsersSnapshot struct {
+	Running bool
+	Users   []User
 }
@@ -1,7 +1,41 @@
 package models
 
+import "time"
+
 type UserStats struct {
 	ID       UserID
 	Uplink   int64
 	Downlink int64
+	// simultaneous source IPs
+	OnlineIPs []string
+}
+
+// DeviceStats is traffic of user device, device
+// traffic is also included to user traffic
+type DeviceStats struct {
+	ID       DeviceID
+	UserID   UserID
+	Uplink   int64
+	Downlink int64
+}
+
+// SysStats is xray process runtime stats
+type SysStats struct {
+	NumGoroutine uint32
+	NumGC        uint32
+	// bytes of allocated heap objects
+	Alloc uint64
+	// bytes of memory obtained from OS
+	Sys    uint64
+	Uptime time.Duration
+}
+
+type InboundUsers struct {
+	Tag   string
+	Count int64
+}
+
+type RuntimeStats struct {
+	Sys      SysStats
+	Inbounds []InboundUsers
 }
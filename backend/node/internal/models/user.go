@@ -6,21 +6,46 @@ import (
 	"strings"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/common/xraysecret"
 )
 
 type UserID = int
 
+type DeviceID = int
+
 type User struct {
 	ID        UserID
 	Name      string
 	VlessUUID string
+	// email kept from migrated setup, empty for "<id>-<name>" one
+	Email string
 }
 
 func (u User) VlessEmail() string {
+	if u.Email != "" {
+		return u.Email
+	}
 	return fmt.Sprintf("%d-%s", u.ID, u.Name)
 }
 
-func ParseVlessEmail(email string) (id UserID, name string, err error) {
+func (u User) VmessID() string {
+	return xraysecret.VmessID(u.VlessUUID)
+}
+
+func (u User) TrojanPassword() string {
+	return xraysecret.TrojanPassword(u.VlessUUID)
+}
+
+func (u User) Shadowsocks2022Key(method string) (string, error) {
+	return xraysecret.Shadowsocks2022Key(u.VlessUUID, method)
+}
+
+// user device email is "<id>-<name>.<device id>",
+// user names have no device separator
+const deviceSeparator = '.'
+
+// ParseVlessEmail parses user email, device id is zero for user own email
+func ParseVlessEmail(email string) (id UserID, device DeviceID, name string, err error) {
 	defer func() {
 		if err != nil {
 			err = xerr.WrapWithInfof(err, "email: %s", email)
@@ -29,12 +54,20 @@ func ParseVlessEmail(email string) (id UserID, name string, err error) {
 
 	i := strings.IndexByte(email, '-')
 	if i <= 0 || i == len(email)-1 {
-		return 0, "", xerr.New("invalid format")
+		return 0, 0, "", xerr.New("invalid format")
 	}
 
 	if id, err = strconv.Atoi(email[:i]); err != nil {
-		return 0, "", xerr.WrapWithStack(err)
+		return 0, 0, "", xerr.WrapWithStack(err)
+	}
+	name = email[i+1:]
+
+	if j := strings.LastIndexByte(name, deviceSeparator); j >= 0 {
+		if device, err = strconv.Atoi(name[j+1:]); err != nil || device <= 0 {
+			return 0, 0, "", xerr.New("invalid device")
+		}
+		name = name[:j]
 	}
 
-	return UserID(id), email[i+1:], nil
+	return UserID(id), device, name, nil
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVlessEmail(t *testing.T) {
+	tests := []struct {
+		email  string
+		id     UserID
+		device DeviceID
+		name   string
+		ok     bool
+	}{
+		{email: "1-john", id: 1, name: "john", ok: true},
+		{email: "12-john-doe", id: 12, name: "john-doe", ok: true},
+		{email: "12-john-doe-prev", id: 12, name: "john-doe-prev", ok: true},
+		{email: "3-john.7", id: 3, device: 7, name: "john", ok: true},
+		{email: "3-john.0"},
+		{email: "3-john.phone"},
+		{email: "john"},
+		{email: "x-john"},
+		{email: "3-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			id, device, name, err := ParseVlessEmail(tt.email)
+			if !tt.ok {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.id, id)
+			require.Equal(t, tt.device, device)
+			require.Equal(t, tt.name, name)
+		})
+	}
+}
@@ -4,4 +4,11 @@ import "github.com/XRay-Addons/xrayman/node/internal/models"
 
 type ClientConfig interface {
 	GetTemplate() (*models.ClientConfigTemplate, error)
+	Validate(cfg string) error
+	Write(cfg string) error
+	// current config file content
+	Read() (string, error)
+	// validate config file, returned apply replaces current config,
+	// revert returned by apply restores replaced one
+	PrepareReload() (apply func() (revert func()), err error)
 }
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"sync"
 
+	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/node/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/node/internal/models"
 	"github.com/XRay-Addons/xrayman/node/internal/version"
+	"go.uber.org/zap"
 )
 
 type Service struct {
@@ -13,6 +18,13 @@ type Service struct {
 	clientCfg   ClientConfig
 	xrayService XRayService
 	xrayAPI     XRayAPI
+	snapshot    UsersSnapshot
+	log         *zap.Logger
+
+	// users of running xray by email, required to restart it on reload.
+	// user devices and previous credentials have own emails
+	users map[string]models.User
+	mu    sync.Mutex
 }
 
 func New(
@@ -20,6 +32,8 @@ func New(
 	clientCfg ClientConfig,
 	xrayService XRayService,
 	xrayAPI XRayAPI,
+	snapshot UsersSnapshot,
+	log *zap.Logger,
 ) (*Service, error) {
 	if serverCfg == nil {
 		return nil, errdefs.NilArg("serverCfg")
@@ -33,12 +47,21 @@ func New(
 	if xrayAPI == nil {
 		return nil, errdefs.NilArg("xrayAPI")
 	}
+	if snapshot == nil {
+		return nil, errdefs.NilArg("snapshot")
+	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
 
 	return &Service{
 		serverCfg:   serverCfg,
 		clientCfg:   clientCfg,
 		xrayService: xrayService,
 		xrayAPI:     xrayAPI,
+		snapshot:    snapshot,
+		log:         log,
+		users:       make(map[string]models.User),
 	}, nil
 }
 
@@ -48,14 +71,10 @@ func (s *Service) Start(ctx context.Context,
 	if s == nil {
 		return nil, errdefs.NilCall()
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// get server config
-	cfg, err := s.serverCfg.GetUsersCfg(params.Users)
-	if err != nil {
-		return nil, err
-	}
-	// start server
-	if err = s.xrayService.Start(ctx, cfg); err != nil {
+	if err := s.start(ctx, params.Users); err != nil {
 		return nil, err
 	}
 	// get server properties
@@ -71,13 +90,173 @@ func (s *Service) Start(ctx context.Context,
 }
 
 func (s *Service) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// stop server
 	if err := s.xrayService.Stop(ctx); err != nil {
 		return err
 	}
+	s.setUsers(nil)
+	s.saveSnapshot(false)
+	return nil
+}
+
+// Restore starts xray with users from snapshot if it was running
+// before node restart. nodeman fixes users difference on next sync.
+func (s *Service) Restore(ctx context.Context) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.snapshot.Load()
+	if err != nil {
+		return err
+	}
+	if !snapshot.Running {
+		return nil
+	}
+	if err := s.start(ctx, snapshot.Users); err != nil {
+		return err
+	}
+	s.log.Info("xray restored from snapshot",
+		zap.Int("users", len(snapshot.Users)))
+	return nil
+}
+
+// Reload re-reads server and client configs and restarts
+// running xray with current users
+func (s *Service) Reload(ctx context.Context) (*models.ReloadResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reload(ctx)
+}
+
+// PushConfigs replaces server and client config files and reloads them.
+// both configs are validated before any file is written, previous files
+// are written back if any file can't be written or xray can't be restarted
+func (s *Service) PushConfigs(ctx context.Context,
+	params models.PushConfigsParams,
+) (*models.ReloadResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validateConfigs(params); err != nil {
+		return nil, err
+	}
+
+	prevServerCfg, err := s.serverCfg.Read()
+	if err != nil {
+		return nil, err
+	}
+	prevClientCfg, err := s.clientCfg.Read()
+	if err != nil {
+		return nil, err
+	}
+	restore := func(err error) error {
+		restoreErr := xerr.Join(
+			s.serverCfg.Write(prevServerCfg),
+			s.clientCfg.Write(prevClientCfg))
+		if restoreErr != nil {
+			s.log.Error("restore config files", zap.Error(restoreErr))
+		}
+		return err
+	}
+
+	// replace config files
+	if err := s.serverCfg.Write(params.ServerConfig); err != nil {
+		return nil, err
+	}
+	if err := s.clientCfg.Write(params.ClientConfig); err != nil {
+		return nil, restore(err)
+	}
+
+	// reload keeps previous configs applied on failure
+	result, err := s.reload(ctx)
+	if err != nil {
+		return nil, restore(err)
+	}
+	return result, nil
+}
+
+// ValidateConfigs checks configs could be pushed, nothing is changed
+func (s *Service) ValidateConfigs(_ context.Context,
+	params models.PushConfigsParams,
+) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.validateConfigs(params)
+}
+
+func (s *Service) validateConfigs(params models.PushConfigsParams) error {
+	if err := s.serverCfg.Validate(params.ServerConfig); err != nil {
+		return xerr.Wrap(err, xerr.WithType(errdefs.ErrInvalidConfig),
+			xerr.WithInfo("server config"))
+	}
+	if err := s.clientCfg.Validate(params.ClientConfig); err != nil {
+		return xerr.Wrap(err, xerr.WithType(errdefs.ErrInvalidConfig),
+			xerr.WithInfo("client config"))
+	}
 	return nil
 }
 
+func (s *Service) reload(ctx context.Context) (*models.ReloadResult, error) {
+	// reload configs, both are validated before any is replaced
+	applyServerCfg, err := s.serverCfg.PrepareReload()
+	if err != nil {
+		return nil, err
+	}
+	applyClientCfg, err := s.clientCfg.PrepareReload()
+	if err != nil {
+		return nil, err
+	}
+	revertServerCfg := applyServerCfg()
+	revertClientCfg := applyClientCfg()
+	s.xrayAPI.SetInbounds(s.serverCfg.GetInbounds())
+	revert := func() {
+		revertServerCfg()
+		revertClientCfg()
+		s.xrayAPI.SetInbounds(s.serverCfg.GetInbounds())
+	}
+
+	// restart server if it's running
+	status, err := s.xrayService.Status(ctx)
+	if err != nil {
+		revert()
+		return nil, err
+	}
+	if status == models.ServiceStatusRunning {
+		if err := s.restart(ctx); err != nil {
+			// run previous configs again
+			revert()
+			return nil, xerr.Join(err, s.restart(ctx))
+		}
+	}
+
+	// get new server properties
+	clientCfg, err := s.clientCfg.GetTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReloadResult{
+		ClientConfigTemplate: *clientCfg,
+		Version:              version.Version,
+	}, nil
+}
+
 func (s *Service) Status(
 	ctx context.Context,
 ) (*models.StatusResult, error) {
@@ -92,16 +271,87 @@ func (s *Service) Status(
 func (s *Service) EditUsers(ctx context.Context,
 	params models.EditUsersParams,
 ) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.xrayAPI.EditUsers(ctx, params.Add, params.Remove); err != nil {
 		return err
 	}
+	for _, u := range params.Add {
+		s.users[u.VlessEmail()] = u
+	}
+	for _, u := range params.Remove {
+		delete(s.users, u.VlessEmail())
+	}
+	s.saveSnapshot(true)
 	return nil
 }
 
 func (s *Service) GetStats(ctx context.Context) (*models.StatsResult, error) {
-	stats, err := s.xrayAPI.GetStats(ctx)
+	stats, err := s.xrayAPI.GetStats(ctx, s.customEmails())
 	if err != nil {
 		return nil, err
 	}
 	return stats, nil
 }
+
+// restart xray with current users
+func (s *Service) restart(ctx context.Context) error {
+	cfg, err := s.serverCfg.GetUsersCfg(s.listUsers())
+	if err != nil {
+		return err
+	}
+	return s.xrayService.Start(ctx, cfg)
+}
+
+func (s *Service) start(ctx context.Context, users []models.User) error {
+	// get server config
+	cfg, err := s.serverCfg.GetUsersCfg(users)
+	if err != nil {
+		return err
+	}
+	// start server
+	if err = s.xrayService.Start(ctx, cfg); err != nil {
+		return err
+	}
+	s.setUsers(users)
+	s.saveSnapshot(true)
+	return nil
+}
+
+// users are already applied to xray, so snapshot saving error
+// shouldn't fail request: nodeman would retry already applied changes
+func (s *Service) saveSnapshot(running bool) {
+	snapshot := models.UsersSnapshot{
+		Running: running,
+		Users:   s.listUsers(),
+	}
+	if err := s.snapshot.Save(snapshot); err != nil {
+		s.log.Warn("save users snapshot", zap.Error(err))
+	}
+}
+
+func (s *Service) setUsers(users []models.User) {
+	clear(s.users)
+	for _, u := range users {
+		s.users[u.VlessEmail()] = u
+	}
+}
+
+func (s *Service) listUsers() []models.User {
+	return slices.Collect(maps.Values(s.users))
+}
+
+// custom emails can't be parsed, stats are matched by running users
+func (s *Service) customEmails() map[string]models.UserID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	emails := make(map[string]models.UserID)
+	for _, u := range s.users {
+		if u.Email != "" {
+			emails[u.Email] = u.ID
+		}
+	}
+	return emails
+}
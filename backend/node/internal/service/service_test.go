@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/clientcfg"
+	"github.com/XRay-Addons/xrayman/node/internal/infra/xray/servercfg"
+	"github.com/XRay-Addons/xrayman/node/internal/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testServerCfg = `{
+  "api": { "tag": "api", "listen": "127.0.0.1:32998" },
+  "inbounds": [
+    {
+      "tag": "reality-in",
+      "port": 443,
+      "protocol": "vless",
+      "settings": { "clients": [], "decryption": "none" },
+      "streamSettings": { "network": "tcp", "security": "reality" }
+    }
+  ]
+}`
+
+const testClientCfg = `[{
+  "outbounds": [
+    {
+      "protocol": "vless",
+      "settings": {
+        "vnext": [
+          { "users": [{ "email": "{{ .VlessEmail }}", "id": "{{ .VlessUUID }}" }] }
+        ]
+      }
+    }
+  ]
+}]`
+
+// fails to start configs containing failMarker
+type testXRayService struct {
+	failMarker string
+	config     string
+	running    bool
+}
+
+func (s *testXRayService) Start(_ context.Context, config string) error {
+	s.running = false
+	if s.failMarker != "" && strings.Contains(config, s.failMarker) {
+		return xerr.New("xray start failed")
+	}
+	s.config = config
+	s.running = true
+	return nil
+}
+
+func (s *testXRayService) Stop(context.Context) error {
+	s.running = false
+	return nil
+}
+
+func (s *testXRayService) Status(context.Context) (models.ServiceStatus, error) {
+	if s.running {
+		return models.ServiceStatusRunning, nil
+	}
+	return models.ServiceStatusStopped, nil
+}
+
+type testXRayAPI struct {
+	inbounds []models.Inbound
+}
+
+func (a *testXRayAPI) EditUsers(context.Context, []models.User, []models.User) error {
+	return nil
+}
+
+func (a *testXRayAPI) GetStats(context.Context, map[string]models.UserID) (*models.StatsResult, error) {
+	return &models.StatsResult{}, nil
+}
+
+func (a *testXRayAPI) SetInbounds(inbounds []models.Inbound) {
+	a.inbounds = inbounds
+}
+
+type testSnapshot struct{}
+
+func (testSnapshot) Load() (*models.UsersSnapshot, error) {
+	return &models.UsersSnapshot{}, nil
+}
+
+func (testSnapshot) Save(models.UsersSnapshot) error {
+	return nil
+}
+
+func TestPushConfigsStartFailure(t *testing.T) {
+	dir := t.TempDir()
+	serverPath := filepath.Join(dir, "server.json")
+	clientPath := filepath.Join(dir, "client.json")
+	require.NoError(t, os.WriteFile(serverPath, []byte(testServerCfg), 0o600))
+	require.NoError(t, os.WriteFile(clientPath, []byte(testClientCfg), 0o600))
+
+	serverCfg, err := servercfg.New(serverPath, servercfg.DefaultInboundRules())
+	require.NoError(t, err)
+	clientCfg, err := clientcfg.New(clientPath)
+	require.NoError(t, err)
+	xrayService := &testXRayService{failMarker: `"port": 8443`}
+	xrayAPI := &testXRayAPI{}
+	s, err := New(serverCfg, clientCfg, xrayService, xrayAPI,
+		testSnapshot{}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	user := models.User{ID: 1, Name: "alice", VlessUUID: "aaaa-bbbb"}
+	_, err = s.Start(context.Background(), models.StartParams{Users: []models.User{user}})
+	require.NoError(t, err)
+	prevInbounds := serverCfg.GetInbounds()
+	prevXRayCfg := xrayService.config
+
+	// xray can't start with new config: files, configs and xray are restored
+	badServerCfg := strings.Replace(testServerCfg, `"port": 443`, `"port": 8443`, 1)
+	newClientCfg := strings.Replace(testClientCfg, `"vless"`, `"vmess"`, 1)
+	_, err = s.PushConfigs(context.Background(), models.PushConfigsParams{
+		ServerConfig: badServerCfg,
+		ClientConfig: newClientCfg,
+	})
+	require.Error(t, err)
+
+	serverFile, err := os.ReadFile(serverPath)
+	require.NoError(t, err)
+	require.Equal(t, testServerCfg, string(serverFile))
+	clientFile, err := os.ReadFile(clientPath)
+	require.NoError(t, err)
+	require.Equal(t, testClientCfg, string(clientFile))
+
+	require.Equal(t, prevInbounds, serverCfg.GetInbounds())
+	require.Equal(t, prevInbounds, xrayAPI.inbounds)
+	require.True(t, xrayService.running)
+	require.Equal(t, prevXRayCfg, xrayService.config)
+	require.Contains(t, xrayService.config, user.VlessEmail())
+
+	// valid config is pushed with running users
+	goodServerCfg := strings.Replace(testServerCfg, `"port": 443`, `"port": 10443`, 1)
+	_, err = s.PushConfigs(context.Background(), models.PushConfigsParams{
+		ServerConfig: goodServerCfg,
+		ClientConfig: newClientCfg,
+	})
+	require.NoError(t, err)
+	serverFile, err = os.ReadFile(serverPath)
+	require.NoError(t, err)
+	require.Equal(t, goodServerCfg, string(serverFile))
+	require.Contains(t, xrayService.config, "10443")
+	require.Contains(t, xrayService.config, user.VlessEmail())
+}
@@ -0,0 +1,8 @@
+package service
+
+import "github.com/XRay-Addons/xrayman/node/internal/models"
+
+type UsersSnapshot interface {
+	Load() (*models.UsersSnapshot, error)
+	Save(snapshot models.UsersSnapshot) error
+}
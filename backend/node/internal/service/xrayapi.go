@@ -8,5 +8,7 @@ import (
 
 type XRayAPI interface {
 	EditUsers(ctx context.Context, add, remove []models.User) error
-	GetStats(ctx context.Context) (*models.StatsResult, error)
+	// emails maps custom users emails to users
+	GetStats(ctx context.Context, emails map[string]models.UserID) (*models.StatsResult, error)
+	SetInbounds(inbounds []models.Inbound)
 }
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	stdlog "log"
 
 	"github.com/XRay-Addons/xrayman/common/logging"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/admincli"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/app"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/version"
@@ -23,6 +25,13 @@ func main() {
 		return
 	}
 
+	if cli.Command != config.ServeCommand {
+		if err := admincli.Run(context.Background(), cli); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	cfg, err := config.NewConfig(cli)
 	if err != nil {
 		stdlog.Printf("config loading: %+v", err)
@@ -0,0 +1,29 @@
+package admincli
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// api token auth
+type tokenSecurity struct {
+	token string
+}
+
+func (s tokenSecurity) BearerAuth(ctx context.Context,
+	op api.OperationName,
+) (api.BearerAuth, error) {
+	return api.BearerAuth{
+		Token: s.token,
+	}, nil
+}
+
+func newClient(apiURL string, token string) (*api.Client, error) {
+	c, err := api.NewClient(apiURL, tokenSecurity{token: token})
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	return c, nil
+}
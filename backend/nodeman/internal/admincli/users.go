@@ -0,0 +1,220 @@
+// Package admincli implements nodeman admin commands
+// working with running nodeman via its api.
+package admincli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/usermigrate"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// Run runs admin command selected in cli, writes its result to stdout
+func Run(ctx context.Context, cli *config.CLI) error {
+	switch cli.Command {
+	case config.UsersImportCommand:
+		c, err := newClient(cli.Users.API, cli.Users.Token)
+		if err != nil {
+			return err
+		}
+		return ImportUsers(ctx, c, cli.Users.Import.File, os.Stdout)
+	case config.UsersExportCommand:
+		c, err := newClient(cli.Users.API, cli.Users.Token)
+		if err != nil {
+			return err
+		}
+		return exportUsersToFile(ctx, c,
+			api.UsersExportFormat(cli.Users.Export.Format), cli.Users.Export.Out)
+	case config.UsersMigrateCommand:
+		c, err := newClient(cli.Users.API, cli.Users.Token)
+		if err != nil {
+			return err
+		}
+		return MigrateUsers(ctx, c, cli.Users.Migrate.File,
+			usermigrate.Source(cli.Users.Migrate.From), os.Stdout, os.Stderr)
+	default:
+		return xerr.Newf("unexpected command %q", cli.Command)
+	}
+}
+
+// ImportUsers imports users from csv or json file, format
+// is detected by file extension. created users are written to out
+func ImportUsers(ctx context.Context, c *api.Client,
+	path string, out io.Writer,
+) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+
+	var req api.ImportUsersReq
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		req = &api.ImportUsersReqTextCsv{Data: bytes.NewReader(data)}
+	case ".json":
+		var r api.ImportUsersRequest
+		if err := r.UnmarshalJSON(data); err != nil {
+			return xerr.WrapWithInfof(err, "parse %s", path)
+		}
+		req = &r
+	default:
+		return xerr.Newf("unsupported users file extension %q, csv or json expected", ext)
+	}
+
+	resp, err := c.ImportUsers(ctx, req)
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	return writeJSON(out, resp)
+}
+
+// MigrateUsers imports users of xray config or other panel
+// keeping their credentials. created users are written to out,
+// skipped clients and changed settings are reported to report
+func MigrateUsers(ctx context.Context, c *api.Client,
+	path string, src usermigrate.Source, out, report io.Writer,
+) (err error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	defer func() {
+		err = xerr.Join(err, xerr.WrapWithStack(f.Close()))
+	}()
+
+	res, err := usermigrate.Read(f, src)
+	if err != nil {
+		return xerr.WrapWithInfof(err, "read %s", path)
+	}
+	if err := writeMigrateReport(report, res); err != nil {
+		return err
+	}
+
+	req := api.ImportUsersRequest{
+		Users: make([]api.ImportUser, 0, len(res.Users)),
+	}
+	for _, u := range res.Users {
+		req.Users = append(req.Users, makeImportUser(u))
+	}
+	resp, err := c.ImportUsers(ctx, &req)
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	return writeJSON(out, resp)
+}
+
+func writeMigrateReport(w io.Writer, res *usermigrate.Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d users to import\n", len(res.Users))
+	if len(res.Skipped) > 0 {
+		fmt.Fprintf(&b, "skipped %d:\n", len(res.Skipped))
+		for _, s := range res.Skipped {
+			fmt.Fprintf(&b, "  %s\n", s)
+		}
+	}
+	if len(res.Warnings) > 0 {
+		fmt.Fprintf(&b, "warnings %d:\n", len(res.Warnings))
+		for _, s := range res.Warnings {
+			fmt.Fprintf(&b, "  %s\n", s)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return xerr.WrapWithStack(err)
+}
+
+func makeImportUser(u models.ImportUser) api.ImportUser {
+	iu := api.ImportUser{
+		DisplayName: api.DisplayName(u.DisplayName),
+	}
+	if u.VlessUUID != "" {
+		iu.VlessUUID = api.NewOptString(u.VlessUUID)
+	}
+	if u.Status == models.UserStatusDisabled {
+		iu.Status = api.NewOptUserStatus(api.UserStatusDisabled)
+	}
+	if u.Quota.Limit > 0 {
+		period := api.TrafficQuotaPeriodOneOff
+		if u.Quota.Period == models.TrafficQuotaPeriodMonthly {
+			period = api.TrafficQuotaPeriodMonthly
+		}
+		iu.Quota = api.NewOptTrafficQuota(api.TrafficQuota{
+			Limit:  u.Quota.Limit,
+			Period: period,
+		})
+	}
+	if !u.ExpiresAt.IsZero() {
+		iu.ExpiresAt = api.NewOptExpiresAt(api.ExpiresAt(u.ExpiresAt))
+	}
+	if u.IPLimit > 0 {
+		iu.IPLimit = api.NewOptIPLimit(api.IPLimit(u.IPLimit))
+	}
+	if u.Email != "" {
+		iu.Email = api.NewOptUserEmail(api.UserEmail(u.Email))
+	}
+	for _, d := range u.Devices {
+		iu.Devices = append(iu.Devices, api.ImportDevice{
+			Label:     api.DeviceLabel(d.Label),
+			VlessUUID: d.VlessUUID,
+		})
+	}
+	return iu
+}
+
+// ExportUsers writes all users with traffic to out in given format
+func ExportUsers(ctx context.Context, c *api.Client,
+	format api.UsersExportFormat, out io.Writer,
+) error {
+	resp, err := c.ExportUsers(ctx, api.ExportUsersParams{
+		Format: api.NewOptUsersExportFormat(format),
+	})
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	switch resp := resp.(type) {
+	case *api.ExportUsersResponse:
+		return writeJSON(out, resp)
+	case *api.ExportUsersOKTextCsv:
+		_, err := io.Copy(out, resp.Data)
+		return xerr.WrapWithStack(err)
+	default:
+		return xerr.Newf("unexpected export response: %T", resp)
+	}
+}
+
+func exportUsersToFile(ctx context.Context, c *api.Client,
+	format api.UsersExportFormat, path string,
+) (err error) {
+	if path == "-" {
+		return ExportUsers(ctx, c, format, os.Stdout)
+	}
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	defer func() {
+		err = xerr.Join(err, xerr.WrapWithStack(f.Close()))
+	}()
+	return ExportUsers(ctx, c, format, f)
+}
+
+type jsonMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+func writeJSON(out io.Writer, v jsonMarshaler) error {
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return xerr.WrapWithStack(err)
+}
@@ -28,6 +28,7 @@ func New(cfg *config.Config, log *zap.Logger) (*App, error) {
 		srcProvider,
 
 		Config,
+		Metrics,
 		Security,
 		Storage,
 		Nodes,
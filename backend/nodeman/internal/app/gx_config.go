@@ -32,12 +32,30 @@ var Config = gx.Module("config", gx.Provide(
 		},
 		"jwt-secret",
 	),
+	gx.Named(
+		func(cfg *config.Config) time.Duration {
+			return cfg.AccessTokenTTL
+		},
+		"access-token-ttl",
+	),
+	gx.Named(
+		func(cfg *config.Config) time.Duration {
+			return cfg.RefreshTokenTTL
+		},
+		"refresh-token-ttl",
+	),
 	gx.Named(
 		func(cfg *config.Config) string {
 			return cfg.AdminPassword
 		},
 		"admin-password",
 	),
+	gx.Named(
+		func(cfg *config.Config) bool {
+			return cfg.LegacyLinks
+		},
+		"legacy-links",
+	),
 	gx.Named(
 		func() string {
 			return JWTIssuer
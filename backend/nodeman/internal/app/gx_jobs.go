@@ -2,10 +2,13 @@ package app
 
 import (
 	"context"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/gx"
 	"github.com/XRay-Addons/xrayman/common/http/server"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/auditman"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/expireman"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/statsman"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/syncman"
 	"go.uber.org/zap"
@@ -69,8 +72,57 @@ var backgroundStatsJob = gx.Options(
 	),
 )
 
+var backgroundExpireJob = gx.Options(
+	gx.Provide(
+		func(ue expireman.UsersExpirer, cfg *config.Config, l *zap.Logger) (*expireman.ExpireMan, error) {
+			return expireman.New(ue, cfg.ExpireCheckInterval, expireman.WithLogger(l))
+		},
+	),
+	gx.Invoke(
+		func(s *expireman.ExpireMan, lc gx.Lifecycle) {
+			lc.AppendJob(gx.Job{
+				Name: "background expire",
+				OnStart: func(context.Context) error {
+					return s.Run()
+				},
+				OnStop: func(context.Context) error {
+					s.Stop()
+					return nil
+				},
+			})
+		},
+	),
+)
+
+// retention is days long, hourly cleanup is enough
+const auditCleanupInterval = time.Hour
+
+var backgroundAuditJob = gx.Options(
+	gx.Provide(
+		func(c auditman.AuditCleaner, l *zap.Logger) (*auditman.AuditMan, error) {
+			return auditman.New(c, auditCleanupInterval, auditman.WithLogger(l))
+		},
+	),
+	gx.Invoke(
+		func(s *auditman.AuditMan, lc gx.Lifecycle) {
+			lc.AppendJob(gx.Job{
+				Name: "background audit cleanup",
+				OnStart: func(context.Context) error {
+					return s.Run()
+				},
+				OnStop: func(context.Context) error {
+					s.Stop()
+					return nil
+				},
+			})
+		},
+	),
+)
+
 var Jobs = gx.Module("jobs",
 	httpServerJob,
 	backgroundSyncJob,
 	backgroundStatsJob,
+	backgroundExpireJob,
+	backgroundAuditJob,
 )
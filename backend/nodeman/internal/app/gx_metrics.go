@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/gx"
+	"github.com/XRay-Addons/xrayman/common/http/router"
+	"github.com/XRay-Addons/xrayman/common/http/server"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/metrics"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/nodes"
+)
+
+var metricsProvider = gx.Provide(
+	metrics.New,
+)
+
+type NodesMetricsParams struct {
+	gx.In
+	Metrics *metrics.Metrics
+	Storage nodes.Storage
+	Timeout time.Duration `name:"storage-call-timeout"`
+}
+
+var nodesMetrics = gx.Invoke(
+	func(p NodesMetricsParams) error {
+		return p.Metrics.CollectNodes(p.Storage, p.Timeout)
+	},
+)
+
+// separate server keeps metrics away from public endpoint
+var metricsServerJob = gx.Invoke(
+	func(cfg *config.Config, m *metrics.Metrics, lc gx.Lifecycle) error {
+		if cfg.MetricsEndpoint == "" {
+			return nil
+		}
+		r, err := router.New(
+			router.WithHandler(cfg.MetricsPath, m.Handler()))
+		if err != nil {
+			return err
+		}
+		s, err := server.New(cfg.MetricsEndpoint, r)
+		if err != nil {
+			return err
+		}
+		lc.AppendJob(gx.Job{
+			Name: "metrics server",
+			OnStart: func(context.Context) error {
+				return s.Listen()
+			},
+			OnStop: func(ctx context.Context) error {
+				return s.Shutdown(ctx)
+			},
+		})
+		return nil
+	},
+)
+
+var Metrics = gx.Module("metrics",
+	metricsProvider,
+	nodesMetrics,
+	metricsServerJob,
+)
@@ -7,8 +7,10 @@ import (
 	"github.com/XRay-Addons/xrayman/common/gx"
 	client "github.com/XRay-Addons/xrayman/nodeman/internal/clients/node"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/httpclient"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/metrics"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/stats/poolstats"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/poolsync"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/webhook"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/statsman"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/syncman"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/nodes"
@@ -52,21 +54,52 @@ var poolClient = gx.Provide(
 	},
 )
 
+type WebhooksParams struct {
+	gx.In
+	Lc      gx.Lifecycle
+	Storage webhook.Storage
+	Log     *zap.Logger
+}
+
+var webhookDispatcher = gx.ProvideAnnotated(
+	func(p WebhooksParams) (*webhook.Dispatcher, error) {
+		d, err := webhook.New(p.Storage, p.Log)
+		if err != nil {
+			return nil, err
+		}
+		p.Lc.AppendCloser(gx.Closer{
+			Name: "webhooks",
+			OnClose: func(context.Context) error {
+				d.Close()
+				return nil
+			},
+		})
+		return d, nil
+	},
+	gx.As(new(users.Notifier)),
+	gx.As(gx.Self()),
+)
+
 var poolSync = gx.ProvideAnnotated(
-	poolsync.New,
+	func(c poolsync.Client, s poolsync.Storage, m *metrics.Metrics, d *webhook.Dispatcher, l *zap.Logger) (*poolsync.Syncer, error) {
+		return poolsync.New(c, s, l, poolsync.WithMetrics(m), poolsync.WithNotifier(d))
+	},
 	gx.As(new(users.Syncer)),
 	gx.As(new(nodes.Syncer)),
 	gx.As(new(syncman.PoolSyncer)),
 )
 
 var poolStats = gx.ProvideAnnotated(
-	poolstats.New,
+	func(c poolstats.Client, s poolstats.Storage, m *metrics.Metrics, d *webhook.Dispatcher, l *zap.Logger) (*poolstats.Stats, error) {
+		return poolstats.New(c, s, l, poolstats.WithMetrics(m), poolstats.WithNotifier(d))
+	},
 	gx.As(new(statsman.StatsUpdater)),
 )
 
 var Nodes = gx.Module("nodes",
 	httpClient,
 	poolClient,
+	webhookDispatcher,
 	poolSync,
 	poolStats,
 )
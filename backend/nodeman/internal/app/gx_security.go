@@ -1,23 +1,49 @@
 package app
 
 import (
+	"time"
+
 	"github.com/XRay-Addons/xrayman/common/gx"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/jwt"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/lockout"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/totp"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/auth"
 )
 
 var j = gx.Provide(
 	gx.Annotate(
-		func(s string, iss string) (*jwt.JWT, error) {
-			return jwt.New(s, jwt.WithIssuer(iss))
+		func(s string, iss string, ttl time.Duration) (*jwt.JWT, error) {
+			return jwt.New(s, jwt.WithIssuer(iss), jwt.WithTTL(ttl))
 		},
-		gx.ParamTags(`name:"jwt-secret"`, `name:"jwt-issuer"`),
+		gx.ParamTags(`name:"jwt-secret"`, `name:"jwt-issuer"`, `name:"access-token-ttl"`),
 		gx.As(new(auth.JWT)),
 		gx.As(new(security.JWT)),
 	),
 )
 
+var l = gx.Provide(
+	gx.Annotate(
+		func(cfg *config.Config) (*lockout.Lockout, error) {
+			return lockout.New(cfg.LockoutThreshold,
+				cfg.LockoutDuration, cfg.LockoutMaxDuration)
+		},
+		gx.As(new(auth.Lockout)),
+	),
+)
+
+var c = gx.Provide(
+	gx.Annotate(
+		func(cfg *config.Config) (*totp.Cipher, error) {
+			return totp.NewCipher(cfg.TOTPKey)
+		},
+		gx.As(new(auth.SecretCipher)),
+	),
+)
+
 var Security = gx.Module("security",
 	j,
+	l,
+	c,
 )
@@ -3,14 +3,18 @@ package app
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/gx"
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
 	"github.com/XRay-Addons/xrayman/common/http/router"
 	"github.com/XRay-Addons/xrayman/common/http/server"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/api"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/audit"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/metrics"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/pages"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/pages/pagecfg"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/settings"
@@ -28,8 +32,20 @@ var apiHandler = gx.Options(
 		security.New,
 		gx.As(new(genapi.SecurityHandler)),
 	),
+	gx.Provide(
+		audit.New,
+	),
 	gx.ProvideNamed(
-		api.NewHandler,
+		func(h genapi.Handler, s genapi.SecurityHandler, a *audit.Recorder,
+			cfg *config.Config,
+		) (http.Handler, error) {
+			// zero limit disables auth rate limiting
+			var limiter mw.RateLimiter
+			if cfg.AuthRateLimit > 0 {
+				limiter = mw.NewKeyLimiter(cfg.AuthRateLimit, time.Minute, cfg.AuthRateBurst)
+			}
+			return api.NewHandler(h, s, a, limiter)
+		},
 		"api-handler",
 	),
 )
@@ -72,6 +88,7 @@ type RouterParams struct {
 	gx.In
 	Cfg        *config.Config
 	Log        *zap.Logger
+	Metrics    *metrics.Metrics
 	ApiHandler http.Handler `name:"api-handler"`
 	UserPage   *pages.Page  `name:"user-page"`
 	AdminPage  *pages.Page  `name:"admin-page"`
@@ -79,12 +96,16 @@ type RouterParams struct {
 
 var r = gx.ProvideNamed(
 	func(p RouterParams) (http.Handler, error) {
-		return router.New(
+		opts := []router.Option{
 			router.WithHandler(p.Cfg.ApiServicePath, p.ApiHandler),
 			router.WithSPA(p.Cfg.UserSpaPath, p.UserPage),
 			router.WithSPA(p.Cfg.AdminSpaPath, p.AdminPage),
 			router.WithCrossOrigin(p.Cfg.AllowedOrigins),
-			router.WithLogger(p.Log))
+			router.WithTrustedProxies(p.Cfg.TrustedProxies),
+			router.WithMetrics(p.Metrics),
+			router.WithLogger(p.Log),
+		}
+		return router.New(opts...)
 	},
 	"router",
 )
@@ -6,12 +6,17 @@ import (
 
 	"github.com/XRay-Addons/xrayman/common/gx"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/auditman"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/expireman"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/audit"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/auth"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/nodes"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/settings"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/subscr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/tokens"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/users"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/version"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/webhooks"
 	"go.uber.org/zap"
 )
 
@@ -29,10 +34,29 @@ type UsersServiceParams struct {
 	Lc          gx.Lifecycle
 	PoolSyncer  users.Syncer
 	Storage     users.Storage
+	Notifier    users.Notifier
 	SyncTimeout time.Duration `name:"service-sync-timeout"`
+	LegacyLinks bool          `name:"legacy-links"`
 	Log         *zap.Logger
 }
 
+type SubscrServiceParams struct {
+	gx.In
+	Storage     subscr.Storage
+	LegacyLinks bool `name:"legacy-links"`
+	Log         *zap.Logger
+}
+
+type AuthServiceParams struct {
+	gx.In
+	Storage    auth.Storage
+	JWT        auth.JWT
+	Lockout    auth.Lockout
+	Cipher     auth.SecretCipher
+	RefreshTTL time.Duration `name:"refresh-token-ttl"`
+	Log        *zap.Logger
+}
+
 var Services = gx.Module("services",
 	gx.ProvideAnnotated(
 		func(p NodesServiceParams) (*nodes.Service, error) {
@@ -53,7 +77,8 @@ var Services = gx.Module("services",
 	),
 	gx.ProvideAnnotated(
 		func(p UsersServiceParams) (*users.Service, error) {
-			us, err := users.New(p.PoolSyncer, p.Storage, p.SyncTimeout, p.Log)
+			us, err := users.New(p.PoolSyncer, p.Storage, p.Notifier, p.SyncTimeout, p.Log,
+				users.WithLegacyLinks(p.LegacyLinks))
 			if err != nil {
 				return nil, err
 			}
@@ -67,9 +92,14 @@ var Services = gx.Module("services",
 			return us, nil
 		},
 		gx.As(new(handler.UsersService)),
+		gx.As(new(expireman.UsersExpirer)),
 	),
 	gx.ProvideAnnotated(
-		subscr.New,
+		func(p SubscrServiceParams) (*subscr.Service, error) {
+			return subscr.New(p.Storage,
+				subscr.WithLegacyLinks(p.LegacyLinks),
+				subscr.WithLogger(p.Log))
+		},
 		gx.As(new(handler.SubscrService)),
 		gx.As(gx.Self()),
 	),
@@ -79,10 +109,25 @@ var Services = gx.Module("services",
 		gx.As(gx.Self()),
 	),
 	gx.ProvideAnnotated(
-		auth.New,
+		func(p AuthServiceParams) (*auth.Service, error) {
+			return auth.New(p.Storage, p.JWT, p.Lockout, p.Cipher, p.RefreshTTL, p.Log)
+		},
 		gx.As(new(handler.AuthService)),
 		gx.As(gx.Self()),
 	),
+	gx.ProvideAnnotated(
+		tokens.New,
+		gx.As(new(handler.TokensService)),
+	),
+	gx.ProvideAnnotated(
+		webhooks.New,
+		gx.As(new(handler.WebhooksService)),
+	),
+	gx.ProvideAnnotated(
+		audit.New,
+		gx.As(new(handler.AuditService)),
+		gx.As(new(auditman.AuditCleaner)),
+	),
 	gx.ProvideAnnotated(
 		version.New,
 		gx.As(new(handler.VersionService)),
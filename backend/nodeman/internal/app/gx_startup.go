@@ -16,6 +16,10 @@ var startupLog = gx.Invoke(
 			cfg.UserSpaPath, cfg.UserSpaUrl))
 		log.Warn(fmt.Sprintf("admin page available on %s via %s",
 			cfg.AdminSpaPath, cfg.AdminSpaUrl))
+		if cfg.MetricsEndpoint != "" {
+			log.Warn(fmt.Sprintf("metrics available on %s%s",
+				cfg.MetricsEndpoint, cfg.MetricsPath))
+		}
 	},
 )
 
@@ -8,13 +8,20 @@ import (
 	"github.com/XRay-Addons/xrayman/nodeman/internal/config"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqldb"
+	httpaudit "github.com/XRay-Addons/xrayman/nodeman/internal/http/audit"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/metrics"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/stats/poolstats"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/poolsync"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/webhook"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/audit"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/auth"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/nodes"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/settings"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/subscr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/tokens"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/users"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/webhooks"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -40,6 +47,7 @@ type StorageParams struct {
 	fx.In
 	DB      dbstorage.DB
 	Timeout time.Duration `name:"storage-call-timeout"`
+	Metrics *metrics.Metrics
 	Log     *zap.Logger
 }
 
@@ -47,6 +55,7 @@ var storage = gx.ProvideAnnotated(
 	func(p StorageParams) (*dbstorage.Storage, error) {
 		return dbstorage.New(p.DB,
 			dbstorage.WithTimeout(p.Timeout),
+			dbstorage.WithMetrics(p.Metrics),
 			dbstorage.WithLogger(p.Log))
 	},
 	gx.As(new(users.Storage)),
@@ -56,6 +65,13 @@ var storage = gx.ProvideAnnotated(
 	gx.As(new(poolstats.Storage)),
 	gx.As(new(settings.Storage)),
 	gx.As(new(auth.Storage)),
+	gx.As(new(security.Storage)),
+	gx.As(new(tokens.Storage)),
+	gx.As(new(audit.Storage)),
+	gx.As(new(httpaudit.Storage)),
+	gx.As(new(httpaudit.StateStorage)),
+	gx.As(new(webhook.Storage)),
+	gx.As(new(webhooks.Storage)),
 	gx.As(gx.Self()),
 )
 
@@ -10,11 +10,13 @@ import (
 // goverter:converter
 // goverter:output:format function
 // goverter:output:file ./converter_generated.go
+// goverter:extend ConvertOptString
 //
 //go:generate goverter gen .
 type Converter interface {
 	ConvertUsers(users []models.UserProfile) []api.User
 	ConvertStartResponse(cfg api.StartResponse) models.NodeSettings
+	ConvertReloadResponse(cfg api.ReloadResponse) models.NodeSettings
 	ConvertUsersUpdate(users models.NodeUsersUpdate) api.EditUsersRequest
 	ConvertNodeStats(stats *api.StatsResponse) *models.NodeStats
 }
@@ -31,3 +33,11 @@ func ConvertNodeStatus(s api.ServiceStatus) models.NodeStatus {
 		panic(fmt.Sprintf("unexpected enum element: %v", s))
 	}
 }
+
+// empty strings are omitted, e.g. user email which nodes generate then
+func ConvertOptString(s string) api.OptString {
+	if s == "" {
+		return api.OptString{}
+	}
+	return api.NewOptString(s)
+}
@@ -2,6 +2,8 @@ package node
 
 import (
 	"context"
+	"errors"
+	"net/http"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	api "github.com/XRay-Addons/xrayman/node/pkg/api/http/openapi-gen"
@@ -44,6 +46,54 @@ func (c *NodeClient) Stop(ctx context.Context) error {
 	return nil
 }
 
+func (c *NodeClient) Reload(ctx context.Context) (*models.NodeSettings, error) {
+	if c == nil || c.client == nil {
+		return nil, errdefs.NilCall()
+	}
+
+	reloadResponse, err := c.client.Reload(ctx)
+	if err != nil {
+		return nil, wrapOgenErr(err)
+	}
+	nodeSettings := converter.ConvertReloadResponse(*reloadResponse)
+	return &nodeSettings, nil
+}
+
+func (c *NodeClient) PushConfig(ctx context.Context, cfg models.NodeXRayConfig) (
+	*models.NodeSettings, error,
+) {
+	if c == nil || c.client == nil {
+		return nil, errdefs.NilCall()
+	}
+
+	pushRequest := api.PushConfigsRequest{
+		ServerConfig: cfg.ServerConfig,
+		ClientConfig: cfg.ClientConfig,
+	}
+	pushResponse, err := c.client.PushConfigs(ctx, &pushRequest)
+	if err != nil {
+		return nil, wrapConfigErr(err)
+	}
+	nodeSettings := converter.ConvertReloadResponse(*pushResponse)
+	return &nodeSettings, nil
+}
+
+// ValidateConfig checks config on node without applying it
+func (c *NodeClient) ValidateConfig(ctx context.Context, cfg models.NodeXRayConfig) error {
+	if c == nil || c.client == nil {
+		return errdefs.NilCall()
+	}
+
+	validateRequest := api.PushConfigsRequest{
+		ServerConfig: cfg.ServerConfig,
+		ClientConfig: cfg.ClientConfig,
+	}
+	if err := c.client.ValidateConfigs(ctx, &validateRequest); err != nil {
+		return wrapConfigErr(err)
+	}
+	return nil
+}
+
 func (c *NodeClient) CheckStatus(ctx context.Context) (models.NodeStatus, error) {
 	if c == nil || c.client == nil {
 		return models.NodeStatusUnknown, errdefs.NilCall()
@@ -82,6 +132,17 @@ func (c *NodeClient) GetStats(ctx context.Context) (*models.NodeStats, error) {
 	return stats, nil
 }
 
+// config rejected by node is payload error, not connection one,
+// pushing it again makes no sense
+func wrapConfigErr(err error) error {
+	var statusErr *api.ErrorStatusCode
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+		return errdefs.PayloadErr(xerr.Newf("node rejected config: %s",
+			statusErr.Response.Details.Value))
+	}
+	return wrapOgenErr(err)
+}
+
 func wrapOgenErr(err error) error {
 	return xerr.Wrap(err, xerr.WithStack(), xerr.WithType(errdefs.ErrConnection))
 }
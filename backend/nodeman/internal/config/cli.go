@@ -13,10 +13,19 @@ var kongVars = kong.Vars{
 
 	"jwtHelp": "jwt secret",
 
+	"totpKeyHelp": `key encrypting stored admin totp secrets (optional, jwt secret if empty).
+changing it requires totp re-enrolment`,
+
+	"accessTTLHelp": "admin access token lifetime, s",
+
+	"refreshTTLHelp": "admin session lifetime without refresh, s",
+
 	"stateHelp": "state sync interval, s",
 
 	"statsHelp": "stats sync interval, s",
 
+	"expireHelp": "expired users check interval, s",
+
 	"apisrvHelp": `public base URL of the API as seen by browsers (used for CORS and SPAs config).
 If empty or relative, the internal API base path is used.
 should be like /internal/api or https://api.example.com/api (optional)`,
@@ -34,11 +43,80 @@ should be like /admin or https://adm.example.com (optional)`,
 	"storageTimeoutHelp": `storage call timeout, s (optional)`,
 
 	"nodeTimeoutHelp": `node call timeout, s (optional)`,
+
+	"proxiesHelp": `comma separated ip addresses or cidrs of reverse proxies
+whose X-Forwarded-For and X-Real-IP headers are trusted (optional)`,
+
+	"authRateHelp": `auth requests per minute per client ip, 0 for unlimited`,
+
+	"authBurstHelp": `auth requests burst per client ip`,
+
+	"lockoutHelp": `failed logins per login and client ip before lockout, 0 for disable`,
+
+	"lockoutDurationHelp": `first lockout duration, s. doubled for every next failure`,
+
+	"lockoutMaxHelp": `max lockout duration, s`,
+
+	"legacyLinksHelp": `accept legacy /sub/<id>-<name> and /user/<id>-<name> links
+along with subscription tokens, insecure, use for migration only (optional)`,
+
+	"metricsHelp": `prometheus metrics endpoint tcp address, like 127.0.0.1:9100.
+metrics are served on /metrics, empty for disable (optional)`,
+
+	"cliApiHelp": "nodeman api base URL, like https://example.com/api",
+
+	"cliTokenHelp": `api token with users:read scope for export
+and users:write scope for import and migrate`,
+
+	"importFileHelp": `users file, csv or json by extension.
+json export of users is accepted as is`,
+
+	"exportOutHelp": "output file, - for stdout",
+
+	"migrateFileHelp": `xray server config, 3x-ui inbounds or marzban users json.
+3x-ui inbounds are its inbounds list api response or
+sqlite3 -json x-ui.db "select * from inbounds" output,
+marzban users are its users list api response.
+skipped clients and changed settings are reported to stderr`,
+
+	"migrateFromHelp": "migrated setup kind",
+}
+
+// commands, as reported by kong
+const (
+	ServeCommand        = "serve"
+	UsersImportCommand  = "users import <file>"
+	UsersExportCommand  = "users export"
+	UsersMigrateCommand = "users migrate <file>"
+)
+
+// bulk users operations via nodeman api
+type UsersCmd struct {
+	API   string `name:"api" env:"NODEMAN_API_URL" required:"" help:"${cliApiHelp}"`
+	Token string `name:"token" env:"NODEMAN_API_TOKEN" required:"" help:"${cliTokenHelp}"`
+
+	Import struct {
+		File string `arg:"" type:"existingfile" help:"${importFileHelp}"`
+	} `cmd:"" help:"Import users from csv or json file."`
+
+	Export struct {
+		Format string `name:"format" enum:"json,csv" default:"json" help:"export format"`
+		Out    string `name:"out" short:"o" default:"-" help:"${exportOutHelp}"`
+	} `cmd:"" help:"Export users with traffic to csv or json."`
+
+	Migrate struct {
+		File string `arg:"" type:"existingfile" help:"${migrateFileHelp}"`
+		From string `name:"from" enum:"xray,3x-ui,marzban" required:"" help:"${migrateFromHelp}"`
+	} `cmd:"" help:"Import users from xray config or other panel keeping their credentials."`
 }
 
 type CLI struct {
 	DBConn    string `name:"db" env:"DBCONN" help:"${dbHelp}"`
 	JwtSecret string `name:"jwt" env:"JWT_SECRET" help:"${jwtHelp}"`
+	TOTPKey   string `name:"totp-key" env:"TOTP_KEY" help:"${totpKeyHelp}"`
+
+	AccessTokenTTL  int `name:"access-ttl" env:"ACCESS_TOKEN_TTL" default:"900" help:"${accessTTLHelp}"`
+	RefreshTokenTTL int `name:"refresh-ttl" env:"REFRESH_TOKEN_TTL" default:"2592000" help:"${refreshTTLHelp}"`
 
 	Endpoint      string `name:"endpoint" env:"ENDPOINT" default:"localhost:80" help:"${endpointHelp}"`
 	AdminPassword string `name:"admpass" env:"ADMIN_PASSWORD" default:"" help:"${admpassHelp}"`
@@ -47,15 +125,34 @@ type CLI struct {
 	UserSpaUrl    string `name:"userspa" env:"USER_SPA_URL" default:"" help:"${userspaHelp}"`
 	AdminSpaUrl   string `name:"adminspa" env:"ADMIN_SPA_URL" default:"" help:"${adminspaHelp}"`
 
-	StateSyncInterval int `name:"state" env:"STATE_SYNC_INTERVAL" default:"5" help:"${stateHelp}"`
-	StatsSyncInterval int `name:"stats" env:"STATS_SYNC_INTERVAL" default:"60" help:"${statsHelp}"`
+	StateSyncInterval   int `name:"state" env:"STATE_SYNC_INTERVAL" default:"5" help:"${stateHelp}"`
+	StatsSyncInterval   int `name:"stats" env:"STATS_SYNC_INTERVAL" default:"60" help:"${statsHelp}"`
+	ExpireCheckInterval int `name:"expire" env:"EXPIRE_CHECK_INTERVAL" default:"60" help:"${expireHelp}"`
 
 	NodeCallTimeout    int `name:"node-timeout" env:"NODE_CALL_TIMEOUT" default:"5" help:"${nodeTimeoutHelp}"`
 	StorageCallTimeout int `name:"storage-timeout" env:"STORAGE_CALL_TIMEOUT" default:"5" help:"${storageTimeoutHelp}"`
 
+	TrustedProxies []string `name:"trusted-proxies" env:"TRUSTED_PROXIES" sep:"," help:"${proxiesHelp}"`
+
+	AuthRateLimit      int `name:"auth-rate" env:"AUTH_RATE_LIMIT" default:"10" help:"${authRateHelp}"`
+	AuthRateBurst      int `name:"auth-burst" env:"AUTH_RATE_BURST" default:"5" help:"${authBurstHelp}"`
+	LockoutThreshold   int `name:"lockout" env:"LOCKOUT_THRESHOLD" default:"5" help:"${lockoutHelp}"`
+	LockoutDuration    int `name:"lockout-duration" env:"LOCKOUT_DURATION" default:"60" help:"${lockoutDurationHelp}"`
+	LockoutMaxDuration int `name:"lockout-max" env:"LOCKOUT_MAX_DURATION" default:"3600" help:"${lockoutMaxHelp}"`
+
+	LegacyLinks bool `name:"legacy-links" env:"LEGACY_LINKS" default:"false" help:"${legacyLinksHelp}"`
+
+	MetricsEndpoint string `name:"metrics" env:"METRICS_ENDPOINT" default:"" help:"${metricsHelp}"`
+
 	LogLevel zapcore.Level `name:"log-lvl" env:"LOG_LEVEL" default:"info" help:"zap log level"`
 
 	Version bool `short:"v" help:"Show version and exit."`
+
+	Serve struct{} `cmd:"" default:"1" help:"Run nodeman server."`
+	Users UsersCmd `cmd:"" help:"Bulk users import and export."`
+
+	// selected command
+	Command string `kong:"-"`
 }
 
 func LoadCLI() (*CLI, error) {
@@ -70,6 +167,7 @@ func LoadCLI() (*CLI, error) {
 	if err := ctx.Validate(); err != nil {
 		return nil, xerr.WrapWithStack(err)
 	}
+	cli.Command = ctx.Command()
 
 	return &cli, nil
 }
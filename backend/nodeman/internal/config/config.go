@@ -1,7 +1,9 @@
 package config
 
 import (
+	"net/netip"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
@@ -13,6 +15,11 @@ type Config struct {
 	DBConn        string
 	AdminPassword string
 	JwtSecret     string
+	// encrypts stored totp secrets
+	TOTPKey string
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 
 	NodeCallTimeout    time.Duration
 	StorageCallTimeout time.Duration
@@ -25,8 +32,24 @@ type Config struct {
 	UserSpaUrl    string
 	AdminSpaUrl   string
 
-	StateSyncInterval time.Duration
-	StatsSyncInterval time.Duration
+	StateSyncInterval   time.Duration
+	StatsSyncInterval   time.Duration
+	ExpireCheckInterval time.Duration
+
+	TrustedProxies []netip.Prefix
+
+	// per minute, zero means unlimited
+	AuthRateLimit      int
+	AuthRateBurst      int
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	LockoutMaxDuration time.Duration
+
+	// accept "<id>-<name>" user keys in public links
+	LegacyLinks bool
+
+	MetricsEndpoint string
+	MetricsPath     string
 
 	AllowedOrigins []string
 	LogLevel       zapcore.Level
@@ -36,6 +59,7 @@ const (
 	apiServicePath = "/api"
 	userSpaPath    = "/u"
 	adminSpaPath   = "/adm"
+	metricsPath    = "/metrics"
 )
 
 func NewConfig(cli *CLI) (*Config, error) {
@@ -58,9 +82,12 @@ func loadConfig(cli *CLI) (*Config, error) {
 		DBConn:            cli.DBConn,
 		AdminPassword:     cli.AdminPassword,
 		JwtSecret:         cli.JwtSecret,
+		TOTPKey:           or(cli.TOTPKey, cli.JwtSecret),
 		StateSyncInterval: time.Duration(cli.StateSyncInterval) * time.Second,
 		StatsSyncInterval: time.Duration(cli.StatsSyncInterval) * time.Second,
 
+		ExpireCheckInterval: time.Duration(cli.ExpireCheckInterval) * time.Second,
+
 		ApiServicePath: apiServicePath,
 		UserSpaPath:    userSpaPath,
 		AdminSpaPath:   adminSpaPath,
@@ -68,6 +95,20 @@ func loadConfig(cli *CLI) (*Config, error) {
 		NodeCallTimeout:    time.Duration(cli.NodeCallTimeout) * time.Second,
 		StorageCallTimeout: time.Duration(cli.StorageCallTimeout) * time.Second,
 
+		AccessTokenTTL:  time.Duration(cli.AccessTokenTTL) * time.Second,
+		RefreshTokenTTL: time.Duration(cli.RefreshTokenTTL) * time.Second,
+
+		AuthRateLimit:      cli.AuthRateLimit,
+		AuthRateBurst:      cli.AuthRateBurst,
+		LockoutThreshold:   cli.LockoutThreshold,
+		LockoutDuration:    time.Duration(cli.LockoutDuration) * time.Second,
+		LockoutMaxDuration: time.Duration(cli.LockoutMaxDuration) * time.Second,
+
+		LegacyLinks: cli.LegacyLinks,
+
+		MetricsEndpoint: cli.MetricsEndpoint,
+		MetricsPath:     metricsPath,
+
 		LogLevel: cli.LogLevel,
 	}
 
@@ -85,9 +126,31 @@ func loadConfig(cli *CLI) (*Config, error) {
 		}
 	}
 
+	for _, p := range cli.TrustedProxies {
+		prefix, err := parsePrefix(p)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix)
+	}
+
 	return &cfg, nil
 }
 
+// parse cidr or single ip
+func parsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, xerr.Newf("invalid trusted proxy: %s", s)
+	}
+	ip = ip.Unmap()
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
+
 func or(a string, b string) string {
 	if a != "" {
 		return a
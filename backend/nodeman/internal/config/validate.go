@@ -11,6 +11,11 @@ func Validate(c *Config) error {
 	if _, err := net.ResolveTCPAddr("tcp", c.Endpoint); err != nil {
 		return xerr.Newf("invalid endpoint: %s", c.Endpoint)
 	}
+	if c.MetricsEndpoint != "" {
+		if _, err := net.ResolveTCPAddr("tcp", c.MetricsEndpoint); err != nil {
+			return xerr.Newf("invalid metrics endpoint: %s", c.MetricsEndpoint)
+		}
+	}
 	if err := checkDBConn(c); err != nil {
 		return err
 	}
@@ -23,6 +28,9 @@ func Validate(c *Config) error {
 	if err := checkAuth(c); err != nil {
 		return err
 	}
+	if err := checkAuthLimits(c); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -64,6 +72,9 @@ func checkSyncIntervals(c *Config) error {
 	if c.StatsSyncInterval <= 0 {
 		return xerr.New("stats sync interval invalid")
 	}
+	if c.ExpireCheckInterval <= 0 {
+		return xerr.New("expire check interval invalid")
+	}
 	return nil
 }
 
@@ -71,5 +82,28 @@ func checkAuth(c *Config) error {
 	if c.JwtSecret == "" {
 		return xerr.New("jwt secret invalid")
 	}
+	if c.AccessTokenTTL <= 0 {
+		return xerr.New("access token ttl invalid")
+	}
+	if c.RefreshTokenTTL < c.AccessTokenTTL {
+		return xerr.New("refresh token ttl invalid")
+	}
+	return nil
+}
+
+func checkAuthLimits(c *Config) error {
+	if c.AuthRateLimit < 0 {
+		return xerr.New("auth rate limit invalid")
+	}
+	if c.AuthRateLimit > 0 && c.AuthRateBurst <= 0 {
+		return xerr.New("auth rate burst invalid")
+	}
+	if c.LockoutThreshold < 0 {
+		return xerr.New("lockout threshold invalid")
+	}
+	if c.LockoutThreshold > 0 &&
+		(c.LockoutDuration <= 0 || c.LockoutMaxDuration < c.LockoutDuration) {
+		return xerr.New("lockout duration invalid")
+	}
 	return nil
 }
@@ -0,0 +1,121 @@
+package dbstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) NewSession(ctx context.Context,
+	session *models.Session, refreshHash []byte,
+) error {
+	// pre-convert
+	req := queries.NewAdminSessionParams{
+		AdminID:     int64(session.AdminID),
+		RefreshHash: refreshHash,
+		ClientIp:    session.ClientIP,
+		ExpiresAt:   session.ExpiresAt,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.NewAdminSessionRow, error) {
+		return q.NewAdminSession(ctx, req)
+	})
+	if err != nil {
+		return err
+	}
+
+	// post-convert
+	session.ID = models.SessionID(resp.SessionID)
+	session.CreatedAt = resp.CreatedAt
+	session.RefreshedAt = resp.RefreshedAt
+
+	return nil
+}
+
+func (s *Storage) GetSession(ctx context.Context, id models.SessionID) (
+	*models.Session, error,
+) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.GetAdminSessionRow, error) {
+		return q.GetAdminSession(ctx, int64(id))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.GetAdminSessionResp(&resp), nil
+}
+
+func (s *Storage) RotateSession(ctx context.Context,
+	refreshHash, newRefreshHash []byte, clientIP string, expiresAt time.Time,
+) (*models.Session, error) {
+	// pre-convert
+	req := queries.RotateAdminSessionParams{
+		NewRefreshHash: newRefreshHash,
+		ClientIp:       clientIP,
+		ExpiresAt:      expiresAt,
+		RefreshHash:    refreshHash,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.RotateAdminSessionRow, error) {
+		return q.RotateAdminSession(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.RotateAdminSessionResp(&resp), nil
+}
+
+func (s *Storage) ListSessions(ctx context.Context, adminID models.AdminID) (
+	[]models.Session, error,
+) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListAdminSessionsRow, error) {
+		return q.ListAdminSessions(ctx, int64(adminID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListAdminSessionsResp(resp), nil
+}
+
+func (s *Storage) RevokeSession(ctx context.Context,
+	adminID models.AdminID, id models.SessionID,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.RevokeAdminSession(ctx, queries.RevokeAdminSessionParams{
+			SessionID: int64(id),
+			AdminID:   int64(adminID),
+		})
+	})
+}
+
+func (s *Storage) RevokeAdminSessions(ctx context.Context, adminID models.AdminID) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.RevokeAdminSessions(ctx, int64(adminID))
+	})
+}
+
+func (s *Storage) RevokeAllSessions(ctx context.Context) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.RevokeAllAdminSessions(ctx)
+	})
+}
@@ -0,0 +1,85 @@
+package dbstorage
+
+import (
+	"context"
+	"database/sql"
+
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) SetAdminTOTPSecret(ctx context.Context,
+	id models.AdminID, secret string,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.SetAdminTOTPSecret(ctx, queries.SetAdminTOTPSecretParams{
+			TotpSecret: sql.NullString{String: secret, Valid: true},
+			AdminID:    int64(id),
+		})
+	})
+}
+
+func (s *Storage) EnableAdminTOTP(ctx context.Context,
+	id models.AdminID, recoveryCodeHashes [][]byte,
+) error {
+	return s.DoTx(ctx, func(ctx context.Context) error {
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.EnableAdminTOTP(ctx, int64(id))
+		}); err != nil {
+			return err
+		}
+		// new enrolment invalidates previous recovery codes
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.DeleteRecoveryCodes(ctx, int64(id))
+		}); err != nil {
+			return err
+		}
+		return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.InsertRecoveryCodes(ctx, queries.InsertRecoveryCodesParams{
+				AdminID:    int64(id),
+				CodeHashes: recoveryCodeHashes,
+			})
+		})
+	})
+}
+
+func (s *Storage) DisableAdminTOTP(ctx context.Context, id models.AdminID) error {
+	return s.DoTx(ctx, func(ctx context.Context) error {
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.DisableAdminTOTP(ctx, int64(id))
+		}); err != nil {
+			return err
+		}
+		return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.DeleteRecoveryCodes(ctx, int64(id))
+		})
+	})
+}
+
+func (s *Storage) UseRecoveryCode(ctx context.Context,
+	id models.AdminID, codeHash []byte,
+) error {
+	_, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.UseRecoveryCode(ctx, queries.UseRecoveryCodeParams{
+			AdminID:  int64(id),
+			CodeHash: codeHash,
+		})
+	})
+	return err
+}
+
+func (s *Storage) UseAdminTOTPStep(ctx context.Context,
+	id models.AdminID, step int64,
+) error {
+	_, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.UseAdminTOTPStep(ctx, queries.UseAdminTOTPStepParams{
+			AdminID: int64(id),
+			Step:    step,
+		})
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+package dbstorage
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) NewAdmin(ctx context.Context,
+	admin *models.Admin, passwordHash []byte,
+) error {
+	// pre-convert
+	req := queries.NewAdminParams{
+		AdminName:    admin.Name,
+		AdminRole:    int16(admin.Role),
+		PasswordHash: passwordHash,
+	}
+
+	// request
+	id, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.NewAdmin(ctx, req)
+	})
+	if err != nil {
+		return err
+	}
+
+	// post-convert
+	admin.ID = models.AdminID(id)
+
+	return nil
+}
+
+func (s *Storage) GetAdmin(ctx context.Context, id models.AdminID) (
+	*models.Admin, error,
+) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.GetAdminRow, error) {
+		return q.GetAdmin(ctx, int64(id))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.GetAdminResp(&resp), nil
+}
+
+func (s *Storage) ListAdmins(ctx context.Context) ([]models.Admin, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListAdminsRow, error) {
+		return q.ListAdmins(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListAdminsResp(resp), nil
+}
+
+func (s *Storage) SetAdminRole(ctx context.Context,
+	id models.AdminID, role models.AdminRole,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.SetAdminRole(ctx, queries.SetAdminRoleParams{
+			AdminRole: int16(role),
+			AdminID:   int64(id),
+		})
+	})
+}
+
+func (s *Storage) SetAdminPassword(ctx context.Context,
+	id models.AdminID, passwordHash []byte,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.SetAdminPassword(ctx, queries.SetAdminPasswordParams{
+			PasswordHash: passwordHash,
+			AdminID:      int64(id),
+		})
+	})
+}
+
+func (s *Storage) DeleteAdmin(ctx context.Context, id models.AdminID) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.DeleteAdmin(ctx, int64(id))
+	})
+}
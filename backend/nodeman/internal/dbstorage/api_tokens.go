@@ -0,0 +1,74 @@
+package dbstorage
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) NewApiToken(ctx context.Context,
+	token *models.ApiToken, tokenHash []byte,
+) error {
+	// pre-convert
+	req := queries.NewApiTokenParams{
+		TokenName: token.Name,
+		TokenHash: tokenHash,
+		Scopes:    convert.ApiTokenScopesReq(token.Scopes),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.NewApiTokenRow, error) {
+		return q.NewApiToken(ctx, req)
+	})
+	if err != nil {
+		return err
+	}
+
+	// post-convert
+	token.ID = models.ApiTokenID(resp.TokenID)
+	token.CreatedAt = resp.CreatedAt
+
+	return nil
+}
+
+func (s *Storage) GetApiToken(ctx context.Context, tokenHash []byte) (
+	*models.ApiToken, error,
+) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.GetApiTokenByHashRow, error) {
+		return q.GetApiTokenByHash(ctx, tokenHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.GetApiTokenResp(&resp), nil
+}
+
+func (s *Storage) ListApiTokens(ctx context.Context) ([]models.ApiToken, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListApiTokensRow, error) {
+		return q.ListApiTokens(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListApiTokensResp(resp), nil
+}
+
+func (s *Storage) RevokeApiToken(ctx context.Context, id models.ApiTokenID) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.RevokeApiToken(ctx, int64(id))
+	})
+}
@@ -0,0 +1,46 @@
+package dbstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) RecordAuditEvent(ctx context.Context, e *models.AuditEvent) error {
+	// pre-convert
+	req := convert.RecordAuditEventReq(e)
+
+	// request
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.RecordAuditEvent(ctx, req)
+	})
+}
+
+func (s *Storage) ListAuditEvents(ctx context.Context,
+	p models.ListAuditEventsParams,
+) ([]models.AuditEvent, error) {
+	// pre-convert
+	req := convert.ListAuditEventsReq(p)
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.AuditEvent, error) {
+		return q.ListAuditEvents(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListAuditEventsResp(resp), nil
+}
+
+func (s *Storage) DeleteAuditEventsBefore(ctx context.Context, before time.Time) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.DeleteAuditEventsBefore(ctx, before)
+	})
+}
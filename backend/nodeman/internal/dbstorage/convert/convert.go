@@ -1,5 +1,10 @@
 package convert
 
+import (
+	"database/sql"
+	"time"
+)
+
 type withFn[From any, To any] = func(f *From, t *To)
 
 type withEFn[From any, To any] = func(f *From, t *To) error
@@ -50,3 +55,15 @@ func cnvArrNoErr[From any, To any](from []From, w withFn[From, To]) []To {
 
 	return to
 }
+
+// zero time is stored as NULL
+func NullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func fromNullTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time
+}
@@ -1,6 +1,13 @@
 package convert
 
 import (
+	"cmp"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
 	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
@@ -32,6 +39,9 @@ func GetNodeResp(r *queries.GetNodeRow) (*models.Node, error) {
 			to.TargetStatus = models.NodeStatus(from.NodeTargetStatus)
 			to.Config.ConnectionInfo.Endpoint = from.NodeEndpoint
 			to.Config.Settings.Version = from.Version
+			to.CurrentConfigVersion = from.CurrentConfigVersion
+			to.TargetConfigVersion = from.TargetConfigVersion
+			to.FailedConfigVersion = from.FailedConfigVersion
 		},
 		func(from *queries.GetNodeRow, to *models.Node) error {
 			return to.Config.ConnectionInfo.AccessKey.Scan(from.NodeAccessKey)
@@ -49,7 +59,9 @@ func ListNodesResp(r []queries.ListNodesRow) ([]models.Node, error) {
 			to.TargetStatus = models.NodeStatus(from.NodeTargetStatus)
 			to.Config.ConnectionInfo.Endpoint = from.NodeEndpoint
 			to.Config.Settings.Version = from.Version
-
+			to.CurrentConfigVersion = from.CurrentConfigVersion
+			to.TargetConfigVersion = from.TargetConfigVersion
+			to.FailedConfigVersion = from.FailedConfigVersion
 		},
 		func(from *queries.ListNodesRow, to *models.Node) error {
 			return to.Config.ConnectionInfo.AccessKey.Scan(from.NodeAccessKey)
@@ -73,6 +85,15 @@ func SetNodeSettingsReq(id models.NodeID,
 	}, nil
 }
 
+func NodeConfigResp(r *queries.GetNodeConfigRow) *models.NodeXRayConfig {
+	return cnvNoErr(r,
+		func(from *queries.GetNodeConfigRow, to *models.NodeXRayConfig) {
+			to.Version = from.ConfigVersion
+			to.ServerConfig = from.ServerConfig
+			to.ClientConfig = from.ClientConfig
+		})
+}
+
 func NewUserReq(r *models.User) *queries.NewUserParams {
 	return cnvNoErr(r,
 		func(from *models.User, to *queries.NewUserParams) {
@@ -80,6 +101,11 @@ func NewUserReq(r *models.User) *queries.NewUserParams {
 			to.UserName = from.Profile.Name
 			to.UserTargetStatus = int16(from.TargetStatus)
 			to.VlessUuid = from.Profile.VlessUUID
+			to.Email = from.Profile.Email
+			to.QuotaBytes = from.Quota.Limit
+			to.QuotaPeriod = int16(from.Quota.Period)
+			to.ExpiresAt = NullTime(from.ExpiresAt)
+			to.IpLimit = int32(from.IPLimit)
 		})
 }
 
@@ -90,11 +116,19 @@ func GetUserViewResp(r *queries.GetUserViewRow) *models.UserView {
 			to.User.Profile.Name = from.UserName
 			to.User.Profile.DisplayName = from.DisplayName
 			to.User.Profile.VlessUUID = from.VlessUuid
+			to.User.Profile.SubToken = from.SubToken
+			to.User.Profile.Email = from.Email
 			to.User.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.User.Quota.Limit = from.QuotaBytes
+			to.User.Quota.Period = models.TrafficQuotaPeriod(from.QuotaPeriod)
+			to.User.ExpiresAt = fromNullTime(from.ExpiresAt)
+			to.User.IPLimit = int(from.IpLimit)
 			to.Traffic.Total.Download = from.DownloadTotal
 			to.Traffic.Total.Upload = from.UploadTotal
 			to.Traffic.LastMonth.Download = from.DownloadLastDays
 			to.Traffic.LastMonth.Upload = from.UploadLastDays
+			to.Traffic.CurrentPeriod.Upload = from.UploadPeriod
+			to.Traffic.CurrentPeriod.Download = from.DownloadPeriod
 		})
 }
 
@@ -105,7 +139,49 @@ func ListUsersResp(r []queries.ListUsersRow) []models.User {
 			to.Profile.Name = from.UserName
 			to.Profile.DisplayName = from.DisplayName
 			to.Profile.VlessUUID = from.VlessUuid
+			to.Profile.SubToken = from.SubToken
+			to.Profile.Email = from.Email
+			to.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.Quota.Limit = from.QuotaBytes
+			to.Quota.Period = models.TrafficQuotaPeriod(from.QuotaPeriod)
+			to.ExpiresAt = fromNullTime(from.ExpiresAt)
+			to.IPLimit = int(from.IpLimit)
+		},
+	)
+}
+
+func GetUserResp(r *queries.GetUserRow) *models.User {
+	return cnvNoErr(r,
+		func(from *queries.GetUserRow, to *models.User) {
+			to.Profile.ID = models.UserID(from.UserID)
+			to.Profile.Name = from.UserName
+			to.Profile.DisplayName = from.DisplayName
+			to.Profile.VlessUUID = from.VlessUuid
+			to.Profile.SubToken = from.SubToken
+			to.Profile.Email = from.Email
+			to.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.Quota.Limit = from.QuotaBytes
+			to.Quota.Period = models.TrafficQuotaPeriod(from.QuotaPeriod)
+			to.ExpiresAt = fromNullTime(from.ExpiresAt)
+			to.IPLimit = int(from.IpLimit)
+		},
+	)
+}
+
+func DisableExpiredUsersResp(r []queries.DisableExpiredUsersRow) []models.User {
+	return cnvArrNoErr(r,
+		func(from *queries.DisableExpiredUsersRow, to *models.User) {
+			to.Profile.ID = models.UserID(from.UserID)
+			to.Profile.Name = from.UserName
+			to.Profile.DisplayName = from.DisplayName
+			to.Profile.VlessUUID = from.VlessUuid
+			to.Profile.SubToken = from.SubToken
+			to.Profile.Email = from.Email
 			to.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.Quota.Limit = from.QuotaBytes
+			to.Quota.Period = models.TrafficQuotaPeriod(from.QuotaPeriod)
+			to.ExpiresAt = fromNullTime(from.ExpiresAt)
+			to.IPLimit = int(from.IpLimit)
 		},
 	)
 }
@@ -117,11 +193,37 @@ func ListUserViewsResp(r []queries.ListUserViewsRow) []models.UserView {
 			to.User.Profile.Name = from.UserName
 			to.User.Profile.DisplayName = from.DisplayName
 			to.User.Profile.VlessUUID = from.VlessUuid
+			to.User.Profile.SubToken = from.SubToken
+			to.User.Profile.Email = from.Email
 			to.User.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.User.Quota.Limit = from.QuotaBytes
+			to.User.Quota.Period = models.TrafficQuotaPeriod(from.QuotaPeriod)
+			to.User.ExpiresAt = fromNullTime(from.ExpiresAt)
+			to.User.IPLimit = int(from.IpLimit)
 			to.Traffic.Total.Upload = from.UploadTotal
 			to.Traffic.Total.Download = from.DownloadTotal
 			to.Traffic.LastMonth.Download = from.DownloadLastDays
 			to.Traffic.LastMonth.Upload = from.UploadLastDays
+			to.Traffic.CurrentPeriod.Upload = from.UploadPeriod
+			to.Traffic.CurrentPeriod.Download = from.DownloadPeriod
+		},
+	)
+}
+
+func ListNodeUsersResp(r []queries.ListNodeUsersRow) []models.User {
+	return cnvArrNoErr(r,
+		func(from *queries.ListNodeUsersRow, to *models.User) {
+			to.Profile.ID = models.UserID(from.UserID)
+			to.Profile.Name = from.UserName
+			to.Profile.DisplayName = from.DisplayName
+			to.Profile.VlessUUID = from.VlessUuid
+			to.Profile.SubToken = from.SubToken
+			to.Profile.Email = from.Email
+			to.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.Quota.Limit = from.QuotaBytes
+			to.Quota.Period = models.TrafficQuotaPeriod(from.QuotaPeriod)
+			to.ExpiresAt = fromNullTime(from.ExpiresAt)
+			to.IPLimit = int(from.IpLimit)
 		},
 	)
 }
@@ -135,10 +237,68 @@ func FindPendingSyncsResp(r []queries.FindPendingSyncsRow) []models.UserSyncStat
 			to.User.Profile.ID = models.UserID(from.UserID)
 			to.User.Profile.Name = from.UserName
 			to.User.Profile.VlessUUID = from.VlessUuid
+			to.User.Profile.Email = from.Email
+			to.CurrentVlessUUID = from.CurrentVlessUuid
+			to.TargetPrevVlessUUID = from.TargetPrevVlessUuid
+			to.CurrentPrevVlessUUID = from.CurrentPrevVlessUuid
+			to.TargetDevices = parseSyncDevices(from.TargetDevices)
+			to.CurrentDevices = parseSyncDevices(from.CurrentDevices)
+		},
+	)
+}
+
+func ListNodeUserSyncsResp(r []queries.ListNodeUserSyncsRow) []models.UserSyncStatus {
+	return cnvArrNoErr(r,
+		func(from *queries.ListNodeUserSyncsRow, to *models.UserSyncStatus) {
+			to.User.TargetStatus = models.UserStatus(from.UserTargetStatus)
+			to.User.Profile.DisplayName = from.DisplayName
+			to.User.Profile.ID = models.UserID(from.UserID)
+			to.User.Profile.Name = from.UserName
+			to.User.Profile.VlessUUID = from.VlessUuid
+			to.User.Profile.Email = from.Email
+			to.TargetPrevVlessUUID = from.TargetPrevVlessUuid
+			to.TargetDevices = parseSyncDevices(from.TargetDevices)
 		},
 	)
 }
 
+// sync devices are stored as "<device_id>:<vless_uuid>"
+// comma separated list ordered by device_id
+func formatSyncDevices(devices []models.UserDevice) string {
+	sorted := slices.Clone(devices)
+	slices.SortFunc(sorted, func(a, b models.UserDevice) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	items := make([]string, 0, len(sorted))
+	for _, d := range sorted {
+		items = append(items, strconv.Itoa(d.ID)+":"+d.VlessUUID)
+	}
+	return strings.Join(items, ",")
+}
+
+func parseSyncDevices(s string) []models.UserDevice {
+	if s == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	devices := make([]models.UserDevice, 0, len(items))
+	for _, item := range items {
+		idStr, vlessUUID, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			continue
+		}
+		devices = append(devices, models.UserDevice{
+			ID:        models.DeviceID(id),
+			VlessUUID: vlessUUID,
+		})
+	}
+	return devices
+}
+
 func UpdateNodeUsersReq(id models.NodeID,
 	patch []models.UserStatusPatch,
 ) queries.InsertNodeUsersParams {
@@ -147,10 +307,16 @@ func UpdateNodeUsersReq(id models.NodeID,
 		NodeID:            int64(id),
 		UserID:            make([]int64, n, n),
 		UserCurrentStatus: make([]int16, n, n),
+		VlessUuid:         make([]string, n, n),
+		PrevVlessUuid:     make([]string, n, n),
+		Devices:           make([]string, n, n),
 	}
 	for i, p := range patch {
 		arg.UserID[i] = int64(p.UserID)
 		arg.UserCurrentStatus[i] = int16(p.Status)
+		arg.VlessUuid[i] = p.VlessUUID
+		arg.PrevVlessUuid[i] = p.PrevVlessUUID
+		arg.Devices[i] = formatSyncDevices(p.Devices)
 	}
 	return arg
 }
@@ -192,3 +358,360 @@ func UpdateNodeStatsReq(nodeID models.NodeID,
 	}
 	return req
 }
+
+func UpdateDevicesStatsReq(stats models.NodeStats) queries.UpdateDevicesStatsParams {
+	n := len(stats.Devices)
+	req := queries.UpdateDevicesStatsParams{
+		DeviceID: make([]int64, n, n),
+		Upload:   make([]int64, n, n),
+		Download: make([]int64, n, n),
+	}
+	for i, d := range stats.Devices {
+		req.DeviceID[i] = int64(d.ID)
+		req.Upload[i] = d.Uplink
+		req.Download[i] = d.Downlink
+	}
+	return req
+}
+
+func ListUserDevicesResp(r []queries.ListUserDevicesRow) []models.UserDevice {
+	return cnvArrNoErr(r,
+		func(from *queries.ListUserDevicesRow, to *models.UserDevice) {
+			to.ID = models.DeviceID(from.DeviceID)
+			to.UserID = models.UserID(from.UserID)
+			to.Label = from.Label
+			to.VlessUUID = from.VlessUuid
+			to.CreatedAt = from.CreatedAt
+			to.Traffic.Upload = from.Upload
+			to.Traffic.Download = from.Download
+		},
+	)
+}
+
+func GetUserDeviceResp(r queries.UserDevice) *models.UserDevice {
+	return cnvNoErr(&r,
+		func(from *queries.UserDevice, to *models.UserDevice) {
+			to.ID = models.DeviceID(from.DeviceID)
+			to.UserID = models.UserID(from.UserID)
+			to.Label = from.Label
+			to.VlessUUID = from.VlessUuid
+			to.CreatedAt = from.CreatedAt
+		},
+	)
+}
+
+func UpdateNodeOnlineIPsReq(nodeID models.NodeID,
+	stats models.NodeStats,
+) queries.InsertNodeOnlineIPsParams {
+	req := queries.InsertNodeOnlineIPsParams{
+		NodeID: int64(nodeID),
+	}
+	for _, u := range stats.Users {
+		for _, ip := range u.OnlineIPs {
+			req.UserID = append(req.UserID, int64(u.ID))
+			req.Ip = append(req.Ip, ip)
+		}
+	}
+	return req
+}
+
+func RecordIPLimitViolationsResp(r []queries.RecordIPLimitViolationsRow) []models.IPLimitViolation {
+	return cnvArrNoErr(r,
+		func(from *queries.RecordIPLimitViolationsRow, to *models.IPLimitViolation) {
+			to.UserID = models.UserID(from.UserID)
+			to.IPCount = int(from.IpCount)
+			to.IPLimit = int(from.IpLimit)
+			to.CreatedAt = from.CreatedAt
+		},
+	)
+}
+
+func ListIPLimitViolationsResp(r []queries.ListIPLimitViolationsRow) []models.IPLimitViolation {
+	return cnvArrNoErr(r,
+		func(from *queries.ListIPLimitViolationsRow, to *models.IPLimitViolation) {
+			to.UserID = models.UserID(from.UserID)
+			to.IPCount = int(from.IpCount)
+			to.IPLimit = int(from.IpLimit)
+			to.CreatedAt = from.CreatedAt
+		},
+	)
+}
+
+func UserIDsResp(r []int64) []models.UserID {
+	ids := make([]models.UserID, len(r))
+	for i, id := range r {
+		ids[i] = models.UserID(id)
+	}
+	return ids
+}
+
+func ListNodeGroupsResp(r []queries.ListNodeGroupsRow) []models.NodeGroup {
+	return cnvArrNoErr(r,
+		func(from *queries.ListNodeGroupsRow, to *models.NodeGroup) {
+			to.ID = models.NodeGroupID(from.GroupID)
+			to.Name = from.GroupName
+			to.NodeIDs = NodeIDsResp(from.NodeIds)
+			to.UserIDs = UserIDsResp(from.UserIds)
+		},
+	)
+}
+
+func NodeIDsResp(r []int64) []models.NodeID {
+	ids := make([]models.NodeID, len(r))
+	for i, id := range r {
+		ids[i] = models.NodeID(id)
+	}
+	return ids
+}
+
+func NodeGroupIDsReq(ids []models.NodeGroupID) []int64 {
+	r := make([]int64, len(ids))
+	for i, id := range ids {
+		r[i] = int64(id)
+	}
+	return r
+}
+
+func UserTrafficHistoryResp(r []queries.GetUserTrafficHistoryRow) []models.DailyTraffic {
+	return cnvArrNoErr(r,
+		func(from *queries.GetUserTrafficHistoryRow, to *models.DailyTraffic) {
+			to.Day = from.Day
+			to.Traffic.Upload = from.Upload
+			to.Traffic.Download = from.Download
+		},
+	)
+}
+
+func NodeTrafficHistoryResp(r []queries.GetNodeTrafficHistoryRow) []models.DailyTraffic {
+	return cnvArrNoErr(r,
+		func(from *queries.GetNodeTrafficHistoryRow, to *models.DailyTraffic) {
+			to.Day = from.Day
+			to.Traffic.Upload = from.Upload
+			to.Traffic.Download = from.Download
+		},
+	)
+}
+
+// rows are ordered by node, group them to per-node histories
+func UserNodesTrafficHistoryResp(r []queries.GetUserNodesTrafficHistoryRow) []models.NodeTrafficHistory {
+	nodes := make([]models.NodeTrafficHistory, 0)
+	for _, row := range r {
+		nodeID := models.NodeID(row.NodeID)
+		if len(nodes) == 0 || nodes[len(nodes)-1].NodeID != nodeID {
+			nodes = append(nodes, models.NodeTrafficHistory{
+				NodeID: nodeID,
+				Days:   make([]models.DailyTraffic, 0),
+			})
+		}
+		last := &nodes[len(nodes)-1]
+		last.Days = append(last.Days, models.DailyTraffic{
+			Day: row.Day,
+			Traffic: models.TrafficStats{
+				Upload:   row.Upload,
+				Download: row.Download,
+			},
+		})
+	}
+	return nodes
+}
+
+func GetAdminResp(r *queries.GetAdminRow) *models.Admin {
+	return cnvNoErr(r,
+		func(from *queries.GetAdminRow, to *models.Admin) {
+			to.ID = models.AdminID(from.AdminID)
+			to.Name = from.AdminName
+			to.Role = models.AdminRole(from.AdminRole)
+		})
+}
+
+func ListAdminsResp(r []queries.ListAdminsRow) []models.Admin {
+	return cnvArrNoErr(r,
+		func(from *queries.ListAdminsRow, to *models.Admin) {
+			to.ID = models.AdminID(from.AdminID)
+			to.Name = from.AdminName
+			to.Role = models.AdminRole(from.AdminRole)
+		},
+	)
+}
+
+func GetApiTokenResp(r *queries.GetApiTokenByHashRow) *models.ApiToken {
+	return cnvNoErr(r,
+		func(from *queries.GetApiTokenByHashRow, to *models.ApiToken) {
+			to.ID = models.ApiTokenID(from.TokenID)
+			to.Name = from.TokenName
+			to.Scopes = apiTokenScopes(from.Scopes)
+			to.CreatedAt = from.CreatedAt
+		})
+}
+
+func ListApiTokensResp(r []queries.ListApiTokensRow) []models.ApiToken {
+	return cnvArrNoErr(r,
+		func(from *queries.ListApiTokensRow, to *models.ApiToken) {
+			to.ID = models.ApiTokenID(from.TokenID)
+			to.Name = from.TokenName
+			to.Scopes = apiTokenScopes(from.Scopes)
+			to.CreatedAt = from.CreatedAt
+		},
+	)
+}
+
+func ApiTokenScopesReq(scopes []models.ApiTokenScope) []int16 {
+	r := make([]int16, len(scopes))
+	for i, s := range scopes {
+		r[i] = int16(s)
+	}
+	return r
+}
+
+func apiTokenScopes(scopes []int16) []models.ApiTokenScope {
+	r := make([]models.ApiTokenScope, len(scopes))
+	for i, s := range scopes {
+		r[i] = models.ApiTokenScope(s)
+	}
+	return r
+}
+
+func GetPasswordResp(r *queries.GetPasswordRow) *models.Auth {
+	return cnvNoErr(r,
+		func(from *queries.GetPasswordRow, to *models.Auth) {
+			to.Admin.ID = models.AdminID(from.AdminID)
+			to.Admin.Name = from.AdminName
+			to.Admin.Role = models.AdminRole(from.AdminRole)
+			to.PasswordHash = from.PasswordHash
+			to.TOTP.Secret = from.TotpSecret.String
+			to.TOTP.Enabled = from.TotpEnabled
+		})
+}
+
+func GetPasswordByNameResp(r *queries.GetPasswordByNameRow) *models.Auth {
+	return cnvNoErr(r,
+		func(from *queries.GetPasswordByNameRow, to *models.Auth) {
+			to.Admin.ID = models.AdminID(from.AdminID)
+			to.Admin.Name = from.AdminName
+			to.Admin.Role = models.AdminRole(from.AdminRole)
+			to.PasswordHash = from.PasswordHash
+			to.TOTP.Secret = from.TotpSecret.String
+			to.TOTP.Enabled = from.TotpEnabled
+		})
+}
+
+func RecordAuditEventReq(e *models.AuditEvent) queries.RecordAuditEventParams {
+	targetIDs := make([]int64, len(e.TargetIDs))
+	for i, id := range e.TargetIDs {
+		targetIDs[i] = int64(id)
+	}
+	params := e.Params
+	if params == "" {
+		params = "{}"
+	}
+	changes := e.Changes
+	if changes == "" {
+		changes = "{}"
+	}
+	return queries.RecordAuditEventParams{
+		ActorKind: int16(e.Actor.Kind),
+		ActorID: sql.NullInt64{
+			Int64: int64(e.Actor.ID),
+			Valid: e.Actor.Kind == models.AuditActorAdmin ||
+				e.Actor.Kind == models.AuditActorApiToken,
+		},
+		ActorName: e.Actor.Name,
+		Operation: e.Operation,
+		TargetIds: targetIDs,
+		Params:    json.RawMessage(params),
+		ClientIp:  e.ClientIP,
+		RequestID: e.RequestID,
+		Success:   e.Success,
+		Error:     e.Error,
+		Changes:   json.RawMessage(changes),
+	}
+}
+
+func ListAuditEventsReq(p models.ListAuditEventsParams) queries.ListAuditEventsParams {
+	return queries.ListAuditEventsParams{
+		BeforeID:  sql.NullInt64{Int64: int64(p.BeforeID), Valid: p.BeforeID != 0},
+		Operation: sql.NullString{String: p.Filter.Operation, Valid: p.Filter.Operation != ""},
+		ActorName: sql.NullString{String: p.Filter.ActorName, Valid: p.Filter.ActorName != ""},
+		TargetID:  sql.NullInt64{Int64: int64(p.Filter.TargetID), Valid: p.Filter.TargetID != 0},
+		FromTime:  sql.NullTime{Time: p.Filter.From, Valid: !p.Filter.From.IsZero()},
+		ToTime:    sql.NullTime{Time: p.Filter.To, Valid: !p.Filter.To.IsZero()},
+		MaxCount:  int32(p.Limit),
+	}
+}
+
+func ListAuditEventsResp(r []queries.AuditEvent) []models.AuditEvent {
+	return cnvArrNoErr(r,
+		func(from *queries.AuditEvent, to *models.AuditEvent) {
+			to.ID = models.AuditEventID(from.EventID)
+			to.Time = from.CreatedAt
+			to.Actor.Kind = models.AuditActorKind(from.ActorKind)
+			to.Actor.ID = int(from.ActorID.Int64)
+			to.Actor.Name = from.ActorName
+			to.Operation = from.Operation
+			to.TargetIDs = make([]int, len(from.TargetIds))
+			for i, id := range from.TargetIds {
+				to.TargetIDs[i] = int(id)
+			}
+			to.Params = string(from.Params)
+			to.ClientIP = from.ClientIp
+			to.RequestID = from.RequestID
+			to.Success = from.Success
+			to.Error = from.Error
+			to.Changes = string(from.Changes)
+		},
+	)
+}
+
+func GetAdminSessionResp(r *queries.GetAdminSessionRow) *models.Session {
+	return cnvNoErr(r,
+		func(from *queries.GetAdminSessionRow, to *models.Session) {
+			to.ID = models.SessionID(from.SessionID)
+			to.AdminID = models.AdminID(from.AdminID)
+			to.ClientIP = from.ClientIp
+			to.CreatedAt = from.CreatedAt
+			to.RefreshedAt = from.RefreshedAt
+			to.ExpiresAt = from.ExpiresAt
+		})
+}
+
+func RotateAdminSessionResp(r *queries.RotateAdminSessionRow) *models.Session {
+	row := queries.GetAdminSessionRow(*r)
+	return GetAdminSessionResp(&row)
+}
+
+func ListAdminSessionsResp(r []queries.ListAdminSessionsRow) []models.Session {
+	return cnvArrNoErr(r,
+		func(from *queries.ListAdminSessionsRow, to *models.Session) {
+			row := queries.GetAdminSessionRow(*from)
+			*to = *GetAdminSessionResp(&row)
+		},
+	)
+}
+
+func ListWebhooksResp(r []queries.Webhook) []models.Webhook {
+	return cnvArrNoErr(r,
+		func(from *queries.Webhook, to *models.Webhook) {
+			to.ID = models.WebhookID(from.WebhookID)
+			to.URL = from.Url
+			to.Events = webhookEvents(from.Events)
+			to.Secret = from.Secret
+			to.CreatedAt = from.CreatedAt
+		},
+	)
+}
+
+func WebhookEventsReq(events []models.WebhookEventType) []int16 {
+	r := make([]int16, len(events))
+	for i, e := range events {
+		r[i] = int16(e)
+	}
+	return r
+}
+
+func webhookEvents(events []int16) []models.WebhookEventType {
+	r := make([]models.WebhookEventType, len(events))
+	for i, e := range events {
+		r[i] = models.WebhookEventType(e)
+	}
+	return r
+}
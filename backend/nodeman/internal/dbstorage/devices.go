@@ -0,0 +1,117 @@
+package dbstorage
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+// NewUserDevice adds device to user, assigns device id and creation time
+func (s *Storage) NewUserDevice(ctx context.Context, d *models.UserDevice) error {
+	// pre-convert
+	req := queries.NewUserDeviceParams{
+		Label:     d.Label,
+		VlessUuid: d.VlessUUID,
+		UserID:    int64(d.UserID),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.NewUserDeviceRow, error) {
+		return q.NewUserDevice(ctx, req)
+	})
+	if err != nil {
+		return err
+	}
+
+	// post-convert
+	d.ID = models.DeviceID(resp.DeviceID)
+	d.CreatedAt = resp.CreatedAt
+
+	return nil
+}
+
+func (s *Storage) CountUserDevices(ctx context.Context, userID models.UserID) (int, error) {
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int32, error) {
+		return q.CountUserDevices(ctx, int64(userID))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp), nil
+}
+
+// ListDevicesVlessUUIDs returns vless uuids of all users devices
+func (s *Storage) ListDevicesVlessUUIDs(ctx context.Context) ([]string, error) {
+	return doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]string, error) {
+		return q.ListDevicesVlessUUIDs(ctx)
+	})
+}
+
+func (s *Storage) GetUserDevice(ctx context.Context,
+	userID models.UserID, id models.DeviceID,
+) (*models.UserDevice, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.UserDevice, error) {
+		return q.GetUserDevice(ctx, queries.GetUserDeviceParams{
+			DeviceID: int64(id),
+			UserID:   int64(userID),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.GetUserDeviceResp(resp), nil
+}
+
+// ListUserDevices returns user devices with their traffic
+func (s *Storage) ListUserDevices(ctx context.Context,
+	userID models.UserID,
+) ([]models.UserDevice, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListUserDevicesRow, error) {
+		return q.ListUserDevices(ctx, int64(userID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListUserDevicesResp(resp), nil
+}
+
+func (s *Storage) SetUserDeviceLabel(ctx context.Context,
+	id models.DeviceID, label string,
+) error {
+	_, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.SetUserDeviceLabel(ctx, queries.SetUserDeviceLabelParams{
+			Label:    label,
+			DeviceID: int64(id),
+		})
+	})
+	return err
+}
+
+func (s *Storage) DeleteUserDevice(ctx context.Context, id models.DeviceID) error {
+	_, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.DeleteUserDevice(ctx, int64(id))
+	})
+	return err
+}
@@ -0,0 +1,116 @@
+package dbstorage
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) NewNodeGroup(ctx context.Context, group *models.NodeGroup) error {
+	// request
+	groupID, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.NewNodeGroup(ctx, group.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	// post-convert
+	group.ID = models.NodeGroupID(groupID)
+
+	return nil
+}
+
+func (s *Storage) ListNodeGroups(ctx context.Context) ([]models.NodeGroup, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListNodeGroupsRow, error) {
+		return q.ListNodeGroups(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListNodeGroupsResp(resp), nil
+}
+
+func (s *Storage) ListNodeGroupNodes(ctx context.Context,
+	id models.NodeGroupID,
+) ([]models.NodeID, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]int64, error) {
+		return q.ListNodeGroupNodes(ctx, int64(id))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.NodeIDsResp(resp), nil
+}
+
+func (s *Storage) DeleteNodeGroup(ctx context.Context,
+	id models.NodeGroupID,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.DeleteNodeGroup(ctx, int64(id))
+	})
+}
+
+func (s *Storage) SetNodeGroups(ctx context.Context, id models.NodeID,
+	groups []models.NodeGroupID,
+) error {
+	// pre-convert
+	arg := queries.InsertNodeGroupNodesParams{
+		NodeID:  int64(id),
+		GroupID: convert.NodeGroupIDsReq(groups),
+	}
+
+	// request
+	return s.DoTx(ctx, func(ctx context.Context) error {
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.DeleteNodeGroupNodes(ctx, int64(id))
+		}); err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			return nil
+		}
+		return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.InsertNodeGroupNodes(ctx, arg)
+		})
+	})
+}
+
+func (s *Storage) SetUserGroups(ctx context.Context, id models.UserID,
+	groups []models.NodeGroupID,
+) error {
+	// pre-convert
+	arg := queries.InsertUserNodeGroupsParams{
+		UserID:  int64(id),
+		GroupID: convert.NodeGroupIDsReq(groups),
+	}
+
+	// request
+	return s.DoTx(ctx, func(ctx context.Context) error {
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.DeleteUserNodeGroups(ctx, int64(id))
+		}); err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			return nil
+		}
+		return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.InsertUserNodeGroups(ctx, arg)
+		})
+	})
+}
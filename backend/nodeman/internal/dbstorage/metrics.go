@@ -0,0 +1,68 @@
+package dbstorage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+)
+
+// Metrics receives one observation per sqlc query call
+type Metrics interface {
+	ObserveQuery(name string, d time.Duration, err error)
+}
+
+// queries.DBTX wrapper measuring each call
+type metricsDB struct {
+	base    queries.DBTX
+	metrics Metrics
+}
+
+var _ queries.DBTX = (*metricsDB)(nil)
+
+func (db *metricsDB) ExecContext(ctx context.Context, query string,
+	args ...any,
+) (res sql.Result, err error) {
+	defer db.observe(query, time.Now(), &err)
+	return db.base.ExecContext(ctx, query, args...)
+}
+
+func (db *metricsDB) PrepareContext(ctx context.Context, query string,
+) (stmt *sql.Stmt, err error) {
+	defer db.observe(query, time.Now(), &err)
+	return db.base.PrepareContext(ctx, query)
+}
+
+func (db *metricsDB) QueryContext(ctx context.Context, query string,
+	args ...any,
+) (rows *sql.Rows, err error) {
+	defer db.observe(query, time.Now(), &err)
+	return db.base.QueryContext(ctx, query, args...)
+}
+
+func (db *metricsDB) QueryRowContext(ctx context.Context, query string,
+	args ...any,
+) *sql.Row {
+	start := time.Now()
+	row := db.base.QueryRowContext(ctx, query, args...)
+	// row.Err doesn't report sql.ErrNoRows, only query failures
+	err := row.Err()
+	db.observe(query, start, &err)
+	return row
+}
+
+func (db *metricsDB) observe(query string, start time.Time, err *error) {
+	db.metrics.ObserveQuery(queryName(query), time.Since(start), *err)
+}
+
+// sqlc queries start with "-- name: QueryName :kind"
+func queryName(query string) string {
+	const prefix = "-- name: "
+	if !strings.HasPrefix(query, prefix) {
+		return "unknown"
+	}
+	name, _, _ := strings.Cut(query[len(prefix):], " ")
+	return name
+}
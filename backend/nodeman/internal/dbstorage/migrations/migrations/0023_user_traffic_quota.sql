@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- quota_bytes: traffic limit for the current period, 0 means unlimited
+-- quota_base_*: total user traffic at the start of the current period
+-- quota_exceeded: user was disabled because of quota overrun
+ALTER TABLE users
+    ADD COLUMN quota_bytes          BIGINT      NOT NULL DEFAULT 0,
+    ADD COLUMN quota_period         SMALLINT    NOT NULL DEFAULT 1,
+    ADD COLUMN quota_period_start   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN quota_base_upload    BIGINT      NOT NULL DEFAULT 0,
+    ADD COLUMN quota_base_download  BIGINT      NOT NULL DEFAULT 0,
+    ADD COLUMN quota_exceeded       BOOLEAN     NOT NULL DEFAULT FALSE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN quota_bytes,
+    DROP COLUMN quota_period,
+    DROP COLUMN quota_period_start,
+    DROP COLUMN quota_base_upload,
+    DROP COLUMN quota_base_download,
+    DROP COLUMN quota_exceeded;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- expires_at: user is disabled after this moment, NULL means never
+ALTER TABLE users
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX users_expires_at_idx ON users (expires_at)
+    WHERE expires_at IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS users_expires_at_idx;
+
+ALTER TABLE users
+    DROP COLUMN expires_at;
+
+-- +goose StatementEnd
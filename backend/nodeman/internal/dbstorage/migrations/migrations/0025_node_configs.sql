@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- xray server and client configs pushed to nodes by admin,
+-- config_version grows per node starting from 1
+CREATE TABLE IF NOT EXISTS node_configs (
+    node_id         BIGINT      NOT NULL REFERENCES nodes(node_id),
+    config_version  BIGINT      NOT NULL,
+    server_config   TEXT        NOT NULL,
+    client_config   TEXT        NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (node_id, config_version)
+);
+
+-- 0 means node uses its local configs
+ALTER TABLE nodes
+    ADD COLUMN target_config_version  BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN current_config_version BIGINT NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE nodes
+    DROP COLUMN target_config_version,
+    DROP COLUMN current_config_version;
+
+DROP TABLE IF EXISTS node_configs;
+
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- node groups (tags) like "premium" or "eu"
+CREATE TABLE IF NOT EXISTS node_groups (
+    group_id    BIGSERIAL   PRIMARY KEY,
+    group_name  TEXT        NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS node_group_nodes (
+    group_id BIGINT NOT NULL REFERENCES node_groups(group_id) ON DELETE CASCADE,
+    node_id  BIGINT NOT NULL REFERENCES nodes(node_id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS node_group_nodes_node_id_idx
+    ON node_group_nodes (node_id);
+
+CREATE TABLE IF NOT EXISTS user_node_groups (
+    user_id  BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    group_id BIGINT NOT NULL REFERENCES node_groups(group_id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, group_id)
+);
+
+-- user is entitled to node without groups,
+-- or to node sharing at least one group with user
+CREATE VIEW user_node_access AS
+SELECT
+    u.user_id,
+    n.node_id
+FROM users u
+CROSS JOIN nodes n
+WHERE NOT EXISTS (
+        SELECT 1 FROM node_group_nodes gn
+        WHERE gn.node_id = n.node_id
+    )
+    OR EXISTS (
+        SELECT 1 FROM node_group_nodes gn
+        INNER JOIN user_node_groups ug
+            ON ug.group_id = gn.group_id
+        WHERE gn.node_id = n.node_id
+          AND ug.user_id = u.user_id
+    );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP VIEW IF EXISTS user_node_access;
+DROP TABLE IF EXISTS user_node_groups;
+DROP TABLE IF EXISTS node_group_nodes;
+DROP TABLE IF EXISTS node_groups;
+
+-- +goose StatementEnd
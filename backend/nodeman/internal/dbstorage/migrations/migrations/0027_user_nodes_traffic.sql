@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- users traffic with node dimension, filled since this migration
+CREATE TABLE IF NOT EXISTS total_user_nodes_traffic (
+    user_id    bigint    NOT NULL,
+    node_id    bigint    NOT NULL,
+    download   bigint    NOT NULL,
+    upload     bigint    NOT NULL,
+
+    PRIMARY KEY (user_id, node_id)
+);
+
+CREATE TABLE IF NOT EXISTS daily_user_nodes_traffic (
+    day        date      NOT NULL,
+    user_id    bigint    NOT NULL,
+    node_id    bigint    NOT NULL,
+    download   bigint    NOT NULL,
+    upload     bigint    NOT NULL,
+
+    PRIMARY KEY (day, user_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS daily_user_nodes_traffic_index
+    ON daily_user_nodes_traffic (user_id, node_id, day DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS daily_user_nodes_traffic_index;
+DROP TABLE IF EXISTS daily_user_nodes_traffic;
+DROP TABLE IF EXISTS total_user_nodes_traffic;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ip_limit: max simultaneous source ips, 0 means unlimited
+ALTER TABLE users
+    ADD COLUMN ip_limit INT NOT NULL DEFAULT 0;
+
+-- last online ips count reported by node
+CREATE TABLE IF NOT EXISTS user_online_ips (
+    user_id    bigint      NOT NULL,
+    node_id    bigint      NOT NULL,
+    ip_count   int         NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (user_id, node_id)
+);
+
+CREATE TABLE IF NOT EXISTS ip_limit_violations (
+    violation_id BIGSERIAL   PRIMARY KEY,
+    user_id      bigint      NOT NULL,
+    ip_count     int         NOT NULL,
+    ip_limit     int         NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ip_limit_violations_index
+    ON ip_limit_violations (user_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS ip_limit_violations_index;
+DROP TABLE IF EXISTS ip_limit_violations;
+DROP TABLE IF EXISTS user_online_ips;
+
+ALTER TABLE users
+    DROP COLUMN ip_limit;
+-- +goose StatementEnd
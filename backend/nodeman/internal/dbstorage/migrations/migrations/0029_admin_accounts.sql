@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- admin_role: 1 owner, 2 operator, 3 support.
+-- existing admin becomes owner named "admin"
+ALTER TABLE admin_auth
+    ADD COLUMN admin_name TEXT,
+    ADD COLUMN admin_role SMALLINT NOT NULL DEFAULT 1;
+
+UPDATE admin_auth
+SET admin_name = CASE
+    WHEN admin_id = 0 THEN 'admin'
+    ELSE 'admin' || admin_id
+END;
+
+ALTER TABLE admin_auth
+    ALTER COLUMN admin_name SET NOT NULL;
+
+CREATE UNIQUE INDEX admin_auth_name_idx ON admin_auth (admin_name)
+    WHERE deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS admin_auth_name_idx;
+
+ALTER TABLE admin_auth
+    DROP COLUMN admin_role,
+    DROP COLUMN admin_name;
+
+-- +goose StatementEnd
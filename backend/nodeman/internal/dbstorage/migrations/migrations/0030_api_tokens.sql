@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- token_hash: sha256 of token secret, secret itself isn't stored
+CREATE TABLE IF NOT EXISTS api_tokens (
+    token_id   BIGSERIAL   PRIMARY KEY,
+    token_name TEXT        NOT NULL,
+    token_hash BYTEA       NOT NULL UNIQUE,
+    scopes     SMALLINT[]  NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- totp_secret is set on enrolment and becomes required
+-- for login only after totp_enabled is set by code verification
+ALTER TABLE admin_auth
+    ADD COLUMN totp_secret TEXT,
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- code_hash: sha256 of one-time recovery code
+CREATE TABLE IF NOT EXISTS admin_recovery_codes (
+    admin_id  BIGINT      NOT NULL REFERENCES admin_auth (admin_id) ON DELETE CASCADE,
+    code_hash BYTEA       NOT NULL,
+    used_at   TIMESTAMPTZ,
+    PRIMARY KEY (admin_id, code_hash)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS admin_recovery_codes;
+
+ALTER TABLE admin_auth
+    DROP COLUMN totp_enabled,
+    DROP COLUMN totp_secret;
+
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- actor_kind: 1 admin, 2 api token, 3 anonymous.
+-- params: request parameters with secrets redacted
+CREATE TABLE IF NOT EXISTS audit_events (
+    event_id   BIGSERIAL   PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor_kind SMALLINT    NOT NULL,
+    actor_id   BIGINT,
+    actor_name TEXT        NOT NULL,
+    operation  TEXT        NOT NULL,
+    target_ids BIGINT[]    NOT NULL,
+    params     JSONB       NOT NULL,
+    client_ip  TEXT        NOT NULL,
+    request_id TEXT        NOT NULL,
+    success    BOOLEAN     NOT NULL,
+    error      TEXT        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
+
+-- events are only appended, and deleted by retention
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+
+-- +goose StatementEnd
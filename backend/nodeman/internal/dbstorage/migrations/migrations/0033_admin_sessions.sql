@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- refresh_hash: sha256 of current refresh token, token is rotated
+-- on every refresh, so stolen token can be used only once
+CREATE TABLE IF NOT EXISTS admin_sessions (
+    session_id   BIGSERIAL   PRIMARY KEY,
+    admin_id     BIGINT      NOT NULL REFERENCES admin_auth (admin_id) ON DELETE CASCADE,
+    refresh_hash BYTEA       NOT NULL UNIQUE,
+    client_ip    TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS admin_sessions_admin_idx
+    ON admin_sessions (admin_id)
+    WHERE revoked_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS admin_sessions;
+-- +goose StatementEnd
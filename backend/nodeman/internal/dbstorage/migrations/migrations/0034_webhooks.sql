@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- secret is stored as is, it's required to sign payloads.
+-- empty events means all events
+CREATE TABLE IF NOT EXISTS webhooks (
+    webhook_id BIGSERIAL   PRIMARY KEY,
+    url        TEXT        NOT NULL,
+    events     SMALLINT[]  NOT NULL,
+    secret     TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhooks;
+-- +goose StatementEnd
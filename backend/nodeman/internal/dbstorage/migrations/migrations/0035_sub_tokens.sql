@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- sub_token: random secret identifying user in subscription
+-- and user page links, existing users get random tokens too
+ALTER TABLE users
+    ADD COLUMN sub_token TEXT NOT NULL
+    DEFAULT replace(gen_random_uuid()::text, '-', '');
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_sub_token_idx
+    ON users (sub_token);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS users_sub_token_idx;
+
+ALTER TABLE users DROP COLUMN sub_token;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- prev_vless_uuid: user identity replaced by credentials rotation,
+-- kept on nodes until prev_vless_uuid_expires_at (grace period)
+ALTER TABLE users
+    ADD COLUMN prev_vless_uuid TEXT NOT NULL DEFAULT '',
+    ADD COLUMN prev_vless_uuid_expires_at TIMESTAMPTZ NULL;
+
+-- vless_uuid, prev_vless_uuid: user identities pushed to node,
+-- user is out of sync if they differ from users ones
+ALTER TABLE syncs
+    ADD COLUMN vless_uuid TEXT NOT NULL DEFAULT '',
+    ADD COLUMN prev_vless_uuid TEXT NOT NULL DEFAULT '';
+
+-- users already on nodes have actual identities
+UPDATE syncs s
+SET vless_uuid = u.vless_uuid
+FROM users u
+WHERE u.user_id = s.user_id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE syncs
+    DROP COLUMN prev_vless_uuid,
+    DROP COLUMN vless_uuid;
+
+ALTER TABLE users
+    DROP COLUMN prev_vless_uuid_expires_at,
+    DROP COLUMN prev_vless_uuid;
+
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- user devices, each one has own credentials on nodes
+CREATE TABLE IF NOT EXISTS user_devices (
+    device_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    vless_uuid TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_devices_user_id_idx
+    ON user_devices (user_id);
+
+-- devices: user devices pushed to node, "<device_id>:<vless_uuid>"
+-- comma separated list ordered by device_id
+ALTER TABLE syncs
+    ADD COLUMN devices TEXT NOT NULL DEFAULT '';
+
+-- device traffic, also included to user traffic
+CREATE TABLE IF NOT EXISTS total_devices_traffic (
+    device_id BIGINT PRIMARY KEY REFERENCES user_devices(device_id) ON DELETE CASCADE,
+    upload BIGINT NOT NULL DEFAULT 0,
+    download BIGINT NOT NULL DEFAULT 0
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS total_devices_traffic;
+
+ALTER TABLE syncs DROP COLUMN devices;
+
+DROP TABLE IF EXISTS user_devices;
+
+-- +goose StatementEnd
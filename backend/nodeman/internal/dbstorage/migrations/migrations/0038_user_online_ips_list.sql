@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- online ips reported by node, one row per ip, so the same ip
+-- connected to several nodes is counted once. reports are
+-- short-living, previous counts are dropped
+DROP TABLE IF EXISTS user_online_ips;
+
+CREATE TABLE IF NOT EXISTS user_online_ips (
+    user_id    bigint      NOT NULL,
+    node_id    bigint      NOT NULL,
+    ip         TEXT        NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (user_id, node_id, ip)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_online_ips;
+
+CREATE TABLE IF NOT EXISTS user_online_ips (
+    user_id    bigint      NOT NULL,
+    node_id    bigint      NOT NULL,
+    ip_count   int         NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (user_id, node_id)
+);
+-- +goose StatementEnd
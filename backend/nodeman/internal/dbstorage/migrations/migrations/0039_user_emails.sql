@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- email: user email on nodes kept from migrated setup,
+-- empty for generated "<user_id>-<user_name>" one
+ALTER TABLE users
+    ADD COLUMN email TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx
+    ON users (email)
+    WHERE email <> '' AND deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS users_email_idx;
+
+ALTER TABLE users DROP COLUMN email;
+
+-- +goose StatementEnd
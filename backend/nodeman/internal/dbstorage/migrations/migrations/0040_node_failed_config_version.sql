@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- last config version rejected by node, it isn't pushed again
+-- until admin pushes next one. 0 means none
+ALTER TABLE nodes
+    ADD COLUMN failed_config_version BIGINT NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE nodes DROP COLUMN failed_config_version;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- totp_last_step: time step of last accepted totp code,
+-- codes of this and earlier steps are rejected as replayed
+ALTER TABLE admin_auth
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE admin_auth DROP COLUMN totp_last_step;
+
+-- +goose StatementEnd
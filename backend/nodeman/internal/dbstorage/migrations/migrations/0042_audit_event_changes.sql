@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- changes: json encoded target fields changed by call,
+-- {"Field": {"Before": ..., "After": ...}}, secrets redacted
+ALTER TABLE audit_events
+    ADD COLUMN changes JSONB NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE audit_events DROP COLUMN changes;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- expired: user was disabled because of expiration,
+-- enabled again when expiration is extended
+ALTER TABLE users
+    ADD COLUMN expired BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN expired;
+
+-- +goose StatementEnd
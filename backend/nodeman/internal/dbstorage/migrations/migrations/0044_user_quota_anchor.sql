@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- quota_anchor: monthly quota periods start at whole months from it,
+-- so late resets don't shift next periods
+-- quota_ignored: user was enabled manually over quota,
+-- quota isn't checked until the next period or quota change
+ALTER TABLE users
+    ADD COLUMN quota_anchor  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN quota_ignored BOOLEAN     NOT NULL DEFAULT FALSE;
+
+UPDATE users SET quota_anchor = quota_period_start;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users
+    DROP COLUMN quota_anchor,
+    DROP COLUMN quota_ignored;
+
+-- +goose StatementEnd
@@ -102,6 +102,69 @@ func (s *Storage) SetNodeSettings(ctx context.Context,
 	})
 }
 
+func (s *Storage) PushNodeConfig(ctx context.Context,
+	id models.NodeID, cfg models.NodeXRayConfig,
+) (models.ConfigVersion, error) {
+	// request
+	version, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.PushNodeConfig(ctx, queries.PushNodeConfigParams{
+			NodeID:       int64(id),
+			ServerConfig: cfg.ServerConfig,
+			ClientConfig: cfg.ClientConfig,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// post-convert
+	return models.ConfigVersion(version), nil
+}
+
+func (s *Storage) GetNodeConfig(ctx context.Context,
+	id models.NodeID, version models.ConfigVersion,
+) (*models.NodeXRayConfig, error) {
+	// request
+	cfg, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.GetNodeConfigRow, error) {
+		return q.GetNodeConfig(ctx, queries.GetNodeConfigParams{
+			NodeID:        int64(id),
+			ConfigVersion: version,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.NodeConfigResp(&cfg), nil
+}
+
+func (s *Storage) SetCurrentNodeConfigVersion(ctx context.Context,
+	id models.NodeID, version models.ConfigVersion,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.SetCurrentNodeConfigVersion(ctx, queries.SetCurrentNodeConfigVersionParams{
+			NodeID:               int64(id),
+			CurrentConfigVersion: version,
+		})
+	})
+}
+
+func (s *Storage) SetFailedNodeConfigVersion(ctx context.Context,
+	id models.NodeID, version models.ConfigVersion,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.SetFailedNodeConfigVersion(ctx, queries.SetFailedNodeConfigVersionParams{
+			NodeID:              int64(id),
+			FailedConfigVersion: version,
+		})
+	})
+}
+
 func (s *Storage) DeleteNode(ctx context.Context,
 	id models.NodeID,
 ) error {
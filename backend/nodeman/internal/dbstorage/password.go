@@ -3,35 +3,55 @@ package dbstorage
 import (
 	"context"
 
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
 	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
 
-const adminID = 0
-
 func (s *Storage) GetAuth(ctx context.Context) (
 	*models.Auth, error,
+) {
+	return s.GetAdminAuthByID(ctx, models.DefaultAdminID)
+}
+
+func (s *Storage) GetAdminAuthByID(ctx context.Context, id models.AdminID) (
+	*models.Auth, error,
 ) {
 	resp, err := doAny(ctx, s, func(ctx context.Context,
 		q *queries.Queries,
 	) (queries.GetPasswordRow, error) {
-		return q.GetPassword(ctx, adminID)
+		return q.GetPassword(ctx, int64(id))
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.Auth{
-		PasswordHash: resp.PasswordHash,
-	}, nil
+	return convert.GetPasswordResp(&resp), nil
 }
 
 func (s *Storage) SetAuth(ctx context.Context, a *models.Auth) error {
 	req := queries.SetPasswordParams{
-		AdminID:      adminID,
+		AdminID:      int64(models.DefaultAdminID),
+		AdminName:    models.DefaultAdminName,
+		AdminRole:    int16(models.AdminRoleOwner),
 		PasswordHash: a.PasswordHash,
 	}
 	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
 		return q.SetPassword(ctx, req)
 	})
 }
+
+func (s *Storage) GetAdminAuth(ctx context.Context, name string) (
+	*models.Auth, error,
+) {
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.GetPasswordByNameRow, error) {
+		return q.GetPasswordByName(ctx, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return convert.GetPasswordByNameResp(&resp), nil
+}
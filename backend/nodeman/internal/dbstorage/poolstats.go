@@ -14,10 +14,32 @@ func (s *Storage) UpdateNodeStats(ctx context.Context,
 ) error {
 	// pre-convert
 	args := convert.UpdateNodeStatsReq(nodeID, stats)
+	devicesArgs := convert.UpdateDevicesStatsReq(stats)
+	onlineArgs := convert.UpdateNodeOnlineIPsReq(nodeID, stats)
 
 	// request
-	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
-		return q.UpdateTotalStats(ctx, args)
+	return s.DoTx(ctx, func(ctx context.Context) error {
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.UpdateTotalStats(ctx, args)
+		}); err != nil {
+			return err
+		}
+		if len(stats.Devices) != 0 {
+			if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+				return q.UpdateDevicesStats(ctx, devicesArgs)
+			}); err != nil {
+				return err
+			}
+		}
+		// node reports all online users, replace previous report
+		if err := doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.DeleteNodeOnlineIPs(ctx, int64(nodeID))
+		}); err != nil {
+			return err
+		}
+		return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+			return q.InsertNodeOnlineIPs(ctx, onlineArgs)
+		})
 	})
 }
 
@@ -28,3 +50,183 @@ func (s *Storage) UpdateDailyStats(ctx context.Context,
 		return q.UpdateDailyStats(ctx, day)
 	})
 }
+
+func (s *Storage) DisableOverQuotaUsers(ctx context.Context) (
+	[]models.UserID, error,
+) {
+	// pre-convert
+	req := queries.DisableOverQuotaUsersParams{
+		UserStatusDisabled: int16(models.UserStatusDisabled),
+		UserStatusEnabled:  int16(models.UserStatusEnabled),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]int64, error) {
+		return q.DisableOverQuotaUsers(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.UserIDsResp(resp), nil
+}
+
+func (s *Storage) RecordIPLimitViolations(ctx context.Context,
+	onlineSince time.Time, violationsSince time.Time,
+) ([]models.IPLimitViolation, error) {
+	// pre-convert
+	req := queries.RecordIPLimitViolationsParams{
+		OnlineSince:       onlineSince,
+		UserStatusEnabled: int16(models.UserStatusEnabled),
+		ViolationsSince:   violationsSince,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.RecordIPLimitViolationsRow, error) {
+		return q.RecordIPLimitViolations(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.RecordIPLimitViolationsResp(resp), nil
+}
+
+func (s *Storage) ListIPLimitViolations(ctx context.Context,
+	id models.UserID, maxCount int,
+) ([]models.IPLimitViolation, error) {
+	// pre-convert
+	req := queries.ListIPLimitViolationsParams{
+		UserID:   int64(id),
+		MaxCount: int32(maxCount),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListIPLimitViolationsRow, error) {
+		return q.ListIPLimitViolations(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListIPLimitViolationsResp(resp), nil
+}
+
+func (s *Storage) HasIPLimitViolation(ctx context.Context,
+	id models.UserID, since time.Time,
+) (bool, error) {
+	return doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (bool, error) {
+		return q.HasIPLimitViolation(ctx,
+			queries.HasIPLimitViolationParams{
+				UserID: int64(id),
+				Since:  since,
+			})
+	})
+}
+
+func (s *Storage) ResetUserQuotas(ctx context.Context,
+	now time.Time,
+) ([]models.UserID, error) {
+	// pre-convert
+	req := queries.ResetUserQuotasParams{
+		Now:                now,
+		UserStatusEnabled:  int16(models.UserStatusEnabled),
+		QuotaPeriodMonthly: int16(models.TrafficQuotaPeriodMonthly),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]int64, error) {
+		return q.ResetUserQuotas(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.UserIDsResp(resp), nil
+}
+
+func (s *Storage) GetUserTrafficHistory(ctx context.Context,
+	id models.UserID, r models.TrafficHistoryRange,
+) ([]models.DailyTraffic, error) {
+	// pre-convert
+	arg := queries.GetUserTrafficHistoryParams{
+		UserID:  int64(id),
+		FromDay: r.From,
+		ToDay:   r.To,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.GetUserTrafficHistoryRow, error) {
+		return q.GetUserTrafficHistory(ctx, arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.UserTrafficHistoryResp(resp), nil
+}
+
+func (s *Storage) GetNodeTrafficHistory(ctx context.Context,
+	id models.NodeID, r models.TrafficHistoryRange,
+) ([]models.DailyTraffic, error) {
+	// pre-convert
+	arg := queries.GetNodeTrafficHistoryParams{
+		NodeID:  int64(id),
+		FromDay: r.From,
+		ToDay:   r.To,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.GetNodeTrafficHistoryRow, error) {
+		return q.GetNodeTrafficHistory(ctx, arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.NodeTrafficHistoryResp(resp), nil
+}
+
+func (s *Storage) GetUserNodesTrafficHistory(ctx context.Context,
+	id models.UserID, r models.TrafficHistoryRange,
+) ([]models.NodeTrafficHistory, error) {
+	// pre-convert
+	arg := queries.GetUserNodesTrafficHistoryParams{
+		UserID:  int64(id),
+		FromDay: r.From,
+		ToDay:   r.To,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.GetUserNodesTrafficHistoryRow, error) {
+		return q.GetUserNodesTrafficHistory(ctx, arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.UserNodesTrafficHistoryResp(resp), nil
+}
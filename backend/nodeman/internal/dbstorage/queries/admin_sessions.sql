@@ -0,0 +1,76 @@
+-- name: NewAdminSession :one
+INSERT INTO admin_sessions (
+    admin_id,
+    refresh_hash,
+    client_ip,
+    expires_at
+) VALUES (
+    sqlc.arg(admin_id)::bigint,
+    sqlc.arg(refresh_hash)::bytea,
+    sqlc.arg(client_ip)::text,
+    sqlc.arg(expires_at)::timestamptz
+)
+RETURNING session_id, created_at, refreshed_at;
+
+-- name: GetAdminSession :one
+SELECT
+    session_id,
+    admin_id,
+    client_ip,
+    created_at,
+    refreshed_at,
+    expires_at
+FROM admin_sessions
+WHERE session_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > now();
+
+-- name: RotateAdminSession :one
+UPDATE admin_sessions
+SET
+    refresh_hash = sqlc.arg(new_refresh_hash)::bytea,
+    client_ip = sqlc.arg(client_ip)::text,
+    refreshed_at = now(),
+    expires_at = sqlc.arg(expires_at)::timestamptz
+WHERE refresh_hash = sqlc.arg(refresh_hash)::bytea
+    AND revoked_at IS NULL
+    AND expires_at > now()
+RETURNING
+    session_id,
+    admin_id,
+    client_ip,
+    created_at,
+    refreshed_at,
+    expires_at;
+
+-- name: ListAdminSessions :many
+SELECT
+    session_id,
+    admin_id,
+    client_ip,
+    created_at,
+    refreshed_at,
+    expires_at
+FROM admin_sessions
+WHERE admin_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > now()
+ORDER BY session_id ASC;
+
+-- name: RevokeAdminSession :exec
+UPDATE admin_sessions
+SET revoked_at = now()
+WHERE session_id = $1
+    AND admin_id = $2
+    AND revoked_at IS NULL;
+
+-- name: RevokeAdminSessions :exec
+UPDATE admin_sessions
+SET revoked_at = now()
+WHERE admin_id = $1
+    AND revoked_at IS NULL;
+
+-- name: RevokeAllAdminSessions :exec
+UPDATE admin_sessions
+SET revoked_at = now()
+WHERE revoked_at IS NULL;
@@ -0,0 +1,56 @@
+-- name: SetAdminTOTPSecret :exec
+UPDATE admin_auth
+SET
+    totp_secret = $1,
+    totp_enabled = FALSE,
+    totp_last_step = 0,
+    updated_at = now()
+WHERE admin_id = $2
+    AND deleted_at IS NULL
+    AND NOT totp_enabled;
+
+-- name: EnableAdminTOTP :exec
+UPDATE admin_auth
+SET
+    totp_enabled = TRUE,
+    updated_at = now()
+WHERE admin_id = $1
+    AND deleted_at IS NULL
+    AND totp_secret IS NOT NULL;
+
+-- name: DisableAdminTOTP :exec
+UPDATE admin_auth
+SET
+    totp_secret = NULL,
+    totp_enabled = FALSE,
+    totp_last_step = 0,
+    updated_at = now()
+WHERE admin_id = $1;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM admin_recovery_codes
+WHERE admin_id = $1;
+
+-- name: InsertRecoveryCodes :exec
+INSERT INTO admin_recovery_codes (admin_id, code_hash)
+SELECT
+    sqlc.arg(admin_id)::bigint,
+    unnest(sqlc.arg(code_hashes)::bytea[]);
+
+-- name: UseRecoveryCode :one
+UPDATE admin_recovery_codes
+SET used_at = now()
+WHERE admin_id = $1
+    AND code_hash = $2
+    AND used_at IS NULL
+RETURNING admin_id;
+
+-- name: UseAdminTOTPStep :one
+UPDATE admin_auth
+SET
+    totp_last_step = sqlc.arg(step)::bigint,
+    updated_at = now()
+WHERE admin_id = sqlc.arg(admin_id)::bigint
+    AND deleted_at IS NULL
+    AND totp_last_step < sqlc.arg(step)::bigint
+RETURNING admin_id;
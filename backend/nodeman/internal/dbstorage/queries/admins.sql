@@ -0,0 +1,47 @@
+-- name: NewAdmin :one
+INSERT INTO admin_auth (
+    admin_name,
+    admin_role,
+    password_hash
+) VALUES ($1, $2, $3)
+RETURNING admin_id;
+
+-- name: GetAdmin :one
+SELECT
+    admin_id,
+    admin_name,
+    admin_role
+FROM admin_auth
+WHERE admin_id = $1
+    AND deleted_at IS NULL;
+
+-- name: ListAdmins :many
+SELECT
+    admin_id,
+    admin_name,
+    admin_role
+FROM admin_auth
+WHERE deleted_at IS NULL
+ORDER BY admin_id ASC;
+
+-- name: SetAdminRole :exec
+UPDATE admin_auth
+SET
+    admin_role = $1,
+    updated_at = now()
+WHERE admin_id = $2
+    AND deleted_at IS NULL;
+
+-- name: SetAdminPassword :exec
+UPDATE admin_auth
+SET
+    password_hash = $1,
+    updated_at = now()
+WHERE admin_id = $2
+    AND deleted_at IS NULL;
+
+-- name: DeleteAdmin :exec
+UPDATE admin_auth
+SET deleted_at = now()
+WHERE admin_id = $1
+    AND deleted_at IS NULL;
@@ -0,0 +1,37 @@
+-- name: NewApiToken :one
+INSERT INTO api_tokens (
+    token_name,
+    token_hash,
+    scopes
+) VALUES (
+    sqlc.arg(token_name)::text,
+    sqlc.arg(token_hash)::bytea,
+    sqlc.arg(scopes)::smallint[]
+)
+RETURNING token_id, created_at;
+
+-- name: GetApiTokenByHash :one
+SELECT
+    token_id,
+    token_name,
+    scopes,
+    created_at
+FROM api_tokens
+WHERE token_hash = $1
+    AND revoked_at IS NULL;
+
+-- name: ListApiTokens :many
+SELECT
+    token_id,
+    token_name,
+    scopes,
+    created_at
+FROM api_tokens
+WHERE revoked_at IS NULL
+ORDER BY token_id ASC;
+
+-- name: RevokeApiToken :exec
+UPDATE api_tokens
+SET revoked_at = now()
+WHERE token_id = $1
+    AND revoked_at IS NULL;
@@ -0,0 +1,55 @@
+-- name: RecordAuditEvent :exec
+INSERT INTO audit_events (
+    actor_kind,
+    actor_id,
+    actor_name,
+    operation,
+    target_ids,
+    params,
+    client_ip,
+    request_id,
+    success,
+    error,
+    changes
+) VALUES (
+    sqlc.arg(actor_kind)::smallint,
+    sqlc.narg(actor_id)::bigint,
+    sqlc.arg(actor_name)::text,
+    sqlc.arg(operation)::text,
+    sqlc.arg(target_ids)::bigint[],
+    sqlc.arg(params)::jsonb,
+    sqlc.arg(client_ip)::text,
+    sqlc.arg(request_id)::text,
+    sqlc.arg(success)::boolean,
+    sqlc.arg(error)::text,
+    sqlc.arg(changes)::jsonb
+);
+
+-- name: ListAuditEvents :many
+SELECT
+    event_id,
+    created_at,
+    actor_kind,
+    actor_id,
+    actor_name,
+    operation,
+    target_ids,
+    params,
+    client_ip,
+    request_id,
+    success,
+    error,
+    changes
+FROM audit_events
+WHERE (sqlc.narg(before_id)::bigint IS NULL OR event_id < sqlc.narg(before_id)::bigint)
+    AND (sqlc.narg(operation)::text IS NULL OR operation = sqlc.narg(operation)::text)
+    AND (sqlc.narg(actor_name)::text IS NULL OR actor_name = sqlc.narg(actor_name)::text)
+    AND (sqlc.narg(target_id)::bigint IS NULL OR sqlc.narg(target_id)::bigint = ANY(target_ids))
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time)::timestamptz)
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time)::timestamptz)
+ORDER BY event_id DESC
+LIMIT sqlc.arg(max_count)::int;
+
+-- name: DeleteAuditEventsBefore :exec
+DELETE FROM audit_events
+WHERE created_at < $1;
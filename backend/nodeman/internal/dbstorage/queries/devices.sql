@@ -0,0 +1,61 @@
+-- name: NewUserDevice :one
+INSERT INTO user_devices (
+    user_id,
+    label,
+    vless_uuid
+)
+SELECT
+    u.user_id,
+    sqlc.arg(label)::text,
+    sqlc.arg(vless_uuid)::text
+FROM users u
+WHERE u.user_id = sqlc.arg(user_id)::bigint
+    AND u.deleted_at IS NULL
+RETURNING device_id, created_at;
+
+-- name: CountUserDevices :one
+SELECT COUNT(*)::int
+FROM user_devices
+WHERE user_id = $1;
+
+-- name: GetUserDevice :one
+SELECT
+    device_id,
+    user_id,
+    label,
+    vless_uuid,
+    created_at
+FROM user_devices
+WHERE device_id = $1
+    AND user_id = $2;
+
+-- name: ListUserDevices :many
+SELECT
+    d.device_id,
+    d.user_id,
+    d.label,
+    d.vless_uuid,
+    d.created_at,
+    COALESCE(t.upload, 0)::bigint AS upload,
+    COALESCE(t.download, 0)::bigint AS download
+FROM user_devices d
+LEFT JOIN total_devices_traffic t
+    ON t.device_id = d.device_id
+WHERE d.user_id = $1
+ORDER BY d.device_id ASC;
+
+-- name: SetUserDeviceLabel :one
+UPDATE user_devices
+SET label = $1
+WHERE device_id = $2
+RETURNING device_id;
+
+-- name: DeleteUserDevice :one
+DELETE FROM user_devices
+WHERE device_id = $1
+RETURNING device_id;
+
+-- name: ListDevicesVlessUUIDs :many
+SELECT vless_uuid
+FROM user_devices
+ORDER BY device_id ASC;
@@ -0,0 +1,56 @@
+-- name: DeleteNodeOnlineIPs :exec
+DELETE FROM user_online_ips
+WHERE node_id = $1;
+
+-- name: InsertNodeOnlineIPs :exec
+INSERT INTO user_online_ips (user_id, node_id, ip)
+SELECT DISTINCT
+    t.user_id,
+    sqlc.arg(node_id)::bigint,
+    t.ip
+FROM ROWS FROM (
+    unnest(sqlc.arg(user_id)::bigint[]),
+    unnest(sqlc.arg(ip)::text[])
+) AS t(user_id, ip);
+
+-- name: RecordIPLimitViolations :many
+-- distinct online ips over nodes reported after online_since,
+-- user violation is recorded once per violations window
+INSERT INTO ip_limit_violations (user_id, ip_count, ip_limit)
+SELECT
+    u.user_id,
+    o.ip_count,
+    u.ip_limit
+FROM users u
+JOIN (
+    SELECT
+        user_id,
+        COUNT(DISTINCT ip)::int AS ip_count
+    FROM user_online_ips
+    WHERE updated_at >= sqlc.arg(online_since)::timestamptz
+    GROUP BY user_id
+) o ON o.user_id = u.user_id
+WHERE u.ip_limit > 0
+    AND o.ip_count > u.ip_limit
+    AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+    AND u.deleted_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM ip_limit_violations v
+        WHERE v.user_id = u.user_id
+          AND v.created_at >= sqlc.arg(violations_since)::timestamptz
+    )
+RETURNING user_id, ip_count, ip_limit, created_at;
+
+-- name: ListIPLimitViolations :many
+SELECT user_id, ip_count, ip_limit, created_at
+FROM ip_limit_violations
+WHERE user_id = sqlc.arg(user_id)::bigint
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_count)::int;
+
+-- name: HasIPLimitViolation :one
+SELECT EXISTS (
+    SELECT 1 FROM ip_limit_violations
+    WHERE user_id = sqlc.arg(user_id)::bigint
+      AND created_at >= sqlc.arg(since)::timestamptz
+)::boolean;
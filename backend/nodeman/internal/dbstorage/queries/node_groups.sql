@@ -0,0 +1,54 @@
+-- name: NewNodeGroup :one
+INSERT INTO node_groups (group_name)
+VALUES ($1)
+RETURNING group_id;
+
+-- name: ListNodeGroups :many
+SELECT
+    g.group_id,
+    g.group_name,
+    COALESCE(
+        (SELECT array_agg(gn.node_id ORDER BY gn.node_id)
+         FROM node_group_nodes gn
+         WHERE gn.group_id = g.group_id),
+        '{}'
+    )::bigint[] AS node_ids,
+    COALESCE(
+        (SELECT array_agg(ug.user_id ORDER BY ug.user_id)
+         FROM user_node_groups ug
+         WHERE ug.group_id = g.group_id),
+        '{}'
+    )::bigint[] AS user_ids
+FROM node_groups g
+ORDER BY g.group_id ASC;
+
+-- name: ListNodeGroupNodes :many
+SELECT node_id
+FROM node_group_nodes
+WHERE group_id = $1;
+
+-- name: DeleteNodeGroup :exec
+DELETE FROM node_groups
+WHERE group_id = $1;
+
+-- name: DeleteNodeGroupNodes :exec
+DELETE FROM node_group_nodes
+WHERE node_id = $1;
+
+-- name: InsertNodeGroupNodes :exec
+INSERT INTO node_group_nodes (group_id, node_id)
+SELECT
+    unnest(sqlc.arg(group_id)::bigint[]),
+    sqlc.arg(node_id)::bigint
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteUserNodeGroups :exec
+DELETE FROM user_node_groups
+WHERE user_id = $1;
+
+-- name: InsertUserNodeGroups :exec
+INSERT INTO user_node_groups (user_id, group_id)
+SELECT
+    sqlc.arg(user_id)::bigint,
+    unnest(sqlc.arg(group_id)::bigint[])
+ON CONFLICT DO NOTHING;
@@ -17,7 +17,10 @@ SELECT
     node_endpoint,
     node_access_key,
     node_current_status,
-    node_target_status
+    node_target_status,
+    target_config_version,
+    current_config_version,
+    failed_config_version
 FROM nodes
 WHERE node_id = $1
     AND deleted_at IS NULL;
@@ -30,7 +33,10 @@ SELECT
     node_endpoint,
     node_access_key,
     node_current_status,
-    node_target_status
+    node_target_status,
+    target_config_version,
+    current_config_version,
+    failed_config_version
 FROM nodes
 WHERE deleted_at IS NULL
 ORDER BY node_id ASC;
@@ -60,6 +66,55 @@ SET
 WHERE node_id = $3
     AND deleted_at IS NULL;
 
+-- name: PushNodeConfig :one
+WITH next AS (
+    UPDATE nodes
+    SET
+        target_config_version = target_config_version + 1,
+        updated_at = now()
+    WHERE node_id = sqlc.arg(node_id)::bigint
+        AND deleted_at IS NULL
+    RETURNING node_id, target_config_version
+)
+INSERT INTO node_configs (
+    node_id,
+    config_version,
+    server_config,
+    client_config
+)
+SELECT
+    next.node_id,
+    next.target_config_version,
+    sqlc.arg(server_config)::text,
+    sqlc.arg(client_config)::text
+FROM next
+RETURNING config_version;
+
+-- name: GetNodeConfig :one
+SELECT
+    config_version,
+    server_config,
+    client_config
+FROM node_configs
+WHERE node_id = $1
+    AND config_version = $2;
+
+-- name: SetCurrentNodeConfigVersion :exec
+UPDATE nodes
+SET
+    current_config_version = $1,
+    updated_at = now()
+WHERE node_id = $2
+    AND deleted_at IS NULL;
+
+-- name: SetFailedNodeConfigVersion :exec
+UPDATE nodes
+SET
+    failed_config_version = $1,
+    updated_at = now()
+WHERE node_id = $2
+    AND deleted_at IS NULL;
+
 -- name: DeleteNode :exec
 UPDATE nodes
 SET deleted_at = now()
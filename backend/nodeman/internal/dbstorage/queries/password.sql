@@ -1,7 +1,11 @@
 -- name: GetPassword :one
 SELECT
     admin_id,
-    password_hash
+    admin_name,
+    admin_role,
+    password_hash,
+    totp_secret,
+    totp_enabled
 FROM admin_auth
 WHERE admin_id = $1
     AND deleted_at IS NULL;
@@ -9,11 +13,25 @@ WHERE admin_id = $1
 -- name: SetPassword :exec
 INSERT INTO admin_auth (
     admin_id,
+    admin_name,
+    admin_role,
     password_hash,
     updated_at
-) VALUES ($1, $2, now())
+) VALUES ($1, $2, $3, $4, now())
 ON CONFLICT (admin_id)
 DO UPDATE
 SET
     password_hash = EXCLUDED.password_hash,
-    updated_at = now();
\ No newline at end of file
+    updated_at = now();
+
+-- name: GetPasswordByName :one
+SELECT
+    admin_id,
+    admin_name,
+    admin_role,
+    password_hash,
+    totp_secret,
+    totp_enabled
+FROM admin_auth
+WHERE admin_name = $1
+    AND deleted_at IS NULL;
@@ -24,8 +24,18 @@ update_users AS (
         upload   = total_users_traffic.upload   + EXCLUDED.upload,
         download = total_users_traffic.download + EXCLUDED.download
     RETURNING 1 -- sqlc require to return something
+),
+-- 3. update users traffic per node
+update_user_nodes AS (
+    INSERT INTO total_user_nodes_traffic (user_id, node_id, upload, download)
+    SELECT user_id, sqlc.arg(node_id)::bigint, upload, download FROM input_data
+    ON CONFLICT (user_id, node_id) DO UPDATE
+    SET
+        upload   = total_user_nodes_traffic.upload   + EXCLUDED.upload,
+        download = total_user_nodes_traffic.download + EXCLUDED.download
+    RETURNING 1
 )
--- 3. update nodes stats
+-- 4. update nodes stats
 INSERT INTO total_nodes_traffic (node_id, upload, download)
 SELECT 
     sqlc.arg(node_id)::bigint,
@@ -67,6 +77,135 @@ WITH
     SET
         upload   = GREATEST(daily_nodes_traffic.upload, EXCLUDED.upload),
         download = GREATEST(daily_nodes_traffic.download, EXCLUDED.download)
+    ),
+    -- update users per node daily stats
+    snapshot_user_nodes AS (
+        INSERT INTO daily_user_nodes_traffic (day, user_id, node_id, upload, download)
+        SELECT
+            sqlc.arg(day)::date AS day,
+            t.user_id,
+            t.node_id,
+            t.upload,
+            t.download
+        FROM total_user_nodes_traffic t
+        ON CONFLICT (day, user_id, node_id) DO UPDATE
+        SET
+            upload   = GREATEST(daily_user_nodes_traffic.upload, EXCLUDED.upload),
+            download = GREATEST(daily_user_nodes_traffic.download, EXCLUDED.download)
+        RETURNING 1
     )
 -- do nothing stub
-SELECT 1;
\ No newline at end of file
+SELECT 1;
+
+-- name: DisableOverQuotaUsers :many
+UPDATE users u
+SET
+    user_target_status = sqlc.arg(user_status_disabled)::smallint,
+    quota_exceeded = TRUE,
+    updated_at = now()
+FROM total_users_traffic t
+WHERE t.user_id = u.user_id
+    AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+    AND u.quota_bytes > 0
+    AND NOT u.quota_ignored
+    AND (t.upload - u.quota_base_upload)
+      + (t.download - u.quota_base_download) >= u.quota_bytes
+    AND u.deleted_at IS NULL
+RETURNING u.user_id;
+
+-- monthly period starts at whole months from quota anchor,
+-- missed resets don't shift it
+-- name: ResetUserQuotas :many
+WITH periods AS (
+    SELECT
+        p.user_id,
+        p.quota_anchor + make_interval(months => (
+            EXTRACT(YEAR FROM age(sqlc.arg(now)::timestamptz, p.quota_anchor)) * 12
+            + EXTRACT(MONTH FROM age(sqlc.arg(now)::timestamptz, p.quota_anchor))
+        )::int) AS period_start
+    FROM users p
+    WHERE p.quota_period = sqlc.arg(quota_period_monthly)::smallint
+        AND p.deleted_at IS NULL
+)
+UPDATE users u
+SET
+    quota_period_start = periods.period_start,
+    quota_base_upload = COALESCE(t.upload, 0),
+    quota_base_download = COALESCE(t.download, 0),
+    user_target_status = CASE
+        WHEN u.quota_exceeded THEN sqlc.arg(user_status_enabled)::smallint
+        ELSE u.user_target_status
+    END,
+    quota_exceeded = FALSE,
+    quota_ignored = FALSE,
+    updated_at = now()
+FROM periods
+LEFT JOIN total_users_traffic t ON t.user_id = periods.user_id
+WHERE periods.user_id = u.user_id
+    AND periods.period_start > u.quota_period_start
+RETURNING u.user_id;
+
+-- daily tables keep cumulative traffic at the end of day,
+-- so day traffic is the difference with the previous snapshot
+
+-- name: GetUserTrafficHistory :many
+SELECT h.day, h.upload, h.download
+FROM (
+    SELECT
+        d.day,
+        GREATEST(d.upload - COALESCE(LAG(d.upload) OVER w, 0), 0)::bigint AS upload,
+        GREATEST(d.download - COALESCE(LAG(d.download) OVER w, 0), 0)::bigint AS download
+    FROM daily_users_traffic d
+    WHERE d.user_id = sqlc.arg(user_id)::bigint
+      AND d.day <= sqlc.arg(to_day)::date
+    WINDOW w AS (ORDER BY d.day)
+) h
+WHERE h.day >= sqlc.arg(from_day)::date
+ORDER BY h.day ASC;
+
+-- name: GetNodeTrafficHistory :many
+SELECT h.day, h.upload, h.download
+FROM (
+    SELECT
+        d.day,
+        GREATEST(d.upload - COALESCE(LAG(d.upload) OVER w, 0), 0)::bigint AS upload,
+        GREATEST(d.download - COALESCE(LAG(d.download) OVER w, 0), 0)::bigint AS download
+    FROM daily_nodes_traffic d
+    WHERE d.node_id = sqlc.arg(node_id)::bigint
+      AND d.day <= sqlc.arg(to_day)::date
+    WINDOW w AS (ORDER BY d.day)
+) h
+WHERE h.day >= sqlc.arg(from_day)::date
+ORDER BY h.day ASC;
+
+-- name: GetUserNodesTrafficHistory :many
+SELECT h.node_id, h.day, h.upload, h.download
+FROM (
+    SELECT
+        d.node_id,
+        d.day,
+        GREATEST(d.upload - COALESCE(LAG(d.upload) OVER w, 0), 0)::bigint AS upload,
+        GREATEST(d.download - COALESCE(LAG(d.download) OVER w, 0), 0)::bigint AS download
+    FROM daily_user_nodes_traffic d
+    WHERE d.user_id = sqlc.arg(user_id)::bigint
+      AND d.day <= sqlc.arg(to_day)::date
+    WINDOW w AS (PARTITION BY d.node_id ORDER BY d.day)
+) h
+WHERE h.day >= sqlc.arg(from_day)::date
+ORDER BY h.node_id ASC, h.day ASC;
+
+-- name: UpdateDevicesStats :exec
+-- devices removed before stats arrived are skipped
+INSERT INTO total_devices_traffic (device_id, upload, download)
+SELECT t.device_id, t.upload, t.download
+FROM ROWS FROM (
+    unnest(sqlc.arg(device_id)::bigint[]),
+    unnest(sqlc.arg(upload)::bigint[]),
+    unnest(sqlc.arg(download)::bigint[])
+) AS t(device_id, upload, download)
+INNER JOIN user_devices d
+    ON d.device_id = t.device_id
+ON CONFLICT (device_id) DO UPDATE
+SET
+    upload   = total_devices_traffic.upload   + EXCLUDED.upload,
+    download = total_devices_traffic.download + EXCLUDED.download;
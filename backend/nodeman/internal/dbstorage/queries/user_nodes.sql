@@ -1,41 +1,162 @@
-
 -- name: FindPendingSyncs :many
+-- user target status on node is disabled if user isn't entitled to node,
+-- previous user identity is required on node only for enabled user
+-- during credentials rotation grace period, user devices - for enabled user.
+-- devices are "<device_id>:<vless_uuid>" comma separated list
 SELECT
     u.user_id,
     u.user_name,
     u.display_name,
     u.vless_uuid,
-    u.user_target_status,
+    u.email,
+    (CASE WHEN a.node_id IS NULL
+        THEN sqlc.arg(default_user_status)::smallint
+        ELSE u.user_target_status
+    END)::smallint AS user_target_status,
     COALESCE(
         s.user_current_status,
         sqlc.arg(default_user_status)::smallint
-    ) AS user_current_status
+    ) AS user_current_status,
+    COALESCE(s.vless_uuid, '')::text AS current_vless_uuid,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        AND u.prev_vless_uuid_expires_at > sqlc.arg(now)::timestamptz
+        THEN u.prev_vless_uuid
+        ELSE ''
+    END)::text AS target_prev_vless_uuid,
+    COALESCE(s.prev_vless_uuid, '')::text AS current_prev_vless_uuid,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        THEN COALESCE(ud.devices, '')
+        ELSE ''
+    END)::text AS target_devices,
+    COALESCE(s.devices, '')::text AS current_devices
 FROM users u
 LEFT JOIN syncs s
     ON s.user_id = u.user_id
    AND s.node_id = $1
+LEFT JOIN user_node_access a
+    ON a.user_id = u.user_id
+   AND a.node_id = $1
+LEFT JOIN LATERAL (
+    SELECT string_agg(d.device_id::text || ':' || d.vless_uuid, ','
+        ORDER BY d.device_id) AS devices
+    FROM user_devices d
+    WHERE d.user_id = u.user_id
+) ud ON TRUE
 WHERE
     COALESCE(
         s.user_current_status,
         sqlc.arg(default_user_status)::smallint
-    ) IS DISTINCT FROM u.user_target_status;
+    ) IS DISTINCT FROM (CASE WHEN a.node_id IS NULL
+        THEN sqlc.arg(default_user_status)::smallint
+        ELSE u.user_target_status
+    END)
+    -- enabled user identity on node is outdated
+    OR (a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        AND COALESCE(s.vless_uuid, '') <> u.vless_uuid)
+    OR COALESCE(s.prev_vless_uuid, '') <> (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        AND u.prev_vless_uuid_expires_at > sqlc.arg(now)::timestamptz
+        THEN u.prev_vless_uuid
+        ELSE ''
+    END)
+    OR COALESCE(s.devices, '') <> (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        THEN COALESCE(ud.devices, '')
+        ELSE ''
+    END);
+
+-- name: ListNodeUsers :many
+-- like ListUsers, but with user target status on node
+SELECT
+    u.user_id,
+    u.display_name,
+    u.user_name,
+    u.vless_uuid,
+    u.sub_token,
+    u.email,
+    (CASE WHEN a.node_id IS NULL
+        THEN sqlc.arg(default_user_status)::smallint
+        ELSE u.user_target_status
+    END)::smallint AS user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
+FROM users u
+LEFT JOIN user_node_access a
+    ON a.user_id = u.user_id
+   AND a.node_id = sqlc.arg(node_id)::bigint
+WHERE u.deleted_at IS NULL
+ORDER BY u.user_id ASC;
+
+-- name: ListNodeUserSyncs :many
+-- target part of FindPendingSyncs for all node users,
+-- node is started with this complete users set.
+-- devices are "<device_id>:<vless_uuid>" comma separated list
+SELECT
+    u.user_id,
+    u.user_name,
+    u.display_name,
+    u.vless_uuid,
+    u.email,
+    (CASE WHEN a.node_id IS NULL
+        THEN sqlc.arg(default_user_status)::smallint
+        ELSE u.user_target_status
+    END)::smallint AS user_target_status,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        AND u.prev_vless_uuid_expires_at > sqlc.arg(now)::timestamptz
+        THEN u.prev_vless_uuid
+        ELSE ''
+    END)::text AS target_prev_vless_uuid,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+        THEN COALESCE(ud.devices, '')
+        ELSE ''
+    END)::text AS target_devices
+FROM users u
+LEFT JOIN user_node_access a
+    ON a.user_id = u.user_id
+   AND a.node_id = sqlc.arg(node_id)::bigint
+LEFT JOIN LATERAL (
+    SELECT string_agg(d.device_id::text || ':' || d.vless_uuid, ','
+        ORDER BY d.device_id) AS devices
+    FROM user_devices d
+    WHERE d.user_id = u.user_id
+) ud ON TRUE
+WHERE u.deleted_at IS NULL
+ORDER BY u.user_id ASC;
 
 -- name: DeleteNodeUsers :exec
 DELETE FROM syncs
 WHERE node_id = $1;
 
 -- name: InsertNodeUsers :exec
-INSERT INTO syncs (user_id, node_id, user_current_status)
+INSERT INTO syncs (user_id, node_id, user_current_status,
+    vless_uuid, prev_vless_uuid, devices)
 SELECT
     t.user_id,
     sqlc.arg(node_id)::bigint,
-    t.user_current_status
+    t.user_current_status,
+    t.vless_uuid,
+    t.prev_vless_uuid,
+    t.devices
 FROM ROWS FROM (
     unnest(sqlc.arg(user_id)::bigint[]),
-    unnest(sqlc.arg(user_current_status)::smallint[])
-) AS t(user_id, user_current_status)
+    unnest(sqlc.arg(user_current_status)::smallint[]),
+    unnest(sqlc.arg(vless_uuid)::text[]),
+    unnest(sqlc.arg(prev_vless_uuid)::text[]),
+    unnest(sqlc.arg(devices)::text[])
+) AS t(user_id, user_current_status, vless_uuid, prev_vless_uuid, devices)
 ON CONFLICT (user_id, node_id)
-DO UPDATE SET user_current_status = EXCLUDED.user_current_status;
+DO UPDATE SET
+    user_current_status = EXCLUDED.user_current_status,
+    vless_uuid = EXCLUDED.vless_uuid,
+    prev_vless_uuid = EXCLUDED.prev_vless_uuid,
+    devices = EXCLUDED.devices;
 
 -- name: GetUserNodes :many
 SELECT
@@ -49,6 +170,9 @@ SELECT
 FROM nodes n
 INNER JOIN syncs s
     ON s.node_id = n.node_id
+INNER JOIN user_node_access a
+    ON a.node_id = n.node_id
+   AND a.user_id = s.user_id
 WHERE s.user_id = $1
     AND s.user_current_status = sqlc.arg(user_status_enabled)::smallint
     AND n.node_target_status = sqlc.arg(node_status_running)::smallint
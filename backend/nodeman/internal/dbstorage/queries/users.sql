@@ -3,9 +3,14 @@ INSERT INTO users (
     display_name,
     user_name,
     vless_uuid,
-    user_target_status
-) VALUES ($1, $2, $3, $4)
-RETURNING user_id;
+    user_target_status,
+    quota_bytes,
+    quota_period,
+    expires_at,
+    ip_limit,
+    email
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING user_id, sub_token;
 
 -- name: GetUserView :one
 SELECT
@@ -13,11 +18,23 @@ SELECT
     u.display_name,
     u.user_name,
     u.vless_uuid,
+    u.sub_token,
+    u.email,
     u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit,
 
     COALESCE(total_stats.upload, 0)   AS upload_total,
     COALESCE(total_stats.download, 0) AS download_total,
 
+    GREATEST(COALESCE(total_stats.upload, 0)
+      - u.quota_base_upload, 0)::bigint AS upload_period,
+
+    GREATEST(COALESCE(total_stats.download, 0)
+      - u.quota_base_download, 0)::bigint AS download_period,
+
     (COALESCE(total_stats.upload, 0)
       - COALESCE(daily_stats.upload, 0))::bigint AS upload_last_days,
 
@@ -58,22 +75,57 @@ SELECT
     u.display_name,
     u.user_name,
     u.vless_uuid,
-    u.user_target_status
+    u.sub_token,
+    u.email,
+    u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
 FROM users u
 WHERE deleted_at IS NULL
 ORDER BY u.user_id ASC;
 
+-- name: GetUser :one
+SELECT
+    u.user_id,
+    u.display_name,
+    u.user_name,
+    u.vless_uuid,
+    u.sub_token,
+    u.email,
+    u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
+FROM users u
+WHERE u.user_id = $1
+    AND deleted_at IS NULL;
+
 -- name: ListUserViews :many
 SELECT
     u.user_id,
     u.display_name,
     u.user_name,
     u.vless_uuid,
+    u.sub_token,
+    u.email,
     u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit,
 
     COALESCE(total_stats.upload, 0)   AS upload_total,
     COALESCE(total_stats.download, 0) AS download_total,
 
+    GREATEST(COALESCE(total_stats.upload, 0)
+      - u.quota_base_upload, 0)::bigint AS upload_period,
+
+    GREATEST(COALESCE(total_stats.download, 0)
+      - u.quota_base_download, 0)::bigint AS download_period,
+
     (COALESCE(total_stats.upload, 0)
       - COALESCE(daily_stats.upload, 0))::bigint AS upload_last_days,
 
@@ -105,16 +157,167 @@ LEFT JOIN (
 WHERE u.deleted_at IS NULL
 ORDER BY u.user_id ASC;
 
+-- user enabled manually over quota isn't disabled
+-- again until the next period
 -- name: SetTargetUserStatus :exec
+UPDATE users u
+SET
+    user_target_status = sqlc.arg(user_target_status),
+    quota_exceeded = FALSE,
+    quota_ignored = sqlc.arg(user_target_status) = sqlc.arg(user_status_enabled)::smallint
+        AND u.quota_bytes > 0
+        AND EXISTS (
+            SELECT 1
+            FROM total_users_traffic t
+            WHERE t.user_id = u.user_id
+                AND (t.upload - u.quota_base_upload)
+                  + (t.download - u.quota_base_download) >= u.quota_bytes
+        ),
+    expired = FALSE,
+    updated_at = now()
+WHERE u.user_id = sqlc.arg(user_id)
+    AND u.deleted_at IS NULL;
+
+-- user disabled on quota is enabled again if its usage is under
+-- new quota, quota period change starts new period at now
+-- name: SetUserQuota :one
+WITH prev AS (
+    SELECT
+        p.user_id,
+        p.quota_period <> sqlc.arg(quota_period)::smallint AS new_period,
+        p.quota_exceeded AND (sqlc.arg(quota_bytes)::bigint = 0
+            OR p.quota_period <> sqlc.arg(quota_period)::smallint
+            OR (COALESCE(t.upload, 0) - p.quota_base_upload)
+             + (COALESCE(t.download, 0) - p.quota_base_download)
+             < sqlc.arg(quota_bytes)::bigint
+        ) AS reenable,
+        COALESCE(t.upload, 0)::bigint AS total_upload,
+        COALESCE(t.download, 0)::bigint AS total_download
+    FROM users p
+    LEFT JOIN total_users_traffic t ON t.user_id = p.user_id
+    WHERE p.user_id = sqlc.arg(user_id)
+        AND p.deleted_at IS NULL
+    FOR UPDATE OF p
+)
+UPDATE users u
+SET
+    quota_bytes = sqlc.arg(quota_bytes)::bigint,
+    quota_period = sqlc.arg(quota_period)::smallint,
+    quota_ignored = FALSE,
+    user_target_status = CASE
+        WHEN prev.reenable THEN sqlc.arg(user_status_enabled)::smallint
+        ELSE u.user_target_status
+    END,
+    quota_exceeded = u.quota_exceeded AND NOT prev.reenable,
+    quota_period_start = CASE
+        WHEN prev.new_period THEN sqlc.arg(now)::timestamptz
+        ELSE u.quota_period_start
+    END,
+    quota_anchor = CASE
+        WHEN prev.new_period THEN sqlc.arg(now)::timestamptz
+        ELSE u.quota_anchor
+    END,
+    quota_base_upload = CASE
+        WHEN prev.new_period THEN prev.total_upload
+        ELSE u.quota_base_upload
+    END,
+    quota_base_download = CASE
+        WHEN prev.new_period THEN prev.total_download
+        ELSE u.quota_base_download
+    END,
+    updated_at = now()
+FROM prev
+WHERE u.user_id = prev.user_id
+RETURNING prev.reenable AS reenabled;
+
+-- name: SetUserIPLimit :exec
 UPDATE users
 SET
-    user_target_status = $1,
+    ip_limit = $1,
     updated_at = now()
 WHERE user_id = $2
     AND deleted_at IS NULL;
 
+-- user disabled on expiration is enabled again
+-- if new expiration time isn't passed
+-- name: SetUserExpiration :one
+WITH prev AS (
+    SELECT
+        p.user_id,
+        p.expired AND (sqlc.narg(expires_at)::timestamptz IS NULL
+            OR sqlc.narg(expires_at)::timestamptz > sqlc.arg(now)::timestamptz
+        ) AS reenable
+    FROM users p
+    WHERE p.user_id = sqlc.arg(user_id)
+        AND p.deleted_at IS NULL
+    FOR UPDATE
+)
+UPDATE users u
+SET
+    expires_at = sqlc.narg(expires_at),
+    user_target_status = CASE
+        WHEN prev.reenable THEN sqlc.arg(user_status_enabled)::smallint
+        ELSE u.user_target_status
+    END,
+    expired = u.expired AND NOT prev.reenable,
+    updated_at = now()
+FROM prev
+WHERE u.user_id = prev.user_id
+RETURNING prev.reenable AS reenabled;
+
+-- select and disable in one statement, so user enabled
+-- or extended concurrently isn't disabled
+-- name: DisableExpiredUsers :many
+UPDATE users u
+SET
+    user_target_status = sqlc.arg(user_status_disabled)::smallint,
+    expired = TRUE,
+    updated_at = now()
+WHERE u.expires_at <= sqlc.arg(now)::timestamptz
+    AND u.user_target_status = sqlc.arg(user_status_enabled)::smallint
+    AND u.deleted_at IS NULL
+RETURNING
+    u.user_id,
+    u.display_name,
+    u.user_name,
+    u.vless_uuid,
+    u.sub_token,
+    u.email,
+    u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit;
+
 -- name: DeleteUser :exec
 UPDATE users
 SET deleted_at = now()
 WHERE user_id = $1
-    AND deleted_at IS NULL;
\ No newline at end of file
+    AND deleted_at IS NULL;
+
+-- name: FindUserBySubToken :one
+SELECT
+    user_id,
+    user_name
+FROM users
+WHERE sub_token = $1
+    AND deleted_at IS NULL;
+
+-- name: RotateUserSubToken :one
+UPDATE users
+SET sub_token = replace(gen_random_uuid()::text, '-', ''),
+    updated_at = now()
+WHERE user_id = $1
+    AND deleted_at IS NULL
+RETURNING sub_token;
+
+-- name: RotateUserCredentials :one
+-- current identity becomes previous one, kept on nodes until grace period ends
+UPDATE users
+SET prev_vless_uuid = vless_uuid,
+    prev_vless_uuid_expires_at = sqlc.narg(prev_expires_at),
+    vless_uuid = sqlc.arg(vless_uuid),
+    updated_at = now()
+WHERE user_id = sqlc.arg(user_id)
+    AND deleted_at IS NULL
+RETURNING user_id;
@@ -0,0 +1,25 @@
+-- name: NewWebhook :one
+INSERT INTO webhooks (
+    url,
+    events,
+    secret
+) VALUES (
+    sqlc.arg(url)::text,
+    sqlc.arg(events)::smallint[],
+    sqlc.arg(secret)::text
+)
+RETURNING webhook_id, created_at;
+
+-- name: ListWebhooks :many
+SELECT
+    webhook_id,
+    url,
+    events,
+    secret,
+    created_at
+FROM webhooks
+ORDER BY webhook_id ASC;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE webhook_id = $1;
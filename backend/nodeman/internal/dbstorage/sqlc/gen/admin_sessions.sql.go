@@ -0,0 +1,237 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: admin_sessions.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+)
+
+const getAdminSession = `-- name: GetAdminSession :one
+SELECT
+    session_id,
+    admin_id,
+    client_ip,
+    created_at,
+    refreshed_at,
+    expires_at
+FROM admin_sessions
+WHERE session_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > now()
+`
+
+type GetAdminSessionRow struct {
+	SessionID   int64
+	AdminID     int64
+	ClientIp    string
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) GetAdminSession(ctx context.Context, sessionID int64) (GetAdminSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, getAdminSession, sessionID)
+	var i GetAdminSessionRow
+	err := row.Scan(
+		&i.SessionID,
+		&i.AdminID,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.RefreshedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const listAdminSessions = `-- name: ListAdminSessions :many
+SELECT
+    session_id,
+    admin_id,
+    client_ip,
+    created_at,
+    refreshed_at,
+    expires_at
+FROM admin_sessions
+WHERE admin_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > now()
+ORDER BY session_id ASC
+`
+
+type ListAdminSessionsRow struct {
+	SessionID   int64
+	AdminID     int64
+	ClientIp    string
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) ListAdminSessions(ctx context.Context, adminID int64) ([]ListAdminSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAdminSessions, adminID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAdminSessionsRow
+	for rows.Next() {
+		var i ListAdminSessionsRow
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.AdminID,
+			&i.ClientIp,
+			&i.CreatedAt,
+			&i.RefreshedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const newAdminSession = `-- name: NewAdminSession :one
+INSERT INTO admin_sessions (
+    admin_id,
+    refresh_hash,
+    client_ip,
+    expires_at
+) VALUES (
+    $1::bigint,
+    $2::bytea,
+    $3::text,
+    $4::timestamptz
+)
+RETURNING session_id, created_at, refreshed_at
+`
+
+type NewAdminSessionParams struct {
+	AdminID     int64
+	RefreshHash []byte
+	ClientIp    string
+	ExpiresAt   time.Time
+}
+
+type NewAdminSessionRow struct {
+	SessionID   int64
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+}
+
+func (q *Queries) NewAdminSession(ctx context.Context, arg NewAdminSessionParams) (NewAdminSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, newAdminSession,
+		arg.AdminID,
+		arg.RefreshHash,
+		arg.ClientIp,
+		arg.ExpiresAt,
+	)
+	var i NewAdminSessionRow
+	err := row.Scan(&i.SessionID, &i.CreatedAt, &i.RefreshedAt)
+	return i, err
+}
+
+const revokeAdminSession = `-- name: RevokeAdminSession :exec
+UPDATE admin_sessions
+SET revoked_at = now()
+WHERE session_id = $1
+    AND admin_id = $2
+    AND revoked_at IS NULL
+`
+
+type RevokeAdminSessionParams struct {
+	SessionID int64
+	AdminID   int64
+}
+
+func (q *Queries) RevokeAdminSession(ctx context.Context, arg RevokeAdminSessionParams) error {
+	_, err := q.db.ExecContext(ctx, revokeAdminSession, arg.SessionID, arg.AdminID)
+	return err
+}
+
+const revokeAdminSessions = `-- name: RevokeAdminSessions :exec
+UPDATE admin_sessions
+SET revoked_at = now()
+WHERE admin_id = $1
+    AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAdminSessions(ctx context.Context, adminID int64) error {
+	_, err := q.db.ExecContext(ctx, revokeAdminSessions, adminID)
+	return err
+}
+
+const revokeAllAdminSessions = `-- name: RevokeAllAdminSessions :exec
+UPDATE admin_sessions
+SET revoked_at = now()
+WHERE revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAllAdminSessions(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, revokeAllAdminSessions)
+	return err
+}
+
+const rotateAdminSession = `-- name: RotateAdminSession :one
+UPDATE admin_sessions
+SET
+    refresh_hash = $1::bytea,
+    client_ip = $2::text,
+    refreshed_at = now(),
+    expires_at = $3::timestamptz
+WHERE refresh_hash = $4::bytea
+    AND revoked_at IS NULL
+    AND expires_at > now()
+RETURNING
+    session_id,
+    admin_id,
+    client_ip,
+    created_at,
+    refreshed_at,
+    expires_at
+`
+
+type RotateAdminSessionParams struct {
+	NewRefreshHash []byte
+	ClientIp       string
+	ExpiresAt      time.Time
+	RefreshHash    []byte
+}
+
+type RotateAdminSessionRow struct {
+	SessionID   int64
+	AdminID     int64
+	ClientIp    string
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) RotateAdminSession(ctx context.Context, arg RotateAdminSessionParams) (RotateAdminSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, rotateAdminSession,
+		arg.NewRefreshHash,
+		arg.ClientIp,
+		arg.ExpiresAt,
+		arg.RefreshHash,
+	)
+	var i RotateAdminSessionRow
+	err := row.Scan(
+		&i.SessionID,
+		&i.AdminID,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.RefreshedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
@@ -0,0 +1,136 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: admin_totp.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM admin_recovery_codes
+WHERE admin_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, adminID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, adminID)
+	return err
+}
+
+const disableAdminTOTP = `-- name: DisableAdminTOTP :exec
+UPDATE admin_auth
+SET
+    totp_secret = NULL,
+    totp_enabled = FALSE,
+    totp_last_step = 0,
+    updated_at = now()
+WHERE admin_id = $1
+`
+
+func (q *Queries) DisableAdminTOTP(ctx context.Context, adminID int64) error {
+	_, err := q.db.ExecContext(ctx, disableAdminTOTP, adminID)
+	return err
+}
+
+const enableAdminTOTP = `-- name: EnableAdminTOTP :exec
+UPDATE admin_auth
+SET
+    totp_enabled = TRUE,
+    updated_at = now()
+WHERE admin_id = $1
+    AND deleted_at IS NULL
+    AND totp_secret IS NOT NULL
+`
+
+func (q *Queries) EnableAdminTOTP(ctx context.Context, adminID int64) error {
+	_, err := q.db.ExecContext(ctx, enableAdminTOTP, adminID)
+	return err
+}
+
+const insertRecoveryCodes = `-- name: InsertRecoveryCodes :exec
+INSERT INTO admin_recovery_codes (admin_id, code_hash)
+SELECT
+    $1::bigint,
+    unnest($2::bytea[])
+`
+
+type InsertRecoveryCodesParams struct {
+	AdminID    int64
+	CodeHashes [][]byte
+}
+
+func (q *Queries) InsertRecoveryCodes(ctx context.Context, arg InsertRecoveryCodesParams) error {
+	_, err := q.db.ExecContext(ctx, insertRecoveryCodes, arg.AdminID, pq.Array(arg.CodeHashes))
+	return err
+}
+
+const setAdminTOTPSecret = `-- name: SetAdminTOTPSecret :exec
+UPDATE admin_auth
+SET
+    totp_secret = $1,
+    totp_enabled = FALSE,
+    totp_last_step = 0,
+    updated_at = now()
+WHERE admin_id = $2
+    AND deleted_at IS NULL
+    AND NOT totp_enabled
+`
+
+type SetAdminTOTPSecretParams struct {
+	TotpSecret sql.NullString
+	AdminID    int64
+}
+
+func (q *Queries) SetAdminTOTPSecret(ctx context.Context, arg SetAdminTOTPSecretParams) error {
+	_, err := q.db.ExecContext(ctx, setAdminTOTPSecret, arg.TotpSecret, arg.AdminID)
+	return err
+}
+
+const useAdminTOTPStep = `-- name: UseAdminTOTPStep :one
+UPDATE admin_auth
+SET
+    totp_last_step = $1::bigint,
+    updated_at = now()
+WHERE admin_id = $2::bigint
+    AND deleted_at IS NULL
+    AND totp_last_step < $1::bigint
+RETURNING admin_id
+`
+
+type UseAdminTOTPStepParams struct {
+	Step    int64
+	AdminID int64
+}
+
+func (q *Queries) UseAdminTOTPStep(ctx context.Context, arg UseAdminTOTPStepParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, useAdminTOTPStep, arg.Step, arg.AdminID)
+	var admin_id int64
+	err := row.Scan(&admin_id)
+	return admin_id, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE admin_recovery_codes
+SET used_at = now()
+WHERE admin_id = $1
+    AND code_hash = $2
+    AND used_at IS NULL
+RETURNING admin_id
+`
+
+type UseRecoveryCodeParams struct {
+	AdminID  int64
+	CodeHash []byte
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, useRecoveryCode, arg.AdminID, arg.CodeHash)
+	var admin_id int64
+	err := row.Scan(&admin_id)
+	return admin_id, err
+}
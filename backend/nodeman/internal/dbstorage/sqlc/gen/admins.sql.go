@@ -0,0 +1,144 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: admins.sql
+
+package queries
+
+import (
+	"context"
+)
+
+const deleteAdmin = `-- name: DeleteAdmin :exec
+UPDATE admin_auth
+SET deleted_at = now()
+WHERE admin_id = $1
+    AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteAdmin(ctx context.Context, adminID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAdmin, adminID)
+	return err
+}
+
+const getAdmin = `-- name: GetAdmin :one
+SELECT
+    admin_id,
+    admin_name,
+    admin_role
+FROM admin_auth
+WHERE admin_id = $1
+    AND deleted_at IS NULL
+`
+
+type GetAdminRow struct {
+	AdminID   int64
+	AdminName string
+	AdminRole int16
+}
+
+func (q *Queries) GetAdmin(ctx context.Context, adminID int64) (GetAdminRow, error) {
+	row := q.db.QueryRowContext(ctx, getAdmin, adminID)
+	var i GetAdminRow
+	err := row.Scan(&i.AdminID, &i.AdminName, &i.AdminRole)
+	return i, err
+}
+
+const listAdmins = `-- name: ListAdmins :many
+SELECT
+    admin_id,
+    admin_name,
+    admin_role
+FROM admin_auth
+WHERE deleted_at IS NULL
+ORDER BY admin_id ASC
+`
+
+type ListAdminsRow struct {
+	AdminID   int64
+	AdminName string
+	AdminRole int16
+}
+
+func (q *Queries) ListAdmins(ctx context.Context) ([]ListAdminsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAdmins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAdminsRow
+	for rows.Next() {
+		var i ListAdminsRow
+		if err := rows.Scan(&i.AdminID, &i.AdminName, &i.AdminRole); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const newAdmin = `-- name: NewAdmin :one
+INSERT INTO admin_auth (
+    admin_name,
+    admin_role,
+    password_hash
+) VALUES ($1, $2, $3)
+RETURNING admin_id
+`
+
+type NewAdminParams struct {
+	AdminName    string
+	AdminRole    int16
+	PasswordHash []byte
+}
+
+func (q *Queries) NewAdmin(ctx context.Context, arg NewAdminParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, newAdmin, arg.AdminName, arg.AdminRole, arg.PasswordHash)
+	var admin_id int64
+	err := row.Scan(&admin_id)
+	return admin_id, err
+}
+
+const setAdminPassword = `-- name: SetAdminPassword :exec
+UPDATE admin_auth
+SET
+    password_hash = $1,
+    updated_at = now()
+WHERE admin_id = $2
+    AND deleted_at IS NULL
+`
+
+type SetAdminPasswordParams struct {
+	PasswordHash []byte
+	AdminID      int64
+}
+
+func (q *Queries) SetAdminPassword(ctx context.Context, arg SetAdminPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setAdminPassword, arg.PasswordHash, arg.AdminID)
+	return err
+}
+
+const setAdminRole = `-- name: SetAdminRole :exec
+UPDATE admin_auth
+SET
+    admin_role = $1,
+    updated_at = now()
+WHERE admin_id = $2
+    AND deleted_at IS NULL
+`
+
+type SetAdminRoleParams struct {
+	AdminRole int16
+	AdminID   int64
+}
+
+func (q *Queries) SetAdminRole(ctx context.Context, arg SetAdminRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setAdminRole, arg.AdminRole, arg.AdminID)
+	return err
+}
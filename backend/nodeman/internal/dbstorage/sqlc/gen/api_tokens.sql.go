@@ -0,0 +1,132 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: api_tokens.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const getApiTokenByHash = `-- name: GetApiTokenByHash :one
+SELECT
+    token_id,
+    token_name,
+    scopes,
+    created_at
+FROM api_tokens
+WHERE token_hash = $1
+    AND revoked_at IS NULL
+`
+
+type GetApiTokenByHashRow struct {
+	TokenID   int64
+	TokenName string
+	Scopes    []int16
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetApiTokenByHash(ctx context.Context, tokenHash []byte) (GetApiTokenByHashRow, error) {
+	row := q.db.QueryRowContext(ctx, getApiTokenByHash, tokenHash)
+	var i GetApiTokenByHashRow
+	err := row.Scan(
+		&i.TokenID,
+		&i.TokenName,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listApiTokens = `-- name: ListApiTokens :many
+SELECT
+    token_id,
+    token_name,
+    scopes,
+    created_at
+FROM api_tokens
+WHERE revoked_at IS NULL
+ORDER BY token_id ASC
+`
+
+type ListApiTokensRow struct {
+	TokenID   int64
+	TokenName string
+	Scopes    []int16
+	CreatedAt time.Time
+}
+
+func (q *Queries) ListApiTokens(ctx context.Context) ([]ListApiTokensRow, error) {
+	rows, err := q.db.QueryContext(ctx, listApiTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApiTokensRow
+	for rows.Next() {
+		var i ListApiTokensRow
+		if err := rows.Scan(
+			&i.TokenID,
+			&i.TokenName,
+			pq.Array(&i.Scopes),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const newApiToken = `-- name: NewApiToken :one
+INSERT INTO api_tokens (
+    token_name,
+    token_hash,
+    scopes
+) VALUES (
+    $1::text,
+    $2::bytea,
+    $3::smallint[]
+)
+RETURNING token_id, created_at
+`
+
+type NewApiTokenParams struct {
+	TokenName string
+	TokenHash []byte
+	Scopes    []int16
+}
+
+type NewApiTokenRow struct {
+	TokenID   int64
+	CreatedAt time.Time
+}
+
+func (q *Queries) NewApiToken(ctx context.Context, arg NewApiTokenParams) (NewApiTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, newApiToken, arg.TokenName, arg.TokenHash, pq.Array(arg.Scopes))
+	var i NewApiTokenRow
+	err := row.Scan(&i.TokenID, &i.CreatedAt)
+	return i, err
+}
+
+const revokeApiToken = `-- name: RevokeApiToken :exec
+UPDATE api_tokens
+SET revoked_at = now()
+WHERE token_id = $1
+    AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeApiToken(ctx context.Context, tokenID int64) error {
+	_, err := q.db.ExecContext(ctx, revokeApiToken, tokenID)
+	return err
+}
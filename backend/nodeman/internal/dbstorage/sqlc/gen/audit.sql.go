@@ -0,0 +1,165 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: audit.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const deleteAuditEventsBefore = `-- name: DeleteAuditEventsBefore :exec
+DELETE FROM audit_events
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteAuditEventsBefore(ctx context.Context, createdAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteAuditEventsBefore, createdAt)
+	return err
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT
+    event_id,
+    created_at,
+    actor_kind,
+    actor_id,
+    actor_name,
+    operation,
+    target_ids,
+    params,
+    client_ip,
+    request_id,
+    success,
+    error,
+    changes
+FROM audit_events
+WHERE ($1::bigint IS NULL OR event_id < $1::bigint)
+    AND ($2::text IS NULL OR operation = $2::text)
+    AND ($3::text IS NULL OR actor_name = $3::text)
+    AND ($4::bigint IS NULL OR $4::bigint = ANY(target_ids))
+    AND ($5::timestamptz IS NULL OR created_at >= $5::timestamptz)
+    AND ($6::timestamptz IS NULL OR created_at < $6::timestamptz)
+ORDER BY event_id DESC
+LIMIT $7::int
+`
+
+type ListAuditEventsParams struct {
+	BeforeID  sql.NullInt64
+	Operation sql.NullString
+	ActorName sql.NullString
+	TargetID  sql.NullInt64
+	FromTime  sql.NullTime
+	ToTime    sql.NullTime
+	MaxCount  int32
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEvents,
+		arg.BeforeID,
+		arg.Operation,
+		arg.ActorName,
+		arg.TargetID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MaxCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.EventID,
+			&i.CreatedAt,
+			&i.ActorKind,
+			&i.ActorID,
+			&i.ActorName,
+			&i.Operation,
+			pq.Array(&i.TargetIds),
+			&i.Params,
+			&i.ClientIp,
+			&i.RequestID,
+			&i.Success,
+			&i.Error,
+			&i.Changes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordAuditEvent = `-- name: RecordAuditEvent :exec
+INSERT INTO audit_events (
+    actor_kind,
+    actor_id,
+    actor_name,
+    operation,
+    target_ids,
+    params,
+    client_ip,
+    request_id,
+    success,
+    error,
+    changes
+) VALUES (
+    $1::smallint,
+    $2::bigint,
+    $3::text,
+    $4::text,
+    $5::bigint[],
+    $6::jsonb,
+    $7::text,
+    $8::text,
+    $9::boolean,
+    $10::text,
+    $11::jsonb
+)
+`
+
+type RecordAuditEventParams struct {
+	ActorKind int16
+	ActorID   sql.NullInt64
+	ActorName string
+	Operation string
+	TargetIds []int64
+	Params    json.RawMessage
+	ClientIp  string
+	RequestID string
+	Success   bool
+	Error     string
+	Changes   json.RawMessage
+}
+
+func (q *Queries) RecordAuditEvent(ctx context.Context, arg RecordAuditEventParams) error {
+	_, err := q.db.ExecContext(ctx, recordAuditEvent,
+		arg.ActorKind,
+		arg.ActorID,
+		arg.ActorName,
+		arg.Operation,
+		pq.Array(arg.TargetIds),
+		arg.Params,
+		arg.ClientIp,
+		arg.RequestID,
+		arg.Success,
+		arg.Error,
+		arg.Changes,
+	)
+	return err
+}
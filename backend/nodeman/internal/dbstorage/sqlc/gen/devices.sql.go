@@ -0,0 +1,206 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: devices.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+)
+
+const countUserDevices = `-- name: CountUserDevices :one
+SELECT COUNT(*)::int
+FROM user_devices
+WHERE user_id = $1
+`
+
+func (q *Queries) CountUserDevices(ctx context.Context, userID int64) (int32, error) {
+	row := q.db.QueryRowContext(ctx, countUserDevices, userID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const deleteUserDevice = `-- name: DeleteUserDevice :one
+DELETE FROM user_devices
+WHERE device_id = $1
+RETURNING device_id
+`
+
+func (q *Queries) DeleteUserDevice(ctx context.Context, deviceID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, deleteUserDevice, deviceID)
+	var device_id int64
+	err := row.Scan(&device_id)
+	return device_id, err
+}
+
+const getUserDevice = `-- name: GetUserDevice :one
+SELECT
+    device_id,
+    user_id,
+    label,
+    vless_uuid,
+    created_at
+FROM user_devices
+WHERE device_id = $1
+    AND user_id = $2
+`
+
+type GetUserDeviceParams struct {
+	DeviceID int64
+	UserID   int64
+}
+
+func (q *Queries) GetUserDevice(ctx context.Context, arg GetUserDeviceParams) (UserDevice, error) {
+	row := q.db.QueryRowContext(ctx, getUserDevice, arg.DeviceID, arg.UserID)
+	var i UserDevice
+	err := row.Scan(
+		&i.DeviceID,
+		&i.UserID,
+		&i.Label,
+		&i.VlessUuid,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listDevicesVlessUUIDs = `-- name: ListDevicesVlessUUIDs :many
+SELECT vless_uuid
+FROM user_devices
+ORDER BY device_id ASC
+`
+
+func (q *Queries) ListDevicesVlessUUIDs(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listDevicesVlessUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var vless_uuid string
+		if err := rows.Scan(&vless_uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, vless_uuid)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserDevices = `-- name: ListUserDevices :many
+SELECT
+    d.device_id,
+    d.user_id,
+    d.label,
+    d.vless_uuid,
+    d.created_at,
+    COALESCE(t.upload, 0)::bigint AS upload,
+    COALESCE(t.download, 0)::bigint AS download
+FROM user_devices d
+LEFT JOIN total_devices_traffic t
+    ON t.device_id = d.device_id
+WHERE d.user_id = $1
+ORDER BY d.device_id ASC
+`
+
+type ListUserDevicesRow struct {
+	DeviceID  int64
+	UserID    int64
+	Label     string
+	VlessUuid string
+	CreatedAt time.Time
+	Upload    int64
+	Download  int64
+}
+
+func (q *Queries) ListUserDevices(ctx context.Context, userID int64) ([]ListUserDevicesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserDevices, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserDevicesRow
+	for rows.Next() {
+		var i ListUserDevicesRow
+		if err := rows.Scan(
+			&i.DeviceID,
+			&i.UserID,
+			&i.Label,
+			&i.VlessUuid,
+			&i.CreatedAt,
+			&i.Upload,
+			&i.Download,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const newUserDevice = `-- name: NewUserDevice :one
+INSERT INTO user_devices (
+    user_id,
+    label,
+    vless_uuid
+)
+SELECT
+    u.user_id,
+    $1::text,
+    $2::text
+FROM users u
+WHERE u.user_id = $3::bigint
+    AND u.deleted_at IS NULL
+RETURNING device_id, created_at
+`
+
+type NewUserDeviceParams struct {
+	Label     string
+	VlessUuid string
+	UserID    int64
+}
+
+type NewUserDeviceRow struct {
+	DeviceID  int64
+	CreatedAt time.Time
+}
+
+func (q *Queries) NewUserDevice(ctx context.Context, arg NewUserDeviceParams) (NewUserDeviceRow, error) {
+	row := q.db.QueryRowContext(ctx, newUserDevice, arg.Label, arg.VlessUuid, arg.UserID)
+	var i NewUserDeviceRow
+	err := row.Scan(&i.DeviceID, &i.CreatedAt)
+	return i, err
+}
+
+const setUserDeviceLabel = `-- name: SetUserDeviceLabel :one
+UPDATE user_devices
+SET label = $1
+WHERE device_id = $2
+RETURNING device_id
+`
+
+type SetUserDeviceLabelParams struct {
+	Label    string
+	DeviceID int64
+}
+
+func (q *Queries) SetUserDeviceLabel(ctx context.Context, arg SetUserDeviceLabelParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, setUserDeviceLabel, arg.Label, arg.DeviceID)
+	var device_id int64
+	err := row.Scan(&device_id)
+	return device_id, err
+}
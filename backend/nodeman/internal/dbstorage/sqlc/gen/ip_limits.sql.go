@@ -0,0 +1,184 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: ip_limits.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const deleteNodeOnlineIPs = `-- name: DeleteNodeOnlineIPs :exec
+DELETE FROM user_online_ips
+WHERE node_id = $1
+`
+
+func (q *Queries) DeleteNodeOnlineIPs(ctx context.Context, nodeID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteNodeOnlineIPs, nodeID)
+	return err
+}
+
+const hasIPLimitViolation = `-- name: HasIPLimitViolation :one
+SELECT EXISTS (
+    SELECT 1 FROM ip_limit_violations
+    WHERE user_id = $1::bigint
+      AND created_at >= $2::timestamptz
+)::boolean
+`
+
+type HasIPLimitViolationParams struct {
+	UserID int64
+	Since  time.Time
+}
+
+func (q *Queries) HasIPLimitViolation(ctx context.Context, arg HasIPLimitViolationParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasIPLimitViolation, arg.UserID, arg.Since)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const insertNodeOnlineIPs = `-- name: InsertNodeOnlineIPs :exec
+INSERT INTO user_online_ips (user_id, node_id, ip)
+SELECT DISTINCT
+    t.user_id,
+    $1::bigint,
+    t.ip
+FROM ROWS FROM (
+    unnest($2::bigint[]),
+    unnest($3::text[])
+) AS t(user_id, ip)
+`
+
+type InsertNodeOnlineIPsParams struct {
+	NodeID int64
+	UserID []int64
+	Ip     []string
+}
+
+func (q *Queries) InsertNodeOnlineIPs(ctx context.Context, arg InsertNodeOnlineIPsParams) error {
+	_, err := q.db.ExecContext(ctx, insertNodeOnlineIPs, arg.NodeID, pq.Array(arg.UserID), pq.Array(arg.Ip))
+	return err
+}
+
+const listIPLimitViolations = `-- name: ListIPLimitViolations :many
+SELECT user_id, ip_count, ip_limit, created_at
+FROM ip_limit_violations
+WHERE user_id = $1::bigint
+ORDER BY created_at DESC
+LIMIT $2::int
+`
+
+type ListIPLimitViolationsParams struct {
+	UserID   int64
+	MaxCount int32
+}
+
+type ListIPLimitViolationsRow struct {
+	UserID    int64
+	IpCount   int32
+	IpLimit   int32
+	CreatedAt time.Time
+}
+
+func (q *Queries) ListIPLimitViolations(ctx context.Context, arg ListIPLimitViolationsParams) ([]ListIPLimitViolationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listIPLimitViolations, arg.UserID, arg.MaxCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListIPLimitViolationsRow
+	for rows.Next() {
+		var i ListIPLimitViolationsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.IpCount,
+			&i.IpLimit,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordIPLimitViolations = `-- name: RecordIPLimitViolations :many
+INSERT INTO ip_limit_violations (user_id, ip_count, ip_limit)
+SELECT
+    u.user_id,
+    o.ip_count,
+    u.ip_limit
+FROM users u
+JOIN (
+    SELECT
+        user_id,
+        COUNT(DISTINCT ip)::int AS ip_count
+    FROM user_online_ips
+    WHERE updated_at >= $1::timestamptz
+    GROUP BY user_id
+) o ON o.user_id = u.user_id
+WHERE u.ip_limit > 0
+    AND o.ip_count > u.ip_limit
+    AND u.user_target_status = $2::smallint
+    AND u.deleted_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM ip_limit_violations v
+        WHERE v.user_id = u.user_id
+          AND v.created_at >= $3::timestamptz
+    )
+RETURNING user_id, ip_count, ip_limit, created_at
+`
+
+type RecordIPLimitViolationsParams struct {
+	OnlineSince       time.Time
+	UserStatusEnabled int16
+	ViolationsSince   time.Time
+}
+
+type RecordIPLimitViolationsRow struct {
+	UserID    int64
+	IpCount   int32
+	IpLimit   int32
+	CreatedAt time.Time
+}
+
+// distinct online ips over nodes reported after online_since,
+// user violation is recorded once per violations window
+func (q *Queries) RecordIPLimitViolations(ctx context.Context, arg RecordIPLimitViolationsParams) ([]RecordIPLimitViolationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, recordIPLimitViolations, arg.OnlineSince, arg.UserStatusEnabled, arg.ViolationsSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecordIPLimitViolationsRow
+	for rows.Next() {
+		var i RecordIPLimitViolationsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.IpCount,
+			&i.IpLimit,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -16,6 +16,53 @@ type AdminAuth struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    sql.NullTime
+	AdminName    string
+	AdminRole    int16
+	TotpSecret   sql.NullString
+	TotpEnabled  bool
+	TotpLastStep int64
+}
+
+type AdminRecoveryCode struct {
+	AdminID  int64
+	CodeHash []byte
+	UsedAt   sql.NullTime
+}
+
+type AdminSession struct {
+	SessionID   int64
+	AdminID     int64
+	RefreshHash []byte
+	ClientIp    string
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+	ExpiresAt   time.Time
+	RevokedAt   sql.NullTime
+}
+
+type ApiToken struct {
+	TokenID   int64
+	TokenName string
+	TokenHash []byte
+	Scopes    []int16
+	CreatedAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type AuditEvent struct {
+	EventID   int64
+	CreatedAt time.Time
+	ActorKind int16
+	ActorID   sql.NullInt64
+	ActorName string
+	Operation string
+	TargetIds []int64
+	Params    json.RawMessage
+	ClientIp  string
+	RequestID string
+	Success   bool
+	Error     string
+	Changes   json.RawMessage
 }
 
 type DailyNodesTraffic struct {
@@ -25,6 +72,14 @@ type DailyNodesTraffic struct {
 	Upload   int64
 }
 
+type DailyUserNodesTraffic struct {
+	Day      time.Time
+	UserID   int64
+	NodeID   int64
+	Download int64
+	Upload   int64
+}
+
 type DailyUsersTraffic struct {
 	Day      time.Time
 	UserID   int64
@@ -32,17 +87,47 @@ type DailyUsersTraffic struct {
 	Upload   int64
 }
 
+type IpLimitViolation struct {
+	ViolationID int64
+	UserID      int64
+	IpCount     int32
+	IpLimit     int32
+	CreatedAt   time.Time
+}
+
 type Node struct {
-	NodeID            int64
-	ClientCfgTemplate string
-	NodeEndpoint      string
-	NodeAccessKey     []byte
-	NodeCurrentStatus int16
-	NodeTargetStatus  int16
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	DeletedAt         sql.NullTime
-	Version           string
+	NodeID               int64
+	ClientCfgTemplate    string
+	NodeEndpoint         string
+	NodeAccessKey        []byte
+	NodeCurrentStatus    int16
+	NodeTargetStatus     int16
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	DeletedAt            sql.NullTime
+	Version              string
+	TargetConfigVersion  int64
+	CurrentConfigVersion int64
+	FailedConfigVersion  int64
+}
+
+type NodeConfig struct {
+	NodeID        int64
+	ConfigVersion int64
+	ServerConfig  string
+	ClientConfig  string
+	CreatedAt     time.Time
+}
+
+type NodeGroup struct {
+	GroupID   int64
+	GroupName string
+	CreatedAt time.Time
+}
+
+type NodeGroupNode struct {
+	GroupID int64
+	NodeID  int64
 }
 
 type Setting struct {
@@ -64,6 +149,15 @@ type Sync struct {
 	UserID            int64
 	NodeID            int64
 	UserCurrentStatus int16
+	VlessUuid         string
+	PrevVlessUuid     string
+	Devices           string
+}
+
+type TotalDevicesTraffic struct {
+	DeviceID int64
+	Upload   int64
+	Download int64
 }
 
 type TotalNodesTraffic struct {
@@ -72,6 +166,13 @@ type TotalNodesTraffic struct {
 	Upload   int64
 }
 
+type TotalUserNodesTraffic struct {
+	UserID   int64
+	NodeID   int64
+	Download int64
+	Upload   int64
+}
+
 type TotalUsersTraffic struct {
 	UserID   int64
 	Download int64
@@ -79,12 +180,60 @@ type TotalUsersTraffic struct {
 }
 
 type User struct {
-	UserID           int64
-	DisplayName      string
-	UserName         string
-	VlessUuid        string
-	UserTargetStatus int16
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	DeletedAt        sql.NullTime
+	UserID                 int64
+	DisplayName            string
+	UserName               string
+	VlessUuid              string
+	UserTargetStatus       int16
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	DeletedAt              sql.NullTime
+	QuotaBytes             int64
+	QuotaPeriod            int16
+	QuotaPeriodStart       time.Time
+	QuotaBaseUpload        int64
+	QuotaBaseDownload      int64
+	QuotaExceeded          bool
+	ExpiresAt              sql.NullTime
+	IpLimit                int32
+	SubToken               string
+	PrevVlessUuid          string
+	PrevVlessUuidExpiresAt sql.NullTime
+	Email                  string
+	Expired                bool
+	QuotaAnchor            time.Time
+	QuotaIgnored           bool
+}
+
+type UserDevice struct {
+	DeviceID  int64
+	UserID    int64
+	Label     string
+	VlessUuid string
+	CreatedAt time.Time
+}
+
+type UserNodeAccess struct {
+	UserID int64
+	NodeID int64
+}
+
+type UserNodeGroup struct {
+	UserID  int64
+	GroupID int64
+}
+
+type UserOnlineIp struct {
+	UserID    int64
+	NodeID    int64
+	Ip        string
+	UpdatedAt time.Time
+}
+
+type Webhook struct {
+	WebhookID int64
+	Url       string
+	Events    []int16
+	Secret    string
+	CreatedAt time.Time
 }
@@ -0,0 +1,175 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: node_groups.sql
+
+package queries
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const deleteNodeGroup = `-- name: DeleteNodeGroup :exec
+DELETE FROM node_groups
+WHERE group_id = $1
+`
+
+func (q *Queries) DeleteNodeGroup(ctx context.Context, groupID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteNodeGroup, groupID)
+	return err
+}
+
+const deleteNodeGroupNodes = `-- name: DeleteNodeGroupNodes :exec
+DELETE FROM node_group_nodes
+WHERE node_id = $1
+`
+
+func (q *Queries) DeleteNodeGroupNodes(ctx context.Context, nodeID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteNodeGroupNodes, nodeID)
+	return err
+}
+
+const deleteUserNodeGroups = `-- name: DeleteUserNodeGroups :exec
+DELETE FROM user_node_groups
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserNodeGroups(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserNodeGroups, userID)
+	return err
+}
+
+const insertNodeGroupNodes = `-- name: InsertNodeGroupNodes :exec
+INSERT INTO node_group_nodes (group_id, node_id)
+SELECT
+    unnest($1::bigint[]),
+    $2::bigint
+ON CONFLICT DO NOTHING
+`
+
+type InsertNodeGroupNodesParams struct {
+	GroupID []int64
+	NodeID  int64
+}
+
+func (q *Queries) InsertNodeGroupNodes(ctx context.Context, arg InsertNodeGroupNodesParams) error {
+	_, err := q.db.ExecContext(ctx, insertNodeGroupNodes, pq.Array(arg.GroupID), arg.NodeID)
+	return err
+}
+
+const insertUserNodeGroups = `-- name: InsertUserNodeGroups :exec
+INSERT INTO user_node_groups (user_id, group_id)
+SELECT
+    $1::bigint,
+    unnest($2::bigint[])
+ON CONFLICT DO NOTHING
+`
+
+type InsertUserNodeGroupsParams struct {
+	UserID  int64
+	GroupID []int64
+}
+
+func (q *Queries) InsertUserNodeGroups(ctx context.Context, arg InsertUserNodeGroupsParams) error {
+	_, err := q.db.ExecContext(ctx, insertUserNodeGroups, arg.UserID, pq.Array(arg.GroupID))
+	return err
+}
+
+const listNodeGroupNodes = `-- name: ListNodeGroupNodes :many
+SELECT node_id
+FROM node_group_nodes
+WHERE group_id = $1
+`
+
+func (q *Queries) ListNodeGroupNodes(ctx context.Context, groupID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listNodeGroupNodes, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var node_id int64
+		if err := rows.Scan(&node_id); err != nil {
+			return nil, err
+		}
+		items = append(items, node_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNodeGroups = `-- name: ListNodeGroups :many
+SELECT
+    g.group_id,
+    g.group_name,
+    COALESCE(
+        (SELECT array_agg(gn.node_id ORDER BY gn.node_id)
+         FROM node_group_nodes gn
+         WHERE gn.group_id = g.group_id),
+        '{}'
+    )::bigint[] AS node_ids,
+    COALESCE(
+        (SELECT array_agg(ug.user_id ORDER BY ug.user_id)
+         FROM user_node_groups ug
+         WHERE ug.group_id = g.group_id),
+        '{}'
+    )::bigint[] AS user_ids
+FROM node_groups g
+ORDER BY g.group_id ASC
+`
+
+type ListNodeGroupsRow struct {
+	GroupID   int64
+	GroupName string
+	NodeIds   []int64
+	UserIds   []int64
+}
+
+func (q *Queries) ListNodeGroups(ctx context.Context) ([]ListNodeGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNodeGroups)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNodeGroupsRow
+	for rows.Next() {
+		var i ListNodeGroupsRow
+		if err := rows.Scan(
+			&i.GroupID,
+			&i.GroupName,
+			pq.Array(&i.NodeIds),
+			pq.Array(&i.UserIds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const newNodeGroup = `-- name: NewNodeGroup :one
+INSERT INTO node_groups (group_name)
+VALUES ($1)
+RETURNING group_id
+`
+
+func (q *Queries) NewNodeGroup(ctx context.Context, groupName string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, newNodeGroup, groupName)
+	var group_id int64
+	err := row.Scan(&group_id)
+	return group_id, err
+}
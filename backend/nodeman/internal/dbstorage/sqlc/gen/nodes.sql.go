@@ -29,20 +29,26 @@ SELECT
     node_endpoint,
     node_access_key,
     node_current_status,
-    node_target_status
+    node_target_status,
+    target_config_version,
+    current_config_version,
+    failed_config_version
 FROM nodes
 WHERE node_id = $1
     AND deleted_at IS NULL
 `
 
 type GetNodeRow struct {
-	NodeID            int64
-	ClientCfgTemplate string
-	Version           string
-	NodeEndpoint      string
-	NodeAccessKey     []byte
-	NodeCurrentStatus int16
-	NodeTargetStatus  int16
+	NodeID               int64
+	ClientCfgTemplate    string
+	Version              string
+	NodeEndpoint         string
+	NodeAccessKey        []byte
+	NodeCurrentStatus    int16
+	NodeTargetStatus     int16
+	TargetConfigVersion  int64
+	CurrentConfigVersion int64
+	FailedConfigVersion  int64
 }
 
 func (q *Queries) GetNode(ctx context.Context, nodeID int64) (GetNodeRow, error) {
@@ -56,10 +62,41 @@ func (q *Queries) GetNode(ctx context.Context, nodeID int64) (GetNodeRow, error)
 		&i.NodeAccessKey,
 		&i.NodeCurrentStatus,
 		&i.NodeTargetStatus,
+		&i.TargetConfigVersion,
+		&i.CurrentConfigVersion,
+		&i.FailedConfigVersion,
 	)
 	return i, err
 }
 
+const getNodeConfig = `-- name: GetNodeConfig :one
+SELECT
+    config_version,
+    server_config,
+    client_config
+FROM node_configs
+WHERE node_id = $1
+    AND config_version = $2
+`
+
+type GetNodeConfigParams struct {
+	NodeID        int64
+	ConfigVersion int64
+}
+
+type GetNodeConfigRow struct {
+	ConfigVersion int64
+	ServerConfig  string
+	ClientConfig  string
+}
+
+func (q *Queries) GetNodeConfig(ctx context.Context, arg GetNodeConfigParams) (GetNodeConfigRow, error) {
+	row := q.db.QueryRowContext(ctx, getNodeConfig, arg.NodeID, arg.ConfigVersion)
+	var i GetNodeConfigRow
+	err := row.Scan(&i.ConfigVersion, &i.ServerConfig, &i.ClientConfig)
+	return i, err
+}
+
 const listNodes = `-- name: ListNodes :many
 SELECT
     node_id,
@@ -68,20 +105,26 @@ SELECT
     node_endpoint,
     node_access_key,
     node_current_status,
-    node_target_status
+    node_target_status,
+    target_config_version,
+    current_config_version,
+    failed_config_version
 FROM nodes
 WHERE deleted_at IS NULL
 ORDER BY node_id ASC
 `
 
 type ListNodesRow struct {
-	NodeID            int64
-	ClientCfgTemplate string
-	Version           string
-	NodeEndpoint      string
-	NodeAccessKey     []byte
-	NodeCurrentStatus int16
-	NodeTargetStatus  int16
+	NodeID               int64
+	ClientCfgTemplate    string
+	Version              string
+	NodeEndpoint         string
+	NodeAccessKey        []byte
+	NodeCurrentStatus    int16
+	NodeTargetStatus     int16
+	TargetConfigVersion  int64
+	CurrentConfigVersion int64
+	FailedConfigVersion  int64
 }
 
 func (q *Queries) ListNodes(ctx context.Context) ([]ListNodesRow, error) {
@@ -101,6 +144,9 @@ func (q *Queries) ListNodes(ctx context.Context) ([]ListNodesRow, error) {
 			&i.NodeAccessKey,
 			&i.NodeCurrentStatus,
 			&i.NodeTargetStatus,
+			&i.TargetConfigVersion,
+			&i.CurrentConfigVersion,
+			&i.FailedConfigVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -150,6 +196,63 @@ func (q *Queries) NewNode(ctx context.Context, arg NewNodeParams) (int64, error)
 	return node_id, err
 }
 
+const pushNodeConfig = `-- name: PushNodeConfig :one
+WITH next AS (
+    UPDATE nodes
+    SET
+        target_config_version = target_config_version + 1,
+        updated_at = now()
+    WHERE node_id = $3::bigint
+        AND deleted_at IS NULL
+    RETURNING node_id, target_config_version
+)
+INSERT INTO node_configs (
+    node_id,
+    config_version,
+    server_config,
+    client_config
+)
+SELECT
+    next.node_id,
+    next.target_config_version,
+    $1::text,
+    $2::text
+FROM next
+RETURNING config_version
+`
+
+type PushNodeConfigParams struct {
+	ServerConfig string
+	ClientConfig string
+	NodeID       int64
+}
+
+func (q *Queries) PushNodeConfig(ctx context.Context, arg PushNodeConfigParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, pushNodeConfig, arg.ServerConfig, arg.ClientConfig, arg.NodeID)
+	var config_version int64
+	err := row.Scan(&config_version)
+	return config_version, err
+}
+
+const setCurrentNodeConfigVersion = `-- name: SetCurrentNodeConfigVersion :exec
+UPDATE nodes
+SET
+    current_config_version = $1,
+    updated_at = now()
+WHERE node_id = $2
+    AND deleted_at IS NULL
+`
+
+type SetCurrentNodeConfigVersionParams struct {
+	CurrentConfigVersion int64
+	NodeID               int64
+}
+
+func (q *Queries) SetCurrentNodeConfigVersion(ctx context.Context, arg SetCurrentNodeConfigVersionParams) error {
+	_, err := q.db.ExecContext(ctx, setCurrentNodeConfigVersion, arg.CurrentConfigVersion, arg.NodeID)
+	return err
+}
+
 const setCurrentNodeStatus = `-- name: SetCurrentNodeStatus :exec
 UPDATE nodes
 SET
@@ -169,6 +272,25 @@ func (q *Queries) SetCurrentNodeStatus(ctx context.Context, arg SetCurrentNodeSt
 	return err
 }
 
+const setFailedNodeConfigVersion = `-- name: SetFailedNodeConfigVersion :exec
+UPDATE nodes
+SET
+    failed_config_version = $1,
+    updated_at = now()
+WHERE node_id = $2
+    AND deleted_at IS NULL
+`
+
+type SetFailedNodeConfigVersionParams struct {
+	FailedConfigVersion int64
+	NodeID              int64
+}
+
+func (q *Queries) SetFailedNodeConfigVersion(ctx context.Context, arg SetFailedNodeConfigVersionParams) error {
+	_, err := q.db.ExecContext(ctx, setFailedNodeConfigVersion, arg.FailedConfigVersion, arg.NodeID)
+	return err
+}
+
 const setNodeSettings = `-- name: SetNodeSettings :exec
 UPDATE nodes
 SET
@@ -7,12 +7,17 @@ package queries
 
 import (
 	"context"
+	"database/sql"
 )
 
 const getPassword = `-- name: GetPassword :one
 SELECT
     admin_id,
-    password_hash
+    admin_name,
+    admin_role,
+    password_hash,
+    totp_secret,
+    totp_enabled
 FROM admin_auth
 WHERE admin_id = $1
     AND deleted_at IS NULL
@@ -20,22 +25,71 @@ WHERE admin_id = $1
 
 type GetPasswordRow struct {
 	AdminID      int64
+	AdminName    string
+	AdminRole    int16
 	PasswordHash []byte
+	TotpSecret   sql.NullString
+	TotpEnabled  bool
 }
 
 func (q *Queries) GetPassword(ctx context.Context, adminID int64) (GetPasswordRow, error) {
 	row := q.db.QueryRowContext(ctx, getPassword, adminID)
 	var i GetPasswordRow
-	err := row.Scan(&i.AdminID, &i.PasswordHash)
+	err := row.Scan(
+		&i.AdminID,
+		&i.AdminName,
+		&i.AdminRole,
+		&i.PasswordHash,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+	)
+	return i, err
+}
+
+const getPasswordByName = `-- name: GetPasswordByName :one
+SELECT
+    admin_id,
+    admin_name,
+    admin_role,
+    password_hash,
+    totp_secret,
+    totp_enabled
+FROM admin_auth
+WHERE admin_name = $1
+    AND deleted_at IS NULL
+`
+
+type GetPasswordByNameRow struct {
+	AdminID      int64
+	AdminName    string
+	AdminRole    int16
+	PasswordHash []byte
+	TotpSecret   sql.NullString
+	TotpEnabled  bool
+}
+
+func (q *Queries) GetPasswordByName(ctx context.Context, adminName string) (GetPasswordByNameRow, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordByName, adminName)
+	var i GetPasswordByNameRow
+	err := row.Scan(
+		&i.AdminID,
+		&i.AdminName,
+		&i.AdminRole,
+		&i.PasswordHash,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+	)
 	return i, err
 }
 
 const setPassword = `-- name: SetPassword :exec
 INSERT INTO admin_auth (
     admin_id,
+    admin_name,
+    admin_role,
     password_hash,
     updated_at
-) VALUES ($1, $2, now())
+) VALUES ($1, $2, $3, $4, now())
 ON CONFLICT (admin_id)
 DO UPDATE
 SET
@@ -45,10 +99,17 @@ SET
 
 type SetPasswordParams struct {
 	AdminID      int64
+	AdminName    string
+	AdminRole    int16
 	PasswordHash []byte
 }
 
 func (q *Queries) SetPassword(ctx context.Context, arg SetPasswordParams) error {
-	_, err := q.db.ExecContext(ctx, setPassword, arg.AdminID, arg.PasswordHash)
+	_, err := q.db.ExecContext(ctx, setPassword,
+		arg.AdminID,
+		arg.AdminName,
+		arg.AdminRole,
+		arg.PasswordHash,
+	)
 	return err
 }
@@ -12,6 +12,276 @@ import (
 	"github.com/lib/pq"
 )
 
+const disableOverQuotaUsers = `-- name: DisableOverQuotaUsers :many
+UPDATE users u
+SET
+    user_target_status = $1::smallint,
+    quota_exceeded = TRUE,
+    updated_at = now()
+FROM total_users_traffic t
+WHERE t.user_id = u.user_id
+    AND u.user_target_status = $2::smallint
+    AND u.quota_bytes > 0
+    AND NOT u.quota_ignored
+    AND (t.upload - u.quota_base_upload)
+      + (t.download - u.quota_base_download) >= u.quota_bytes
+    AND u.deleted_at IS NULL
+RETURNING u.user_id
+`
+
+type DisableOverQuotaUsersParams struct {
+	UserStatusDisabled int16
+	UserStatusEnabled  int16
+}
+
+func (q *Queries) DisableOverQuotaUsers(ctx context.Context, arg DisableOverQuotaUsersParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, disableOverQuotaUsers, arg.UserStatusDisabled, arg.UserStatusEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var user_id int64
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNodeTrafficHistory = `-- name: GetNodeTrafficHistory :many
+SELECT h.day, h.upload, h.download
+FROM (
+    SELECT
+        d.day,
+        GREATEST(d.upload - COALESCE(LAG(d.upload) OVER w, 0), 0)::bigint AS upload,
+        GREATEST(d.download - COALESCE(LAG(d.download) OVER w, 0), 0)::bigint AS download
+    FROM daily_nodes_traffic d
+    WHERE d.node_id = $1::bigint
+      AND d.day <= $2::date
+    WINDOW w AS (ORDER BY d.day)
+) h
+WHERE h.day >= $3::date
+ORDER BY h.day ASC
+`
+
+type GetNodeTrafficHistoryParams struct {
+	NodeID  int64
+	ToDay   time.Time
+	FromDay time.Time
+}
+
+type GetNodeTrafficHistoryRow struct {
+	Day      time.Time
+	Upload   int64
+	Download int64
+}
+
+func (q *Queries) GetNodeTrafficHistory(ctx context.Context, arg GetNodeTrafficHistoryParams) ([]GetNodeTrafficHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNodeTrafficHistory, arg.NodeID, arg.ToDay, arg.FromDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNodeTrafficHistoryRow
+	for rows.Next() {
+		var i GetNodeTrafficHistoryRow
+		if err := rows.Scan(&i.Day, &i.Upload, &i.Download); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserNodesTrafficHistory = `-- name: GetUserNodesTrafficHistory :many
+SELECT h.node_id, h.day, h.upload, h.download
+FROM (
+    SELECT
+        d.node_id,
+        d.day,
+        GREATEST(d.upload - COALESCE(LAG(d.upload) OVER w, 0), 0)::bigint AS upload,
+        GREATEST(d.download - COALESCE(LAG(d.download) OVER w, 0), 0)::bigint AS download
+    FROM daily_user_nodes_traffic d
+    WHERE d.user_id = $1::bigint
+      AND d.day <= $2::date
+    WINDOW w AS (PARTITION BY d.node_id ORDER BY d.day)
+) h
+WHERE h.day >= $3::date
+ORDER BY h.node_id ASC, h.day ASC
+`
+
+type GetUserNodesTrafficHistoryParams struct {
+	UserID  int64
+	ToDay   time.Time
+	FromDay time.Time
+}
+
+type GetUserNodesTrafficHistoryRow struct {
+	NodeID   int64
+	Day      time.Time
+	Upload   int64
+	Download int64
+}
+
+func (q *Queries) GetUserNodesTrafficHistory(ctx context.Context, arg GetUserNodesTrafficHistoryParams) ([]GetUserNodesTrafficHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserNodesTrafficHistory, arg.UserID, arg.ToDay, arg.FromDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserNodesTrafficHistoryRow
+	for rows.Next() {
+		var i GetUserNodesTrafficHistoryRow
+		if err := rows.Scan(
+			&i.NodeID,
+			&i.Day,
+			&i.Upload,
+			&i.Download,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTrafficHistory = `-- name: GetUserTrafficHistory :many
+
+SELECT h.day, h.upload, h.download
+FROM (
+    SELECT
+        d.day,
+        GREATEST(d.upload - COALESCE(LAG(d.upload) OVER w, 0), 0)::bigint AS upload,
+        GREATEST(d.download - COALESCE(LAG(d.download) OVER w, 0), 0)::bigint AS download
+    FROM daily_users_traffic d
+    WHERE d.user_id = $1::bigint
+      AND d.day <= $2::date
+    WINDOW w AS (ORDER BY d.day)
+) h
+WHERE h.day >= $3::date
+ORDER BY h.day ASC
+`
+
+type GetUserTrafficHistoryParams struct {
+	UserID  int64
+	ToDay   time.Time
+	FromDay time.Time
+}
+
+type GetUserTrafficHistoryRow struct {
+	Day      time.Time
+	Upload   int64
+	Download int64
+}
+
+// daily tables keep cumulative traffic at the end of day,
+// so day traffic is the difference with the previous snapshot
+func (q *Queries) GetUserTrafficHistory(ctx context.Context, arg GetUserTrafficHistoryParams) ([]GetUserTrafficHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserTrafficHistory, arg.UserID, arg.ToDay, arg.FromDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserTrafficHistoryRow
+	for rows.Next() {
+		var i GetUserTrafficHistoryRow
+		if err := rows.Scan(&i.Day, &i.Upload, &i.Download); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetUserQuotas = `-- name: ResetUserQuotas :many
+WITH periods AS (
+    SELECT
+        p.user_id,
+        p.quota_anchor + make_interval(months => (
+            EXTRACT(YEAR FROM age($2::timestamptz, p.quota_anchor)) * 12
+            + EXTRACT(MONTH FROM age($2::timestamptz, p.quota_anchor))
+        )::int) AS period_start
+    FROM users p
+    WHERE p.quota_period = $3::smallint
+        AND p.deleted_at IS NULL
+)
+UPDATE users u
+SET
+    quota_period_start = periods.period_start,
+    quota_base_upload = COALESCE(t.upload, 0),
+    quota_base_download = COALESCE(t.download, 0),
+    user_target_status = CASE
+        WHEN u.quota_exceeded THEN $1::smallint
+        ELSE u.user_target_status
+    END,
+    quota_exceeded = FALSE,
+    quota_ignored = FALSE,
+    updated_at = now()
+FROM periods
+LEFT JOIN total_users_traffic t ON t.user_id = periods.user_id
+WHERE periods.user_id = u.user_id
+    AND periods.period_start > u.quota_period_start
+RETURNING u.user_id
+`
+
+type ResetUserQuotasParams struct {
+	UserStatusEnabled  int16
+	Now                time.Time
+	QuotaPeriodMonthly int16
+}
+
+// monthly period starts at whole months from quota anchor,
+// missed resets don't shift it
+func (q *Queries) ResetUserQuotas(ctx context.Context, arg ResetUserQuotasParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, resetUserQuotas, arg.UserStatusEnabled, arg.Now, arg.QuotaPeriodMonthly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var user_id int64
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const setLocalTxFastMode = `-- name: SetLocalTxFastMode :exec
 SET LOCAL synchronous_commit = OFF
 `
@@ -51,6 +321,22 @@ WITH
     SET
         upload   = GREATEST(daily_nodes_traffic.upload, EXCLUDED.upload),
         download = GREATEST(daily_nodes_traffic.download, EXCLUDED.download)
+    ),
+    -- update users per node daily stats
+    snapshot_user_nodes AS (
+        INSERT INTO daily_user_nodes_traffic (day, user_id, node_id, upload, download)
+        SELECT
+            $1::date AS day,
+            t.user_id,
+            t.node_id,
+            t.upload,
+            t.download
+        FROM total_user_nodes_traffic t
+        ON CONFLICT (day, user_id, node_id) DO UPDATE
+        SET
+            upload   = GREATEST(daily_user_nodes_traffic.upload, EXCLUDED.upload),
+            download = GREATEST(daily_user_nodes_traffic.download, EXCLUDED.download)
+        RETURNING 1
     )
 SELECT 1
 `
@@ -61,6 +347,34 @@ func (q *Queries) UpdateDailyStats(ctx context.Context, day time.Time) error {
 	return err
 }
 
+const updateDevicesStats = `-- name: UpdateDevicesStats :exec
+INSERT INTO total_devices_traffic (device_id, upload, download)
+SELECT t.device_id, t.upload, t.download
+FROM ROWS FROM (
+    unnest($1::bigint[]),
+    unnest($2::bigint[]),
+    unnest($3::bigint[])
+) AS t(device_id, upload, download)
+INNER JOIN user_devices d
+    ON d.device_id = t.device_id
+ON CONFLICT (device_id) DO UPDATE
+SET
+    upload   = total_devices_traffic.upload   + EXCLUDED.upload,
+    download = total_devices_traffic.download + EXCLUDED.download
+`
+
+type UpdateDevicesStatsParams struct {
+	DeviceID []int64
+	Upload   []int64
+	Download []int64
+}
+
+// devices removed before stats arrived are skipped
+func (q *Queries) UpdateDevicesStats(ctx context.Context, arg UpdateDevicesStatsParams) error {
+	_, err := q.db.ExecContext(ctx, updateDevicesStats, pq.Array(arg.DeviceID), pq.Array(arg.Upload), pq.Array(arg.Download))
+	return err
+}
+
 const updateTotalStats = `-- name: UpdateTotalStats :exec
 WITH 
 input_data AS (
@@ -82,6 +396,15 @@ update_users AS (
         upload   = total_users_traffic.upload   + EXCLUDED.upload,
         download = total_users_traffic.download + EXCLUDED.download
     RETURNING 1 -- sqlc require to return something
+),
+update_user_nodes AS (
+    INSERT INTO total_user_nodes_traffic (user_id, node_id, upload, download)
+    SELECT user_id, $1::bigint, upload, download FROM input_data
+    ON CONFLICT (user_id, node_id) DO UPDATE
+    SET
+        upload   = total_user_nodes_traffic.upload   + EXCLUDED.upload,
+        download = total_user_nodes_traffic.download + EXCLUDED.download
+    RETURNING 1
 )
 INSERT INTO total_nodes_traffic (node_id, upload, download)
 SELECT 
@@ -104,7 +427,8 @@ type UpdateTotalStatsParams struct {
 
 // 1. input -> flat table
 // 2. update users traffic
-// 3. update nodes stats
+// 3. update users traffic per node
+// 4. update nodes stats
 func (q *Queries) UpdateTotalStats(ctx context.Context, arg UpdateTotalStatsParams) error {
 	_, err := q.db.ExecContext(ctx, updateTotalStats,
 		arg.NodeID,
@@ -7,6 +7,8 @@ package queries
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/lib/pq"
 )
@@ -27,38 +29,100 @@ SELECT
     u.user_name,
     u.display_name,
     u.vless_uuid,
-    u.user_target_status,
+    u.email,
+    (CASE WHEN a.node_id IS NULL
+        THEN $2::smallint
+        ELSE u.user_target_status
+    END)::smallint AS user_target_status,
     COALESCE(
         s.user_current_status,
         $2::smallint
-    ) AS user_current_status
+    ) AS user_current_status,
+    COALESCE(s.vless_uuid, '')::text AS current_vless_uuid,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = $3::smallint
+        AND u.prev_vless_uuid_expires_at > $4::timestamptz
+        THEN u.prev_vless_uuid
+        ELSE ''
+    END)::text AS target_prev_vless_uuid,
+    COALESCE(s.prev_vless_uuid, '')::text AS current_prev_vless_uuid,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = $3::smallint
+        THEN COALESCE(ud.devices, '')
+        ELSE ''
+    END)::text AS target_devices,
+    COALESCE(s.devices, '')::text AS current_devices
 FROM users u
 LEFT JOIN syncs s
     ON s.user_id = u.user_id
    AND s.node_id = $1
+LEFT JOIN user_node_access a
+    ON a.user_id = u.user_id
+   AND a.node_id = $1
+LEFT JOIN LATERAL (
+    SELECT string_agg(d.device_id::text || ':' || d.vless_uuid, ','
+        ORDER BY d.device_id) AS devices
+    FROM user_devices d
+    WHERE d.user_id = u.user_id
+) ud ON TRUE
 WHERE
     COALESCE(
         s.user_current_status,
         $2::smallint
-    ) IS DISTINCT FROM u.user_target_status
+    ) IS DISTINCT FROM (CASE WHEN a.node_id IS NULL
+        THEN $2::smallint
+        ELSE u.user_target_status
+    END)
+    -- enabled user identity on node is outdated
+    OR (a.node_id IS NOT NULL
+        AND u.user_target_status = $3::smallint
+        AND COALESCE(s.vless_uuid, '') <> u.vless_uuid)
+    OR COALESCE(s.prev_vless_uuid, '') <> (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = $3::smallint
+        AND u.prev_vless_uuid_expires_at > $4::timestamptz
+        THEN u.prev_vless_uuid
+        ELSE ''
+    END)
+    OR COALESCE(s.devices, '') <> (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = $3::smallint
+        THEN COALESCE(ud.devices, '')
+        ELSE ''
+    END)
 `
 
 type FindPendingSyncsParams struct {
 	NodeID            int64
 	DefaultUserStatus int16
+	UserStatusEnabled int16
+	Now               time.Time
 }
 
 type FindPendingSyncsRow struct {
-	UserID            int64
-	UserName          string
-	DisplayName       string
-	VlessUuid         string
-	UserTargetStatus  int16
-	UserCurrentStatus int16
+	UserID               int64
+	UserName             string
+	DisplayName          string
+	VlessUuid            string
+	Email                string
+	UserTargetStatus     int16
+	UserCurrentStatus    int16
+	CurrentVlessUuid     string
+	TargetPrevVlessUuid  string
+	CurrentPrevVlessUuid string
+	TargetDevices        string
+	CurrentDevices       string
 }
 
+// user target status on node is disabled if user isn't entitled to node,
+// previous user identity is required on node only for enabled user
+// during credentials rotation grace period, user devices - for enabled user.
+// devices are "<device_id>:<vless_uuid>" comma separated list
 func (q *Queries) FindPendingSyncs(ctx context.Context, arg FindPendingSyncsParams) ([]FindPendingSyncsRow, error) {
-	rows, err := q.db.QueryContext(ctx, findPendingSyncs, arg.NodeID, arg.DefaultUserStatus)
+	rows, err := q.db.QueryContext(ctx, findPendingSyncs,
+		arg.NodeID,
+		arg.DefaultUserStatus,
+		arg.UserStatusEnabled,
+		arg.Now,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -71,8 +135,14 @@ func (q *Queries) FindPendingSyncs(ctx context.Context, arg FindPendingSyncsPara
 			&i.UserName,
 			&i.DisplayName,
 			&i.VlessUuid,
+			&i.Email,
 			&i.UserTargetStatus,
 			&i.UserCurrentStatus,
+			&i.CurrentVlessUuid,
+			&i.TargetPrevVlessUuid,
+			&i.CurrentPrevVlessUuid,
+			&i.TargetDevices,
+			&i.CurrentDevices,
 		); err != nil {
 			return nil, err
 		}
@@ -99,6 +169,9 @@ SELECT
 FROM nodes n
 INNER JOIN syncs s
     ON s.node_id = n.node_id
+INNER JOIN user_node_access a
+    ON a.node_id = n.node_id
+   AND a.user_id = s.user_id
 WHERE s.user_id = $1
     AND s.user_current_status = $2::smallint
     AND n.node_target_status = $3::smallint
@@ -154,26 +227,220 @@ func (q *Queries) GetUserNodes(ctx context.Context, arg GetUserNodesParams) ([]G
 }
 
 const insertNodeUsers = `-- name: InsertNodeUsers :exec
-INSERT INTO syncs (user_id, node_id, user_current_status)
+INSERT INTO syncs (user_id, node_id, user_current_status,
+    vless_uuid, prev_vless_uuid, devices)
 SELECT
     t.user_id,
     $1::bigint,
-    t.user_current_status
+    t.user_current_status,
+    t.vless_uuid,
+    t.prev_vless_uuid,
+    t.devices
 FROM ROWS FROM (
     unnest($2::bigint[]),
-    unnest($3::smallint[])
-) AS t(user_id, user_current_status)
+    unnest($3::smallint[]),
+    unnest($4::text[]),
+    unnest($5::text[]),
+    unnest($6::text[])
+) AS t(user_id, user_current_status, vless_uuid, prev_vless_uuid, devices)
 ON CONFLICT (user_id, node_id)
-DO UPDATE SET user_current_status = EXCLUDED.user_current_status
+DO UPDATE SET
+    user_current_status = EXCLUDED.user_current_status,
+    vless_uuid = EXCLUDED.vless_uuid,
+    prev_vless_uuid = EXCLUDED.prev_vless_uuid,
+    devices = EXCLUDED.devices
 `
 
 type InsertNodeUsersParams struct {
 	NodeID            int64
 	UserID            []int64
 	UserCurrentStatus []int16
+	VlessUuid         []string
+	PrevVlessUuid     []string
+	Devices           []string
 }
 
 func (q *Queries) InsertNodeUsers(ctx context.Context, arg InsertNodeUsersParams) error {
-	_, err := q.db.ExecContext(ctx, insertNodeUsers, arg.NodeID, pq.Array(arg.UserID), pq.Array(arg.UserCurrentStatus))
+	_, err := q.db.ExecContext(ctx, insertNodeUsers,
+		arg.NodeID,
+		pq.Array(arg.UserID),
+		pq.Array(arg.UserCurrentStatus),
+		pq.Array(arg.VlessUuid),
+		pq.Array(arg.PrevVlessUuid),
+		pq.Array(arg.Devices),
+	)
 	return err
 }
+
+const listNodeUserSyncs = `-- name: ListNodeUserSyncs :many
+SELECT
+    u.user_id,
+    u.user_name,
+    u.display_name,
+    u.vless_uuid,
+    u.email,
+    (CASE WHEN a.node_id IS NULL
+        THEN $1::smallint
+        ELSE u.user_target_status
+    END)::smallint AS user_target_status,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = $2::smallint
+        AND u.prev_vless_uuid_expires_at > $3::timestamptz
+        THEN u.prev_vless_uuid
+        ELSE ''
+    END)::text AS target_prev_vless_uuid,
+    (CASE WHEN a.node_id IS NOT NULL
+        AND u.user_target_status = $2::smallint
+        THEN COALESCE(ud.devices, '')
+        ELSE ''
+    END)::text AS target_devices
+FROM users u
+LEFT JOIN user_node_access a
+    ON a.user_id = u.user_id
+   AND a.node_id = $4::bigint
+LEFT JOIN LATERAL (
+    SELECT string_agg(d.device_id::text || ':' || d.vless_uuid, ','
+        ORDER BY d.device_id) AS devices
+    FROM user_devices d
+    WHERE d.user_id = u.user_id
+) ud ON TRUE
+WHERE u.deleted_at IS NULL
+ORDER BY u.user_id ASC
+`
+
+type ListNodeUserSyncsParams struct {
+	DefaultUserStatus int16
+	UserStatusEnabled int16
+	Now               time.Time
+	NodeID            int64
+}
+
+type ListNodeUserSyncsRow struct {
+	UserID              int64
+	UserName            string
+	DisplayName         string
+	VlessUuid           string
+	Email               string
+	UserTargetStatus    int16
+	TargetPrevVlessUuid string
+	TargetDevices       string
+}
+
+// target part of FindPendingSyncs for all node users,
+// node is started with this complete users set.
+// devices are "<device_id>:<vless_uuid>" comma separated list
+func (q *Queries) ListNodeUserSyncs(ctx context.Context, arg ListNodeUserSyncsParams) ([]ListNodeUserSyncsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNodeUserSyncs,
+		arg.DefaultUserStatus,
+		arg.UserStatusEnabled,
+		arg.Now,
+		arg.NodeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNodeUserSyncsRow
+	for rows.Next() {
+		var i ListNodeUserSyncsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.UserName,
+			&i.DisplayName,
+			&i.VlessUuid,
+			&i.Email,
+			&i.UserTargetStatus,
+			&i.TargetPrevVlessUuid,
+			&i.TargetDevices,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNodeUsers = `-- name: ListNodeUsers :many
+SELECT
+    u.user_id,
+    u.display_name,
+    u.user_name,
+    u.vless_uuid,
+    u.sub_token,
+    u.email,
+    (CASE WHEN a.node_id IS NULL
+        THEN $1::smallint
+        ELSE u.user_target_status
+    END)::smallint AS user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
+FROM users u
+LEFT JOIN user_node_access a
+    ON a.user_id = u.user_id
+   AND a.node_id = $2::bigint
+WHERE u.deleted_at IS NULL
+ORDER BY u.user_id ASC
+`
+
+type ListNodeUsersParams struct {
+	DefaultUserStatus int16
+	NodeID            int64
+}
+
+type ListNodeUsersRow struct {
+	UserID           int64
+	DisplayName      string
+	UserName         string
+	VlessUuid        string
+	SubToken         string
+	Email            string
+	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
+}
+
+// like ListUsers, but with user target status on node
+func (q *Queries) ListNodeUsers(ctx context.Context, arg ListNodeUsersParams) ([]ListNodeUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNodeUsers, arg.DefaultUserStatus, arg.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNodeUsersRow
+	for rows.Next() {
+		var i ListNodeUsersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.DisplayName,
+			&i.UserName,
+			&i.VlessUuid,
+			&i.SubToken,
+			&i.Email,
+			&i.UserTargetStatus,
+			&i.QuotaBytes,
+			&i.QuotaPeriod,
+			&i.ExpiresAt,
+			&i.IpLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
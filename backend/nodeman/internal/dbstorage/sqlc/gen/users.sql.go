@@ -7,6 +7,7 @@ package queries
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -22,17 +23,181 @@ func (q *Queries) DeleteUser(ctx context.Context, userID int64) error {
 	return err
 }
 
+const disableExpiredUsers = `-- name: DisableExpiredUsers :many
+UPDATE users u
+SET
+    user_target_status = $1::smallint,
+    expired = TRUE,
+    updated_at = now()
+WHERE u.expires_at <= $2::timestamptz
+    AND u.user_target_status = $3::smallint
+    AND u.deleted_at IS NULL
+RETURNING
+    u.user_id,
+    u.display_name,
+    u.user_name,
+    u.vless_uuid,
+    u.sub_token,
+    u.email,
+    u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
+`
+
+type DisableExpiredUsersParams struct {
+	UserStatusDisabled int16
+	Now                time.Time
+	UserStatusEnabled  int16
+}
+
+type DisableExpiredUsersRow struct {
+	UserID           int64
+	DisplayName      string
+	UserName         string
+	VlessUuid        string
+	SubToken         string
+	Email            string
+	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
+}
+
+// select and disable in one statement, so user enabled
+// or extended concurrently isn't disabled
+func (q *Queries) DisableExpiredUsers(ctx context.Context, arg DisableExpiredUsersParams) ([]DisableExpiredUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, disableExpiredUsers, arg.UserStatusDisabled, arg.Now, arg.UserStatusEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DisableExpiredUsersRow
+	for rows.Next() {
+		var i DisableExpiredUsersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.DisplayName,
+			&i.UserName,
+			&i.VlessUuid,
+			&i.SubToken,
+			&i.Email,
+			&i.UserTargetStatus,
+			&i.QuotaBytes,
+			&i.QuotaPeriod,
+			&i.ExpiresAt,
+			&i.IpLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findUserBySubToken = `-- name: FindUserBySubToken :one
+SELECT
+    user_id,
+    user_name
+FROM users
+WHERE sub_token = $1
+    AND deleted_at IS NULL
+`
+
+type FindUserBySubTokenRow struct {
+	UserID   int64
+	UserName string
+}
+
+func (q *Queries) FindUserBySubToken(ctx context.Context, subToken string) (FindUserBySubTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, findUserBySubToken, subToken)
+	var i FindUserBySubTokenRow
+	err := row.Scan(&i.UserID, &i.UserName)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT
+    u.user_id,
+    u.display_name,
+    u.user_name,
+    u.vless_uuid,
+    u.sub_token,
+    u.email,
+    u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
+FROM users u
+WHERE u.user_id = $1
+    AND deleted_at IS NULL
+`
+
+type GetUserRow struct {
+	UserID           int64
+	DisplayName      string
+	UserName         string
+	VlessUuid        string
+	SubToken         string
+	Email            string
+	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
+}
+
+func (q *Queries) GetUser(ctx context.Context, userID int64) (GetUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getUser, userID)
+	var i GetUserRow
+	err := row.Scan(
+		&i.UserID,
+		&i.DisplayName,
+		&i.UserName,
+		&i.VlessUuid,
+		&i.SubToken,
+		&i.Email,
+		&i.UserTargetStatus,
+		&i.QuotaBytes,
+		&i.QuotaPeriod,
+		&i.ExpiresAt,
+		&i.IpLimit,
+	)
+	return i, err
+}
+
 const getUserView = `-- name: GetUserView :one
 SELECT
     u.user_id,
     u.display_name,
     u.user_name,
     u.vless_uuid,
+    u.sub_token,
+    u.email,
     u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit,
 
     COALESCE(total_stats.upload, 0)   AS upload_total,
     COALESCE(total_stats.download, 0) AS download_total,
 
+    GREATEST(COALESCE(total_stats.upload, 0)
+      - u.quota_base_upload, 0)::bigint AS upload_period,
+
+    GREATEST(COALESCE(total_stats.download, 0)
+      - u.quota_base_download, 0)::bigint AS download_period,
+
     (COALESCE(total_stats.upload, 0)
       - COALESCE(daily_stats.upload, 0))::bigint AS upload_last_days,
 
@@ -78,9 +243,17 @@ type GetUserViewRow struct {
 	DisplayName      string
 	UserName         string
 	VlessUuid        string
+	SubToken         string
+	Email            string
 	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
 	UploadTotal      int64
 	DownloadTotal    int64
+	UploadPeriod     int64
+	DownloadPeriod   int64
 	UploadLastDays   int64
 	DownloadLastDays int64
 }
@@ -93,9 +266,17 @@ func (q *Queries) GetUserView(ctx context.Context, arg GetUserViewParams) (GetUs
 		&i.DisplayName,
 		&i.UserName,
 		&i.VlessUuid,
+		&i.SubToken,
+		&i.Email,
 		&i.UserTargetStatus,
+		&i.QuotaBytes,
+		&i.QuotaPeriod,
+		&i.ExpiresAt,
+		&i.IpLimit,
 		&i.UploadTotal,
 		&i.DownloadTotal,
+		&i.UploadPeriod,
+		&i.DownloadPeriod,
 		&i.UploadLastDays,
 		&i.DownloadLastDays,
 	)
@@ -108,11 +289,23 @@ SELECT
     u.display_name,
     u.user_name,
     u.vless_uuid,
+    u.sub_token,
+    u.email,
     u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit,
 
     COALESCE(total_stats.upload, 0)   AS upload_total,
     COALESCE(total_stats.download, 0) AS download_total,
 
+    GREATEST(COALESCE(total_stats.upload, 0)
+      - u.quota_base_upload, 0)::bigint AS upload_period,
+
+    GREATEST(COALESCE(total_stats.download, 0)
+      - u.quota_base_download, 0)::bigint AS download_period,
+
     (COALESCE(total_stats.upload, 0)
       - COALESCE(daily_stats.upload, 0))::bigint AS upload_last_days,
 
@@ -150,9 +343,17 @@ type ListUserViewsRow struct {
 	DisplayName      string
 	UserName         string
 	VlessUuid        string
+	SubToken         string
+	Email            string
 	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
 	UploadTotal      int64
 	DownloadTotal    int64
+	UploadPeriod     int64
+	DownloadPeriod   int64
 	UploadLastDays   int64
 	DownloadLastDays int64
 }
@@ -171,9 +372,17 @@ func (q *Queries) ListUserViews(ctx context.Context, fromDay time.Time) ([]ListU
 			&i.DisplayName,
 			&i.UserName,
 			&i.VlessUuid,
+			&i.SubToken,
+			&i.Email,
 			&i.UserTargetStatus,
+			&i.QuotaBytes,
+			&i.QuotaPeriod,
+			&i.ExpiresAt,
+			&i.IpLimit,
 			&i.UploadTotal,
 			&i.DownloadTotal,
+			&i.UploadPeriod,
+			&i.DownloadPeriod,
 			&i.UploadLastDays,
 			&i.DownloadLastDays,
 		); err != nil {
@@ -196,7 +405,13 @@ SELECT
     u.display_name,
     u.user_name,
     u.vless_uuid,
-    u.user_target_status
+    u.sub_token,
+    u.email,
+    u.user_target_status,
+    u.quota_bytes,
+    u.quota_period,
+    u.expires_at,
+    u.ip_limit
 FROM users u
 WHERE deleted_at IS NULL
 ORDER BY u.user_id ASC
@@ -207,7 +422,13 @@ type ListUsersRow struct {
 	DisplayName      string
 	UserName         string
 	VlessUuid        string
+	SubToken         string
+	Email            string
 	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
 }
 
 func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
@@ -224,7 +445,13 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 			&i.DisplayName,
 			&i.UserName,
 			&i.VlessUuid,
+			&i.SubToken,
+			&i.Email,
 			&i.UserTargetStatus,
+			&i.QuotaBytes,
+			&i.QuotaPeriod,
+			&i.ExpiresAt,
+			&i.IpLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -244,9 +471,14 @@ INSERT INTO users (
     display_name,
     user_name,
     vless_uuid,
-    user_target_status
-) VALUES ($1, $2, $3, $4)
-RETURNING user_id
+    user_target_status,
+    quota_bytes,
+    quota_period,
+    expires_at,
+    ip_limit,
+    email
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING user_id, sub_token
 `
 
 type NewUserParams struct {
@@ -254,35 +486,245 @@ type NewUserParams struct {
 	UserName         string
 	VlessUuid        string
 	UserTargetStatus int16
+	QuotaBytes       int64
+	QuotaPeriod      int16
+	ExpiresAt        sql.NullTime
+	IpLimit          int32
+	Email            string
 }
 
-func (q *Queries) NewUser(ctx context.Context, arg NewUserParams) (int64, error) {
+type NewUserRow struct {
+	UserID   int64
+	SubToken string
+}
+
+func (q *Queries) NewUser(ctx context.Context, arg NewUserParams) (NewUserRow, error) {
 	row := q.db.QueryRowContext(ctx, newUser,
 		arg.DisplayName,
 		arg.UserName,
 		arg.VlessUuid,
 		arg.UserTargetStatus,
+		arg.QuotaBytes,
+		arg.QuotaPeriod,
+		arg.ExpiresAt,
+		arg.IpLimit,
+		arg.Email,
 	)
+	var i NewUserRow
+	err := row.Scan(&i.UserID, &i.SubToken)
+	return i, err
+}
+
+const rotateUserCredentials = `-- name: RotateUserCredentials :one
+UPDATE users
+SET prev_vless_uuid = vless_uuid,
+    prev_vless_uuid_expires_at = $1,
+    vless_uuid = $2,
+    updated_at = now()
+WHERE user_id = $3
+    AND deleted_at IS NULL
+RETURNING user_id
+`
+
+type RotateUserCredentialsParams struct {
+	PrevExpiresAt sql.NullTime
+	VlessUuid     string
+	UserID        int64
+}
+
+// current identity becomes previous one, kept on nodes until grace period ends
+func (q *Queries) RotateUserCredentials(ctx context.Context, arg RotateUserCredentialsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, rotateUserCredentials, arg.PrevExpiresAt, arg.VlessUuid, arg.UserID)
 	var user_id int64
 	err := row.Scan(&user_id)
 	return user_id, err
 }
 
-const setTargetUserStatus = `-- name: SetTargetUserStatus :exec
+const rotateUserSubToken = `-- name: RotateUserSubToken :one
 UPDATE users
+SET sub_token = replace(gen_random_uuid()::text, '-', ''),
+    updated_at = now()
+WHERE user_id = $1
+    AND deleted_at IS NULL
+RETURNING sub_token
+`
+
+func (q *Queries) RotateUserSubToken(ctx context.Context, userID int64) (string, error) {
+	row := q.db.QueryRowContext(ctx, rotateUserSubToken, userID)
+	var sub_token string
+	err := row.Scan(&sub_token)
+	return sub_token, err
+}
+
+const setTargetUserStatus = `-- name: SetTargetUserStatus :exec
+UPDATE users u
 SET
     user_target_status = $1,
+    quota_exceeded = FALSE,
+    quota_ignored = $1 = $2::smallint
+        AND u.quota_bytes > 0
+        AND EXISTS (
+            SELECT 1
+            FROM total_users_traffic t
+            WHERE t.user_id = u.user_id
+                AND (t.upload - u.quota_base_upload)
+                  + (t.download - u.quota_base_download) >= u.quota_bytes
+        ),
+    expired = FALSE,
     updated_at = now()
-WHERE user_id = $2
-    AND deleted_at IS NULL
+WHERE u.user_id = $3
+    AND u.deleted_at IS NULL
 `
 
 type SetTargetUserStatusParams struct {
-	UserTargetStatus int16
-	UserID           int64
+	UserTargetStatus  int16
+	UserStatusEnabled int16
+	UserID            int64
 }
 
+// user enabled manually over quota isn't disabled
+// again until the next period
 func (q *Queries) SetTargetUserStatus(ctx context.Context, arg SetTargetUserStatusParams) error {
-	_, err := q.db.ExecContext(ctx, setTargetUserStatus, arg.UserTargetStatus, arg.UserID)
+	_, err := q.db.ExecContext(ctx, setTargetUserStatus, arg.UserTargetStatus, arg.UserStatusEnabled, arg.UserID)
 	return err
 }
+
+const setUserExpiration = `-- name: SetUserExpiration :one
+WITH prev AS (
+    SELECT
+        p.user_id,
+        p.expired AND ($1::timestamptz IS NULL
+            OR $1::timestamptz > $3::timestamptz
+        ) AS reenable
+    FROM users p
+    WHERE p.user_id = $4
+        AND p.deleted_at IS NULL
+    FOR UPDATE
+)
+UPDATE users u
+SET
+    expires_at = $1,
+    user_target_status = CASE
+        WHEN prev.reenable THEN $2::smallint
+        ELSE u.user_target_status
+    END,
+    expired = u.expired AND NOT prev.reenable,
+    updated_at = now()
+FROM prev
+WHERE u.user_id = prev.user_id
+RETURNING prev.reenable AS reenabled
+`
+
+type SetUserExpirationParams struct {
+	ExpiresAt         sql.NullTime
+	UserStatusEnabled int16
+	Now               time.Time
+	UserID            int64
+}
+
+// user disabled on expiration is enabled again
+// if new expiration time isn't passed
+func (q *Queries) SetUserExpiration(ctx context.Context, arg SetUserExpirationParams) (sql.NullBool, error) {
+	row := q.db.QueryRowContext(ctx, setUserExpiration,
+		arg.ExpiresAt,
+		arg.UserStatusEnabled,
+		arg.Now,
+		arg.UserID,
+	)
+	var reenabled sql.NullBool
+	err := row.Scan(&reenabled)
+	return reenabled, err
+}
+
+const setUserIPLimit = `-- name: SetUserIPLimit :exec
+UPDATE users
+SET
+    ip_limit = $1,
+    updated_at = now()
+WHERE user_id = $2
+    AND deleted_at IS NULL
+`
+
+type SetUserIPLimitParams struct {
+	IpLimit int32
+	UserID  int64
+}
+
+func (q *Queries) SetUserIPLimit(ctx context.Context, arg SetUserIPLimitParams) error {
+	_, err := q.db.ExecContext(ctx, setUserIPLimit, arg.IpLimit, arg.UserID)
+	return err
+}
+
+const setUserQuota = `-- name: SetUserQuota :one
+WITH prev AS (
+    SELECT
+        p.user_id,
+        p.quota_period <> $2::smallint AS new_period,
+        p.quota_exceeded AND ($1::bigint = 0
+            OR p.quota_period <> $2::smallint
+            OR (COALESCE(t.upload, 0) - p.quota_base_upload)
+             + (COALESCE(t.download, 0) - p.quota_base_download)
+             < $1::bigint
+        ) AS reenable,
+        COALESCE(t.upload, 0)::bigint AS total_upload,
+        COALESCE(t.download, 0)::bigint AS total_download
+    FROM users p
+    LEFT JOIN total_users_traffic t ON t.user_id = p.user_id
+    WHERE p.user_id = $5
+        AND p.deleted_at IS NULL
+    FOR UPDATE OF p
+)
+UPDATE users u
+SET
+    quota_bytes = $1::bigint,
+    quota_period = $2::smallint,
+    quota_ignored = FALSE,
+    user_target_status = CASE
+        WHEN prev.reenable THEN $3::smallint
+        ELSE u.user_target_status
+    END,
+    quota_exceeded = u.quota_exceeded AND NOT prev.reenable,
+    quota_period_start = CASE
+        WHEN prev.new_period THEN $4::timestamptz
+        ELSE u.quota_period_start
+    END,
+    quota_anchor = CASE
+        WHEN prev.new_period THEN $4::timestamptz
+        ELSE u.quota_anchor
+    END,
+    quota_base_upload = CASE
+        WHEN prev.new_period THEN prev.total_upload
+        ELSE u.quota_base_upload
+    END,
+    quota_base_download = CASE
+        WHEN prev.new_period THEN prev.total_download
+        ELSE u.quota_base_download
+    END,
+    updated_at = now()
+FROM prev
+WHERE u.user_id = prev.user_id
+RETURNING prev.reenable AS reenabled
+`
+
+type SetUserQuotaParams struct {
+	QuotaBytes        int64
+	QuotaPeriod       int16
+	UserStatusEnabled int16
+	Now               time.Time
+	UserID            int64
+}
+
+// user disabled on quota is enabled again if its usage is under
+// new quota, quota period change starts new period at now
+func (q *Queries) SetUserQuota(ctx context.Context, arg SetUserQuotaParams) (sql.NullBool, error) {
+	row := q.db.QueryRowContext(ctx, setUserQuota,
+		arg.QuotaBytes,
+		arg.QuotaPeriod,
+		arg.UserStatusEnabled,
+		arg.Now,
+		arg.UserID,
+	)
+	var reenabled sql.NullBool
+	err := row.Scan(&reenabled)
+	return reenabled, err
+}
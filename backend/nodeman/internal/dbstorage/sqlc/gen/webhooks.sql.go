@@ -0,0 +1,94 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.31.1
+// source: webhooks.sql
+
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE webhook_id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhook, webhookID)
+	return err
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT
+    webhook_id,
+    url,
+    events,
+    secret,
+    created_at
+FROM webhooks
+ORDER BY webhook_id ASC
+`
+
+func (q *Queries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.WebhookID,
+			&i.Url,
+			pq.Array(&i.Events),
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const newWebhook = `-- name: NewWebhook :one
+INSERT INTO webhooks (
+    url,
+    events,
+    secret
+) VALUES (
+    $1::text,
+    $2::smallint[],
+    $3::text
+)
+RETURNING webhook_id, created_at
+`
+
+type NewWebhookParams struct {
+	Url    string
+	Events []int16
+	Secret string
+}
+
+type NewWebhookRow struct {
+	WebhookID int64
+	CreatedAt time.Time
+}
+
+func (q *Queries) NewWebhook(ctx context.Context, arg NewWebhookParams) (NewWebhookRow, error) {
+	row := q.db.QueryRowContext(ctx, newWebhook, arg.Url, pq.Array(arg.Events), arg.Secret)
+	var i NewWebhookRow
+	err := row.Scan(&i.WebhookID, &i.CreatedAt)
+	return i, err
+}
@@ -40,6 +40,12 @@ func TranslatePgErr(err error) error {
 		return err
 	}
 
+	// constraint violations are caused by request data,
+	// like duplicated name or reference to absent item
+	if errors.As(err, &pgErr) && isPgIntegrityError(pgErr.Code) {
+		return xerr.WrapWithType(err, errdefs.ErrInvaildPayload)
+	}
+
 	// all other errors mark as temporary and retriable because
 	// I know nothing about all about what pg could return as errors
 	// (i thing everything except plain and clear info)
@@ -63,3 +69,13 @@ func isPgSyntaxError(code string) bool {
 		return false
 	}
 }
+
+func isPgIntegrityError(code string) bool {
+	switch code {
+	case errcode.ForeignKeyViolation,
+		errcode.UniqueViolation:
+		return true
+	default:
+		return false
+	}
+}
@@ -10,11 +10,15 @@ import (
 	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	httpaudit "github.com/XRay-Addons/xrayman/nodeman/internal/http/audit"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/poolsync"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/audit"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/auth"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/nodes"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/settings"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/subscr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/tokens"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/users"
 	"go.uber.org/zap"
 )
@@ -43,6 +47,7 @@ type Storage struct {
 	db      DB
 	timeout time.Duration
 	log     *zap.Logger
+	metrics Metrics
 }
 
 var _ users.Storage = (*Storage)(nil)
@@ -51,12 +56,17 @@ var _ subscr.Storage = (*Storage)(nil)
 var _ auth.Storage = (*Storage)(nil)
 var _ poolsync.Storage = (*Storage)(nil)
 var _ settings.Storage = (*Storage)(nil)
+var _ security.Storage = (*Storage)(nil)
+var _ tokens.Storage = (*Storage)(nil)
+var _ audit.Storage = (*Storage)(nil)
+var _ httpaudit.Storage = (*Storage)(nil)
 
 type option func(o *options)
 
 type options struct {
 	timeout time.Duration
 	log     *zap.Logger
+	metrics Metrics
 }
 
 func WithTimeout(t time.Duration) option {
@@ -73,6 +83,12 @@ func WithLogger(l *zap.Logger) option {
 	}
 }
 
+func WithMetrics(m Metrics) option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
 func New(db DB, opts ...option) (s *Storage, err error) {
 	if db == nil {
 		return nil, errdefs.NilArg("db")
@@ -89,6 +105,7 @@ func New(db DB, opts ...option) (s *Storage, err error) {
 		db:      db,
 		timeout: o.timeout,
 		log:     o.log,
+		metrics: o.metrics,
 	}, nil
 }
 
@@ -181,13 +198,14 @@ type voidFn = func(context.Context, *queries.Queries) error
 
 func doVoid(ctx context.Context, s *Storage, fn voidFn) error {
 
-	var q *queries.Queries
-
+	var dbtx queries.DBTX = s.db
 	if tx, ok := ctx.Value(txCtxKey).(TX); ok {
-		q = queries.New(tx)
-	} else {
-		q = queries.New(s.db)
+		dbtx = tx
+	}
+	if s.metrics != nil {
+		dbtx = &metricsDB{base: dbtx, metrics: s.metrics}
 	}
+	q := queries.New(dbtx)
 
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
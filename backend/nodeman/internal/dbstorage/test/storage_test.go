@@ -67,6 +67,44 @@ func TestStorage_Nodes(t *testing.T) {
 	require.ErrorIs(t, err, errdefs.ErrNotFound)
 }
 
+func TestStorage_NodeConfig(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+
+	node := models.Node{
+		CurrentStatus: models.NodeStatusUnknown,
+		TargetStatus:  models.NodeStatusRunning,
+	}
+	require.NoError(t, s.NewNode(ctx, &node))
+
+	cfg1 := models.NodeXRayConfig{ServerConfig: `{"srv": 1}`, ClientConfig: `{"cl": 1}`}
+	cfg2 := models.NodeXRayConfig{ServerConfig: `{"srv": 2}`, ClientConfig: `{"cl": 2}`}
+
+	v1, err := s.PushNodeConfig(ctx, node.ID, cfg1)
+	require.NoError(t, err)
+	v2, err := s.PushNodeConfig(ctx, node.ID, cfg2)
+	require.NoError(t, err)
+	require.Greater(t, v2, v1)
+
+	stored, err := s.GetNode(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, v2, stored.TargetConfigVersion)
+	require.Equal(t, models.ConfigVersion(0), stored.CurrentConfigVersion)
+
+	storedCfg, err := s.GetNodeConfig(ctx, node.ID, v1)
+	require.NoError(t, err)
+	require.Equal(t, v1, storedCfg.Version)
+	require.Equal(t, cfg1.ServerConfig, storedCfg.ServerConfig)
+	require.Equal(t, cfg1.ClientConfig, storedCfg.ClientConfig)
+
+	require.NoError(t, s.SetCurrentNodeConfigVersion(ctx, node.ID, v2))
+	stored, err = s.GetNode(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, v2, stored.CurrentConfigVersion)
+}
+
 func TestStorage_Users(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	ctx := context.Background()
@@ -185,6 +223,286 @@ func TestStorage_UserNodes(t *testing.T) {
 	require.Equal(t, 2, len(pendingSyncs))
 }
 
+func TestStorage_SubTokens(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	user := models.User{Profile: models.UserProfile{Name: "user"}}
+	require.NoError(t, s.NewUser(ctx, &user))
+	require.NotEmpty(t, user.Profile.SubToken)
+
+	id, name, err := s.FindUserBySubToken(ctx, user.Profile.SubToken)
+	require.NoError(t, err)
+	require.Equal(t, user.Profile.ID, id)
+	require.Equal(t, user.Profile.Name, name)
+
+	view, err := s.GetUserView(ctx, id, name)
+	require.NoError(t, err)
+	require.Equal(t, user.Profile.SubToken, view.User.Profile.SubToken)
+
+	token, err := s.RotateUserSubToken(ctx, id)
+	require.NoError(t, err)
+	require.NotEqual(t, user.Profile.SubToken, token)
+
+	_, _, err = s.FindUserBySubToken(ctx, user.Profile.SubToken)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	id, _, err = s.FindUserBySubToken(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, user.Profile.ID, id)
+}
+
+func TestStorage_RotateCredentials(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	user := models.User{
+		Profile:      models.UserProfile{VlessUUID: "uuid1"},
+		TargetStatus: models.UserStatusEnabled,
+	}
+	require.NoError(t, s.NewUser(ctx, &user))
+	node := models.Node{
+		CurrentStatus: models.NodeStatusRunning,
+		TargetStatus:  models.NodeStatusRunning,
+	}
+	require.NoError(t, s.NewNode(ctx, &node))
+
+	require.NoError(t, s.UpdateNodeUsers(ctx, node.ID, []models.UserStatusPatch{
+		{UserID: user.Profile.ID, Status: models.UserStatusEnabled, VlessUUID: "uuid1"},
+	}))
+	pendingSyncs, err := s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Empty(t, pendingSyncs)
+
+	// previous identity is required on node during grace period
+	err = s.RotateUserCredentials(ctx, user.Profile.ID, "uuid2", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	pendingSyncs, err = s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, pendingSyncs, 1)
+	require.Equal(t, "uuid2", pendingSyncs[0].User.Profile.VlessUUID)
+	require.Equal(t, "uuid1", pendingSyncs[0].CurrentVlessUUID)
+	require.Equal(t, "uuid1", pendingSyncs[0].TargetPrevVlessUUID)
+	require.Empty(t, pendingSyncs[0].CurrentPrevVlessUUID)
+
+	require.NoError(t, s.UpdateNodeUsers(ctx, node.ID, []models.UserStatusPatch{{
+		UserID: user.Profile.ID, Status: models.UserStatusEnabled,
+		VlessUUID: "uuid2", PrevVlessUUID: "uuid1",
+	}}))
+	pendingSyncs, err = s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Empty(t, pendingSyncs)
+
+	// node is started with previous identity too
+	userSyncs, err := s.ListNodeUserSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, userSyncs, 1)
+	require.Equal(t, "uuid2", userSyncs[0].User.Profile.VlessUUID)
+	require.Equal(t, "uuid1", userSyncs[0].TargetPrevVlessUUID)
+
+	// without grace period previous identity is removed
+	require.NoError(t, s.RotateUserCredentials(ctx, user.Profile.ID, "uuid3", time.Time{}))
+	pendingSyncs, err = s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, pendingSyncs, 1)
+	require.Equal(t, "uuid3", pendingSyncs[0].User.Profile.VlessUUID)
+	require.Equal(t, "uuid1", pendingSyncs[0].CurrentPrevVlessUUID)
+	require.Empty(t, pendingSyncs[0].TargetPrevVlessUUID)
+
+	err = s.RotateUserCredentials(ctx, user.Profile.ID+1, "uuid4", time.Time{})
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
+
+func TestStorage_UserDevices(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	user := models.User{
+		Profile:      models.UserProfile{VlessUUID: "uuid"},
+		TargetStatus: models.UserStatusEnabled,
+	}
+	require.NoError(t, s.NewUser(ctx, &user))
+	node := models.Node{
+		CurrentStatus: models.NodeStatusRunning,
+		TargetStatus:  models.NodeStatusRunning,
+	}
+	require.NoError(t, s.NewNode(ctx, &node))
+	require.NoError(t, s.UpdateNodeUsers(ctx, node.ID, []models.UserStatusPatch{
+		{UserID: user.Profile.ID, Status: models.UserStatusEnabled, VlessUUID: "uuid"},
+	}))
+
+	phone := models.UserDevice{UserID: user.Profile.ID, Label: "phone", VlessUUID: "phone-uuid"}
+	laptop := models.UserDevice{UserID: user.Profile.ID, Label: "laptop", VlessUUID: "laptop-uuid"}
+	for _, d := range []*models.UserDevice{&phone, &laptop} {
+		require.NoError(t, s.NewUserDevice(ctx, d))
+	}
+	require.ErrorIs(t, s.NewUserDevice(ctx, &models.UserDevice{
+		UserID: user.Profile.ID + 1, Label: "tv", VlessUUID: "tv-uuid",
+	}), errdefs.ErrNotFound)
+
+	count, err := s.CountUserDevices(ctx, user.Profile.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	device, err := s.GetUserDevice(ctx, user.Profile.ID, phone.ID)
+	require.NoError(t, err)
+	require.Equal(t, "phone-uuid", device.VlessUUID)
+	_, err = s.GetUserDevice(ctx, user.Profile.ID+1, phone.ID)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	// new devices are pushed to nodes
+	pendingSyncs, err := s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, pendingSyncs, 1)
+	require.Empty(t, pendingSyncs[0].CurrentDevices)
+	require.Len(t, pendingSyncs[0].TargetDevices, 2)
+	require.Equal(t, phone.ID, pendingSyncs[0].TargetDevices[0].ID)
+	require.Equal(t, "laptop-uuid", pendingSyncs[0].TargetDevices[1].VlessUUID)
+
+	require.NoError(t, s.UpdateNodeUsers(ctx, node.ID, []models.UserStatusPatch{{
+		UserID: user.Profile.ID, Status: models.UserStatusEnabled,
+		VlessUUID: "uuid", Devices: pendingSyncs[0].TargetDevices,
+	}}))
+	pendingSyncs, err = s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Empty(t, pendingSyncs)
+
+	// node is started with user devices too
+	userSyncs, err := s.ListNodeUserSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, userSyncs, 1)
+	require.Len(t, userSyncs[0].TargetDevices, 2)
+	require.Equal(t, "phone-uuid", userSyncs[0].TargetDevices[0].VlessUUID)
+
+	// device traffic is counted separately
+	require.NoError(t, s.UpdateNodeStats(ctx, node.ID, models.NodeStats{
+		Users:   []models.UserStats{{ID: user.Profile.ID, Uplink: 3, Downlink: 4}},
+		Devices: []models.DeviceStats{{ID: laptop.ID, UserID: user.Profile.ID, Uplink: 1, Downlink: 2}},
+	}))
+	devices, err := s.ListUserDevices(ctx, user.Profile.ID)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	require.Equal(t, models.TrafficStats{}, devices[0].Traffic)
+	require.Equal(t, models.TrafficStats{Upload: 1, Download: 2}, devices[1].Traffic)
+
+	require.NoError(t, s.SetUserDeviceLabel(ctx, laptop.ID, "work laptop"))
+	devices, err = s.ListUserDevices(ctx, user.Profile.ID)
+	require.NoError(t, err)
+	require.Equal(t, "work laptop", devices[1].Label)
+	require.ErrorIs(t, s.SetUserDeviceLabel(ctx, laptop.ID+1, "tv"), errdefs.ErrNotFound)
+
+	// deleted devices are removed from nodes
+	require.NoError(t, s.DeleteUserDevice(ctx, phone.ID))
+	require.ErrorIs(t, s.DeleteUserDevice(ctx, phone.ID), errdefs.ErrNotFound)
+	pendingSyncs, err = s.FindPendingSyncs(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, pendingSyncs, 1)
+	require.Len(t, pendingSyncs[0].CurrentDevices, 2)
+	require.Len(t, pendingSyncs[0].TargetDevices, 1)
+	require.Equal(t, laptop.ID, pendingSyncs[0].TargetDevices[0].ID)
+}
+
+func TestStorage_NodeGroups(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+
+	premiumUser := models.User{TargetStatus: models.UserStatusEnabled}
+	regularUser := models.User{TargetStatus: models.UserStatusEnabled}
+	for _, u := range []*models.User{&premiumUser, &regularUser} {
+		require.NoError(t, s.NewUser(ctx, u))
+	}
+
+	publicNode := models.Node{
+		CurrentStatus: models.NodeStatusRunning,
+		TargetStatus:  models.NodeStatusRunning,
+	}
+	premiumNode := models.Node{
+		CurrentStatus: models.NodeStatusRunning,
+		TargetStatus:  models.NodeStatusRunning,
+	}
+	for _, n := range []*models.Node{&publicNode, &premiumNode} {
+		require.NoError(t, s.NewNode(ctx, n))
+	}
+
+	premium := models.NodeGroup{Name: "premium"}
+	require.NoError(t, s.NewNodeGroup(ctx, &premium))
+	require.Error(t, s.NewNodeGroup(ctx, &models.NodeGroup{Name: "premium"}))
+
+	require.NoError(t, s.SetNodeGroups(ctx, premiumNode.ID,
+		[]models.NodeGroupID{premium.ID}))
+	require.NoError(t, s.SetUserGroups(ctx, premiumUser.Profile.ID,
+		[]models.NodeGroupID{premium.ID}))
+	require.ErrorIs(t, s.SetUserGroups(ctx, regularUser.Profile.ID,
+		[]models.NodeGroupID{premium.ID + 1}), errdefs.ErrInvaildPayload)
+
+	groups, err := s.ListNodeGroups(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.NodeGroup{{
+		ID:      premium.ID,
+		Name:    "premium",
+		NodeIDs: []models.NodeID{premiumNode.ID},
+		UserIDs: []models.UserID{premiumUser.Profile.ID},
+	}}, groups)
+
+	// public node is available to all users
+	users, err := s.ListNodeUsers(ctx, publicNode.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusEnabled, users[0].TargetStatus)
+	require.Equal(t, models.UserStatusEnabled, users[1].TargetStatus)
+
+	// premium node is available to premium user only
+	users, err = s.ListNodeUsers(ctx, premiumNode.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusEnabled, users[0].TargetStatus)
+	require.Equal(t, models.UserStatusDisabled, users[1].TargetStatus)
+
+	pendingSyncs, err := s.FindPendingSyncs(ctx, premiumNode.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pendingSyncs))
+	require.Equal(t, premiumUser.Profile.ID, pendingSyncs[0].User.Profile.ID)
+
+	// regular user enabled on premium node before groups change
+	// must be removed by sync
+	err = s.SetNodeUsers(ctx, premiumNode.ID, []models.UserStatusPatch{
+		{UserID: premiumUser.Profile.ID, Status: models.UserStatusEnabled},
+		{UserID: regularUser.Profile.ID, Status: models.UserStatusEnabled},
+	})
+	require.NoError(t, err)
+	pendingSyncs, err = s.FindPendingSyncs(ctx, premiumNode.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pendingSyncs))
+	require.Equal(t, regularUser.Profile.ID, pendingSyncs[0].User.Profile.ID)
+	require.Equal(t, models.UserStatusDisabled, pendingSyncs[0].User.TargetStatus)
+
+	nodes, err := s.GetUserNodes(ctx, regularUser.Profile.ID)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(nodes))
+	nodes, err = s.GetUserNodes(ctx, premiumUser.Profile.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(nodes))
+	require.Equal(t, premiumNode.ID, nodes[0].ID)
+
+	// group removal makes node public again
+	nodeIDs, err := s.ListNodeGroupNodes(ctx, premium.ID)
+	require.NoError(t, err)
+	require.Equal(t, []models.NodeID{premiumNode.ID}, nodeIDs)
+	require.NoError(t, s.DeleteNodeGroup(ctx, premium.ID))
+	pendingSyncs, err = s.FindPendingSyncs(ctx, premiumNode.ID)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(pendingSyncs))
+}
+
 func TestStorage_Stats(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	ctx := context.Background()
@@ -287,6 +605,396 @@ func TestStorage_Stats(t *testing.T) {
 	require.Equal(t, int64(12), userView.Traffic.LastMonth.Download)
 }
 
+func TestStorage_TrafficHistory(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+
+	user := models.User{TargetStatus: models.UserStatusEnabled}
+	require.NoError(t, s.NewUser(ctx, &user))
+	node1 := models.Node{TargetStatus: models.NodeStatusRunning}
+	node2 := models.Node{TargetStatus: models.NodeStatusRunning}
+	require.NoError(t, s.NewNode(ctx, &node1))
+	require.NoError(t, s.NewNode(ctx, &node2))
+
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day1.AddDate(0, 0, 2)
+
+	// day1: node1 1/2, day2: node1 3/4 + node2 5/6, day3: nothing
+	require.NoError(t, s.UpdateNodeStats(ctx, node1.ID, models.NodeStats{
+		Users: []models.UserStats{{ID: user.Profile.ID, Uplink: 1, Downlink: 2}},
+	}))
+	require.NoError(t, s.UpdateDailyStats(ctx, day1))
+	require.NoError(t, s.UpdateNodeStats(ctx, node1.ID, models.NodeStats{
+		Users: []models.UserStats{{ID: user.Profile.ID, Uplink: 3, Downlink: 4}},
+	}))
+	require.NoError(t, s.UpdateNodeStats(ctx, node2.ID, models.NodeStats{
+		Users: []models.UserStats{{ID: user.Profile.ID, Uplink: 5, Downlink: 6}},
+	}))
+	require.NoError(t, s.UpdateDailyStats(ctx, day2))
+	require.NoError(t, s.UpdateDailyStats(ctx, day3))
+
+	userDays, err := s.GetUserTrafficHistory(ctx, user.Profile.ID,
+		models.TrafficHistoryRange{From: day2, To: day3})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(userDays))
+	require.True(t, day2.Equal(userDays[0].Day))
+	require.Equal(t, models.TrafficStats{Upload: 8, Download: 10}, userDays[0].Traffic)
+	require.Equal(t, models.TrafficStats{}, userDays[1].Traffic)
+
+	nodeDays, err := s.GetNodeTrafficHistory(ctx, node1.ID,
+		models.TrafficHistoryRange{From: day1, To: day2})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(nodeDays))
+	require.Equal(t, models.TrafficStats{Upload: 1, Download: 2}, nodeDays[0].Traffic)
+	require.Equal(t, models.TrafficStats{Upload: 3, Download: 4}, nodeDays[1].Traffic)
+
+	userNodes, err := s.GetUserNodesTrafficHistory(ctx, user.Profile.ID,
+		models.TrafficHistoryRange{From: day2, To: day2})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(userNodes))
+	require.Equal(t, node1.ID, userNodes[0].NodeID)
+	require.Equal(t, models.TrafficStats{Upload: 3, Download: 4}, userNodes[0].Days[0].Traffic)
+	require.Equal(t, node2.ID, userNodes[1].NodeID)
+	require.Equal(t, models.TrafficStats{Upload: 5, Download: 6}, userNodes[1].Days[0].Traffic)
+}
+
+func TestStorage_Quota(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	limited := models.User{
+		TargetStatus: models.UserStatusEnabled,
+		Quota: models.TrafficQuota{
+			Limit:  10,
+			Period: models.TrafficQuotaPeriodMonthly,
+		},
+	}
+	unlimited := models.User{
+		TargetStatus: models.UserStatusEnabled,
+		Quota: models.TrafficQuota{
+			Period: models.TrafficQuotaPeriodOneOff,
+		},
+	}
+	for _, user := range []*models.User{&limited, &unlimited} {
+		require.NoError(t, s.NewUser(ctx, user))
+	}
+	node := models.Node{
+		CurrentStatus: models.NodeStatusRunning,
+		TargetStatus:  models.NodeStatusRunning,
+	}
+	require.NoError(t, s.NewNode(ctx, &node))
+
+	// usage under quota
+	err := s.UpdateNodeStats(ctx, node.ID, models.NodeStats{
+		Users: []models.UserStats{
+			{ID: limited.Profile.ID, Uplink: 2, Downlink: 3},
+			{ID: unlimited.Profile.ID, Uplink: 20, Downlink: 30},
+		},
+	})
+	require.NoError(t, err)
+	disabled, err := s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Empty(t, disabled)
+
+	// usage over quota
+	err = s.UpdateNodeStats(ctx, node.ID, models.NodeStats{
+		Users: []models.UserStats{
+			{ID: limited.Profile.ID, Uplink: 2, Downlink: 3},
+		},
+	})
+	require.NoError(t, err)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.UserID{limited.Profile.ID}, disabled)
+
+	userView, err := s.GetUserView(ctx, limited.Profile.ID, limited.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusDisabled, userView.User.TargetStatus)
+	require.Equal(t, limited.Quota, userView.User.Quota)
+	require.Equal(t, int64(4), userView.Traffic.CurrentPeriod.Upload)
+	require.Equal(t, int64(6), userView.Traffic.CurrentPeriod.Download)
+
+	// manual enable over quota isn't disabled again in this period
+	err = s.SetTargetUserStatus(ctx, limited.Profile.ID, models.UserStatusEnabled)
+	require.NoError(t, err)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Empty(t, disabled)
+
+	// quota change checks quota again
+	enabled, err := s.SetUserQuota(ctx, limited.Profile.ID, limited.Quota, time.Now())
+	require.NoError(t, err)
+	require.False(t, enabled)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.UserID{limited.Profile.ID}, disabled)
+
+	// period not finished yet
+	reset, err := s.ResetUserQuotas(ctx, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, reset)
+
+	// new period enables user back and starts counting from zero
+	reset, err = s.ResetUserQuotas(ctx, time.Now().Add(32*24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []models.UserID{limited.Profile.ID}, reset)
+
+	userView, err = s.GetUserView(ctx, limited.Profile.ID, limited.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusEnabled, userView.User.TargetStatus)
+	require.Equal(t, int64(0), userView.Traffic.CurrentPeriod.Upload)
+	require.Equal(t, int64(0), userView.Traffic.CurrentPeriod.Download)
+	require.Equal(t, int64(4), userView.Traffic.Total.Upload)
+	require.Equal(t, int64(6), userView.Traffic.Total.Download)
+
+	// late reset doesn't shift period start, the next one is due
+	// one month after the previous period start, not after reset
+	reset, err = s.ResetUserQuotas(ctx, time.Now().Add(50*24*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, reset)
+	reset, err = s.ResetUserQuotas(ctx, time.Now().Add(63*24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []models.UserID{limited.Profile.ID}, reset)
+
+	err = s.UpdateNodeStats(ctx, node.ID, models.NodeStats{
+		Users: []models.UserStats{
+			{ID: limited.Profile.ID, Uplink: 5, Downlink: 5},
+		},
+	})
+	require.NoError(t, err)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.UserID{limited.Profile.ID}, disabled)
+
+	// quota raised over usage enables user back
+	raised := models.TrafficQuota{Limit: 20, Period: models.TrafficQuotaPeriodMonthly}
+	enabled, err = s.SetUserQuota(ctx, limited.Profile.ID, raised, time.Now())
+	require.NoError(t, err)
+	require.True(t, enabled)
+	userView, err = s.GetUserView(ctx, limited.Profile.ID, limited.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusEnabled, userView.User.TargetStatus)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Empty(t, disabled)
+
+	// quota lowered under usage disables user again
+	enabled, err = s.SetUserQuota(ctx, limited.Profile.ID, limited.Quota, time.Now())
+	require.NoError(t, err)
+	require.False(t, enabled)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.UserID{limited.Profile.ID}, disabled)
+
+	// period change starts new period from zero
+	oneOff := models.TrafficQuota{Limit: 10, Period: models.TrafficQuotaPeriodOneOff}
+	enabled, err = s.SetUserQuota(ctx, limited.Profile.ID, oneOff, time.Now())
+	require.NoError(t, err)
+	require.True(t, enabled)
+	userView, err = s.GetUserView(ctx, limited.Profile.ID, limited.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusEnabled, userView.User.TargetStatus)
+	require.Equal(t, int64(0), userView.Traffic.CurrentPeriod.Upload)
+	require.Equal(t, int64(0), userView.Traffic.CurrentPeriod.Download)
+	disabled, err = s.DisableOverQuotaUsers(ctx)
+	require.NoError(t, err)
+	require.Empty(t, disabled)
+
+	_, err = s.SetUserQuota(ctx, 999, oneOff, time.Now())
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
+
+func TestStorage_IPLimit(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	limited := models.User{
+		TargetStatus: models.UserStatusEnabled,
+		IPLimit:      2,
+	}
+	unlimited := models.User{
+		TargetStatus: models.UserStatusEnabled,
+	}
+	for _, user := range []*models.User{&limited, &unlimited} {
+		require.NoError(t, s.NewUser(ctx, user))
+	}
+	node1 := models.Node{TargetStatus: models.NodeStatusRunning}
+	node2 := models.Node{TargetStatus: models.NodeStatusRunning}
+	for _, node := range []*models.Node{&node1, &node2} {
+		require.NoError(t, s.NewNode(ctx, node))
+	}
+
+	userView, err := s.GetUserView(ctx, limited.Profile.ID, limited.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, 2, userView.User.IPLimit)
+
+	// online ips under limit
+	err = s.UpdateNodeStats(ctx, node1.ID, models.NodeStats{
+		Users: []models.UserStats{
+			{ID: limited.Profile.ID, OnlineIPs: []string{"10.0.0.1", "10.0.0.2"}},
+			{ID: unlimited.Profile.ID, OnlineIPs: []string{
+				"10.0.1.1", "10.0.1.2", "10.0.1.3", "10.0.1.4", "10.0.1.5",
+			}},
+		},
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	violations, err := s.RecordIPLimitViolations(ctx, now.Add(-time.Minute), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, violations)
+
+	// the same ip on several nodes is counted once
+	err = s.UpdateNodeStats(ctx, node2.ID, models.NodeStats{
+		Users: []models.UserStats{
+			{ID: limited.Profile.ID, OnlineIPs: []string{"10.0.0.1"}},
+		},
+	})
+	require.NoError(t, err)
+	violations, err = s.RecordIPLimitViolations(ctx, now.Add(-time.Minute), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, violations)
+
+	// distinct online ips over nodes
+	err = s.UpdateNodeStats(ctx, node2.ID, models.NodeStats{
+		Users: []models.UserStats{
+			{ID: limited.Profile.ID, OnlineIPs: []string{"10.0.0.1", "10.0.0.3"}},
+		},
+	})
+	require.NoError(t, err)
+	violations, err = s.RecordIPLimitViolations(ctx, now.Add(-time.Minute), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	require.Equal(t, limited.Profile.ID, violations[0].UserID)
+	require.Equal(t, 3, violations[0].IPCount)
+	require.Equal(t, 2, violations[0].IPLimit)
+
+	// violation is recorded once per window
+	violations, err = s.RecordIPLimitViolations(ctx, now.Add(-time.Minute), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, violations)
+
+	warned, err := s.HasIPLimitViolation(ctx, limited.Profile.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, warned)
+	warned, err = s.HasIPLimitViolation(ctx, unlimited.Profile.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.False(t, warned)
+
+	list, err := s.ListIPLimitViolations(ctx, limited.Profile.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	// new node report replaces previous one
+	err = s.UpdateNodeStats(ctx, node2.ID, models.NodeStats{})
+	require.NoError(t, err)
+	violations, err = s.RecordIPLimitViolations(ctx, now.Add(-time.Minute), time.Now())
+	require.NoError(t, err)
+	require.Empty(t, violations)
+
+	// raised limit is not violated
+	require.NoError(t, s.SetUserIPLimit(ctx, limited.Profile.ID, 5))
+	userView, err = s.GetUserView(ctx, limited.Profile.ID, limited.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, 5, userView.User.IPLimit)
+}
+
+func TestStorage_Expiration(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	now := time.Now()
+	expired := models.User{
+		TargetStatus: models.UserStatusEnabled,
+		ExpiresAt:    now.Add(-time.Hour),
+	}
+	active := models.User{
+		TargetStatus: models.UserStatusEnabled,
+		ExpiresAt:    now.Add(time.Hour),
+	}
+	eternal := models.User{
+		TargetStatus: models.UserStatusEnabled,
+	}
+	for _, user := range []*models.User{&expired, &active, &eternal} {
+		require.NoError(t, s.NewUser(ctx, user))
+	}
+
+	users, err := s.DisableExpiredUsers(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(users))
+	require.Equal(t, expired.Profile.ID, users[0].Profile.ID)
+	userView, err := s.GetUserView(ctx, expired.Profile.ID, expired.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusDisabled, userView.User.TargetStatus)
+
+	// disabled users are not disabled again
+	users, err = s.DisableExpiredUsers(ctx, now)
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	// expiration still passed, user stays disabled
+	enabled, err := s.SetUserExpiration(ctx, expired.Profile.ID,
+		now.Add(-30*time.Minute), now)
+	require.NoError(t, err)
+	require.False(t, enabled)
+
+	// prolong expired user, it's enabled again
+	enabled, err = s.SetUserExpiration(ctx, expired.Profile.ID,
+		now.Add(2*time.Hour), now)
+	require.NoError(t, err)
+	require.True(t, enabled)
+	userView, err = s.GetUserView(ctx, expired.Profile.ID, expired.Profile.Name)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusEnabled, userView.User.TargetStatus)
+	users, err = s.DisableExpiredUsers(ctx, now)
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	// disabled users are not listed
+	err = s.SetTargetUserStatus(ctx, active.Profile.ID, models.UserStatusDisabled)
+	require.NoError(t, err)
+	users, err = s.DisableExpiredUsers(ctx, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(users))
+	require.Equal(t, expired.Profile.ID, users[0].Profile.ID)
+
+	// manually disabled user isn't enabled by prolongation
+	enabled, err = s.SetUserExpiration(ctx, active.Profile.ID,
+		now.Add(5*time.Hour), now)
+	require.NoError(t, err)
+	require.False(t, enabled)
+
+	// manual status change drops expiration reason
+	err = s.SetTargetUserStatus(ctx, expired.Profile.ID, models.UserStatusDisabled)
+	require.NoError(t, err)
+	enabled, err = s.SetUserExpiration(ctx, expired.Profile.ID,
+		now.Add(5*time.Hour), now)
+	require.NoError(t, err)
+	require.False(t, enabled)
+
+	// reset expiration
+	_, err = s.SetUserExpiration(ctx, expired.Profile.ID, time.Time{}, now)
+	require.NoError(t, err)
+	userView, err = s.GetUserView(ctx, expired.Profile.ID, expired.Profile.Name)
+	require.NoError(t, err)
+	require.True(t, userView.User.ExpiresAt.IsZero())
+
+	// unknown user
+	_, err = s.SetUserExpiration(ctx, 999, time.Time{}, now)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
+
 func TestStorage_Password(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	ctx := context.Background()
@@ -302,7 +1010,296 @@ func TestStorage_Password(t *testing.T) {
 
 	dbauth, err := s.GetAuth(ctx)
 	require.NoError(t, err)
-	require.Equal(t, auth, *dbauth)
+	require.Equal(t, auth.PasswordHash, dbauth.PasswordHash)
+	require.Equal(t, models.Admin{
+		ID:   models.DefaultAdminID,
+		Name: models.DefaultAdminName,
+		Role: models.AdminRoleOwner,
+	}, dbauth.Admin)
+}
+
+func TestStorage_Admins(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	require.NoError(t, s.SetAuth(ctx, &models.Auth{PasswordHash: []byte("hash")}))
+
+	support := models.Admin{Name: "support", Role: models.AdminRoleSupport}
+	require.NoError(t, s.NewAdmin(ctx, &support, []byte("support-hash")))
+	require.NotEqual(t, models.DefaultAdminID, support.ID)
+
+	// names are unique
+	dup := models.Admin{Name: "support", Role: models.AdminRoleOperator}
+	require.ErrorIs(t, s.NewAdmin(ctx, &dup, []byte("hash")), errdefs.ErrInvaildPayload)
+
+	auth, err := s.GetAdminAuth(ctx, "support")
+	require.NoError(t, err)
+	require.Equal(t, support, auth.Admin)
+	require.Equal(t, []byte("support-hash"), auth.PasswordHash)
+
+	require.NoError(t, s.SetAdminRole(ctx, support.ID, models.AdminRoleOperator))
+	require.NoError(t, s.SetAdminPassword(ctx, support.ID, []byte("new-hash")))
+	admin, err := s.GetAdmin(ctx, support.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.AdminRoleOperator, admin.Role)
+	auth, err = s.GetAdminAuth(ctx, "support")
+	require.NoError(t, err)
+	require.Equal(t, []byte("new-hash"), auth.PasswordHash)
+
+	admins, err := s.ListAdmins(ctx)
+	require.NoError(t, err)
+	require.Len(t, admins, 2)
+
+	require.NoError(t, s.DeleteAdmin(ctx, support.ID))
+	_, err = s.GetAdmin(ctx, support.ID)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+	_, err = s.GetAdminAuth(ctx, "support")
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+}
+
+func TestStorage_AdminTOTP(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	require.NoError(t, s.SetAuth(ctx, &models.Auth{PasswordHash: []byte("hash")}))
+	id := models.DefaultAdminID
+
+	// secret is pending until enabled
+	require.NoError(t, s.SetAdminTOTPSecret(ctx, id, "SECRET"))
+	auth, err := s.GetAdminAuthByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, models.AdminTOTP{Secret: "SECRET"}, auth.TOTP)
+
+	require.NoError(t, s.EnableAdminTOTP(ctx, id, [][]byte{[]byte("c1"), []byte("c2")}))
+	auth, err = s.GetAdminAuth(ctx, models.DefaultAdminName)
+	require.NoError(t, err)
+	require.Equal(t, models.AdminTOTP{Secret: "SECRET", Enabled: true}, auth.TOTP)
+
+	// enabled secret isn't replaced by new setup
+	require.NoError(t, s.SetAdminTOTPSecret(ctx, id, "OTHER"))
+	auth, err = s.GetAdminAuthByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "SECRET", auth.TOTP.Secret)
+
+	// recovery codes are one-time
+	require.NoError(t, s.UseRecoveryCode(ctx, id, []byte("c1")))
+	require.ErrorIs(t, s.UseRecoveryCode(ctx, id, []byte("c1")), errdefs.ErrNotFound)
+	require.ErrorIs(t, s.UseRecoveryCode(ctx, id, []byte("c3")), errdefs.ErrNotFound)
+
+	// totp time steps are accepted once and only growing
+	require.NoError(t, s.UseAdminTOTPStep(ctx, id, 100))
+	require.ErrorIs(t, s.UseAdminTOTPStep(ctx, id, 100), errdefs.ErrNotFound)
+	require.ErrorIs(t, s.UseAdminTOTPStep(ctx, id, 99), errdefs.ErrNotFound)
+	require.NoError(t, s.UseAdminTOTPStep(ctx, id, 101))
+
+	require.NoError(t, s.DisableAdminTOTP(ctx, id))
+	auth, err = s.GetAuth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, models.AdminTOTP{}, auth.TOTP)
+	require.ErrorIs(t, s.UseRecoveryCode(ctx, id, []byte("c2")), errdefs.ErrNotFound)
+}
+
+func TestStorage_AuditEvents(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	admin := models.AuditActor{Kind: models.AuditActorAdmin, ID: 0, Name: "admin"}
+	events := []models.AuditEvent{
+		{Actor: admin, Operation: "DisableUser", TargetIDs: []int{1},
+			Params: `{"ID": 1}`, Success: true},
+		{Actor: admin, Operation: "StopNode", TargetIDs: []int{2},
+			Params: `{"ID": 2}`, Success: true},
+		{Actor: models.AuditActor{Kind: models.AuditActorAnonymous, Name: "admin"},
+			Operation: "Auth", Success: false, Error: "access denied"},
+	}
+	for i := range events {
+		require.NoError(t, s.RecordAuditEvent(ctx, &events[i]))
+	}
+
+	all, err := s.ListAuditEvents(ctx, models.ListAuditEventsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	// newest first
+	require.Equal(t, "Auth", all[0].Operation)
+	require.Empty(t, all[0].TargetIDs)
+	require.Equal(t, "StopNode", all[1].Operation)
+	require.Equal(t, admin, all[1].Actor)
+	require.JSONEq(t, `{"ID": 2}`, all[1].Params)
+
+	page, err := s.ListAuditEvents(ctx, models.ListAuditEventsParams{
+		BeforeID: all[1].ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "DisableUser", page[0].Operation)
+
+	filtered, err := s.ListAuditEvents(ctx, models.ListAuditEventsParams{
+		Filter: models.AuditFilter{TargetID: 2}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "StopNode", filtered[0].Operation)
+
+	filtered, err = s.ListAuditEvents(ctx, models.ListAuditEventsParams{
+		Filter: models.AuditFilter{Operation: "Auth", From: time.Now().Add(-time.Hour)}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+
+	require.NoError(t, s.DeleteAuditEventsBefore(ctx, time.Now().Add(time.Hour)))
+	all, err = s.ListAuditEvents(ctx, models.ListAuditEventsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, all)
+}
+
+func TestStorage_ApiTokens(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	token := models.ApiToken{
+		Name: "billing",
+		Scopes: []models.ApiTokenScope{
+			models.ApiTokenScopeUsersRead,
+			models.ApiTokenScopeStatsRead,
+		},
+	}
+	require.NoError(t, s.NewApiToken(ctx, &token, []byte("hash")))
+	require.False(t, token.CreatedAt.IsZero())
+
+	// hashes are unique
+	dup := models.ApiToken{Name: "dup"}
+	require.ErrorIs(t, s.NewApiToken(ctx, &dup, []byte("hash")), errdefs.ErrInvaildPayload)
+
+	readToken, err := s.GetApiToken(ctx, []byte("hash"))
+	require.NoError(t, err)
+	require.Equal(t, token.ID, readToken.ID)
+	require.Equal(t, token.Name, readToken.Name)
+	require.Equal(t, token.Scopes, readToken.Scopes)
+
+	_, err = s.GetApiToken(ctx, []byte("other"))
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	tokens, err := s.ListApiTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+
+	require.NoError(t, s.RevokeApiToken(ctx, token.ID))
+	_, err = s.GetApiToken(ctx, []byte("hash"))
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+	tokens, err = s.ListApiTokens(ctx)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
+
+func TestStorage_Sessions(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	require.NoError(t, s.SetAuth(ctx, &models.Auth{PasswordHash: []byte("pwd")}))
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	session := models.Session{
+		AdminID:   models.DefaultAdminID,
+		ClientIP:  "10.0.0.1",
+		ExpiresAt: expiresAt,
+	}
+	require.NoError(t, s.NewSession(ctx, &session, []byte("refresh")))
+	require.False(t, session.CreatedAt.IsZero())
+
+	readSession, err := s.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.Equal(t, session.AdminID, readSession.AdminID)
+	require.Equal(t, session.ClientIP, readSession.ClientIP)
+
+	// refresh token is valid once
+	rotated, err := s.RotateSession(ctx, []byte("refresh"), []byte("refresh2"),
+		"10.0.0.2", expiresAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, session.ID, rotated.ID)
+	require.Equal(t, "10.0.0.2", rotated.ClientIP)
+	_, err = s.RotateSession(ctx, []byte("refresh"), []byte("refresh3"),
+		"10.0.0.2", expiresAt)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	// expired session isn't active
+	expired := models.Session{
+		AdminID:   models.DefaultAdminID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, s.NewSession(ctx, &expired, []byte("expired")))
+	_, err = s.GetSession(ctx, expired.ID)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	sessions, err := s.ListSessions(ctx, models.DefaultAdminID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	// sessions are revoked by owner only
+	require.NoError(t, s.RevokeSession(ctx, models.DefaultAdminID+1, session.ID))
+	_, err = s.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.NoError(t, s.RevokeSession(ctx, models.DefaultAdminID, session.ID))
+	_, err = s.GetSession(ctx, session.ID)
+	require.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	other := models.Session{
+		AdminID:   models.DefaultAdminID,
+		ExpiresAt: expiresAt,
+	}
+	require.NoError(t, s.NewSession(ctx, &other, []byte("other")))
+	require.NoError(t, s.RevokeAllSessions(ctx))
+	sessions, err = s.ListSessions(ctx, models.DefaultAdminID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestStorage_Webhooks(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+
+	s, _ := setupTestDB(t, logger)
+	logger.Info("new test db inited")
+
+	hook := models.Webhook{
+		URL: "https://example.com/hook",
+		Events: []models.WebhookEventType{
+			models.WebhookEventNodeUnavailable,
+			models.WebhookEventUserExpired,
+		},
+		Secret: "secret",
+	}
+	require.NoError(t, s.NewWebhook(ctx, &hook))
+	require.False(t, hook.CreatedAt.IsZero())
+
+	all := models.Webhook{URL: "http://example.com/all", Secret: "secret2"}
+	require.NoError(t, s.NewWebhook(ctx, &all))
+
+	hooks, err := s.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	require.Equal(t, hook.ID, hooks[0].ID)
+	require.Equal(t, hook.URL, hooks[0].URL)
+	require.Equal(t, hook.Events, hooks[0].Events)
+	require.Equal(t, hook.Secret, hooks[0].Secret)
+	require.Empty(t, hooks[1].Events)
+
+	require.NoError(t, s.DeleteWebhook(ctx, hook.ID))
+	hooks, err = s.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	require.Equal(t, all.ID, hooks[0].ID)
 }
 
 func TestStorage_Settings(t *testing.T) {
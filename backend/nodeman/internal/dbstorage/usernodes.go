@@ -2,6 +2,7 @@ package dbstorage
 
 import (
 	"context"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
 	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
@@ -15,6 +16,8 @@ func (s *Storage) FindPendingSyncs(ctx context.Context,
 	arg := queries.FindPendingSyncsParams{
 		NodeID:            int64(id),
 		DefaultUserStatus: int16(models.UserStatusDisabled),
+		UserStatusEnabled: int16(models.UserStatusEnabled),
+		Now:               time.Now(),
 	}
 
 	// request
@@ -31,6 +34,58 @@ func (s *Storage) FindPendingSyncs(ctx context.Context,
 	return convert.FindPendingSyncsResp(resp), nil
 }
 
+// ListNodeUsers returns users with their target status on node:
+// users not entitled to node are disabled on it
+func (s *Storage) ListNodeUsers(ctx context.Context,
+	id models.NodeID,
+) ([]models.User, error) {
+	// pre-convert
+	arg := queries.ListNodeUsersParams{
+		NodeID:            int64(id),
+		DefaultUserStatus: int16(models.UserStatusDisabled),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListNodeUsersRow, error) {
+		return q.ListNodeUsers(ctx, arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListNodeUsersResp(resp), nil
+}
+
+// ListNodeUserSyncs returns target state of all node users,
+// current state isn't set
+func (s *Storage) ListNodeUserSyncs(ctx context.Context,
+	id models.NodeID,
+) ([]models.UserSyncStatus, error) {
+	// pre-convert
+	arg := queries.ListNodeUserSyncsParams{
+		NodeID:            int64(id),
+		DefaultUserStatus: int16(models.UserStatusDisabled),
+		UserStatusEnabled: int16(models.UserStatusEnabled),
+		Now:               time.Now(),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.ListNodeUserSyncsRow, error) {
+		return q.ListNodeUserSyncs(ctx, arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListNodeUserSyncsResp(resp), nil
+}
+
 const UserNodeLocksMask = 1 << 33
 
 func (s *Storage) SetNodeUsers(ctx context.Context, id models.NodeID,
@@ -2,6 +2,7 @@ package dbstorage
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
@@ -16,9 +17,9 @@ func (s *Storage) NewUser(ctx context.Context, user *models.User) error {
 	arg := convert.NewUserReq(user)
 
 	// request
-	userID, err := doAny(ctx, s, func(ctx context.Context,
+	resp, err := doAny(ctx, s, func(ctx context.Context,
 		q *queries.Queries,
-	) (int64, error) {
+	) (queries.NewUserRow, error) {
 		return q.NewUser(ctx, *arg)
 	})
 	if err != nil {
@@ -26,7 +27,8 @@ func (s *Storage) NewUser(ctx context.Context, user *models.User) error {
 	}
 
 	// post-convert
-	user.Profile.ID = models.UserID(userID)
+	user.Profile.ID = models.UserID(resp.UserID)
+	user.Profile.SubToken = resp.SubToken
 
 	return nil
 }
@@ -73,6 +75,23 @@ func (s *Storage) ListUsers(ctx context.Context) (
 	return convert.ListUsersResp(resp), nil
 }
 
+func (s *Storage) GetUser(ctx context.Context,
+	id models.UserID,
+) (*models.User, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.GetUserRow, error) {
+		return q.GetUser(ctx, int64(id))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.GetUserResp(&resp), nil
+}
+
 func (s *Storage) ListUserViews(ctx context.Context) ([]models.UserView, error) {
 	// request
 	from := time.Now().Add(-month)
@@ -97,10 +116,122 @@ func (s *Storage) SetTargetUserStatus(ctx context.Context,
 	) error {
 		return q.SetTargetUserStatus(ctx,
 			queries.SetTargetUserStatusParams{
-				UserTargetStatus: int16(status),
-				UserID:           int64(id),
+				UserTargetStatus:  int16(status),
+				UserStatusEnabled: int16(models.UserStatusEnabled),
+				UserID:            int64(id),
+			})
+	})
+}
+
+// SetUserQuota changes user traffic quota, user disabled on quota
+// is enabled if its usage is under new quota, quota period change
+// starts new period at now. returns whether user was enabled
+func (s *Storage) SetUserQuota(ctx context.Context,
+	id models.UserID, quota models.TrafficQuota, now time.Time,
+) (bool, error) {
+	// pre-convert
+	req := queries.SetUserQuotaParams{
+		QuotaBytes:        quota.Limit,
+		QuotaPeriod:       int16(quota.Period),
+		UserStatusEnabled: int16(models.UserStatusEnabled),
+		Now:               now,
+		UserID:            int64(id),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (sql.NullBool, error) {
+		return q.SetUserQuota(ctx, req)
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Bool, nil
+}
+
+func (s *Storage) SetUserIPLimit(ctx context.Context,
+	id models.UserID, ipLimit int,
+) error {
+	return doVoid(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) error {
+		return q.SetUserIPLimit(ctx,
+			queries.SetUserIPLimitParams{
+				IpLimit: int32(ipLimit),
+				UserID:  int64(id),
+			})
+	})
+}
+
+// SetUserExpiration changes user expiration time, user disabled
+// on expiration is enabled if it isn't expired at now anymore.
+// returns whether user was enabled
+func (s *Storage) SetUserExpiration(ctx context.Context,
+	id models.UserID, expiresAt time.Time, now time.Time,
+) (bool, error) {
+	// pre-convert
+	req := queries.SetUserExpirationParams{
+		ExpiresAt:         convert.NullTime(expiresAt),
+		UserStatusEnabled: int16(models.UserStatusEnabled),
+		Now:               now,
+		UserID:            int64(id),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (sql.NullBool, error) {
+		return q.SetUserExpiration(ctx, req)
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Bool, nil
+}
+
+// RotateUserCredentials replaces user vless uuid, previous one
+// is kept on nodes until prevExpiresAt, zero time means not kept
+func (s *Storage) RotateUserCredentials(ctx context.Context,
+	id models.UserID, vlessUUID string, prevExpiresAt time.Time,
+) error {
+	_, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (int64, error) {
+		return q.RotateUserCredentials(ctx,
+			queries.RotateUserCredentialsParams{
+				PrevExpiresAt: convert.NullTime(prevExpiresAt),
+				VlessUuid:     vlessUUID,
+				UserID:        int64(id),
 			})
 	})
+	return err
+}
+
+// DisableExpiredUsers disables enabled users expired at now
+// and returns them
+func (s *Storage) DisableExpiredUsers(ctx context.Context,
+	now time.Time,
+) ([]models.User, error) {
+	// pre-convert
+	req := queries.DisableExpiredUsersParams{
+		UserStatusDisabled: int16(models.UserStatusDisabled),
+		Now:                now,
+		UserStatusEnabled:  int16(models.UserStatusEnabled),
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.DisableExpiredUsersRow, error) {
+		return q.DisableExpiredUsers(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.DisableExpiredUsersResp(resp), nil
 }
 
 func (s *Storage) DeleteUser(ctx context.Context,
@@ -112,3 +243,31 @@ func (s *Storage) DeleteUser(ctx context.Context,
 		return q.DeleteUser(ctx, int64(id))
 	})
 }
+
+func (s *Storage) FindUserBySubToken(ctx context.Context,
+	token string,
+) (models.UserID, string, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.FindUserBySubTokenRow, error) {
+		return q.FindUserBySubToken(ctx, token)
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	// post-convert
+	return models.UserID(resp.UserID), resp.UserName, nil
+}
+
+// replace user subscription token with new random one
+func (s *Storage) RotateUserSubToken(ctx context.Context,
+	id models.UserID,
+) (string, error) {
+	return doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (string, error) {
+		return q.RotateUserSubToken(ctx, int64(id))
+	})
+}
@@ -0,0 +1,55 @@
+package dbstorage
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/convert"
+	queries "github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage/sqlc/gen"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+func (s *Storage) NewWebhook(ctx context.Context, webhook *models.Webhook) error {
+	// pre-convert
+	req := queries.NewWebhookParams{
+		Url:    webhook.URL,
+		Events: convert.WebhookEventsReq(webhook.Events),
+		Secret: webhook.Secret,
+	}
+
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) (queries.NewWebhookRow, error) {
+		return q.NewWebhook(ctx, req)
+	})
+	if err != nil {
+		return err
+	}
+
+	// post-convert
+	webhook.ID = models.WebhookID(resp.WebhookID)
+	webhook.CreatedAt = resp.CreatedAt
+
+	return nil
+}
+
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	// request
+	resp, err := doAny(ctx, s, func(ctx context.Context,
+		q *queries.Queries,
+	) ([]queries.Webhook, error) {
+		return q.ListWebhooks(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// post-convert
+	return convert.ListWebhooksResp(resp), nil
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id models.WebhookID) error {
+	return doVoid(ctx, s, func(ctx context.Context, q *queries.Queries) error {
+		return q.DeleteWebhook(ctx, int64(id))
+	})
+}
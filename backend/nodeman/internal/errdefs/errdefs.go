@@ -1,6 +1,8 @@
 package errdefs
 
 import (
+	"time"
+
 	"github.com/XRay-Addons/xrayman/common/xerr"
 )
 
@@ -12,6 +14,10 @@ var (
 	ErrAccessDenied         = xerr.Define("access denied")
 	ErrInvaildPayload       = xerr.Define("invalid payload")
 	ErrNotFound             = xerr.Define("not found")
+	ErrOTPRequired          = xerr.Define("otp required")
+	ErrLockedOut            = xerr.Define("locked out")
+	// node marked unavailable, it's already reported as node event
+	ErrNodeUnavailable = xerr.Define("node unavailable")
 )
 
 func NilCall() error {
@@ -27,6 +33,17 @@ func AccessDenied() error {
 		xerr.WithStack())
 }
 
+func OTPRequired() error {
+	return xerr.Wrap(ErrOTPRequired,
+		xerr.WithStack())
+}
+
+func LockedOut(until time.Time) error {
+	return xerr.Wrap(ErrLockedOut,
+		xerr.WithStack(),
+		xerr.WithInfof("locked until %s", until.Format(time.RFC3339)))
+}
+
 //func InvalidPayload(details string) error {
 //	return xerr.Wrap(ErrInvaildPayload,
 //		xerr.WithStack(),
@@ -3,17 +3,29 @@ package api
 import (
 	"net/http"
 
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/audit"
 	genapi "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
 )
 
-func NewHandler(h genapi.Handler, s genapi.SecurityHandler) (http.Handler, error) {
+// nil limiter disables auth operations rate limiting
+func NewHandler(h genapi.Handler, s genapi.SecurityHandler, a *audit.Recorder,
+	limiter mw.RateLimiter,
+) (http.Handler, error) {
 	if h == nil {
 		return nil, errdefs.NilArg("api.Handler")
 	}
+	if a == nil {
+		return nil, errdefs.NilArg("audit")
+	}
 
-	apiHandler, err := genapi.NewServer(h, s)
+	// limited requests aren't audited
+	apiHandler, err := genapi.NewServer(h, s, genapi.WithMiddleware(
+		RateLimit(limiter, authOperations...),
+		a.Middleware,
+	))
 	if err != nil {
 		return nil, xerr.WrapWithStack(err)
 	}
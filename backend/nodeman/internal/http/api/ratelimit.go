@@ -0,0 +1,43 @@
+package api
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/httperrdefs"
+	genapi "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+	"github.com/ogen-go/ogen/middleware"
+)
+
+// operations checking admin credentials, limited per client ip
+var authOperations = []genapi.OperationName{
+	genapi.AuthOperation,
+	genapi.RefreshOperation,
+	genapi.EnableTOTPOperation,
+	genapi.DisableTOTPOperation,
+}
+
+// api middleware limiting given operations per client ip.
+// operations are matched by name, so api prefix and path
+// params don't matter
+func RateLimit(l mw.RateLimiter, operations ...genapi.OperationName) middleware.Middleware {
+	return func(req middleware.Request, next middleware.Next) (middleware.Response, error) {
+		if l == nil || !slices.Contains(operations, req.OperationName) {
+			return next(req)
+		}
+		key := mw.ClientIP(req.Context)
+		if key == "" && req.Raw != nil {
+			key = req.Raw.RemoteAddr
+		}
+		if ok, wait := l.Allow(key); !ok {
+			if headers := mw.GetHeaders(req.Context); headers != nil {
+				retryAfter := int(math.Ceil(wait.Seconds()))
+				headers.Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			}
+			return middleware.Response{}, httperrdefs.ErrTooManyRequests
+		}
+		return next(req)
+	}
+}
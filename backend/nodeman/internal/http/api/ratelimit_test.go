@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/httperrdefs"
+	genapi "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+	"github.com/ogen-go/ogen/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	l := mw.NewKeyLimiter(1, time.Hour, 1)
+	limit := RateLimit(l, authOperations...)
+
+	call := func(op genapi.OperationName, path, remote string) (http.Header, error) {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		r.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		var req middleware.Request
+		// headers and client ip are set by router middlewares
+		mw.Headers()(mw.RealIP(nil)(http.HandlerFunc(
+			func(_ http.ResponseWriter, r *http.Request) {
+				req = middleware.Request{
+					Context:       r.Context(),
+					OperationName: op,
+					Raw:           r,
+				}
+			}))).ServeHTTP(w, r)
+		_, err := limit(req, func(middleware.Request) (middleware.Response, error) {
+			return middleware.Response{}, nil
+		})
+		return w.Header(), err
+	}
+
+	_, err := call(genapi.AuthOperation, "/api/auth", "1.1.1.1:100")
+	require.NoError(t, err)
+	// operation is limited whatever api prefix or trailing slash is
+	headers, err := call(genapi.AuthOperation, "/v1/api/auth/", "1.1.1.1:200")
+	require.ErrorIs(t, err, httperrdefs.ErrTooManyRequests)
+	require.NotEmpty(t, headers.Get("Retry-After"))
+
+	// other operations and clients aren't limited
+	_, err = call(genapi.ListUsersOperation, "/api/users", "1.1.1.1:100")
+	require.NoError(t, err)
+	_, err = call(genapi.AuthOperation, "/api/auth", "2.2.2.2:100")
+	require.NoError(t, err)
+}
+
+func TestRateLimit_NoLimiter(t *testing.T) {
+	limit := RateLimit(nil, authOperations...)
+	for range 10 {
+		_, err := limit(middleware.Request{
+			Context:       context.Background(),
+			OperationName: genapi.AuthOperation,
+		}, func(middleware.Request) (middleware.Response, error) {
+			return middleware.Response{}, nil
+		})
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,322 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/ogen-go/ogen/middleware"
+	"go.uber.org/zap"
+)
+
+const redacted = "***"
+
+// request fields never written to audit log, lowercase
+var secretFields = map[string]struct{}{
+	"password":  {},
+	"otp":       {},
+	"code":      {},
+	"accesskey": {},
+	"secret":    {},
+	// refresh token is sent in body
+	"refresh_token": {},
+	// user credentials, recorded only as changed
+	"vlessuuid": {},
+	"subtoken":  {},
+}
+
+// request fields recorded as size and hash only, lowercase.
+// pushed xray configs contain node private keys
+var digestFields = map[string]struct{}{
+	"serverconfig": {},
+	"clientconfig": {},
+}
+
+// longer request arrays, like imported users, are recorded as count only
+const maxParamItems = 20
+
+// Recorder writes audit events for mutating api calls
+type Recorder struct {
+	storage Storage
+	states  StateStorage
+	log     *zap.Logger
+}
+
+func New(storage Storage, states StateStorage, log *zap.Logger) (*Recorder, error) {
+	if storage == nil {
+		return nil, errdefs.NilArg("storage")
+	}
+	if states == nil {
+		return nil, errdefs.NilArg("states")
+	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
+	return &Recorder{storage: storage, states: states, log: log}, nil
+}
+
+// api middleware, runs after security handler so actor is known.
+// read-only GET operations aren't recorded
+func (r *Recorder) Middleware(req middleware.Request, next middleware.Next) (
+	middleware.Response, error,
+) {
+	if r == nil || req.Raw == nil || req.Raw.Method == http.MethodGet {
+		return next(req)
+	}
+
+	params := requestParams(req)
+	before := r.state(req, params)
+
+	resp, err := next(req)
+
+	// targets are taken before long ids lists are summarized
+	targets := targetIDs(params, resp.Type)
+	redact(params)
+	event := models.AuditEvent{
+		Actor:     actor(req),
+		Operation: req.OperationName,
+		TargetIDs: targets,
+		Params:    encodeParams(params),
+		ClientIP:  clientIP(req.Raw),
+		RequestID: chimw.GetReqID(req.Context),
+		Success:   err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if before != nil && err == nil {
+		event.Changes = encodeChanges(diffStates(before, r.state(req, params)))
+	}
+
+	// call is already done, record it even if client is gone
+	ctx := context.WithoutCancel(req.Context)
+	if rerr := r.storage.RecordAuditEvent(ctx, &event); rerr != nil {
+		r.log.Error("record audit event",
+			zap.String("operation", event.Operation),
+			zap.String(mw.RequestIDLogTag, event.RequestID),
+			zap.Error(rerr))
+	}
+
+	return resp, err
+}
+
+// target state, nil if operation has no target or it can't be loaded
+func (r *Recorder) state(req middleware.Request, params map[string]any) map[string]any {
+	ctx := context.WithoutCancel(req.Context)
+	state, err := r.loadState(ctx, req.OperationName, params)
+	if err != nil {
+		r.log.Warn("load audit target state",
+			zap.String("operation", req.OperationName),
+			zap.String(mw.RequestIDLogTag, chimw.GetReqID(req.Context)),
+			zap.Error(err))
+		return nil
+	}
+	return state
+}
+
+func actor(req middleware.Request) models.AuditActor {
+	if admin, ok := security.AdminFromContext(req.Context); ok {
+		return models.AuditActor{
+			Kind: models.AuditActorAdmin,
+			ID:   admin.ID,
+			Name: admin.Name,
+		}
+	}
+	if token, ok := security.ApiTokenFromContext(req.Context); ok {
+		return models.AuditActor{
+			Kind: models.AuditActorApiToken,
+			ID:   token.ID,
+			Name: token.Name,
+		}
+	}
+	// login attempt is attributed to login name
+	if auth, ok := req.Body.(*api.AuthRequest); ok {
+		return models.AuditActor{
+			Kind: models.AuditActorAnonymous,
+			Name: auth.Login.Or(models.DefaultAdminName),
+		}
+	}
+	return models.AuditActor{Kind: models.AuditActorAnonymous}
+}
+
+// json body fields merged with path and query parameters,
+// redacted before recording
+func requestParams(req middleware.Request) map[string]any {
+	params := make(map[string]any)
+	if len(req.RawBody) > 0 {
+		// non-object body is ignored
+		_ = json.Unmarshal(req.RawBody, &params)
+	}
+	for k, v := range req.Params {
+		params[k.Name] = v
+	}
+	return params
+}
+
+func redact(m map[string]any) {
+	for k, v := range m {
+		if _, ok := secretFields[strings.ToLower(k)]; ok {
+			m[k] = redacted
+			continue
+		}
+		if _, ok := digestFields[strings.ToLower(k)]; ok {
+			m[k] = digest(v)
+			continue
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			redact(v)
+		case []any:
+			if len(v) > maxParamItems {
+				m[k] = map[string]any{"Count": len(v)}
+				continue
+			}
+			for _, item := range v {
+				if im, ok := item.(map[string]any); ok {
+					redact(im)
+				}
+			}
+		}
+	}
+}
+
+// size and sha256 of value, enough to tell recorded values apart
+func digest(v any) map[string]any {
+	s, ok := v.(string)
+	if !ok {
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+	hash := sha256.Sum256([]byte(s))
+	return map[string]any{
+		"Size":   len(s),
+		"SHA256": hex.EncodeToString(hash[:]),
+	}
+}
+
+func encodeParams(params map[string]any) string {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// request ID or IDs and IDs of created objects, like Node.ID
+// of NewNode response or Users[].Profile.ID of ImportUsers one
+func targetIDs(params map[string]any, resp any) []int {
+	ids := make([]int, 0, 2)
+	if id, ok := asID(params["ID"]); ok {
+		ids = append(ids, id)
+	}
+	ids = appendIDs(ids, params["IDs"])
+	m, ok := asMap(resp)
+	if !ok {
+		return ids
+	}
+	if id, ok := objectID(m); ok {
+		return appendUnique(ids, id)
+	}
+	for _, v := range m {
+		switch v := v.(type) {
+		case map[string]any:
+			if id, ok := objectID(v); ok {
+				ids = appendUnique(ids, id)
+			}
+		case []any:
+			for _, item := range v {
+				if im, ok := item.(map[string]any); ok {
+					if id, ok := objectID(im); ok {
+						ids = appendUnique(ids, id)
+					}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// ids param is decoded from path or query as typed slice
+// and from json body as []any
+func appendIDs(ids []int, v any) []int {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
+		return ids
+	}
+	for i := range rv.Len() {
+		if id, ok := asID(rv.Index(i).Interface()); ok {
+			ids = appendUnique(ids, id)
+		}
+	}
+	return ids
+}
+
+// object ID, user id is kept in its profile
+func objectID(m map[string]any) (int, bool) {
+	if id, ok := asID(m["ID"]); ok {
+		return id, true
+	}
+	if profile, ok := m["Profile"].(map[string]any); ok {
+		return asID(profile["ID"])
+	}
+	return 0, false
+}
+
+func asMap(v any) (map[string]any, bool) {
+	marshaler, ok := v.(json.Marshaler)
+	if !ok {
+		return nil, false
+	}
+	b, err := marshaler.MarshalJSON()
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+func asID(v any) (int, bool) {
+	switch id := v.(type) {
+	case float64:
+		return int(id), true
+	case int:
+		return id, true
+	case int64:
+		return int(id), true
+	}
+	// path parameters have api named int types
+	if v := reflect.ValueOf(v); v.IsValid() && v.CanInt() {
+		return int(v.Int()), true
+	}
+	return 0, false
+}
+
+func appendUnique(ids []int, id int) []int {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
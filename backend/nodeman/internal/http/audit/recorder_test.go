@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+	"github.com/ogen-go/ogen/middleware"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type testStorage struct {
+	events []models.AuditEvent
+}
+
+func (s *testStorage) RecordAuditEvent(_ context.Context, e *models.AuditEvent) error {
+	s.events = append(s.events, *e)
+	return nil
+}
+
+// user state changed by call under test
+type testStates struct {
+	user *models.User
+}
+
+func (s *testStates) GetUser(_ context.Context, id models.UserID) (*models.User, error) {
+	if s.user == nil || s.user.Profile.ID != id {
+		return nil, xerr.WrapWithType(xerr.New("no user"), errdefs.ErrNotFound)
+	}
+	user := *s.user
+	return &user, nil
+}
+
+func (s *testStates) GetNode(_ context.Context, _ models.NodeID) (*models.Node, error) {
+	return nil, xerr.WrapWithType(xerr.New("no node"), errdefs.ErrNotFound)
+}
+
+func (s *testStates) GetSettings(_ context.Context) (*models.Settings, error) {
+	return &models.Settings{}, nil
+}
+
+func newRequest(method string, op string, body any) middleware.Request {
+	raw, _ := http.NewRequest(method, "/", nil)
+	raw.RemoteAddr = "10.0.0.1:4321"
+	rawBody, _ := json.Marshal(body)
+	return middleware.Request{
+		Context:       context.Background(),
+		OperationName: op,
+		Body:          body,
+		RawBody:       rawBody,
+		Raw:           raw,
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	storage := &testStorage{}
+	r, err := New(storage, &testStates{}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	// read-only call isn't recorded
+	_, err = r.Middleware(newRequest(http.MethodGet, "ListUsers", nil),
+		func(middleware.Request) (middleware.Response, error) {
+			return middleware.Response{}, nil
+		})
+	require.NoError(t, err)
+	require.Empty(t, storage.events)
+
+	// created object id is taken from response
+	_, err = r.Middleware(newRequest(http.MethodPost, "NewNode", &api.NewNodeRequest{
+		Endpoint:  "1.2.3.4:80",
+		AccessKey: "key",
+	}), func(middleware.Request) (middleware.Response, error) {
+		return middleware.Response{Type: &api.NewNodeResponse{Node: api.Node{ID: 7}}}, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, storage.events, 1)
+	e := storage.events[0]
+	require.Equal(t, "NewNode", e.Operation)
+	require.Equal(t, []int{7}, e.TargetIDs)
+	require.Equal(t, "10.0.0.1", e.ClientIP)
+	require.True(t, e.Success)
+	require.JSONEq(t, `{"Endpoint":"1.2.3.4:80","AccessKey":"***"}`, e.Params)
+
+	// failed login is recorded without password
+	_, err = r.Middleware(newRequest(http.MethodPost, "Auth", &api.AuthRequest{
+		Login:    api.NewOptString("operator"),
+		Password: "pwd",
+	}), func(middleware.Request) (middleware.Response, error) {
+		return middleware.Response{}, xerr.New("access denied")
+	})
+	require.Error(t, err)
+	require.Len(t, storage.events, 2)
+	e = storage.events[1]
+	require.Equal(t, models.AuditActor{Kind: models.AuditActorAnonymous, Name: "operator"}, e.Actor)
+	require.False(t, e.Success)
+	require.Equal(t, "access denied", e.Error)
+	require.NotContains(t, e.Params, "pwd")
+	require.Empty(t, e.TargetIDs)
+}
+
+func TestRecorderChanges(t *testing.T) {
+	storage := &testStorage{}
+	states := &testStates{user: &models.User{
+		Profile:      models.UserProfile{ID: 3, Name: "u", VlessUUID: "old-uuid"},
+		TargetStatus: models.UserStatusEnabled,
+		IPLimit:      2,
+	}}
+	r, err := New(storage, states, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	newUserRequest := func(op string) middleware.Request {
+		req := newRequest(http.MethodPost, op, nil)
+		req.RawBody = nil
+		req.Params = middleware.Parameters{
+			{Name: "ID", In: "path"}: api.UserID(3),
+		}
+		return req
+	}
+
+	// changed fields are recorded with prior values
+	_, err = r.Middleware(newUserRequest("SetUserIPLimit"),
+		func(middleware.Request) (middleware.Response, error) {
+			states.user.IPLimit = 5
+			return middleware.Response{}, nil
+		})
+	require.NoError(t, err)
+	require.Len(t, storage.events, 1)
+	require.Equal(t, []int{3}, storage.events[0].TargetIDs)
+	require.JSONEq(t, `{"IPLimit":{"Before":2,"After":5}}`, storage.events[0].Changes)
+
+	// changed credentials are recorded without values
+	_, err = r.Middleware(newUserRequest("RotateUserCredentials"),
+		func(middleware.Request) (middleware.Response, error) {
+			states.user.Profile.VlessUUID = "new-uuid"
+			return middleware.Response{}, nil
+		})
+	require.NoError(t, err)
+	require.Len(t, storage.events, 2)
+	require.JSONEq(t, `{"Profile.VlessUUID":{"Before":"***","After":"***"}}`,
+		storage.events[1].Changes)
+
+	// deleted user has no state after call
+	_, err = r.Middleware(newUserRequest("DeleteUser"),
+		func(middleware.Request) (middleware.Response, error) {
+			states.user = nil
+			return middleware.Response{}, nil
+		})
+	require.NoError(t, err)
+	require.Len(t, storage.events, 3)
+	var changes map[string]map[string]any
+	require.NoError(t, json.Unmarshal([]byte(storage.events[2].Changes), &changes))
+	require.Equal(t, float64(5), changes["IPLimit"]["Before"])
+	require.NotContains(t, changes["IPLimit"], "After")
+	require.NotContains(t, storage.events[2].Changes, "new-uuid")
+}
+
+func TestRecorderMultipleTargets(t *testing.T) {
+	storage := &testStorage{}
+	r, err := New(storage, &testStates{}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	// pushed configs are recorded without node private keys
+	serverConfig := `{"inbounds":[{"streamSettings":{"realitySettings":` +
+		`{"privateKey":"4BHzOYgdeeG4de3oFimrg865ky_5X9cVoxLc_VmtEHc"}}}]}`
+	_, err = r.Middleware(newRequest(http.MethodPost, "PushNodeConfig", &api.PushNodeConfigRequest{
+		IDs:          []api.NodeID{2, 5},
+		ServerConfig: serverConfig,
+		ClientConfig: `[{"outbounds":[]}]`,
+	}), func(middleware.Request) (middleware.Response, error) {
+		return middleware.Response{}, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, storage.events, 1)
+	e := storage.events[0]
+	require.Equal(t, []int{2, 5}, e.TargetIDs)
+	require.NotContains(t, e.Params, "privateKey")
+	require.NotContains(t, e.Params, "4BHzOYgdeeG4de3oFimrg865ky_5X9cVoxLc_VmtEHc")
+	var recorded struct {
+		ServerConfig struct {
+			Size   int
+			SHA256 string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(e.Params), &recorded))
+	require.Equal(t, len(serverConfig), recorded.ServerConfig.Size)
+	require.Len(t, recorded.ServerConfig.SHA256, 64)
+
+	// large import is recorded as users count, created users are targets
+	importReq := &api.ImportUsersRequest{}
+	importResp := &api.ImportUsersResponse{}
+	for i := range 100 {
+		importReq.Users = append(importReq.Users, api.ImportUser{
+			DisplayName: api.DisplayName(fmt.Sprintf("user%d", i)),
+		})
+		importResp.Users = append(importResp.Users, api.User{
+			Profile: api.UserProfile{ID: api.UserID(i + 1)},
+		})
+	}
+	_, err = r.Middleware(newRequest(http.MethodPost, "ImportUsers", importReq),
+		func(middleware.Request) (middleware.Response, error) {
+			return middleware.Response{Type: importResp}, nil
+		})
+	require.NoError(t, err)
+	require.Len(t, storage.events, 2)
+	e = storage.events[1]
+	require.Len(t, e.TargetIDs, 100)
+	require.Equal(t, 1, e.TargetIDs[0])
+	require.JSONEq(t, `{"Users":{"Count":100}}`, e.Params)
+}
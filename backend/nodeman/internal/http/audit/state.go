@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// operations with target state recorded before and after call
+var (
+	userOperations = map[api.OperationName]struct{}{
+		api.EnableUserOperation:            {},
+		api.DisableUserOperation:           {},
+		api.DeleteUserOperation:            {},
+		api.SetUserQuotaOperation:          {},
+		api.SetUserIPLimitOperation:        {},
+		api.SetUserExpirationOperation:     {},
+		api.RotateUserCredentialsOperation: {},
+		api.RotateUserSubTokenOperation:    {},
+		api.SetUserGroupsOperation:         {},
+	}
+	nodeOperations = map[api.OperationName]struct{}{
+		api.StartNodeOperation:  {},
+		api.StopNodeOperation:   {},
+		api.ReloadNodeOperation: {},
+		api.DeleteNodeOperation: {},
+	}
+)
+
+// field value change
+type change struct {
+	Before any `json:",omitempty"`
+	After  any `json:",omitempty"`
+}
+
+// load operation target state flattened to dotted field names,
+// nil if operation has no recorded target or target isn't found
+func (r *Recorder) loadState(ctx context.Context,
+	operation api.OperationName, params map[string]any,
+) (map[string]any, error) {
+	var (
+		state any
+		err   error
+	)
+	id, hasID := asID(params["ID"])
+	_, isUserOp := userOperations[operation]
+	_, isNodeOp := nodeOperations[operation]
+	switch {
+	case isUserOp && hasID:
+		state, err = r.states.GetUser(ctx, id)
+	case isNodeOp && hasID:
+		state, err = r.states.GetNode(ctx, id)
+	case operation == api.SetSettingsOperation:
+		state, err = r.states.GetSettings(ctx)
+	default:
+		return nil, nil
+	}
+	// deleted target has no state after call
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return flatten(state)
+}
+
+// json object fields with nested objects joined by dot
+func flatten(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	flat := make(map[string]any)
+	flattenInto(flat, "", m)
+	return flat, nil
+}
+
+func flattenInto(flat map[string]any, prefix string, m map[string]any) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flattenInto(flat, k, nested)
+			continue
+		}
+		flat[k] = v
+	}
+}
+
+// changed fields, changed secrets are listed with hidden values
+func diffStates(before, after map[string]any) map[string]change {
+	changes := make(map[string]change)
+	for k, b := range before {
+		a, ok := after[k]
+		if ok && reflect.DeepEqual(a, b) {
+			continue
+		}
+		changes[k] = change{Before: b, After: a}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok {
+			changes[k] = change{After: a}
+		}
+	}
+	for k, c := range changes {
+		if !isSecretField(k) {
+			continue
+		}
+		if c.Before != nil {
+			c.Before = redacted
+		}
+		if c.After != nil {
+			c.After = redacted
+		}
+		changes[k] = c
+	}
+	return changes
+}
+
+// last dotted name segment is secret field
+func isSecretField(name string) bool {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	_, ok := secretFields[strings.ToLower(name)]
+	return ok
+}
+
+func encodeChanges(changes map[string]change) string {
+	if len(changes) == 0 {
+		return "{}"
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
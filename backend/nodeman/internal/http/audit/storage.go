@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	// append audit event
+	RecordAuditEvent(ctx context.Context, e *models.AuditEvent) error
+}
+
+// mutated targets state, recorded before and after call
+type StateStorage interface {
+	// get user by id, return ErrNotFound if not exists
+	GetUser(ctx context.Context, id models.UserID) (*models.User, error)
+	// get node by id, return ErrNotFound if not exists
+	GetNode(ctx context.Context, id models.NodeID) (*models.Node, error)
+	// get dynamic settings
+	GetSettings(ctx context.Context) (*models.Settings, error)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+func (h *Handler) NewAdmin(ctx context.Context, req *api.NewAdminRequest) (
+	*api.Admin, error,
+) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertNewAdminRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.auth.NewAdmin(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertAdmin(res), nil
+}
+
+func (h *Handler) ListAdmins(ctx context.Context) (*api.ListAdminsResponse, error) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.auth.ListAdmins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListAdminsResult(res), nil
+}
+
+func (h *Handler) GetCurrentAdmin(ctx context.Context) (*api.Admin, error) {
+	if h == nil {
+		return nil, errdefs.NilCall()
+	}
+	admin, ok := security.AdminFromContext(ctx)
+	if !ok {
+		return nil, errdefs.AccessDenied()
+	}
+	return converter.ConvertAdmin(admin), nil
+}
+
+func (h *Handler) SetAdminRole(ctx context.Context, req *api.SetAdminRoleRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetAdminRoleRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.auth.SetAdminRole(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) SetAdminPassword(ctx context.Context, req *api.SetAdminPasswordRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetAdminPasswordRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.auth.SetAdminPassword(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) DeleteAdmin(ctx context.Context, req *api.DeleteAdminRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertDeleteAdminRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.auth.DeleteAdmin(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) SetupTOTP(ctx context.Context) (*api.TOTPSetupResponse, error) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	admin, ok := security.AdminFromContext(ctx)
+	if !ok {
+		return nil, errdefs.AccessDenied()
+	}
+	res, err := h.auth.SetupTOTP(ctx, *admin)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertTOTPSetupResult(res), nil
+}
+
+func (h *Handler) EnableTOTP(ctx context.Context, req *api.EnableTOTPRequest) (
+	*api.EnableTOTPResponse, error,
+) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	admin, ok := security.AdminFromContext(ctx)
+	if !ok {
+		return nil, errdefs.AccessDenied()
+	}
+	res, err := h.auth.EnableTOTP(ctx, converter.ConvertEnableTOTPRequest(admin.ID, mw.ClientIP(ctx), req))
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertEnableTOTPResult(res), nil
+}
+
+func (h *Handler) DisableTOTP(ctx context.Context, req *api.DisableTOTPRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	admin, ok := security.AdminFromContext(ctx)
+	if !ok {
+		return errdefs.AccessDenied()
+	}
+	return h.auth.DisableTOTP(ctx, converter.ConvertDisableTOTPRequest(admin.ID, mw.ClientIP(ctx), req))
+}
+
+func (h *Handler) ResetAdminTOTP(ctx context.Context, req *api.ResetAdminTOTPRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertResetAdminTOTPRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.auth.ResetAdminTOTP(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+func (h *Handler) ListAuditEvents(ctx context.Context,
+	req api.ListAuditEventsParams,
+) (*api.ListAuditEventsResponse, error) {
+	if h == nil || h.audit == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertListAuditEventsRequest(req)
+	res, err := h.audit.ListAuditEvents(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListAuditEventsResult(res), nil
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+//go:generate mockgen -source=audit_service.go -destination=./mocks/mock_audit_service.go -package=mocks
+type AuditService interface {
+	ListAuditEvents(ctx context.Context, p models.ListAuditEventsParams) (*models.ListAuditEventsResult, error)
+}
@@ -3,9 +3,12 @@ package handler
 import (
 	"context"
 
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/security"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
 )
 
@@ -19,9 +22,90 @@ func (h *Handler) Auth(ctx context.Context, req *api.AuthRequest) (
 	if err != nil {
 		return nil, err
 	}
+	p.ClientIP = mw.ClientIP(ctx)
 	res, err := h.auth.Auth(ctx, *p)
 	if err != nil {
 		return nil, err
 	}
 	return converter.ConvertAuthResult(res), nil
 }
+
+func (h *Handler) ListAuthLockouts(ctx context.Context) (*api.ListAuthLockoutsResponse, error) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.auth.ListAuthLockouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListAuthLockoutsResult(res), nil
+}
+
+func (h *Handler) UnlockAuth(ctx context.Context, req *api.UnlockAuthRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertUnlockAuthRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.auth.UnlockAuth(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) Refresh(ctx context.Context, req *api.RefreshRequest) (
+	*api.AuthResponse, error,
+) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertRefreshRequest(mw.ClientIP(ctx), req)
+	res, err := h.auth.Refresh(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertAuthResult(res), nil
+}
+
+// revoke session of request, its access token is rejected after that
+func (h *Handler) Logout(ctx context.Context) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	session, ok := security.SessionFromContext(ctx)
+	if !ok {
+		return errdefs.AccessDenied()
+	}
+	return h.auth.RevokeSession(ctx, models.RevokeSessionParams{
+		AdminID: session.AdminID,
+		ID:      session.ID,
+	})
+}
+
+func (h *Handler) ListSessions(ctx context.Context) (*api.ListSessionsResponse, error) {
+	if h == nil || h.auth == nil {
+		return nil, errdefs.NilCall()
+	}
+	session, ok := security.SessionFromContext(ctx)
+	if !ok {
+		return nil, errdefs.AccessDenied()
+	}
+	res, err := h.auth.ListSessions(ctx, session.AdminID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListSessionsResult(res), nil
+}
+
+func (h *Handler) RevokeSession(ctx context.Context, req *api.RevokeSessionRequest) error {
+	if h == nil || h.auth == nil {
+		return errdefs.NilCall()
+	}
+	session, ok := security.SessionFromContext(ctx)
+	if !ok {
+		return errdefs.AccessDenied()
+	}
+	return h.auth.RevokeSession(ctx, converter.ConvertRevokeSessionRequest(session.AdminID, req))
+}
@@ -9,4 +9,19 @@ import (
 //go:generate mockgen -source=auth_service.go -destination=./mocks/mock_auth_service.go -package=mocks
 type AuthService interface {
 	Auth(ctx context.Context, p models.AuthParams) (*models.AuthResult, error)
+	NewAdmin(ctx context.Context, p models.NewAdminParams) (*models.Admin, error)
+	ListAdmins(ctx context.Context) (*models.ListAdminsResult, error)
+	SetAdminRole(ctx context.Context, p models.SetAdminRoleParams) error
+	SetAdminPassword(ctx context.Context, p models.SetAdminPasswordParams) error
+	DeleteAdmin(ctx context.Context, p models.DeleteAdminParams) error
+	SetupTOTP(ctx context.Context, admin models.Admin) (*models.TOTPSetupResult, error)
+	EnableTOTP(ctx context.Context, p models.EnableTOTPParams) (*models.EnableTOTPResult, error)
+	DisableTOTP(ctx context.Context, p models.DisableTOTPParams) error
+	ResetAdminTOTP(ctx context.Context, p models.ResetAdminTOTPParams) error
+	ListAuthLockouts(ctx context.Context) (*models.ListAuthLockoutsResult, error)
+	UnlockAuth(ctx context.Context, p models.UnlockAuthParams) error
+	Refresh(ctx context.Context, p models.RefreshParams) (*models.AuthResult, error)
+	ListSessions(ctx context.Context, adminID models.AdminID,
+		current models.SessionID) (*models.ListSessionsResult, error)
+	RevokeSession(ctx context.Context, p models.RevokeSessionParams) error
 }
@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// goverter:converter
+// goverter:output:format function
+// goverter:output:file ./audit_generated.go
+// goverter:extend ConvertAuditTime ConvertAuditActorKind ConvertNextBeforeID
+// goverter:enum:unknown @panic
+//
+//go:generate goverter gen .
+type Audit interface {
+	ConvertListAuditEventsResult(r *models.ListAuditEventsResult) *api.ListAuditEventsResponse
+}
+
+func ConvertListAuditEventsRequest(r api.ListAuditEventsParams) models.ListAuditEventsParams {
+	return models.ListAuditEventsParams{
+		Filter: models.AuditFilter{
+			Operation: r.Operation.Or(""),
+			ActorName: r.Actor.Or(""),
+			TargetID:  r.TargetID.Or(0),
+			From:      r.From.Or(time.Time{}),
+			To:        r.To.Or(time.Time{}),
+		},
+		BeforeID: models.AuditEventID(r.BeforeID.Or(0)),
+		Limit:    r.Limit.Or(0),
+	}
+}
+
+func ConvertAuditTime(t time.Time) time.Time {
+	return t
+}
+
+func ConvertAuditActorKind(k models.AuditActorKind) api.AuditActorKind {
+	switch k {
+	case models.AuditActorAdmin:
+		return api.AuditActorKindAdmin
+	case models.AuditActorApiToken:
+		return api.AuditActorKindApiToken
+	case models.AuditActorAnonymous:
+		return api.AuditActorKindAnonymous
+	default:
+		panic(fmt.Sprintf("unexpected enum element: %v", k))
+	}
+}
+
+// zero means no more events
+func ConvertNextBeforeID(id models.AuditEventID) api.OptInt {
+	if id == 0 {
+		return api.OptInt{}
+	}
+	return api.NewOptInt(id)
+}
@@ -10,16 +10,95 @@ import (
 // goverter:converter
 // goverter:output:format function
 // goverter:output:file ./auth_generated.go
-// goverter:extend ConvertExpireTime
+// goverter:extend ConvertExpireTime ConvertOptString ConvertTime
 // goverter:enum:unknown @panic
 //
 //go:generate goverter gen .
 type AuthConverter interface {
+	// goverter:map Otp OTP
+	// goverter:ignore ClientIP
 	ConvertAuthRequest(r *api.AuthRequest) (*models.AuthParams, error)
 
 	ConvertAuthResult(r *models.AuthResult) *api.AuthResponse
+
+	ConvertNewAdminRequest(r *api.NewAdminRequest) (*models.NewAdminParams, error)
+
+	ConvertAdmin(r *models.Admin) *api.Admin
+
+	ConvertListAdminsResult(r *models.ListAdminsResult) *api.ListAdminsResponse
+
+	ConvertSetAdminRoleRequest(r *api.SetAdminRoleRequest) (*models.SetAdminRoleParams, error)
+
+	ConvertSetAdminPasswordRequest(r *api.SetAdminPasswordRequest) (*models.SetAdminPasswordParams, error)
+
+	ConvertDeleteAdminRequest(r *api.DeleteAdminRequest) (*models.DeleteAdminParams, error)
+
+	ConvertTOTPSetupResult(r *models.TOTPSetupResult) *api.TOTPSetupResponse
+
+	ConvertEnableTOTPResult(r *models.EnableTOTPResult) *api.EnableTOTPResponse
+
+	ConvertResetAdminTOTPRequest(r *api.ResetAdminTOTPRequest) (*models.ResetAdminTOTPParams, error)
+
+	// goverter:map Key.Login Login
+	// goverter:map Key.ClientIP ClientIP
+	ConvertAuthLockout(r models.AuthLockout) api.AuthLockout
+
+	ConvertListAuthLockoutsResult(r *models.ListAuthLockoutsResult) *api.ListAuthLockoutsResponse
+
+	ConvertUnlockAuthRequest(r *api.UnlockAuthRequest) (*models.UnlockAuthParams, error)
+
+	ConvertSession(r models.Session) api.Session
+
+	ConvertListSessionsResult(r *models.ListSessionsResult) *api.ListSessionsResponse
 }
 
 func ConvertExpireTime(i time.Duration) int {
 	return int(i.Seconds())
 }
+
+// unset login means default admin, unset otp means no second factor
+func ConvertOptString(s api.OptString) string {
+	return s.Or("")
+}
+
+// totp is changed by admin for own account,
+// client ip is resolved by middleware
+func ConvertEnableTOTPRequest(id models.AdminID, clientIP string,
+	r *api.EnableTOTPRequest,
+) models.EnableTOTPParams {
+	return models.EnableTOTPParams{
+		AdminID:  id,
+		Code:     r.Code,
+		ClientIP: clientIP,
+	}
+}
+
+func ConvertDisableTOTPRequest(id models.AdminID, clientIP string,
+	r *api.DisableTOTPRequest,
+) models.DisableTOTPParams {
+	return models.DisableTOTPParams{
+		AdminID:  id,
+		Code:     r.Code,
+		ClientIP: clientIP,
+	}
+}
+
+func ConvertTime(t time.Time) time.Time {
+	return t
+}
+
+// client ip is resolved by middleware
+func ConvertRefreshRequest(clientIP string, r *api.RefreshRequest) models.RefreshParams {
+	return models.RefreshParams{
+		RefreshToken: r.RefreshToken,
+		ClientIP:     clientIP,
+	}
+}
+
+// admin revokes own sessions only
+func ConvertRevokeSessionRequest(id models.AdminID, r *api.RevokeSessionRequest) models.RevokeSessionParams {
+	return models.RevokeSessionParams{
+		AdminID: id,
+		ID:      models.SessionID(r.ID),
+	}
+}
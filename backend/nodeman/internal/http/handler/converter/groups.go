@@ -0,0 +1,25 @@
+package converter
+
+import (
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// goverter:converter
+// goverter:output:format function
+// goverter:output:file ./groups_generated.go
+// goverter:enum:unknown @panic
+//
+//go:generate goverter gen .
+type Groups interface {
+	ConvertNewNodeGroupRequest(r *api.NewNodeGroupRequest) (*models.NewNodeGroupParams, error)
+	ConvertNewNodeGroupResult(r *models.NewNodeGroupResult) *api.NewNodeGroupResponse
+
+	ConvertDeleteNodeGroupRequest(r *api.DeleteNodeGroupRequest) (*models.DeleteNodeGroupParams, error)
+
+	ConvertListNodeGroupsResult(r *models.ListNodeGroupsResult) *api.ListNodeGroupsResponse
+
+	ConvertSetNodeGroupsRequest(r *api.SetNodeGroupsRequest) (*models.SetNodeGroupsParams, error)
+
+	ConvertSetUserGroupsRequest(r *api.SetUserGroupsRequest) (*models.SetUserGroupsParams, error)
+}
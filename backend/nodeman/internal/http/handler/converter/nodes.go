@@ -22,6 +22,10 @@ type Nodes interface {
 
 	ConvertStopNodeRequest(r *api.StopNodeRequest) (*models.StopNodeParams, error)
 
+	ConvertReloadNodeRequest(r *api.ReloadNodeRequest) (*models.ReloadNodeParams, error)
+
+	ConvertPushNodeConfigRequest(r *api.PushNodeConfigRequest) (*models.PushNodeConfigParams, error)
+
 	ConvertListNodesResult(r *models.ListNodeResult) *api.ListNodeResponse
 
 	ConvertDeleteNodeRequest(r *api.DeleteNodeRequest) (*models.DeleteNodeParams, error)
@@ -1,6 +1,8 @@
 package converter
 
 import (
+	"fmt"
+
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
 )
@@ -8,6 +10,9 @@ import (
 // goverter:converter
 // goverter:output:format function
 // goverter:output:file ./settings_generated.go
+// goverter:extend ConvertIPLimitPolicy RConvertIPLimitPolicy
+// goverter:extend ConvertIPLimitMessage RConvertIPLimitMessage
+// goverter:extend ConvertAuditRetention RConvertAuditRetention
 // goverter:enum:unknown @panic
 //
 //go:generate goverter gen .
@@ -15,3 +20,44 @@ type DynamicConfig interface {
 	ConvertSettingsRequest(r api.Settings) (*models.Settings, error)
 	ConvertSettingsResult(r models.Settings) *api.Settings
 }
+
+// unset policy means warn
+func ConvertIPLimitPolicy(p api.OptIPLimitPolicy) models.IPLimitPolicy {
+	v, ok := p.Get()
+	if !ok {
+		return models.IPLimitPolicyWarn
+	}
+	switch v {
+	case api.IPLimitPolicyWarn:
+		return models.IPLimitPolicyWarn
+	case api.IPLimitPolicyDisable:
+		return models.IPLimitPolicyDisable
+	default:
+		panic(fmt.Sprintf("unexpected enum element: %v", v))
+	}
+}
+
+// settings stored before ip limits have no policy, it means warn
+func RConvertIPLimitPolicy(p models.IPLimitPolicy) api.OptIPLimitPolicy {
+	if p == models.IPLimitPolicyDisable {
+		return api.NewOptIPLimitPolicy(api.IPLimitPolicyDisable)
+	}
+	return api.NewOptIPLimitPolicy(api.IPLimitPolicyWarn)
+}
+
+func ConvertIPLimitMessage(s api.OptString) string {
+	return s.Or("")
+}
+
+func RConvertIPLimitMessage(s string) api.OptString {
+	return api.NewOptString(s)
+}
+
+// unset retention means keep forever
+func ConvertAuditRetention(d api.OptInt) int {
+	return d.Or(0)
+}
+
+func RConvertAuditRetention(d int) api.OptInt {
+	return api.NewOptInt(d)
+}
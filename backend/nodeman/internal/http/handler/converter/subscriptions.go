@@ -1,6 +1,9 @@
 package converter
 
 import (
+	"bytes"
+	"fmt"
+
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
 	jx "github.com/go-faster/jx"
@@ -9,11 +12,49 @@ import (
 // goverter:converter
 // goverter:output:format function
 // goverter:output:file ./subscriptions_generated.go
+// goverter:extend ConvertSubFormat ConvertOptString ConvertSubDeviceID
 // goverter:enum:unknown @panic
 //
 //go:generate goverter gen .
 type Subscriptions interface {
+	// goverter:map Device DeviceID
 	ConvertUserSubRequest(r *api.UserSubParams) (*models.UserSubParams, error)
+}
+
+// unset format is detected by user agent
+func ConvertSubFormat(f api.OptSubFormat) models.SubFormat {
+	v, ok := f.Get()
+	if !ok {
+		return 0
+	}
+	switch v {
+	case api.SubFormatXray:
+		return models.SubFormatXray
+	case api.SubFormatLinks:
+		return models.SubFormatLinks
+	case api.SubFormatClash:
+		return models.SubFormatClash
+	case api.SubFormatSingBox:
+		return models.SubFormatSingBox
+	default:
+		panic(fmt.Sprintf("unexpected enum element: %v", v))
+	}
+}
+
+// unset device means user own credentials
+func ConvertSubDeviceID(d api.OptDeviceID) models.DeviceID {
+	return models.DeviceID(d.Or(0))
+}
 
-	ConvertUserSubResultBody(r []models.ClientConfigItem) ([]jx.Raw, error)
+// response content type depends on format
+func ConvertUserSubResult(r *models.UserSubResult) api.UserSubRes {
+	switch r.Format {
+	case models.SubFormatLinks:
+		return &api.UserSubResponseTextPlain{Data: bytes.NewReader(r.Content)}
+	case models.SubFormatClash:
+		return &api.UserSubResponseTextYaml{Data: bytes.NewReader(r.Content)}
+	default:
+		content := api.SubscriptionJSON(jx.Raw(r.Content))
+		return &content
+	}
 }
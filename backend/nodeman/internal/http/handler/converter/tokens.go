@@ -0,0 +1,29 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// goverter:converter
+// goverter:output:format function
+// goverter:output:file ./tokens_generated.go
+// goverter:extend ConvertTokenCreatedAt
+// goverter:enum:unknown @panic
+//
+//go:generate goverter gen .
+type Tokens interface {
+	ConvertNewApiTokenRequest(r *api.NewApiTokenRequest) (*models.NewApiTokenParams, error)
+
+	ConvertNewApiTokenResult(r *models.NewApiTokenResult) *api.NewApiTokenResponse
+
+	ConvertListApiTokensResult(r *models.ListApiTokensResult) *api.ListApiTokensResponse
+
+	ConvertRevokeApiTokenRequest(r *api.RevokeApiTokenRequest) (*models.RevokeApiTokenParams, error)
+}
+
+func ConvertTokenCreatedAt(t time.Time) time.Time {
+	return t
+}
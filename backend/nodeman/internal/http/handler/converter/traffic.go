@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// goverter:converter
+// goverter:output:format function
+// goverter:output:file ./traffic_generated.go
+// goverter:extend ConvertDay ConvertTrafficStats
+// goverter:enum:unknown @panic
+//
+//go:generate goverter gen .
+type Traffic interface {
+	ConvertUserTrafficHistoryResult(r *models.UserTrafficHistoryResult) *api.TrafficHistoryResponse
+
+	ConvertUserNodesTrafficHistoryResult(r *models.UserNodesTrafficHistoryResult) *api.UserNodesTrafficHistoryResponse
+
+	ConvertNodeTrafficHistoryResult(r *models.NodeTrafficHistoryResult) *api.TrafficHistoryResponse
+}
+
+func ConvertUserTrafficHistoryRequest(r api.GetUserTrafficHistoryParams) models.UserTrafficHistoryParams {
+	return models.UserTrafficHistoryParams{
+		ID:    models.UserID(r.ID),
+		Range: models.TrafficHistoryRange{From: r.From, To: r.To},
+	}
+}
+
+func ConvertUserNodesTrafficHistoryRequest(r api.GetUserNodesTrafficHistoryParams) models.UserNodesTrafficHistoryParams {
+	return models.UserNodesTrafficHistoryParams{
+		ID:    models.UserID(r.ID),
+		Range: models.TrafficHistoryRange{From: r.From, To: r.To},
+	}
+}
+
+func ConvertNodeTrafficHistoryRequest(r api.GetNodeTrafficHistoryParams) models.NodeTrafficHistoryParams {
+	return models.NodeTrafficHistoryParams{
+		ID:    models.NodeID(r.ID),
+		Range: models.TrafficHistoryRange{From: r.From, To: r.To},
+	}
+}
+
+func ConvertDay(t time.Time) time.Time {
+	return t
+}
+
+// explicit to avoid helper clash with users converter
+func ConvertTrafficStats(s models.TrafficStats) api.TrafficStats {
+	return api.TrafficStats{
+		Upload:   s.Upload,
+		Download: s.Download,
+	}
+}
@@ -1,6 +1,12 @@
 package converter
 
 import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/usercsv"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
 )
@@ -8,6 +14,10 @@ import (
 // goverter:converter
 // goverter:output:format function
 // goverter:output:file ./users_generated.go
+// goverter:extend ConvertExpiresAt RConvertExpiresAt ConvertViolationTime
+// goverter:extend ConvertGracePeriod RConvertGraceUntil
+// goverter:extend ConvertOptString ConvertImportStatus ConvertImportQuota ConvertImportIPLimit
+// goverter:extend ConvertImportEmail
 // goverter:enum:unknown @panic
 //
 //go:generate goverter gen .
@@ -26,10 +36,182 @@ type Users interface {
 
 	ConvertDeleteUserRequest(r *api.DeleteUserRequest) (*models.DeleteUserParams, error)
 
+	ConvertSetUserQuotaRequest(r *api.SetUserQuotaRequest) (*models.SetUserQuotaParams, error)
+
+	ConvertSetUserExpirationRequest(r *api.SetUserExpirationRequest) (*models.SetUserExpirationParams, error)
+
+	ConvertSetUserIPLimitRequest(r *api.SetUserIPLimitRequest) (*models.SetUserIPLimitParams, error)
+
+	ConvertRotateUserCredentialsRequest(r *api.RotateUserCredentialsRequest) (*models.RotateUserCredentialsParams, error)
+	ConvertRotateUserCredentialsResult(r *models.RotateUserCredentialsResult) *api.RotateUserCredentialsResponse
+
+	ConvertRotateUserSubTokenRequest(r *api.RotateUserSubTokenRequest) (*models.RotateUserSubTokenParams, error)
+	// goverter:map SubToken SubscriptionPath | GetSubscriptionPath
+	ConvertRotateUserSubTokenResult(r *models.RotateUserSubTokenResult) *api.RotateUserSubTokenResponse
+
+	ConvertNewUserDeviceRequest(r *api.NewUserDeviceRequest) (*models.NewUserDeviceParams, error)
+	ConvertNewUserDeviceResult(r *models.UserDevice) *api.UserDevice
+	ConvertListUserDevicesResult(r *models.ListUserDevicesResult) *api.ListUserDevicesResponse
+	ConvertSetUserDeviceLabelRequest(r *api.SetUserDeviceLabelRequest) (*models.SetUserDeviceLabelParams, error)
+	ConvertDeleteUserDeviceRequest(r *api.DeleteUserDeviceRequest) (*models.DeleteUserDeviceParams, error)
+
+	ConvertImportUsersRequest(r *api.ImportUsersRequest) (*models.ImportUsersParams, error)
+	ConvertImportUsersResult(r *models.ImportUsersResult) *api.ImportUsersResponse
+
+	ConvertExportUsersResult(r *models.ListUsersResult) *api.ExportUsersResponse
+	// goverter:map User.Profile.ID ID
+	// goverter:map User.Profile.Name Name
+	// goverter:map User.Profile.DisplayName DisplayName
+	// goverter:map User.Profile.VlessUUID VlessUUID
+	// goverter:map User.Profile.SubToken SubToken
+	// goverter:map User.TargetStatus Status
+	// goverter:map User.Quota Quota
+	// goverter:map User.ExpiresAt ExpiresAt
+	// goverter:map User.IPLimit IPLimit
+	// goverter:map User.Profile.Email Email | RConvertUserEmail
+	ConvertExportUser(r models.UserView) api.ExportUser
+
+	ConvertIPLimitViolationsResult(r *models.IPLimitViolationsResult) *api.IPLimitViolationsResponse
+
 	// goverter:map . SubscriptionPath | GetUserSubscription
 	ConvertProfile(r models.UserProfile) api.UserProfile
 }
 
+func ConvertIPLimitViolationsRequest(r api.GetIPLimitViolationsParams) models.IPLimitViolationsParams {
+	return models.IPLimitViolationsParams{
+		ID: models.UserID(r.ID),
+	}
+}
+
+func ConvertListUserDevicesRequest(r api.ListUserDevicesParams) models.ListUserDevicesParams {
+	return models.ListUserDevicesParams{
+		UserID: models.UserID(r.UserID),
+	}
+}
+
+func ConvertImportUsersCSVRequest(r *api.ImportUsersReqTextCsv) (*models.ImportUsersParams, error) {
+	users, err := usercsv.Read(r.Data)
+	if err != nil {
+		return nil, errdefs.PayloadErr(err)
+	}
+	return &models.ImportUsersParams{
+		Users: users,
+	}, nil
+}
+
+// unset status means enabled user
+func ConvertImportStatus(s api.OptUserStatus) models.UserStatus {
+	v, ok := s.Get()
+	if !ok {
+		return 0
+	}
+	switch v {
+	case api.UserStatusUnknown:
+		return models.UserStatusUnknown
+	case api.UserStatusEnabled:
+		return models.UserStatusEnabled
+	case api.UserStatusDisabled:
+		return models.UserStatusDisabled
+	default:
+		panic(fmt.Sprintf("unexpected enum element: %v", v))
+	}
+}
+
+// unset quota means unlimited one-off quota
+func ConvertImportQuota(q api.OptTrafficQuota) models.TrafficQuota {
+	v, ok := q.Get()
+	if !ok {
+		return models.TrafficQuota{}
+	}
+	quota := models.TrafficQuota{Limit: v.Limit}
+	switch v.Period {
+	case api.TrafficQuotaPeriodOneOff:
+		quota.Period = models.TrafficQuotaPeriodOneOff
+	case api.TrafficQuotaPeriodMonthly:
+		quota.Period = models.TrafficQuotaPeriodMonthly
+	default:
+		panic(fmt.Sprintf("unexpected enum element: %v", v.Period))
+	}
+	return quota
+}
+
+// unset email means generated one
+func ConvertImportEmail(e api.OptUserEmail) string {
+	return string(e.Or(""))
+}
+
+// generated emails are not exported, nodes derive them
+func RConvertUserEmail(s string) api.OptUserEmail {
+	if s == "" {
+		return api.OptUserEmail{}
+	}
+	return api.NewOptUserEmail(api.UserEmail(s))
+}
+
+// unset ip limit means unlimited
+func ConvertImportIPLimit(l api.OptIPLimit) int {
+	return int(l.Or(0))
+}
+
+// unset format means json
+func ConvertUsersExportFormat(f api.OptUsersExportFormat) models.UsersExportFormat {
+	v, ok := f.Get()
+	if !ok {
+		return models.UsersExportFormatJSON
+	}
+	switch v {
+	case api.UsersExportFormatJSON:
+		return models.UsersExportFormatJSON
+	case api.UsersExportFormatCsv:
+		return models.UsersExportFormatCSV
+	default:
+		panic(fmt.Sprintf("unexpected enum element: %v", v))
+	}
+}
+
+func ConvertExportUsersCSVResult(r *models.ListUsersResult) (*api.ExportUsersOKTextCsv, error) {
+	var buf bytes.Buffer
+	if err := usercsv.Write(&buf, r.Users); err != nil {
+		return nil, err
+	}
+	return &api.ExportUsersOKTextCsv{Data: &buf}, nil
+}
+
+func ConvertViolationTime(t time.Time) time.Time {
+	return t
+}
+
 func GetUserSubscription(source models.UserProfile) string {
 	return source.SubscriptionURL()
 }
+
+func GetSubscriptionPath(subToken string) string {
+	return models.SubscriptionURL(subToken)
+}
+
+// unset expiration means user never expires
+func ConvertExpiresAt(t api.OptExpiresAt) time.Time {
+	if v, ok := t.Get(); ok {
+		return time.Time(v)
+	}
+	return time.Time{}
+}
+
+func RConvertExpiresAt(t time.Time) api.OptExpiresAt {
+	if t.IsZero() {
+		return api.OptExpiresAt{}
+	}
+	return api.NewOptExpiresAt(api.ExpiresAt(t))
+}
+
+// unset grace period means previous credentials stop working at once
+func ConvertGracePeriod(seconds api.OptInt) time.Duration {
+	return time.Duration(seconds.Or(0)) * time.Second
+}
+
+func RConvertGraceUntil(t time.Time) api.OptDateTime {
+	if t.IsZero() {
+		return api.OptDateTime{}
+	}
+	return api.NewOptDateTime(t)
+}
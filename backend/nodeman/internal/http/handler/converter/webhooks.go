@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+// goverter:converter
+// goverter:output:format function
+// goverter:output:file ./webhooks_generated.go
+// goverter:extend ConvertWebhookCreatedAt
+// goverter:enum:unknown @panic
+//
+//go:generate goverter gen .
+type Webhooks interface {
+	ConvertNewWebhookRequest(r *api.NewWebhookRequest) (*models.NewWebhookParams, error)
+
+	// goverter:map Webhook.Secret Secret
+	ConvertNewWebhookResult(r *models.NewWebhookResult) *api.NewWebhookResponse
+
+	ConvertListWebhooksResult(r *models.ListWebhooksResult) *api.ListWebhooksResponse
+
+	ConvertDeleteWebhookRequest(r *api.DeleteWebhookRequest) (*models.DeleteWebhookParams, error)
+}
+
+func ConvertWebhookCreatedAt(t time.Time) time.Time {
+	return t
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+func (h *Handler) NewNodeGroup(ctx context.Context, req *api.NewNodeGroupRequest) (
+	*api.NewNodeGroupResponse, error,
+) {
+	if h == nil || h.nodes == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertNewNodeGroupRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.nodes.NewNodeGroup(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertNewNodeGroupResult(res), nil
+}
+
+func (h *Handler) DeleteNodeGroup(ctx context.Context, req *api.DeleteNodeGroupRequest) error {
+	if h == nil || h.nodes == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertDeleteNodeGroupRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.nodes.DeleteNodeGroup(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) ListNodeGroups(ctx context.Context) (*api.ListNodeGroupsResponse, error) {
+	if h == nil || h.nodes == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.nodes.ListNodeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListNodeGroupsResult(res), nil
+}
+
+func (h *Handler) SetNodeGroups(ctx context.Context, req *api.SetNodeGroupsRequest) error {
+	if h == nil || h.nodes == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetNodeGroupsRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.nodes.SetNodeGroups(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) SetUserGroups(ctx context.Context, req *api.SetUserGroupsRequest) error {
+	if h == nil || h.users == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetUserGroupsRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.users.SetUserGroups(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
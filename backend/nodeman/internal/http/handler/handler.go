@@ -20,6 +20,9 @@ type Handler struct {
 	nodes    NodesService
 	subscr   SubscrService
 	auth     AuthService
+	tokens   TokensService
+	webhooks WebhooksService
+	audit    AuditService
 	settings SettingsService
 	version  VersionService
 	log      *zap.Logger
@@ -33,6 +36,9 @@ func New(
 	subscr SubscrService,
 	settings SettingsService,
 	auth AuthService,
+	tokens TokensService,
+	webhooks WebhooksService,
+	audit AuditService,
 	version VersionService,
 	logger *zap.Logger,
 ) (*Handler, error) {
@@ -51,6 +57,15 @@ func New(
 	if auth == nil {
 		return nil, errdefs.NilArg("auth")
 	}
+	if tokens == nil {
+		return nil, errdefs.NilArg("tokens")
+	}
+	if webhooks == nil {
+		return nil, errdefs.NilArg("webhooks")
+	}
+	if audit == nil {
+		return nil, errdefs.NilArg("audit")
+	}
 	if version == nil {
 		return nil, errdefs.NilArg("version")
 	}
@@ -63,6 +78,9 @@ func New(
 		subscr:   subscr,
 		settings: settings,
 		auth:     auth,
+		tokens:   tokens,
+		webhooks: webhooks,
+		audit:    audit,
 		version:  version,
 		log:      logger,
 	}, nil
@@ -89,6 +107,12 @@ func (h *Handler) translateError(err error) *api.ErrorStatusCode {
 	if errors.Is(err, ogenerrors.ErrSecurityRequirementIsNotSatisfied) {
 		return httperrdefs.ErrAuthToken
 	}
+	if errors.Is(err, errdefs.ErrOTPRequired) {
+		return httperrdefs.ErrOTPRequired
+	}
+	if errors.Is(err, errdefs.ErrLockedOut) {
+		return httperrdefs.ErrLockedOut
+	}
 	if errors.Is(err, errdefs.ErrAccessDenied) {
 		return httperrdefs.ErrAccessDenied
 	}
@@ -53,6 +53,34 @@ func (h *Handler) StopNode(ctx context.Context, req *api.StopNodeRequest) error
 	return nil
 }
 
+func (h *Handler) ReloadNode(ctx context.Context, req *api.ReloadNodeRequest) error {
+	if h == nil || h.nodes == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertReloadNodeRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.nodes.ReloadNode(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) PushNodeConfig(ctx context.Context, req *api.PushNodeConfigRequest) error {
+	if h == nil || h.nodes == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertPushNodeConfigRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.nodes.PushNodeConfig(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (h *Handler) ListNodes(ctx context.Context) (*api.ListNodeResponse, error) {
 	if h == nil || h.nodes == nil {
 		return nil, errdefs.NilCall()
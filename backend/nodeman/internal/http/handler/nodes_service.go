@@ -11,6 +11,13 @@ type NodesService interface {
 	NewNode(ctx context.Context, p models.NewNodeParams) (*models.NewNodeResult, error)
 	StartNode(ctx context.Context, p models.StartNodeParams) error
 	StopNode(ctx context.Context, p models.StopNodeParams) error
+	ReloadNode(ctx context.Context, p models.ReloadNodeParams) error
+	PushNodeConfig(ctx context.Context, p models.PushNodeConfigParams) error
+	NewNodeGroup(ctx context.Context, p models.NewNodeGroupParams) (*models.NewNodeGroupResult, error)
+	ListNodeGroups(ctx context.Context) (*models.ListNodeGroupsResult, error)
+	DeleteNodeGroup(ctx context.Context, p models.DeleteNodeGroupParams) error
+	SetNodeGroups(ctx context.Context, p models.SetNodeGroupsParams) error
+	GetNodeTrafficHistory(ctx context.Context, p models.NodeTrafficHistoryParams) (*models.NodeTrafficHistoryResult, error)
 	ListNodes(ctx context.Context) (*models.ListNodeResult, error)
 	DeleteNode(ctx context.Context, p models.DeleteNodeParams) error
 }
@@ -6,11 +6,10 @@ import (
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
-	"github.com/go-faster/jx"
 )
 
 func (h *Handler) UserSub(ctx context.Context, req api.UserSubParams) (
-	[]jx.Raw, error,
+	api.UserSubRes, error,
 ) {
 	if h == nil || h.subscr == nil {
 		return nil, errdefs.NilCall()
@@ -23,15 +22,11 @@ func (h *Handler) UserSub(ctx context.Context, req api.UserSubParams) (
 	if err != nil {
 		return nil, err
 	}
-	subResponse, err := converter.ConvertUserSubResultBody(sub.ClientConfigs)
-	if err != nil {
-		return nil, err
-	}
 
 	// write to context header with key = "k" and value "v"
 	if err := h.writeHeaders(ctx, sub.Headers); err != nil {
 		return nil, err
 	}
 
-	return subResponse, nil
+	return converter.ConvertUserSubResult(sub), nil
 }
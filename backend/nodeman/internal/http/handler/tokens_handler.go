@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+func (h *Handler) NewApiToken(ctx context.Context, req *api.NewApiTokenRequest) (
+	*api.NewApiTokenResponse, error,
+) {
+	if h == nil || h.tokens == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertNewApiTokenRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.tokens.NewApiToken(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertNewApiTokenResult(res), nil
+}
+
+func (h *Handler) ListApiTokens(ctx context.Context) (*api.ListApiTokensResponse, error) {
+	if h == nil || h.tokens == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.tokens.ListApiTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListApiTokensResult(res), nil
+}
+
+func (h *Handler) RevokeApiToken(ctx context.Context, req *api.RevokeApiTokenRequest) error {
+	if h == nil || h.tokens == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertRevokeApiTokenRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.tokens.RevokeApiToken(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+//go:generate mockgen -source=tokens_service.go -destination=./mocks/mock_tokens_service.go -package=mocks
+type TokensService interface {
+	NewApiToken(ctx context.Context, p models.NewApiTokenParams) (*models.NewApiTokenResult, error)
+	ListApiTokens(ctx context.Context) (*models.ListApiTokensResult, error)
+	RevokeApiToken(ctx context.Context, p models.RevokeApiTokenParams) error
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+func (h *Handler) GetUserTrafficHistory(ctx context.Context,
+	req api.GetUserTrafficHistoryParams,
+) (*api.TrafficHistoryResponse, error) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertUserTrafficHistoryRequest(req)
+	res, err := h.users.GetUserTrafficHistory(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertUserTrafficHistoryResult(res), nil
+}
+
+func (h *Handler) GetUserNodesTrafficHistory(ctx context.Context,
+	req api.GetUserNodesTrafficHistoryParams,
+) (*api.UserNodesTrafficHistoryResponse, error) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertUserNodesTrafficHistoryRequest(req)
+	res, err := h.users.GetUserNodesTrafficHistory(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertUserNodesTrafficHistoryResult(res), nil
+}
+
+func (h *Handler) GetNodeTrafficHistory(ctx context.Context,
+	req api.GetNodeTrafficHistoryParams,
+) (*api.TrafficHistoryResponse, error) {
+	if h == nil || h.nodes == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertNodeTrafficHistoryRequest(req)
+	res, err := h.nodes.GetNodeTrafficHistory(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertNodeTrafficHistoryResult(res), nil
+}
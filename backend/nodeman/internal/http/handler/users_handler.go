@@ -2,9 +2,11 @@ package handler
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
 )
 
@@ -50,6 +52,52 @@ func (h *Handler) ListUsers(ctx context.Context) (*api.ListUsersResponse, error)
 	return converter.ConvertListUsersResult(res), nil
 }
 
+func (h *Handler) ImportUsers(ctx context.Context, req api.ImportUsersReq) (
+	*api.ImportUsersResponse, error,
+) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	// import content depends on content type
+	var p *models.ImportUsersParams
+	var err error
+	switch req := req.(type) {
+	case *api.ImportUsersRequest:
+		p, err = converter.ConvertImportUsersRequest(req)
+	case *api.ImportUsersReqTextCsv:
+		p, err = converter.ConvertImportUsersCSVRequest(req)
+	default:
+		panic(fmt.Sprintf("unexpected import request: %T", req))
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.users.ImportUsers(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertImportUsersResult(res), nil
+}
+
+func (h *Handler) ExportUsers(ctx context.Context, req api.ExportUsersParams) (
+	api.ExportUsersRes, error,
+) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// export content type depends on format
+	switch converter.ConvertUsersExportFormat(req.Format) {
+	case models.UsersExportFormatCSV:
+		return converter.ConvertExportUsersCSVResult(res)
+	default:
+		return converter.ConvertExportUsersResult(res), nil
+	}
+}
+
 func (h *Handler) EnableUser(ctx context.Context, req *api.EnableUserRequest) error {
 	if h == nil || h.users == nil {
 		return errdefs.NilCall()
@@ -91,3 +139,152 @@ func (h *Handler) DeleteUser(ctx context.Context, req *api.DeleteUserRequest) er
 	}
 	return nil
 }
+
+func (h *Handler) SetUserQuota(ctx context.Context, req *api.SetUserQuotaRequest) error {
+	if h == nil || h.users == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetUserQuotaRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.users.SetUserQuota(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) SetUserIPLimit(ctx context.Context, req *api.SetUserIPLimitRequest) error {
+	if h == nil || h.users == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetUserIPLimitRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.users.SetUserIPLimit(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) RotateUserCredentials(ctx context.Context, req *api.RotateUserCredentialsRequest) (
+	*api.RotateUserCredentialsResponse, error,
+) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertRotateUserCredentialsRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.users.RotateUserCredentials(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertRotateUserCredentialsResult(res), nil
+}
+
+func (h *Handler) RotateUserSubToken(ctx context.Context, req *api.RotateUserSubTokenRequest) (
+	*api.RotateUserSubTokenResponse, error,
+) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertRotateUserSubTokenRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.users.RotateUserSubToken(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertRotateUserSubTokenResult(res), nil
+}
+
+func (h *Handler) NewUserDevice(ctx context.Context, req *api.NewUserDeviceRequest) (
+	*api.UserDevice, error,
+) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertNewUserDeviceRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.users.NewUserDevice(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertNewUserDeviceResult(res), nil
+}
+
+func (h *Handler) ListUserDevices(ctx context.Context,
+	req api.ListUserDevicesParams,
+) (*api.ListUserDevicesResponse, error) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertListUserDevicesRequest(req)
+	res, err := h.users.ListUserDevices(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListUserDevicesResult(res), nil
+}
+
+func (h *Handler) SetUserDeviceLabel(ctx context.Context, req *api.SetUserDeviceLabelRequest) error {
+	if h == nil || h.users == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetUserDeviceLabelRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.users.SetUserDeviceLabel(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) DeleteUserDevice(ctx context.Context, req *api.DeleteUserDeviceRequest) error {
+	if h == nil || h.users == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertDeleteUserDeviceRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.users.DeleteUserDevice(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) GetIPLimitViolations(ctx context.Context,
+	req api.GetIPLimitViolationsParams,
+) (*api.IPLimitViolationsResponse, error) {
+	if h == nil || h.users == nil {
+		return nil, errdefs.NilCall()
+	}
+	p := converter.ConvertIPLimitViolationsRequest(req)
+	res, err := h.users.GetIPLimitViolations(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertIPLimitViolationsResult(res), nil
+}
+
+func (h *Handler) SetUserExpiration(ctx context.Context, req *api.SetUserExpirationRequest) error {
+	if h == nil || h.users == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertSetUserExpirationRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.users.SetUserExpiration(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
@@ -11,7 +11,21 @@ type UsersService interface {
 	NewUser(ctx context.Context, p models.NewUserParams) (*models.User, error)
 	GetUserView(ctx context.Context, p models.GetUserParams) (*models.UserView, error)
 	ListUsers(ctx context.Context) (*models.ListUsersResult, error)
+	ImportUsers(ctx context.Context, p models.ImportUsersParams) (*models.ImportUsersResult, error)
 	DisableUser(ctx context.Context, p models.DisableUserParams) error
 	EnableUser(ctx context.Context, p models.EnableUserParams) error
+	SetUserQuota(ctx context.Context, p models.SetUserQuotaParams) error
+	SetUserIPLimit(ctx context.Context, p models.SetUserIPLimitParams) error
+	RotateUserCredentials(ctx context.Context, p models.RotateUserCredentialsParams) (*models.RotateUserCredentialsResult, error)
+	RotateUserSubToken(ctx context.Context, p models.RotateUserSubTokenParams) (*models.RotateUserSubTokenResult, error)
+	NewUserDevice(ctx context.Context, p models.NewUserDeviceParams) (*models.UserDevice, error)
+	ListUserDevices(ctx context.Context, p models.ListUserDevicesParams) (*models.ListUserDevicesResult, error)
+	SetUserDeviceLabel(ctx context.Context, p models.SetUserDeviceLabelParams) error
+	DeleteUserDevice(ctx context.Context, p models.DeleteUserDeviceParams) error
+	GetIPLimitViolations(ctx context.Context, p models.IPLimitViolationsParams) (*models.IPLimitViolationsResult, error)
+	SetUserExpiration(ctx context.Context, p models.SetUserExpirationParams) error
+	SetUserGroups(ctx context.Context, p models.SetUserGroupsParams) error
+	GetUserTrafficHistory(ctx context.Context, p models.UserTrafficHistoryParams) (*models.UserTrafficHistoryResult, error)
+	GetUserNodesTrafficHistory(ctx context.Context, p models.UserNodesTrafficHistoryParams) (*models.UserNodesTrafficHistoryResult, error)
 	DeleteUser(ctx context.Context, p models.DeleteUserParams) error
 }
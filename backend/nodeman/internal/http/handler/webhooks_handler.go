@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler/converter"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+)
+
+func (h *Handler) NewWebhook(ctx context.Context, req *api.NewWebhookRequest) (
+	*api.NewWebhookResponse, error,
+) {
+	if h == nil || h.webhooks == nil {
+		return nil, errdefs.NilCall()
+	}
+	p, err := converter.ConvertNewWebhookRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.webhooks.NewWebhook(ctx, *p)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertNewWebhookResult(res), nil
+}
+
+func (h *Handler) ListWebhooks(ctx context.Context) (*api.ListWebhooksResponse, error) {
+	if h == nil || h.webhooks == nil {
+		return nil, errdefs.NilCall()
+	}
+	res, err := h.webhooks.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertListWebhooksResult(res), nil
+}
+
+func (h *Handler) DeleteWebhook(ctx context.Context, req *api.DeleteWebhookRequest) error {
+	if h == nil || h.webhooks == nil {
+		return errdefs.NilCall()
+	}
+	p, err := converter.ConvertDeleteWebhookRequest(req)
+	if err != nil {
+		return err
+	}
+	if err = h.webhooks.DeleteWebhook(ctx, *p); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+//go:generate mockgen -source=webhooks_service.go -destination=./mocks/mock_webhooks_service.go -package=mocks
+type WebhooksService interface {
+	NewWebhook(ctx context.Context, p models.NewWebhookParams) (*models.NewWebhookResult, error)
+	ListWebhooks(ctx context.Context) (*models.ListWebhooksResult, error)
+	DeleteWebhook(ctx context.Context, p models.DeleteWebhookParams) error
+}
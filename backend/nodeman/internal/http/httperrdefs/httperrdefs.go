@@ -18,6 +18,12 @@ var (
 		"unknown error", "we really don't know")
 	ErrNotFound = new(http.StatusNotFound,
 		"somebody not found", "try another")
+	ErrOTPRequired = new(http.StatusUnauthorized,
+		"second factor required", "send totp or recovery code")
+	ErrLockedOut = new(http.StatusTooManyRequests,
+		"too many failed attempts", "try later")
+	ErrTooManyRequests = new(http.StatusTooManyRequests,
+		"too many requests", "try later")
 	ErrAccessDenied = new(http.StatusForbidden,
 		"Access denied", "denied deined")
 	ErrTemporaryUnavailable = new(http.StatusServiceUnavailable,
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type adminCtxKeyType struct{}
+
+var adminCtxKey = adminCtxKeyType{}
+
+func withAdmin(ctx context.Context, admin *models.Admin) context.Context {
+	return context.WithValue(ctx, adminCtxKey, admin)
+}
+
+// get admin authenticated by security handler
+func AdminFromContext(ctx context.Context) (*models.Admin, bool) {
+	admin, ok := ctx.Value(adminCtxKey).(*models.Admin)
+	return admin, ok
+}
+
+type tokenCtxKeyType struct{}
+
+var tokenCtxKey = tokenCtxKeyType{}
+
+func withApiToken(ctx context.Context, token *models.ApiToken) context.Context {
+	return context.WithValue(ctx, tokenCtxKey, token)
+}
+
+// get api token authenticated by security handler
+func ApiTokenFromContext(ctx context.Context) (*models.ApiToken, bool) {
+	token, ok := ctx.Value(tokenCtxKey).(*models.ApiToken)
+	return token, ok
+}
+
+type sessionCtxKeyType struct{}
+
+var sessionCtxKey = sessionCtxKeyType{}
+
+func withSession(ctx context.Context, session *models.Session) context.Context {
+	return context.WithValue(ctx, sessionCtxKey, session)
+}
+
+// get admin session authenticated by security handler
+func SessionFromContext(ctx context.Context) (*models.Session, bool) {
+	session, ok := ctx.Value(sessionCtxKey).(*models.Session)
+	return session, ok
+}
@@ -2,35 +2,189 @@ package security
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strconv"
 
+	mw "github.com/XRay-Addons/xrayman/common/http/middleware"
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/httperrdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/apitoken"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
-	jwt JWT
+	jwt     JWT
+	storage Storage
+	log     *zap.Logger
 }
 
 var _ api.SecurityHandler = (*Handler)(nil)
 
-func New(jwt JWT) (*Handler, error) {
+func New(jwt JWT, storage Storage, log *zap.Logger) (*Handler, error) {
 	if jwt == nil {
 		return nil, errdefs.NilArg("jwt")
 	}
-	return &Handler{jwt: jwt}, nil
+	if storage == nil {
+		return nil, errdefs.NilArg("storage")
+	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
+	return &Handler{jwt: jwt, storage: storage, log: log}, nil
 }
 
+// validate token and check admin role or api token scope is allowed
+// for operation. allowed roles and scopes are listed in api spec
+// security requirements, operation without roles is allowed to owner only.
+// denied calls are written to audit log
 func (h *Handler) HandleBearerAuth(ctx context.Context,
 	operationName api.OperationName, t api.BearerAuth,
 ) (context.Context, error) {
-	if h == nil || h.jwt == nil {
+	if h == nil || h.jwt == nil || h.storage == nil || h.log == nil {
 		return ctx, errdefs.NilCall()
 	}
-	if err := h.jwt.ValidateToken(t.GetToken()); err != nil {
+	authCtx, err := h.handleBearerAuth(ctx, operationName, t)
+	if errors.Is(err, httperrdefs.ErrAuthToken) ||
+		errors.Is(err, errdefs.ErrAccessDenied) {
+		h.recordDenied(ctx, operationName, err)
+	}
+	return authCtx, err
+}
+
+func (h *Handler) handleBearerAuth(ctx context.Context,
+	operationName api.OperationName, t api.BearerAuth,
+) (context.Context, error) {
+	if apitoken.IsApiToken(t.GetToken()) {
+		return h.handleApiToken(ctx, operationName, t)
+	}
+
+	subject, sessionID, err := h.jwt.ValidateToken(t.GetToken())
+	if err != nil {
+		err = xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
+		return ctx, err
+	}
+	id, err := strconv.Atoi(subject)
+	if err != nil {
+		err = xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
+		return ctx, err
+	}
+
+	// token is valid only while its session isn't revoked
+	session, err := h.getSession(ctx, models.AdminID(id), sessionID)
+	if err != nil {
+		return ctx, err
+	}
+
+	// admin could be deleted or changed after token issued
+	admin, err := h.storage.GetAdmin(ctx, models.AdminID(id))
+	if errors.Is(err, errdefs.ErrNotFound) {
+		err = xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
+		return ctx, err
+	}
+	if err != nil {
+		return ctx, err
+	}
+
+	if !roleAllowed(admin.Role, t.GetRoles()) {
+		return ctx, xerr.Wrap(errdefs.ErrAccessDenied,
+			xerr.WithStack(),
+			xerr.WithDetails(models.AuditActor{
+				Kind: models.AuditActorAdmin,
+				ID:   admin.ID,
+				Name: admin.Name,
+			}),
+			xerr.WithInfof("operation %s, role %s", operationName, admin.Role))
+	}
+
+	return withSession(withAdmin(ctx, admin), session), nil
+}
+
+func (h *Handler) getSession(ctx context.Context,
+	adminID models.AdminID, sessionID string,
+) (*models.Session, error) {
+	id, err := strconv.Atoi(sessionID)
+	if err != nil {
+		return nil, xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
+	}
+	session, err := h.storage.GetSession(ctx, models.SessionID(id))
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return nil, xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.AdminID != adminID {
+		err = xerr.Newf("session %d belongs to admin %d", session.ID, session.AdminID)
+		return nil, xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
+	}
+	return session, nil
+}
+
+func (h *Handler) handleApiToken(ctx context.Context,
+	operationName api.OperationName, t api.BearerAuth,
+) (context.Context, error) {
+	// revoked token isn't found
+	token, err := h.storage.GetApiToken(ctx, apitoken.Hash(t.GetToken()))
+	if errors.Is(err, errdefs.ErrNotFound) {
 		err = xerr.WrapWithType(err, httperrdefs.ErrAuthToken)
 		return ctx, err
 	}
-	return ctx, nil
+	if err != nil {
+		return ctx, err
+	}
+
+	if !scopeAllowed(token.Scopes, t.GetRoles()) {
+		return ctx, xerr.Wrap(errdefs.ErrAccessDenied,
+			xerr.WithStack(),
+			xerr.WithDetails(models.AuditActor{
+				Kind: models.AuditActorApiToken,
+				ID:   token.ID,
+				Name: token.Name,
+			}),
+			xerr.WithInfof("operation %s, api token %d", operationName, token.ID))
+	}
+
+	return withApiToken(ctx, token), nil
+}
+
+// audit denied call, actor is known only if token itself is valid
+func (h *Handler) recordDenied(ctx context.Context,
+	operationName api.OperationName, err error,
+) {
+	actor := models.AuditActor{Kind: models.AuditActorAnonymous}
+	if a := xerr.ExtractDetails[models.AuditActor](err); a != nil {
+		actor = *a
+	}
+	event := models.AuditEvent{
+		Actor:     actor,
+		Operation: operationName,
+		ClientIP:  mw.ClientIP(ctx),
+		RequestID: chimw.GetReqID(ctx),
+		Error:     err.Error(),
+	}
+	ctx = context.WithoutCancel(ctx)
+	if rerr := h.storage.RecordAuditEvent(ctx, &event); rerr != nil {
+		h.log.Error("record denied call audit event",
+			zap.String("operation", operationName),
+			zap.String(mw.RequestIDLogTag, event.RequestID),
+			zap.Error(rerr))
+	}
+}
+
+func roleAllowed(role models.AdminRole, allowed []string) bool {
+	if role == models.AdminRoleOwner {
+		return true
+	}
+	return slices.Contains(allowed, role.String())
+}
+
+func scopeAllowed(scopes []models.ApiTokenScope, allowed []string) bool {
+	return slices.ContainsFunc(scopes, func(s models.ApiTokenScope) bool {
+		return slices.Contains(allowed, s.String())
+	})
 }
@@ -0,0 +1,211 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/apitoken"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	api "github.com/XRay-Addons/xrayman/nodeman/pkg/api/http/openapi-gen"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testJWT struct{}
+
+// token is "subject/session", "bad" is invalid token
+func (testJWT) ValidateToken(token string) (string, string, error) {
+	if token == "bad" {
+		return "", "", xerr.New("invalid token")
+	}
+	subject, session, _ := strings.Cut(token, "/")
+	return subject, session, nil
+}
+
+type testStorage struct {
+	admins   map[models.AdminID]models.Admin
+	tokens   map[string]models.ApiToken
+	sessions map[models.SessionID]models.Session
+	events   *[]models.AuditEvent
+}
+
+func (s testStorage) GetAdmin(_ context.Context, id models.AdminID) (*models.Admin, error) {
+	admin, ok := s.admins[id]
+	if !ok {
+		return nil, xerr.WrapWithType(xerr.New("no admin"), errdefs.ErrNotFound)
+	}
+	return &admin, nil
+}
+
+func (s testStorage) GetApiToken(_ context.Context, hash []byte) (*models.ApiToken, error) {
+	token, ok := s.tokens[string(hash)]
+	if !ok {
+		return nil, xerr.WrapWithType(xerr.New("no token"), errdefs.ErrNotFound)
+	}
+	return &token, nil
+}
+
+func (s testStorage) GetSession(_ context.Context, id models.SessionID) (*models.Session, error) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, xerr.WrapWithType(xerr.New("no session"), errdefs.ErrNotFound)
+	}
+	return &session, nil
+}
+
+func (s testStorage) RecordAuditEvent(_ context.Context, e *models.AuditEvent) error {
+	if s.events != nil {
+		*s.events = append(*s.events, *e)
+	}
+	return nil
+}
+
+func TestHandleBearerAuth(t *testing.T) {
+	var events []models.AuditEvent
+	storage := testStorage{
+		events: &events,
+		admins: map[models.AdminID]models.Admin{
+			0: {ID: 0, Name: "admin", Role: models.AdminRoleOwner},
+			1: {ID: 1, Name: "operator", Role: models.AdminRoleOperator},
+			2: {ID: 2, Name: "support", Role: models.AdminRoleSupport},
+		},
+		sessions: map[models.SessionID]models.Session{
+			10: {ID: 10, AdminID: 0},
+			11: {ID: 11, AdminID: 1},
+			12: {ID: 12, AdminID: 2},
+			13: {ID: 13, AdminID: 3},
+		},
+	}
+	h, err := New(testJWT{}, storage, zap.NewNop())
+	require.NoError(t, err)
+
+	view := []string{"operator", "support"}
+	manage := []string{"operator"}
+	owner := []string{}
+
+	tests := []struct {
+		name    string
+		token   string
+		roles   []string
+		allowed bool
+	}{
+		{"owner view", "0/10", view, true},
+		{"owner only", "0/10", owner, true},
+		{"operator manage", "1/11", manage, true},
+		{"operator owner only", "1/11", owner, false},
+		{"support view", "2/12", view, true},
+		{"support manage", "2/12", manage, false},
+		{"support owner only", "2/12", owner, false},
+		{"deleted admin", "3/13", view, false},
+		{"invalid subject", "admin/10", view, false},
+		{"invalid token", "bad", view, false},
+		{"no session", "1", view, false},
+		{"revoked session", "1/99", view, false},
+		{"other admin session", "2/10", view, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := h.HandleBearerAuth(context.Background(), "op",
+				api.BearerAuth{Token: tt.token, Roles: tt.roles})
+			if !tt.allowed {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			admin, ok := AdminFromContext(ctx)
+			require.True(t, ok)
+			session, ok := SessionFromContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, tt.token,
+				strconv.Itoa(admin.ID)+"/"+strconv.Itoa(session.ID))
+		})
+	}
+
+	// role check failure is access denied, not token error
+	events = nil
+	_, err = h.HandleBearerAuth(context.Background(), "op",
+		api.BearerAuth{Token: "2/12", Roles: owner})
+	require.True(t, errors.Is(err, errdefs.ErrAccessDenied))
+
+	// denied call is audited with known admin
+	require.Len(t, events, 1)
+	require.Equal(t, "op", events[0].Operation)
+	require.False(t, events[0].Success)
+	require.Equal(t, models.AuditActor{
+		Kind: models.AuditActorAdmin, ID: 2, Name: "support",
+	}, events[0].Actor)
+
+	// invalid token is audited with anonymous actor
+	events = nil
+	_, err = h.HandleBearerAuth(context.Background(), "op",
+		api.BearerAuth{Token: "bad", Roles: view})
+	require.Error(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, models.AuditActorAnonymous, events[0].Actor.Kind)
+
+	// allowed call isn't audited here
+	events = nil
+	_, err = h.HandleBearerAuth(context.Background(), "op",
+		api.BearerAuth{Token: "1/11", Roles: view})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestHandleBearerAuthApiToken(t *testing.T) {
+	secret, hash, err := apitoken.New()
+	require.NoError(t, err)
+	revoked, _, err := apitoken.New()
+	require.NoError(t, err)
+
+	storage := testStorage{tokens: map[string]models.ApiToken{
+		string(hash): {ID: 1, Name: "billing", Scopes: []models.ApiTokenScope{
+			models.ApiTokenScopeUsersRead,
+			models.ApiTokenScopeStatsRead,
+		}},
+	}}
+	h, err := New(testJWT{}, storage, zap.NewNop())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		roles   []string
+		allowed bool
+	}{
+		{"scope allowed", secret, []string{"operator", "support", "users:read"}, true},
+		{"other scope allowed", secret, []string{"operator", "support", "stats:read"}, true},
+		{"scope missing", secret, []string{"operator", "users:write"}, false},
+		{"owner only", secret, []string{}, false},
+		{"admin roles only", secret, []string{"operator", "support"}, false},
+		{"revoked token", revoked, []string{"users:read"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := h.HandleBearerAuth(context.Background(), "op",
+				api.BearerAuth{Token: tt.token, Roles: tt.roles})
+			if !tt.allowed {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			token, ok := ApiTokenFromContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, 1, token.ID)
+			_, ok = AdminFromContext(ctx)
+			require.False(t, ok)
+		})
+	}
+
+	// scope check failure is access denied, unknown token is token error
+	_, err = h.HandleBearerAuth(context.Background(), "op",
+		api.BearerAuth{Token: secret, Roles: []string{"nodes:write"}})
+	require.True(t, errors.Is(err, errdefs.ErrAccessDenied))
+	_, err = h.HandleBearerAuth(context.Background(), "op",
+		api.BearerAuth{Token: revoked, Roles: []string{"users:read"}})
+	require.False(t, errors.Is(err, errdefs.ErrAccessDenied))
+}
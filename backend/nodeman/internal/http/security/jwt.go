@@ -1,5 +1,6 @@
 package security
 
 type JWT interface {
-	ValidateToken(tokenString string) error
+	// validate token, return token subject and session id
+	ValidateToken(tokenString string) (string, string, error)
 }
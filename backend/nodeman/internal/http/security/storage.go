@@ -0,0 +1,18 @@
+package security
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	// get admin by id, return ErrNotFound if not exists
+	GetAdmin(ctx context.Context, id models.AdminID) (*models.Admin, error)
+	// get active api token by hash, return ErrNotFound if not exists or revoked
+	GetApiToken(ctx context.Context, tokenHash []byte) (*models.ApiToken, error)
+	// get active session by id, return ErrNotFound if not exists, revoked or expired
+	GetSession(ctx context.Context, id models.SessionID) (*models.Session, error)
+	// append audit event
+	RecordAuditEvent(ctx context.Context, e *models.AuditEvent) error
+}
@@ -0,0 +1,36 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
+// prefix distinguishes api tokens from admin jwt
+const prefix = "xrm_"
+
+const secretSize = 32
+
+// generate new token secret and its hash to store
+func New() (string, []byte, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, xerr.WrapWithStack(err)
+	}
+	secret := prefix + base64.RawURLEncoding.EncodeToString(b)
+	return secret, Hash(secret), nil
+}
+
+// secret is random, so plain sha256 is enough
+// and allows to find token by hash
+func Hash(secret string) []byte {
+	h := sha256.Sum256([]byte(secret))
+	return h[:]
+}
+
+func IsApiToken(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
@@ -0,0 +1,21 @@
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiToken(t *testing.T) {
+	secret, hash, err := New()
+	require.NoError(t, err)
+	require.True(t, IsApiToken(secret))
+	require.Equal(t, hash, Hash(secret))
+
+	secret2, hash2, err := New()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, secret2)
+	require.NotEqual(t, hash, hash2)
+
+	require.False(t, IsApiToken("eyJhbGciOiJIUzI1NiJ9.e30.sig"))
+}
@@ -18,7 +18,7 @@ type JWT struct {
 var _ (auth.JWT) = (*JWT)(nil)
 var _ (security.JWT) = (*JWT)(nil)
 
-const defaultTTL = 72 * time.Hour
+const defaultTTL = 15 * time.Minute
 const defaultIssuer = "issuer"
 
 const bearerTokenType = "Bearer"
@@ -60,10 +60,14 @@ func New(secret string, opts ...option) (*JWT, error) {
 	}, nil
 }
 
-func (j *JWT) GenerateToken(subject string) (*models.AuthResult, error) {
+// generate access token for session, it's valid
+// only until session is revoked
+func (j *JWT) GenerateToken(subject string, sessionID string) (*models.AuthResult, error) {
 	token, err := jwtools.GenerateToken(j.secret,
+		jwtools.WithTTL(j.config.ttl),
 		jwtools.WithIssuer(j.config.issuer),
-		jwtools.WithSubject(subject))
+		jwtools.WithSubject(subject),
+		jwtools.WithID(sessionID))
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +78,15 @@ func (j *JWT) GenerateToken(subject string) (*models.AuthResult, error) {
 	}, nil
 }
 
-func (j *JWT) ValidateToken(tokenString string) error {
-	return jwtools.ValidateToken(tokenString, j.secret,
-		jwtools.WithIssuerCheck(&j.config.issuer))
+// validate token, return token subject and session id
+func (j *JWT) ValidateToken(tokenString string) (string, string, error) {
+	var subject, sessionID string
+	if err := jwtools.ValidateToken(tokenString, j.secret,
+		jwtools.WithIssuerCheck(&j.config.issuer),
+		jwtools.WithSubjectOut(&subject),
+		jwtools.WithIDOut(&sessionID),
+	); err != nil {
+		return "", "", err
+	}
+	return subject, sessionID, nil
 }
@@ -0,0 +1,129 @@
+package lockout
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/service/auth"
+)
+
+// Lockout counts failed logins in memory and locks key after threshold,
+// lock duration is doubled for every next failure up to max duration
+type Lockout struct {
+	mu          sync.Mutex
+	threshold   int
+	duration    time.Duration
+	maxDuration time.Duration
+	entries     map[models.AuthLockoutKey]*entry
+	now         func() time.Time
+}
+
+type entry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+var _ auth.Lockout = (*Lockout)(nil)
+
+// zero threshold disables lockout
+func New(threshold int, duration, maxDuration time.Duration) (*Lockout, error) {
+	if threshold < 0 {
+		return nil, errdefs.NilArg("threshold")
+	}
+	if threshold > 0 && (duration <= 0 || maxDuration < duration) {
+		return nil, errdefs.NilArg("duration")
+	}
+	return &Lockout{
+		threshold:   threshold,
+		duration:    duration,
+		maxDuration: maxDuration,
+		entries:     make(map[models.AuthLockoutKey]*entry),
+		now:         time.Now,
+	}, nil
+}
+
+// return lock end if key is locked
+func (l *Lockout) Locked(key models.AuthLockoutKey) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok || !e.lockedUntil.After(l.now()) {
+		return time.Time{}, false
+	}
+	return e.lockedUntil, true
+}
+
+// register failed login, return lock end if key became locked
+func (l *Lockout) Fail(key models.AuthLockoutKey) (time.Time, bool) {
+	if l.threshold == 0 {
+		return time.Time{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	e, ok := l.entries[key]
+	if !ok {
+		e = &entry{}
+		l.entries[key] = e
+	}
+	e.failures++
+	e.lastFailure = now
+	if e.failures < l.threshold {
+		return time.Time{}, false
+	}
+
+	d := l.duration
+	for i := l.threshold; i < e.failures && d < l.maxDuration; i++ {
+		d *= 2
+	}
+	e.lockedUntil = now.Add(min(d, l.maxDuration))
+	return e.lockedUntil, true
+}
+
+// forget failures after successful login or manual unlock
+func (l *Lockout) Reset(key models.AuthLockoutKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// get active lockouts, longest first
+func (l *Lockout) List() []models.AuthLockout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	lockouts := make([]models.AuthLockout, 0)
+	for key, e := range l.entries {
+		if e.lockedUntil.After(now) {
+			lockouts = append(lockouts, models.AuthLockout{
+				Key:         key,
+				Failures:    e.failures,
+				LockedUntil: e.lockedUntil,
+			})
+		}
+	}
+	slices.SortFunc(lockouts, func(a, b models.AuthLockout) int {
+		return b.LockedUntil.Compare(a.LockedUntil)
+	})
+	return lockouts
+}
+
+// failures are forgotten after max duration without new ones
+func (l *Lockout) sweep(now time.Time) {
+	for key, e := range l.entries {
+		if now.Sub(e.lastFailure) > l.maxDuration && !e.lockedUntil.After(now) {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockout(t *testing.T) {
+	l, err := New(3, time.Minute, 5*time.Minute)
+	require.NoError(t, err)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	key := models.AuthLockoutKey{Login: "admin", ClientIP: "1.1.1.1"}
+	other := models.AuthLockoutKey{Login: "admin", ClientIP: "2.2.2.2"}
+
+	for range 2 {
+		_, locked := l.Fail(key)
+		require.False(t, locked)
+	}
+	until, locked := l.Fail(key)
+	require.True(t, locked)
+	require.Equal(t, now.Add(time.Minute), until)
+
+	_, locked = l.Locked(key)
+	require.True(t, locked)
+	_, locked = l.Locked(other)
+	require.False(t, locked)
+	require.Len(t, l.List(), 1)
+
+	// progressive
+	until, _ = l.Fail(key)
+	require.Equal(t, now.Add(2*time.Minute), until)
+	until, _ = l.Fail(key)
+	require.Equal(t, now.Add(4*time.Minute), until)
+	until, _ = l.Fail(key)
+	require.Equal(t, now.Add(5*time.Minute), until)
+
+	// lock expires
+	now = now.Add(5 * time.Minute)
+	_, locked = l.Locked(key)
+	require.False(t, locked)
+	require.Empty(t, l.List())
+
+	// reset on success
+	l.Reset(key)
+	_, locked = l.Fail(key)
+	require.False(t, locked)
+
+	// old failures are forgotten
+	l.Fail(other)
+	l.Fail(other)
+	now = now.Add(time.Hour)
+	_, locked = l.Fail(other)
+	require.False(t, locked)
+}
+
+func TestLockoutDisabled(t *testing.T) {
+	l, err := New(0, 0, 0)
+	require.NoError(t, err)
+	key := models.AuthLockoutKey{Login: "admin"}
+	for range 100 {
+		_, locked := l.Fail(key)
+		require.False(t, locked)
+	}
+}
@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/http/middleware"
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/dbstorage"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/poolop"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/stats/poolstats"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "nodeman"
+
+type Metrics struct {
+	registry *prometheus.Registry
+	log      *zap.Logger
+
+	httpRequests *prometheus.HistogramVec
+
+	nodeOps      *prometheus.HistogramVec
+	nodeOpErrors *prometheus.CounterVec
+
+	statsBatches *prometheus.CounterVec
+	statsUsers   *prometheus.CounterVec
+	userTraffic  *prometheus.CounterVec
+	nodeTraffic  *prometheus.CounterVec
+
+	dbQueries     *prometheus.HistogramVec
+	dbQueryErrors *prometheus.CounterVec
+}
+
+var _ middleware.HTTPMetrics = (*Metrics)(nil)
+var _ poolop.Metrics = (*Metrics)(nil)
+var _ poolstats.Metrics = (*Metrics)(nil)
+var _ dbstorage.Metrics = (*Metrics)(nil)
+
+func New(log *zap.Logger) (*Metrics, error) {
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		log:      log,
+
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP requests duration by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+
+		nodeOps: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "op_duration_seconds",
+			Help:      "Node operations (sync, reload, stats) duration.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"op", "node"}),
+		nodeOpErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "node",
+			Name:      "op_errors_total",
+			Help:      "Failed node operations (sync, reload, stats).",
+		}, []string{"op", "node"}),
+
+		statsBatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "stats",
+			Name:      "batches_total",
+			Help:      "Node stats batches saved to storage.",
+		}, []string{"node"}),
+		statsUsers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "stats",
+			Name:      "user_records_total",
+			Help:      "User stats records saved to storage.",
+		}, []string{"node"}),
+		userTraffic: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "traffic",
+			Name:      "user_bytes_total",
+			Help:      "User traffic since nodeman start.",
+		}, []string{"user", "direction"}),
+		nodeTraffic: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "traffic",
+			Name:      "node_bytes_total",
+			Help:      "Node traffic since nodeman start.",
+		}, []string{"node", "direction"}),
+
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Storage queries duration.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"query"}),
+		dbQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_errors_total",
+			Help:      "Failed storage queries.",
+		}, []string{"query"}),
+	}
+
+	err := registerAll(m.registry,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.nodeOps,
+		m.nodeOpErrors,
+		m.statsBatches,
+		m.statsUsers,
+		m.userTraffic,
+		m.nodeTraffic,
+		m.dbQueries,
+		m.dbQueryErrors,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CollectNodes adds nodes statuses read from storage on every scrape
+func (m *Metrics) CollectNodes(storage Storage, timeout time.Duration) error {
+	if m == nil {
+		return errdefs.NilCall()
+	}
+	if storage == nil {
+		return errdefs.NilArg("storage")
+	}
+	return registerAll(m.registry, newNodesCollector(storage, timeout, m.log))
+}
+
+// Handler serves metrics in prometheus format,
+// failed collectors don't fail the scrape
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+func (m *Metrics) ObserveRequest(method string, route string,
+	status int, d time.Duration,
+) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.
+		WithLabelValues(method, route, strconv.Itoa(status)).
+		Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveNodeOp(op string, id models.NodeID,
+	d time.Duration, err error,
+) {
+	if m == nil {
+		return
+	}
+	node := strconv.Itoa(id)
+	m.nodeOps.WithLabelValues(op, node).Observe(d.Seconds())
+	if err != nil {
+		m.nodeOpErrors.WithLabelValues(op, node).Inc()
+	}
+}
+
+func (m *Metrics) ObserveNodeStats(id models.NodeID, stats models.NodeStats) {
+	if m == nil {
+		return
+	}
+	node := strconv.Itoa(id)
+	m.statsBatches.WithLabelValues(node).Inc()
+	m.statsUsers.WithLabelValues(node).Add(float64(len(stats.Users)))
+
+	var uplink, downlink int64
+	for _, u := range stats.Users {
+		user := strconv.Itoa(u.ID)
+		m.userTraffic.WithLabelValues(user, "uplink").Add(float64(max(u.Uplink, 0)))
+		m.userTraffic.WithLabelValues(user, "downlink").Add(float64(max(u.Downlink, 0)))
+		uplink += max(u.Uplink, 0)
+		downlink += max(u.Downlink, 0)
+	}
+	m.nodeTraffic.WithLabelValues(node, "uplink").Add(float64(uplink))
+	m.nodeTraffic.WithLabelValues(node, "downlink").Add(float64(downlink))
+}
+
+func (m *Metrics) ObserveQuery(name string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.dbQueries.WithLabelValues(name).Observe(d.Seconds())
+	if err != nil {
+		m.dbQueryErrors.WithLabelValues(name).Inc()
+	}
+}
+
+func registerAll(r prometheus.Registerer, cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			return xerr.WrapWithStack(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type nodesStorage struct {
+	nodes []models.Node
+	err   error
+}
+
+func (s *nodesStorage) ListNodes(ctx context.Context) ([]models.Node, error) {
+	return s.nodes, s.err
+}
+
+func TestMetrics_NodeStats(t *testing.T) {
+	m, err := New(zap.NewNop())
+	require.NoError(t, err)
+
+	stats := models.NodeStats{Users: []models.UserStats{
+		{ID: 1, Uplink: 10, Downlink: 100},
+		{ID: 2, Uplink: 20, Downlink: 200},
+	}}
+	m.ObserveNodeStats(7, stats)
+	m.ObserveNodeStats(7, stats)
+
+	require.Equal(t, 2., testutil.ToFloat64(m.statsBatches.WithLabelValues("7")))
+	require.Equal(t, 4., testutil.ToFloat64(m.statsUsers.WithLabelValues("7")))
+	require.Equal(t, 40., testutil.ToFloat64(m.userTraffic.WithLabelValues("2", "uplink")))
+	require.Equal(t, 200., testutil.ToFloat64(m.userTraffic.WithLabelValues("1", "downlink")))
+	require.Equal(t, 60., testutil.ToFloat64(m.nodeTraffic.WithLabelValues("7", "uplink")))
+	require.Equal(t, 600., testutil.ToFloat64(m.nodeTraffic.WithLabelValues("7", "downlink")))
+}
+
+func TestMetrics_NodeOps(t *testing.T) {
+	m, err := New(zap.NewNop())
+	require.NoError(t, err)
+
+	m.ObserveNodeOp("sync", 1, time.Second, nil)
+	m.ObserveNodeOp("sync", 1, time.Second, xerr.New("node unavailable"))
+
+	require.Equal(t, 1, testutil.CollectAndCount(m.nodeOps))
+	require.Equal(t, 1., testutil.ToFloat64(m.nodeOpErrors.WithLabelValues("sync", "1")))
+}
+
+func TestMetrics_NodesStatus(t *testing.T) {
+	storage := &nodesStorage{nodes: []models.Node{
+		{ID: 1, CurrentStatus: models.NodeStatusRunning, TargetStatus: models.NodeStatusRunning},
+		{ID: 2, CurrentStatus: models.NodeStatusUnknown, TargetStatus: models.NodeStatusStopped},
+	}}
+	c := newNodesCollector(storage, time.Second, zap.NewNop())
+
+	// one series per node and status, both for current and target
+	require.Equal(t, 12, testutil.CollectAndCount(c))
+}
+
+func TestMetrics_NodesStatusStorageError(t *testing.T) {
+	m, err := New(zap.NewNop())
+	require.NoError(t, err)
+	storage := &nodesStorage{err: xerr.New("db unavailable")}
+	require.NoError(t, m.CollectNodes(storage, time.Second))
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotContains(t, rr.Body.String(), "node_current_status{")
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var nodeStatuses = []models.NodeStatus{
+	models.NodeStatusUnknown,
+	models.NodeStatusStopped,
+	models.NodeStatusRunning,
+}
+
+// nodes collector reads nodes statuses from storage on every scrape
+type nodesCollector struct {
+	storage Storage
+	timeout time.Duration
+	log     *zap.Logger
+
+	current *prometheus.Desc
+	target  *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*nodesCollector)(nil)
+
+func newNodesCollector(storage Storage, timeout time.Duration,
+	log *zap.Logger,
+) *nodesCollector {
+	labels := []string{"node", "status"}
+	return &nodesCollector{
+		storage: storage,
+		timeout: timeout,
+		log:     log,
+
+		current: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "current_status"),
+			"Node current status, 1 for the status node is in.",
+			labels, nil),
+		target: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "target_status"),
+			"Node target status, 1 for the status node should be in.",
+			labels, nil),
+	}
+}
+
+func (c *nodesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.current
+	ch <- c.target
+}
+
+func (c *nodesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	// storage failure must not fail the whole scrape,
+	// node series are just missing until storage is back
+	nodes, err := c.storage.ListNodes(ctx)
+	if err != nil {
+		c.log.Warn("collect nodes metrics", zap.Error(err))
+		return
+	}
+
+	for _, node := range nodes {
+		id := strconv.Itoa(node.ID)
+		for _, status := range nodeStatuses {
+			ch <- prometheus.MustNewConstMetric(c.current,
+				prometheus.GaugeValue, isStatus(node.CurrentStatus, status),
+				id, status.String())
+			ch <- prometheus.MustNewConstMetric(c.target,
+				prometheus.GaugeValue, isStatus(node.TargetStatus, status),
+				id, status.String())
+		}
+	}
+}
+
+func isStatus(s models.NodeStatus, expected models.NodeStatus) float64 {
+	if s == expected {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	ListNodes(ctx context.Context) ([]models.Node, error)
+}
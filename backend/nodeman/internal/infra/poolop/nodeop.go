@@ -2,6 +2,7 @@ package poolop
 
 import (
 	"context"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"go.uber.org/zap"
@@ -10,3 +11,12 @@ import (
 type NodeOp interface {
 	Exec(ctx context.Context, node models.Node, log *zap.Logger) error
 }
+
+type Metrics interface {
+	ObserveNodeOp(op string, id models.NodeID, d time.Duration, err error)
+}
+
+// Notifier receives node op failures
+type Notifier interface {
+	Notify(ctx context.Context, e models.WebhookEvent)
+}
@@ -2,10 +2,13 @@ package poolop
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/safego"
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/waveexec"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"go.uber.org/zap"
@@ -20,15 +23,46 @@ type execItem struct {
 }
 
 type PoolOp struct {
-	storage Storage
-	nodeOp  NodeOp
-	log     *zap.Logger
+	storage  Storage
+	nodeOp   NodeOp
+	log      *zap.Logger
+	name     string
+	metrics  Metrics
+	notifier Notifier
 
 	nodeExecs map[models.NodeID]nodeExec
 	mu        sync.RWMutex
+
+	// nodes whose last op failed, failure is reported once
+	failing   map[models.NodeID]bool
+	failingMu sync.Mutex
+}
+
+type options struct {
+	name     string
+	metrics  Metrics
+	notifier Notifier
 }
 
-func New(s Storage, op NodeOp, log *zap.Logger) (*PoolOp, error) {
+type Option func(o *options)
+
+// WithMetrics reports every node op exec as op with given name
+func WithMetrics(name string, m Metrics) Option {
+	return func(o *options) {
+		o.name = name
+		o.metrics = m
+	}
+}
+
+// WithNotifier reports node op failures, only first failure
+// after successful op is reported
+func WithNotifier(n Notifier) Option {
+	return func(o *options) {
+		o.notifier = n
+	}
+}
+
+func New(s Storage, op NodeOp, log *zap.Logger, opts ...Option) (*PoolOp, error) {
 	if s == nil {
 		return nil, xerr.NilArg("s")
 	}
@@ -38,12 +72,20 @@ func New(s Storage, op NodeOp, log *zap.Logger) (*PoolOp, error) {
 	if log == nil {
 		return nil, xerr.NilArg("log")
 	}
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return &PoolOp{
-		storage: s,
-		nodeOp:  op,
-		log:     log,
+		storage:  s,
+		nodeOp:   op,
+		log:      log,
+		name:     o.name,
+		metrics:  o.metrics,
+		notifier: o.notifier,
 
 		nodeExecs: make(map[models.NodeID]nodeExec),
+		failing:   make(map[models.NodeID]bool),
 	}, nil
 }
 
@@ -104,8 +146,16 @@ func (o *PoolOp) exec(ctx context.Context, items []execItem) {
 		var nodeExec nodeExec
 		var exists bool
 		if nodeExec, exists = o.nodeExecs[item.node.ID]; !exists {
-			nodeOp := func(ctx context.Context) (*empty, error) {
-				err := o.nodeOp.Exec(ctx, item.node, o.log)
+			nodeOp := func(ctx context.Context) (_ *empty, err error) {
+				if o.metrics != nil {
+					start := time.Now()
+					defer func() {
+						o.metrics.ObserveNodeOp(o.name, item.node.ID,
+							time.Since(start), err)
+					}()
+				}
+				err = o.nodeOp.Exec(ctx, item.node, o.log)
+				o.reportResult(ctx, item.node.ID, err)
 				return nil, err
 			}
 			nodeExec = waveexec.New(nodeOp)
@@ -129,3 +179,22 @@ func (o *PoolOp) exec(ctx context.Context, items []execItem) {
 	}
 	wg.Wait()
 }
+
+func (o *PoolOp) reportResult(ctx context.Context, id models.NodeID, err error) {
+	if o.notifier == nil {
+		return
+	}
+	o.failingMu.Lock()
+	wasFailing := o.failing[id]
+	o.failing[id] = err != nil
+	o.failingMu.Unlock()
+
+	// unavailable node is reported by node op itself
+	if err != nil && !wasFailing && !errors.Is(err, errdefs.ErrNodeUnavailable) {
+		o.notifier.Notify(ctx, models.WebhookEvent{
+			Type:   models.WebhookEventSyncError,
+			NodeID: id,
+			Error:  o.name + ": " + err.Error(),
+		})
+	}
+}
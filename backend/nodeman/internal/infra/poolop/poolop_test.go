@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -69,3 +70,64 @@ func TestPoolOp(t *testing.T) {
 	log.Info("panic test error", zap.Error(res.Nodes[1].Err))
 	require.Equal(t, len(np.nodes), op.deferCallsCount)
 }
+
+type failingOp struct {
+	fail        bool
+	unavailable bool
+}
+
+func (o *failingOp) Exec(ctx context.Context, node models.Node, log *zap.Logger) error {
+	if o.unavailable {
+		return xerr.Wrap(xerr.New("node op fail"),
+			xerr.WithType(errdefs.ErrNodeUnavailable))
+	}
+	if o.fail {
+		return xerr.New("node op fail")
+	}
+	return nil
+}
+
+type notifier struct {
+	events []models.WebhookEvent
+	lock   sync.Mutex
+}
+
+func (n *notifier) Notify(ctx context.Context, e models.WebhookEvent) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.events = append(n.events, e)
+}
+
+func TestPoolOp_Notifier(t *testing.T) {
+	np := nodePool{nodes: []models.Node{{ID: 1}}}
+	op := failingOp{}
+	n := notifier{}
+	poolOp, err := New(&np, &op, zaptest.NewLogger(t),
+		WithMetrics("sync", nil), WithNotifier(&n))
+	require.NoError(t, err)
+	defer poolOp.Close()
+
+	require.NoError(t, poolOp.ExecNode(t.Context(), 1))
+	require.Empty(t, n.events)
+
+	// repeated failures are reported once
+	op.fail = true
+	require.Error(t, poolOp.ExecNode(t.Context(), 1))
+	require.Error(t, poolOp.ExecNode(t.Context(), 1))
+	require.Len(t, n.events, 1)
+	require.Equal(t, models.WebhookEventSyncError, n.events[0].Type)
+	require.Equal(t, 1, n.events[0].NodeID)
+
+	op.fail = false
+	require.NoError(t, poolOp.ExecNode(t.Context(), 1))
+	op.fail = true
+	require.Error(t, poolOp.ExecNode(t.Context(), 1))
+	require.Len(t, n.events, 2)
+
+	// unavailable node is reported by node op, not as sync error
+	op.fail, op.unavailable = false, false
+	require.NoError(t, poolOp.ExecNode(t.Context(), 1))
+	op.unavailable = true
+	require.Error(t, poolOp.ExecNode(t.Context(), 1))
+	require.Len(t, n.events, 2)
+}
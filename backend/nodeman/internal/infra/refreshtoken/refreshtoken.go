@@ -0,0 +1,32 @@
+package refreshtoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
+// prefix makes leaked refresh tokens easy to recognize,
+// they are never accepted as bearer token
+const prefix = "xrr_"
+
+const secretSize = 32
+
+// generate new refresh token and its hash to store
+func New() (string, []byte, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, xerr.WrapWithStack(err)
+	}
+	token := prefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, Hash(token), nil
+}
+
+// token is random, so plain sha256 is enough
+// and allows to find session by hash
+func Hash(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
@@ -0,0 +1,18 @@
+package refreshtoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshToken(t *testing.T) {
+	token, hash, err := New()
+	require.NoError(t, err)
+	require.Equal(t, hash, Hash(token))
+
+	token2, hash2, err := New()
+	require.NoError(t, err)
+	require.NotEqual(t, token, token2)
+	require.NotEqual(t, hash, hash2)
+}
@@ -0,0 +1,69 @@
+package poolstats
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// online ips reported earlier are stale, e.g. node is unavailable
+	onlineIPsTTL = 5 * time.Minute
+	// user violation is recorded once per window
+	ipViolationsWindow = time.Hour
+)
+
+// record users exceeded ip limit, then warn or disable them
+// according to settings policy. warned users get announce
+// message in subscription, disabled users are removed
+// from nodes on the next pool sync
+func (s *Stats) checkIPLimits(ctx context.Context) error {
+	settings, err := s.storage.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+	disable := settings.IPLimitPolicy == models.IPLimitPolicyDisable
+
+	now := time.Now()
+	var violations []models.IPLimitViolation
+	if err := s.storage.DoTx(ctx, func(ctx context.Context) error {
+		violations, err = s.storage.RecordIPLimitViolations(ctx,
+			now.Add(-onlineIPsTTL), now.Add(-ipViolationsWindow))
+		if err != nil {
+			return err
+		}
+		if !disable {
+			return nil
+		}
+		for _, v := range violations {
+			if err := s.storage.SetTargetUserStatus(ctx,
+				v.UserID, models.UserStatusDisabled,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	msg := "ip limit exceeded, user warned"
+	if disable {
+		msg = "ip limit exceeded, user disabled"
+	}
+	for _, v := range violations {
+		s.log.Info(msg,
+			zap.Int("id", v.UserID),
+			zap.Int("ips", v.IPCount),
+			zap.Int("limit", v.IPLimit))
+		if disable && s.notifier != nil {
+			s.notifier.Notify(ctx, models.WebhookEvent{
+				Type:   models.WebhookEventUserIPLimitExceeded,
+				UserID: v.UserID,
+			})
+		}
+	}
+	return nil
+}
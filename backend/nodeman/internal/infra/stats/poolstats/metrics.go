@@ -0,0 +1,12 @@
+package poolstats
+
+import (
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/poolop"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Metrics interface {
+	poolop.Metrics
+	// called for every stats batch saved to storage
+	ObserveNodeStats(id models.NodeID, stats models.NodeStats)
+}
@@ -8,12 +8,19 @@ import (
 )
 
 type nodeStorage struct {
-	base   Storage
-	nodeID models.NodeID
+	base    Storage
+	nodeID  models.NodeID
+	metrics Metrics
 }
 
 var _ node.Storage = (*nodeStorage)(nil)
 
 func (s *nodeStorage) UpdateNodeStats(ctx context.Context, stats models.NodeStats) error {
-	return s.base.UpdateNodeStats(ctx, s.nodeID, stats)
+	if err := s.base.UpdateNodeStats(ctx, s.nodeID, stats); err != nil {
+		return err
+	}
+	if s.metrics != nil {
+		s.metrics.ObserveNodeStats(s.nodeID, stats)
+	}
+	return nil
 }
@@ -13,6 +13,7 @@ import (
 type nodeOp struct {
 	storage Storage
 	client  Client
+	metrics Metrics
 }
 
 var _ poolop.NodeOp = (*nodeOp)(nil)
@@ -24,8 +25,9 @@ func (op *nodeOp) Exec(ctx context.Context, node models.Node, log *zap.Logger) e
 	}
 
 	nodeStorage := &nodeStorage{
-		base:   op.storage,
-		nodeID: node.ID,
+		base:    op.storage,
+		nodeID:  node.ID,
+		metrics: op.metrics,
 	}
 	nodeClient, err := op.client.GetNodeClient(node.Config.ConnectionInfo)
 	if err != nil {
@@ -12,30 +12,64 @@ import (
 )
 
 type Stats struct {
-	storage Storage
-	op      *poolop.PoolOp
+	storage  Storage
+	op       *poolop.PoolOp
+	notifier Notifier
+	log      *zap.Logger
 }
 
+type Notifier = poolop.Notifier
+
 var _ statsman.StatsUpdater = (*Stats)(nil)
 
-func New(client Client, storage Storage, log *zap.Logger) (*Stats, error) {
+type options struct {
+	metrics  Metrics
+	notifier Notifier
+}
+
+type Option func(o *options)
+
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithNotifier reports users disabled by quota overrun
+func WithNotifier(n Notifier) Option {
+	return func(o *options) {
+		o.notifier = n
+	}
+}
+
+func New(client Client, storage Storage, log *zap.Logger, opts ...Option) (*Stats, error) {
 	if client == nil {
 		return nil, errdefs.NilArg("client")
 	}
 	if storage == nil {
 		return nil, errdefs.NilArg("storage")
 	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	op, err := poolop.New(
 		storage,
-		&nodeOp{storage: storage, client: client},
+		&nodeOp{storage: storage, client: client, metrics: o.metrics},
 		log,
+		poolop.WithMetrics("stats", o.metrics),
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &Stats{
-		op:      op,
-		storage: storage,
+		op:       op,
+		storage:  storage,
+		notifier: o.notifier,
+		log:      log,
 	}, nil
 }
 
@@ -47,9 +81,54 @@ func (s *Stats) Close() {
 }
 
 func (s *Stats) UpdatePoolStats(ctx context.Context) (*models.PoolOpResult, error) {
-	return s.op.ExecAll(ctx)
+	res, err := s.op.ExecAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// disable users who ran out of traffic quota,
+	// they will be removed from nodes on the next pool sync
+	disabled, err := s.storage.DisableOverQuotaUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(disabled) != 0 {
+		s.log.Info("traffic quota exceeded, users disabled",
+			zap.Ints("users", disabled))
+	}
+	if s.notifier != nil {
+		for _, id := range disabled {
+			s.notifier.Notify(ctx, models.WebhookEvent{
+				Type:   models.WebhookEventUserQuotaReached,
+				UserID: id,
+			})
+		}
+	}
+
+	// ip limits are side feature, traffic stats are updated anyway
+	if err := s.checkIPLimits(ctx); err != nil {
+		s.log.Error("ip limits check failed", zap.Error(err))
+	}
+
+	return res, nil
 }
 
 func (s *Stats) UpdateDailyStats(ctx context.Context) error {
-	return s.storage.UpdateDailyStats(ctx, time.Now())
+	now := time.Now()
+	if err := s.storage.UpdateDailyStats(ctx, now); err != nil {
+		return err
+	}
+
+	// start new monthly quota periods, users disabled
+	// because of quota overrun are enabled back
+	reset, err := s.storage.ResetUserQuotas(ctx, now)
+	if err != nil {
+		return err
+	}
+	if len(reset) != 0 {
+		s.log.Info("traffic quota period reset",
+			zap.Ints("users", reset))
+	}
+
+	return nil
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
 
+type TxFn = func(context.Context) error
+
 type Storage interface {
 	ListNodes(ctx context.Context) (
 		[]models.Node, error)
@@ -17,6 +19,23 @@ type Storage interface {
 		stats models.NodeStats) error
 	UpdateDailyStats(ctx context.Context,
 		day time.Time) error
+	// disable enabled users who ran out of traffic quota
+	DisableOverQuotaUsers(ctx context.Context) (
+		[]models.UserID, error)
+	// record violations of enabled users whose online ips
+	// exceed ip limit, return recorded violations
+	RecordIPLimitViolations(ctx context.Context,
+		onlineSince time.Time, violationsSince time.Time) (
+		[]models.IPLimitViolation, error)
+	// change user target status
+	SetTargetUserStatus(ctx context.Context, id models.UserID,
+		status models.UserStatus) error
+	GetSettings(ctx context.Context) (*models.Settings, error)
+	// call multiple operations as tx
+	DoTx(ctx context.Context, fn TxFn) error
+	// start new period for users with expired monthly quota
+	ResetUserQuotas(ctx context.Context, now time.Time) (
+		[]models.UserID, error)
 }
 
 var _ poolop.Storage = (Storage)(nil)
@@ -0,0 +1,171 @@
+package subformat
+
+import (
+	"cmp"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/go-faster/yaml"
+)
+
+// name of proxy group selecting server
+const clashSelector = "Proxy"
+
+// Clash renders clash meta (mihomo) profile, all servers
+// are put into one selector group used for all traffic
+type Clash struct{}
+
+type clashProfile struct {
+	Proxies     []clashProxy      `yaml:"proxies"`
+	ProxyGroups []clashProxyGroup `yaml:"proxy-groups"`
+	Rules       []string          `yaml:"rules"`
+}
+
+type clashProxyGroup struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Proxies []string `yaml:"proxies"`
+}
+
+type clashProxy struct {
+	Name              string            `yaml:"name"`
+	Type              string            `yaml:"type"`
+	Server            string            `yaml:"server"`
+	Port              int               `yaml:"port"`
+	UUID              string            `yaml:"uuid,omitempty"`
+	Password          string            `yaml:"password,omitempty"`
+	AlterID           *int              `yaml:"alterId,omitempty"`
+	Cipher            string            `yaml:"cipher,omitempty"`
+	Flow              string            `yaml:"flow,omitempty"`
+	UDP               bool              `yaml:"udp"`
+	Network           string            `yaml:"network,omitempty"`
+	TLS               bool              `yaml:"tls,omitempty"`
+	ServerName        string            `yaml:"servername,omitempty"`
+	SNI               string            `yaml:"sni,omitempty"`
+	ALPN              []string          `yaml:"alpn,omitempty"`
+	SkipCertVerify    bool              `yaml:"skip-cert-verify,omitempty"`
+	ClientFingerprint string            `yaml:"client-fingerprint,omitempty"`
+	RealityOpts       *clashRealityOpts `yaml:"reality-opts,omitempty"`
+	WSOpts            *clashWSOpts      `yaml:"ws-opts,omitempty"`
+	GRPCOpts          *clashGRPCOpts    `yaml:"grpc-opts,omitempty"`
+}
+
+type clashRealityOpts struct {
+	PublicKey string `yaml:"public-key"`
+	ShortID   string `yaml:"short-id,omitempty"`
+}
+
+type clashWSOpts struct {
+	Path             string            `yaml:"path,omitempty"`
+	Headers          map[string]string `yaml:"headers,omitempty"`
+	V2rayHTTPUpgrade bool              `yaml:"v2ray-http-upgrade,omitempty"`
+}
+
+type clashGRPCOpts struct {
+	ServiceName string `yaml:"grpc-service-name,omitempty"`
+}
+
+func (Clash) Render(cfgs []models.ClientConfigItem) ([]byte, error) {
+	proxies, err := parseProxies(cfgs)
+	if err != nil {
+		return nil, err
+	}
+	profile := clashProfile{
+		Rules: []string{"MATCH," + clashSelector},
+	}
+	var names []string
+	for _, p := range proxies {
+		cp, ok := clashConvert(&p)
+		if !ok {
+			continue
+		}
+		profile.Proxies = append(profile.Proxies, cp)
+		names = append(names, cp.Name)
+	}
+	if len(proxies) != 0 && len(names) == 0 {
+		return nil, xerr.New("no servers supported by clash")
+	}
+	profile.ProxyGroups = []clashProxyGroup{{
+		Name:    clashSelector,
+		Type:    "select",
+		Proxies: append(names, "DIRECT"),
+	}}
+	out, err := yaml.Marshal(profile)
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	return out, nil
+}
+
+// convert proxy to clash one, false for transports clash doesn't support
+func clashConvert(p *proxy) (clashProxy, bool) {
+	cp := clashProxy{
+		Name:   p.Name,
+		Type:   p.Protocol,
+		Server: p.Address,
+		Port:   p.Port,
+		UDP:    true,
+	}
+	switch p.Protocol {
+	case protocolVless:
+		cp.UUID = p.ID
+		cp.Flow = p.Flow
+	case protocolVmess:
+		alterID := 0
+		cp.UUID = p.ID
+		cp.AlterID = &alterID
+		cp.Cipher = cmp.Or(p.Encryption, "auto")
+	case protocolTrojan:
+		cp.Password = p.ID
+	case protocolShadowsocks:
+		cp.Type = "ss"
+		cp.Password = p.ID
+		cp.Cipher = p.Method
+	}
+
+	switch p.Network {
+	case networkTCP:
+	case networkWS:
+		cp.Network = networkWS
+		cp.WSOpts = &clashWSOpts{Path: p.Path, Headers: hostHeader(p.Host)}
+	case networkHTTPUpgrade:
+		cp.Network = networkWS
+		cp.WSOpts = &clashWSOpts{
+			Path:             p.Path,
+			Headers:          hostHeader(p.Host),
+			V2rayHTTPUpgrade: true,
+		}
+	case networkGRPC:
+		cp.Network = networkGRPC
+		cp.GRPCOpts = &clashGRPCOpts{ServiceName: p.ServiceName}
+	default:
+		return clashProxy{}, false
+	}
+
+	if p.Security != "" {
+		// trojan is always tls and uses sni key
+		if p.Protocol == protocolTrojan {
+			cp.SNI = p.SNI
+		} else {
+			cp.TLS = true
+			cp.ServerName = p.SNI
+		}
+		cp.ALPN = p.ALPN
+		cp.SkipCertVerify = p.Insecure
+		cp.ClientFingerprint = p.Fingerprint
+	}
+	if p.Security == securityReality {
+		cp.RealityOpts = &clashRealityOpts{
+			PublicKey: p.PublicKey,
+			ShortID:   p.ShortID,
+		}
+	}
+	return cp, true
+}
+
+func hostHeader(host string) map[string]string {
+	if host == "" {
+		return nil
+	}
+	return map[string]string{"Host": host}
+}
@@ -0,0 +1,125 @@
+package subformat
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+// Links renders base64 encoded list of share links,
+// one vless://, vmess://, trojan:// or ss:// link per line
+type Links struct{}
+
+func (Links) Render(cfgs []models.ClientConfigItem) ([]byte, error) {
+	proxies, err := parseProxies(cfgs)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, p := range proxies {
+		link, err := shareLink(&p)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(link)
+		buf.WriteByte('\n')
+	}
+	out := make([]byte, base64.StdEncoding.EncodedLen(buf.Len()))
+	base64.StdEncoding.Encode(out, buf.Bytes())
+	return out, nil
+}
+
+func shareLink(p *proxy) (string, error) {
+	switch p.Protocol {
+	case protocolVmess:
+		return vmessLink(p)
+	case protocolShadowsocks:
+		return ssLink(p), nil
+	}
+	q := url.Values{}
+	q.Set("type", p.Network)
+	setNonEmpty(q, "path", p.Path)
+	setNonEmpty(q, "host", p.Host)
+	setNonEmpty(q, "serviceName", p.ServiceName)
+	setNonEmpty(q, "mode", p.Mode)
+	setNonEmpty(q, "flow", p.Flow)
+	if p.Protocol == protocolVless {
+		q.Set("encryption", cmp.Or(p.Encryption, "none"))
+	}
+	q.Set("security", cmp.Or(p.Security, "none"))
+	setNonEmpty(q, "sni", p.SNI)
+	setNonEmpty(q, "fp", p.Fingerprint)
+	setNonEmpty(q, "alpn", strings.Join(p.ALPN, ","))
+	setNonEmpty(q, "pbk", p.PublicKey)
+	setNonEmpty(q, "sid", p.ShortID)
+	setNonEmpty(q, "spx", p.SpiderX)
+	if p.Insecure {
+		q.Set("allowInsecure", "1")
+	}
+	u := url.URL{
+		Scheme:   p.Protocol,
+		User:     url.User(p.ID),
+		Host:     p.Server(),
+		RawQuery: q.Encode(),
+		Fragment: p.Name,
+	}
+	return u.String(), nil
+}
+
+// vmess link is base64 encoded json, as defined by v2rayN
+func vmessLink(p *proxy) (string, error) {
+	tls := ""
+	if p.Security == securityTLS {
+		tls = securityTLS
+	}
+	path := p.Path
+	if p.Network == networkGRPC {
+		path = p.ServiceName
+	}
+	data, err := json.Marshal(map[string]string{
+		"v":    "2",
+		"ps":   p.Name,
+		"add":  p.Address,
+		"port": strconv.Itoa(p.Port),
+		"id":   p.ID,
+		"aid":  "0",
+		"scy":  cmp.Or(p.Encryption, "auto"),
+		"net":  p.Network,
+		"type": "none",
+		"host": p.Host,
+		"path": path,
+		"tls":  tls,
+		"sni":  p.SNI,
+		"alpn": strings.Join(p.ALPN, ","),
+		"fp":   p.Fingerprint,
+	})
+	if err != nil {
+		return "", xerr.WrapWithStack(err)
+	}
+	return "vmess://" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// ss link is SIP002 one, 2022 ciphers userinfo is
+// percent-encoded "method:password" instead of base64
+func ssLink(p *proxy) string {
+	u := url.URL{
+		Scheme:   "ss",
+		User:     url.UserPassword(p.Method, p.ID),
+		Host:     p.Server(),
+		Fragment: p.Name,
+	}
+	return u.String()
+}
+
+func setNonEmpty(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
@@ -0,0 +1,271 @@
+package subformat
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+const (
+	protocolVless       = "vless"
+	protocolVmess       = "vmess"
+	protocolTrojan      = "trojan"
+	protocolShadowsocks = "shadowsocks"
+)
+
+const (
+	securityTLS     = "tls"
+	securityReality = "reality"
+)
+
+const (
+	networkTCP         = "tcp"
+	networkWS          = "ws"
+	networkGRPC        = "grpc"
+	networkHTTPUpgrade = "httpupgrade"
+	networkXHTTP       = "xhttp"
+)
+
+// proxy outbound extracted from xray client config,
+// format independent description of one server
+type proxy struct {
+	Name     string
+	Protocol string
+	Address  string
+	Port     int
+	// vless and vmess uuid, trojan or shadowsocks password
+	ID         string
+	Flow       string
+	Encryption string
+	// shadowsocks cipher
+	Method string
+
+	Network     string
+	Path        string
+	Host        string
+	ServiceName string
+	Mode        string
+
+	Security    string
+	SNI         string
+	Fingerprint string
+	ALPN        []string
+	Insecure    bool
+	PublicKey   string
+	ShortID     string
+	SpiderX     string
+}
+
+func (p *proxy) Server() string {
+	return net.JoinHostPort(p.Address, strconv.Itoa(p.Port))
+}
+
+// subset of xray client config used to extract proxies
+type xrayConfig struct {
+	Remarks   string         `json:"remarks"`
+	Outbounds []xrayOutbound `json:"outbounds"`
+}
+
+type xrayOutbound struct {
+	Tag      string `json:"tag"`
+	Protocol string `json:"protocol"`
+	Settings struct {
+		Vnext []struct {
+			Address string `json:"address"`
+			Port    int    `json:"port"`
+			Users   []struct {
+				ID         string `json:"id"`
+				Flow       string `json:"flow"`
+				Encryption string `json:"encryption"`
+				Security   string `json:"security"`
+			} `json:"users"`
+		} `json:"vnext"`
+		Servers []struct {
+			Address  string `json:"address"`
+			Port     int    `json:"port"`
+			Password string `json:"password"`
+			Flow     string `json:"flow"`
+			Method   string `json:"method"`
+		} `json:"servers"`
+	} `json:"settings"`
+	StreamSettings xrayStream `json:"streamSettings"`
+}
+
+type xrayStream struct {
+	Network     string `json:"network"`
+	Security    string `json:"security"`
+	TLSSettings struct {
+		ServerName    string   `json:"serverName"`
+		Fingerprint   string   `json:"fingerprint"`
+		ALPN          []string `json:"alpn"`
+		AllowInsecure bool     `json:"allowInsecure"`
+	} `json:"tlsSettings"`
+	RealitySettings struct {
+		ServerName  string `json:"serverName"`
+		Fingerprint string `json:"fingerprint"`
+		PublicKey   string `json:"publicKey"`
+		ShortID     string `json:"shortId"`
+		SpiderX     string `json:"spiderX"`
+	} `json:"realitySettings"`
+	WSSettings struct {
+		Path    string            `json:"path"`
+		Host    string            `json:"host"`
+		Headers map[string]string `json:"headers"`
+	} `json:"wsSettings"`
+	GRPCSettings struct {
+		ServiceName string `json:"serviceName"`
+	} `json:"grpcSettings"`
+	HTTPUpgradeSettings struct {
+		Path string `json:"path"`
+		Host string `json:"host"`
+	} `json:"httpupgradeSettings"`
+	XHTTPSettings struct {
+		Path string `json:"path"`
+		Host string `json:"host"`
+		Mode string `json:"mode"`
+	} `json:"xhttpSettings"`
+}
+
+// extract proxies from rendered client configs,
+// non-proxy outbounds (freedom, blackhole, dns) are skipped
+func parseProxies(cfgs []models.ClientConfigItem) ([]proxy, error) {
+	var proxies []proxy
+	for _, item := range cfgs {
+		var cfg xrayConfig
+		if err := json.Unmarshal(item, &cfg); err != nil {
+			return nil, xerr.WrapWithStack(err)
+		}
+		for _, o := range cfg.Outbounds {
+			for _, p := range parseOutbound(o) {
+				p.Name = proxyName(cfg.Remarks, o.Tag, &p)
+				proxies = append(proxies, p)
+			}
+		}
+	}
+	return uniqueNames(proxies), nil
+}
+
+func parseOutbound(o xrayOutbound) []proxy {
+	var proxies []proxy
+	switch o.Protocol {
+	case protocolVless, protocolVmess:
+		for _, v := range o.Settings.Vnext {
+			for _, u := range v.Users {
+				p := proxy{
+					Protocol:   o.Protocol,
+					Address:    v.Address,
+					Port:       v.Port,
+					ID:         u.ID,
+					Flow:       u.Flow,
+					Encryption: u.Encryption,
+				}
+				if o.Protocol == protocolVmess {
+					p.Encryption = u.Security
+				}
+				proxies = append(proxies, p)
+			}
+		}
+	case protocolTrojan:
+		for _, s := range o.Settings.Servers {
+			proxies = append(proxies, proxy{
+				Protocol: o.Protocol,
+				Address:  s.Address,
+				Port:     s.Port,
+				ID:       s.Password,
+				Flow:     s.Flow,
+			})
+		}
+	case protocolShadowsocks:
+		for _, s := range o.Settings.Servers {
+			proxies = append(proxies, proxy{
+				Protocol: o.Protocol,
+				Address:  s.Address,
+				Port:     s.Port,
+				ID:       s.Password,
+				Method:   s.Method,
+			})
+		}
+	}
+	for i := range proxies {
+		applyStream(&proxies[i], o.StreamSettings)
+	}
+	return proxies
+}
+
+func applyStream(p *proxy, s xrayStream) {
+	p.Network = s.Network
+	if p.Network == "" || p.Network == "raw" {
+		p.Network = networkTCP
+	}
+	switch p.Network {
+	case networkWS:
+		p.Path = s.WSSettings.Path
+		p.Host = s.WSSettings.Host
+		if p.Host == "" {
+			p.Host = s.WSSettings.Headers["Host"]
+		}
+	case networkGRPC:
+		p.ServiceName = s.GRPCSettings.ServiceName
+	case networkHTTPUpgrade:
+		p.Path = s.HTTPUpgradeSettings.Path
+		p.Host = s.HTTPUpgradeSettings.Host
+	case networkXHTTP:
+		p.Path = s.XHTTPSettings.Path
+		p.Host = s.XHTTPSettings.Host
+		p.Mode = s.XHTTPSettings.Mode
+	}
+
+	p.Security = s.Security
+	switch p.Security {
+	case securityTLS:
+		p.SNI = s.TLSSettings.ServerName
+		p.Fingerprint = s.TLSSettings.Fingerprint
+		p.ALPN = s.TLSSettings.ALPN
+		p.Insecure = s.TLSSettings.AllowInsecure
+	case securityReality:
+		p.SNI = s.RealitySettings.ServerName
+		p.Fingerprint = s.RealitySettings.Fingerprint
+		p.PublicKey = s.RealitySettings.PublicKey
+		p.ShortID = s.RealitySettings.ShortID
+		p.SpiderX = s.RealitySettings.SpiderX
+	default:
+		p.Security = ""
+	}
+}
+
+// config remarks name the server in client apps, outbound tag
+// is used for configs with several proxies
+func proxyName(remarks, tag string, p *proxy) string {
+	switch {
+	case remarks != "":
+		return remarks
+	case tag != "":
+		return tag
+	default:
+		return p.Server()
+	}
+}
+
+// clients identify proxies by name, so duplicates get suffixes
+func uniqueNames(proxies []proxy) []proxy {
+	seen := make(map[string]int, len(proxies))
+	for i := range proxies {
+		name := proxies[i].Name
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			proxies[i].Name = name + " " + strconv.Itoa(n)
+		}
+	}
+	return proxies
+}
+
+func proxyNames(proxies []proxy) []string {
+	names := make([]string, 0, len(proxies))
+	for _, p := range proxies {
+		names = append(names, p.Name)
+	}
+	return names
+}
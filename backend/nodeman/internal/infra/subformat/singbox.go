@@ -0,0 +1,201 @@
+package subformat
+
+import (
+	"cmp"
+	"encoding/json"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+// tag of outbound selecting server
+const singBoxSelector = "proxy"
+
+// SingBox renders sing-box profile with tun inbound,
+// all servers are put into one selector used for all traffic
+type SingBox struct{}
+
+type singBoxProfile struct {
+	Inbounds  []singBoxInbound `json:"inbounds"`
+	Outbounds []any            `json:"outbounds"`
+	Route     singBoxRoute     `json:"route"`
+}
+
+type singBoxInbound struct {
+	Type        string   `json:"type"`
+	Tag         string   `json:"tag"`
+	Address     []string `json:"address"`
+	AutoRoute   bool     `json:"auto_route"`
+	StrictRoute bool     `json:"strict_route"`
+}
+
+type singBoxRoute struct {
+	Final               string `json:"final"`
+	AutoDetectInterface bool   `json:"auto_detect_interface"`
+}
+
+// selector and direct outbounds
+type singBoxBasicOutbound struct {
+	Type      string   `json:"type"`
+	Tag       string   `json:"tag"`
+	Outbounds []string `json:"outbounds,omitempty"`
+}
+
+type singBoxOutbound struct {
+	Type       string            `json:"type"`
+	Tag        string            `json:"tag"`
+	Server     string            `json:"server"`
+	ServerPort int               `json:"server_port"`
+	UUID       string            `json:"uuid,omitempty"`
+	Password   string            `json:"password,omitempty"`
+	Security   string            `json:"security,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Flow       string            `json:"flow,omitempty"`
+	TLS        *singBoxTLS       `json:"tls,omitempty"`
+	Transport  *singBoxTransport `json:"transport,omitempty"`
+}
+
+type singBoxTLS struct {
+	Enabled    bool            `json:"enabled"`
+	ServerName string          `json:"server_name,omitempty"`
+	Insecure   bool            `json:"insecure,omitempty"`
+	ALPN       []string        `json:"alpn,omitempty"`
+	UTLS       *singBoxUTLS    `json:"utls,omitempty"`
+	Reality    *singBoxReality `json:"reality,omitempty"`
+}
+
+type singBoxUTLS struct {
+	Enabled     bool   `json:"enabled"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+type singBoxReality struct {
+	Enabled   bool   `json:"enabled"`
+	PublicKey string `json:"public_key"`
+	ShortID   string `json:"short_id,omitempty"`
+}
+
+type singBoxTransport struct {
+	Type        string            `json:"type"`
+	Path        string            `json:"path,omitempty"`
+	Host        string            `json:"host,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	ServiceName string            `json:"service_name,omitempty"`
+}
+
+func (SingBox) Render(cfgs []models.ClientConfigItem) ([]byte, error) {
+	proxies, err := parseProxies(cfgs)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var servers []any
+	for _, p := range proxies {
+		o, ok := singBoxConvert(&p)
+		if !ok {
+			continue
+		}
+		servers = append(servers, o)
+		names = append(names, o.Tag)
+	}
+	if len(proxies) != 0 && len(servers) == 0 {
+		return nil, xerr.New("no servers supported by sing-box")
+	}
+	outbounds := make([]any, 0, len(servers)+2)
+	outbounds = append(outbounds, singBoxBasicOutbound{
+		Type:      "selector",
+		Tag:       singBoxSelector,
+		Outbounds: append(names, "direct"),
+	})
+	outbounds = append(outbounds, servers...)
+	outbounds = append(outbounds, singBoxBasicOutbound{
+		Type: "direct",
+		Tag:  "direct",
+	})
+	profile := singBoxProfile{
+		Inbounds: []singBoxInbound{{
+			Type:        "tun",
+			Tag:         "tun-in",
+			Address:     []string{"172.19.0.1/30"},
+			AutoRoute:   true,
+			StrictRoute: true,
+		}},
+		Outbounds: outbounds,
+		Route: singBoxRoute{
+			Final:               singBoxSelector,
+			AutoDetectInterface: true,
+		},
+	}
+	out, err := json.Marshal(profile)
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	return out, nil
+}
+
+// convert proxy to sing-box outbound, false for
+// transports sing-box doesn't support
+func singBoxConvert(p *proxy) (singBoxOutbound, bool) {
+	o := singBoxOutbound{
+		Type:       p.Protocol,
+		Tag:        p.Name,
+		Server:     p.Address,
+		ServerPort: p.Port,
+	}
+	switch p.Protocol {
+	case protocolVless:
+		o.UUID = p.ID
+		o.Flow = p.Flow
+	case protocolVmess:
+		o.UUID = p.ID
+		o.Security = cmp.Or(p.Encryption, "auto")
+	case protocolTrojan:
+		o.Password = p.ID
+	case protocolShadowsocks:
+		o.Password = p.ID
+		o.Method = p.Method
+	}
+
+	switch p.Network {
+	case networkTCP:
+	case networkWS:
+		o.Transport = &singBoxTransport{
+			Type:    networkWS,
+			Path:    p.Path,
+			Headers: hostHeader(p.Host),
+		}
+	case networkHTTPUpgrade:
+		o.Transport = &singBoxTransport{
+			Type: networkHTTPUpgrade,
+			Path: p.Path,
+			Host: p.Host,
+		}
+	case networkGRPC:
+		o.Transport = &singBoxTransport{
+			Type:        networkGRPC,
+			ServiceName: p.ServiceName,
+		}
+	default:
+		return singBoxOutbound{}, false
+	}
+
+	if p.Security != "" {
+		o.TLS = &singBoxTLS{
+			Enabled:    true,
+			ServerName: p.SNI,
+			Insecure:   p.Insecure,
+			ALPN:       p.ALPN,
+		}
+		if p.Fingerprint != "" {
+			o.TLS.UTLS = &singBoxUTLS{Enabled: true, Fingerprint: p.Fingerprint}
+		}
+	}
+	if p.Security == securityReality {
+		o.TLS.Reality = &singBoxReality{
+			Enabled:   true,
+			PublicKey: p.PublicKey,
+			ShortID:   p.ShortID,
+		}
+	}
+	return o, true
+}
@@ -0,0 +1,39 @@
+package subformat
+
+import (
+	"strings"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+// user agent substrings of client apps, checked in order,
+// first match defines format. apps not listed get xray configs
+var userAgents = []struct {
+	match  string
+	format models.SubFormat
+}{
+	{"clash", models.SubFormatClash},
+	{"mihomo", models.SubFormatClash},
+	{"stash", models.SubFormatClash},
+	{"sing-box", models.SubFormatSingBox},
+	{"sfa/", models.SubFormatSingBox},
+	{"sfi/", models.SubFormatSingBox},
+	{"sfm/", models.SubFormatSingBox},
+	{"shadowrocket", models.SubFormatLinks},
+	{"quantumult", models.SubFormatLinks},
+	{"nekobox", models.SubFormatLinks},
+	{"nekoray", models.SubFormatLinks},
+	{"v2box", models.SubFormatLinks},
+	{"loon", models.SubFormatLinks},
+}
+
+// detect subscription format by client user agent
+func Detect(userAgent string) models.SubFormat {
+	ua := strings.ToLower(userAgent)
+	for _, a := range userAgents {
+		if strings.Contains(ua, a.match) {
+			return a.format
+		}
+	}
+	return models.SubFormatXray
+}
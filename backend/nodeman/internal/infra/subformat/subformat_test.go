@@ -0,0 +1,173 @@
+package subformat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/go-faster/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfigs = []models.ClientConfigItem{
+	[]byte(`{
+  "remarks": "Reality",
+  "outbounds": [
+    {
+      "protocol": "vless",
+      "settings": {"vnext": [{"address": "1.2.3.4", "port": 443, "users": [
+        {"id": "uuid-1", "flow": "xtls-rprx-vision", "encryption": "none"}
+      ]}]},
+      "streamSettings": {
+        "network": "tcp",
+        "security": "reality",
+        "realitySettings": {"serverName": "example.com", "fingerprint": "chrome",
+          "publicKey": "pbk", "shortId": "ab"}
+      }
+    },
+    {"protocol": "freedom", "tag": "direct"}
+  ]
+}`),
+	[]byte(`{
+  "outbounds": [
+    {
+      "tag": "Trojan WS",
+      "protocol": "trojan",
+      "settings": {"servers": [{"address": "cdn.example.com", "port": 8443, "password": "pwd"}]},
+      "streamSettings": {
+        "network": "ws",
+        "security": "tls",
+        "tlsSettings": {"serverName": "cdn.example.com"},
+        "wsSettings": {"path": "/ws", "host": "cdn.example.com"}
+      }
+    },
+    {
+      "protocol": "vless",
+      "settings": {"vnext": [{"address": "5.6.7.8", "port": 443, "users": [{"id": "uuid-1"}]}]},
+      "streamSettings": {"network": "xhttp", "xhttpSettings": {"path": "/x"}}
+    }
+  ]
+}`),
+}
+
+func TestDetect(t *testing.T) {
+	require.Equal(t, models.SubFormatClash, Detect("clash.meta/1.18"))
+	require.Equal(t, models.SubFormatClash, Detect("FlClash/v0.8 clash-verge Platform/android"))
+	require.Equal(t, models.SubFormatSingBox, Detect("SFA/1.11.0 (sing-box 1.11.0)"))
+	require.Equal(t, models.SubFormatLinks, Detect("Shadowrocket/2070 CFNetwork"))
+	require.Equal(t, models.SubFormatXray, Detect("v2rayNG/1.9.0"))
+	require.Equal(t, models.SubFormatXray, Detect(""))
+}
+
+func TestXray(t *testing.T) {
+	out, err := Xray{}.Render(testConfigs)
+	require.NoError(t, err)
+	var cfgs []json.RawMessage
+	require.NoError(t, json.Unmarshal(out, &cfgs))
+	require.Len(t, cfgs, 2)
+}
+
+func TestLinks(t *testing.T) {
+	out, err := Links{}.Render(testConfigs)
+	require.NoError(t, err)
+	decoded, err := base64.StdEncoding.DecodeString(string(out))
+	require.NoError(t, err)
+	links := strings.Split(strings.TrimSpace(string(decoded)), "\n")
+	require.Len(t, links, 3)
+	require.Equal(t, "vless://uuid-1@1.2.3.4:443?encryption=none&flow=xtls-rprx-vision&fp=chrome"+
+		"&pbk=pbk&security=reality&sid=ab&sni=example.com&type=tcp#Reality", links[0])
+	require.Equal(t, "trojan://pwd@cdn.example.com:8443?host=cdn.example.com&path=%2Fws"+
+		"&security=tls&sni=cdn.example.com&type=ws#Trojan%20WS", links[1])
+	require.True(t, strings.HasPrefix(links[2], "vless://uuid-1@5.6.7.8:443?"))
+}
+
+func TestClash(t *testing.T) {
+	out, err := Clash{}.Render(testConfigs)
+	require.NoError(t, err)
+	var profile clashProfile
+	require.NoError(t, yaml.Unmarshal(out, &profile))
+	// xhttp isn't supported by clash
+	require.Len(t, profile.Proxies, 2)
+	require.Equal(t, "Reality", profile.Proxies[0].Name)
+	require.True(t, profile.Proxies[0].TLS)
+	require.Equal(t, "pbk", profile.Proxies[0].RealityOpts.PublicKey)
+	require.Equal(t, "pwd", profile.Proxies[1].Password)
+	require.Equal(t, "/ws", profile.Proxies[1].WSOpts.Path)
+	require.Equal(t, []string{"Reality", "Trojan WS", "DIRECT"}, profile.ProxyGroups[0].Proxies)
+}
+
+func TestSingBox(t *testing.T) {
+	out, err := SingBox{}.Render(testConfigs)
+	require.NoError(t, err)
+	var profile struct {
+		Outbounds []singBoxOutbound `json:"outbounds"`
+	}
+	require.NoError(t, json.Unmarshal(out, &profile))
+	// selector, two servers, direct
+	require.Len(t, profile.Outbounds, 4)
+	require.Equal(t, "selector", profile.Outbounds[0].Type)
+	require.Equal(t, "vless", profile.Outbounds[1].Type)
+	require.Equal(t, "pbk", profile.Outbounds[1].TLS.Reality.PublicKey)
+	require.Equal(t, "ws", profile.Outbounds[2].Transport.Type)
+	require.Equal(t, "direct", profile.Outbounds[3].Type)
+}
+
+var testSSConfigs = []models.ClientConfigItem{
+	[]byte(`{
+  "remarks": "SS",
+  "outbounds": [
+    {
+      "protocol": "shadowsocks",
+      "settings": {"servers": [{"address": "1.2.3.4", "port": 8388,
+        "method": "2022-blake3-aes-128-gcm", "password": "psk:key"}]}
+    }
+  ]
+}`),
+}
+
+func TestShadowsocks(t *testing.T) {
+	out, err := Links{}.Render(testSSConfigs)
+	require.NoError(t, err)
+	decoded, err := base64.StdEncoding.DecodeString(string(out))
+	require.NoError(t, err)
+	require.Equal(t, "ss://2022-blake3-aes-128-gcm:psk%3Akey@1.2.3.4:8388#SS\n", string(decoded))
+
+	out, err = Clash{}.Render(testSSConfigs)
+	require.NoError(t, err)
+	var clash clashProfile
+	require.NoError(t, yaml.Unmarshal(out, &clash))
+	require.Len(t, clash.Proxies, 1)
+	require.Equal(t, "ss", clash.Proxies[0].Type)
+	require.Equal(t, "2022-blake3-aes-128-gcm", clash.Proxies[0].Cipher)
+	require.Equal(t, "psk:key", clash.Proxies[0].Password)
+
+	out, err = SingBox{}.Render(testSSConfigs)
+	require.NoError(t, err)
+	var singBox struct {
+		Outbounds []singBoxOutbound `json:"outbounds"`
+	}
+	require.NoError(t, json.Unmarshal(out, &singBox))
+	require.Len(t, singBox.Outbounds, 3)
+	require.Equal(t, "shadowsocks", singBox.Outbounds[1].Type)
+	require.Equal(t, "2022-blake3-aes-128-gcm", singBox.Outbounds[1].Method)
+	require.Equal(t, "psk:key", singBox.Outbounds[1].Password)
+}
+
+func TestUnsupportedServers(t *testing.T) {
+	// xhttp only configs can't be converted
+	xhttp := []models.ClientConfigItem{[]byte(`{"outbounds": [{
+  "protocol": "vless",
+  "settings": {"vnext": [{"address": "5.6.7.8", "port": 443, "users": [{"id": "uuid-1"}]}]},
+  "streamSettings": {"network": "xhttp"}
+}]}`)}
+	_, err := Clash{}.Render(xhttp)
+	require.Error(t, err)
+	_, err = SingBox{}.Render(xhttp)
+	require.Error(t, err)
+
+	// no servers at all is not an error
+	_, err = Clash{}.Render(nil)
+	require.NoError(t, err)
+}
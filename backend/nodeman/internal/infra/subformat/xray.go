@@ -0,0 +1,19 @@
+package subformat
+
+import (
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/go-faster/jx"
+)
+
+// Xray renders json array of xray client configs as is
+type Xray struct{}
+
+func (Xray) Render(cfgs []models.ClientConfigItem) ([]byte, error) {
+	e := new(jx.Encoder)
+	e.ArrStart()
+	for _, cfg := range cfgs {
+		e.Raw(cfg)
+	}
+	e.ArrEnd()
+	return e.Bytes(), nil
+}
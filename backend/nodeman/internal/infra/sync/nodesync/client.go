@@ -9,6 +9,9 @@ import (
 type Client interface {
 	Start(ctx context.Context, users []models.UserProfile) (*models.NodeSettings, error)
 	Stop(ctx context.Context) error
+	Reload(ctx context.Context) (*models.NodeSettings, error)
+	PushConfig(ctx context.Context, cfg models.NodeXRayConfig) (*models.NodeSettings, error)
+	ValidateConfig(ctx context.Context, cfg models.NodeXRayConfig) error
 	CheckStatus(ctx context.Context) (models.NodeStatus, error)
 	UpdateUsers(ctx context.Context, upd models.NodeUsersUpdate) error
 }
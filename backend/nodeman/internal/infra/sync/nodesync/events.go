@@ -0,0 +1,12 @@
+package nodesync
+
+import "context"
+
+// Events receives node availability changes
+type Events interface {
+	// node stopped responding and marked as unavailable
+	NodeUnavailable(ctx context.Context, cause error)
+	// node with unknown status is running again, it could be
+	// new node as well, so receiver tracks unavailable ones
+	NodeRecovered(ctx context.Context)
+}
@@ -9,7 +9,9 @@ import (
 type TxFn = func(context.Context) error
 
 type UsersStorage interface {
-	ListUsers(ctx context.Context) ([]models.User, error)
+	// target state of all users on this node, users not entitled
+	// to node are disabled, current state isn't set
+	ListUserSyncs(ctx context.Context) ([]models.UserSyncStatus, error)
 }
 
 type StateStorage interface {
@@ -30,10 +32,22 @@ type SyncsStorage interface {
 		patch []models.UserStatusPatch) error
 }
 
+type ConfigStorage interface {
+	// config pushed to node storage but not applied yet, nil if none
+	GetPendingNodeConfig(ctx context.Context) (
+		*models.NodeXRayConfig, error)
+	SetCurrentNodeConfigVersion(ctx context.Context,
+		v models.ConfigVersion) error
+	// config rejected by node, it isn't pushed again
+	SetFailedNodeConfigVersion(ctx context.Context,
+		v models.ConfigVersion) error
+}
+
 type Storage interface {
 	UsersStorage
 	StateStorage
 	SyncsStorage
+	ConfigStorage
 	// call multiple operations as tx
 	DoTx(ctx context.Context, fn TxFn) error
 }
@@ -4,21 +4,68 @@ import (
 	"context"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
 
-func SyncState(ctx context.Context, client Client, storage Storage) error {
+type options struct {
+	events Events
+}
+
+type Option func(o *options)
+
+// WithEvents reports node availability changes
+func WithEvents(e Events) Option {
+	return func(o *options) {
+		o.events = e
+	}
+}
+
+func SyncState(ctx context.Context, client Client, storage Storage, opts ...Option) error {
 	if client == nil {
 		return errdefs.NilArg("client")
 	}
 	if storage == nil {
 		return errdefs.NilArg("storage")
 	}
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	s := syncer{
 		storage: storage,
 		client:  client,
+		events:  o.events,
 	}
 	if err := s.SyncNodeState(ctx); err != nil {
 		return err
 	}
 	return nil
 }
+
+func ReloadConfig(ctx context.Context, client Client, storage Storage) error {
+	if client == nil {
+		return errdefs.NilArg("client")
+	}
+	if storage == nil {
+		return errdefs.NilArg("storage")
+	}
+	s := syncer{
+		storage: storage,
+		client:  client,
+	}
+	if err := s.ReloadNodeConfig(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateConfig checks config on node, node configs are kept as is
+func ValidateConfig(ctx context.Context, client Client, cfg models.NodeXRayConfig) error {
+	if client == nil {
+		return errdefs.NilArg("client")
+	}
+	if err := client.ValidateConfig(ctx, cfg); err != nil {
+		return err
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package nodesync
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
@@ -12,6 +14,8 @@ import (
 type syncer struct {
 	storage Storage
 	client  Client
+	// optional
+	events Events
 }
 
 // sync node state between node (available via client) and uow.
@@ -62,7 +66,14 @@ func (s *syncer) SyncNodeState(ctx context.Context) (err error) {
 		fallbackTO := time.Second
 		fallbackCtx, fallbackCancel := context.WithTimeout(context.Background(), fallbackTO)
 		defer func() { fallbackCancel() }()
-		err = xerr.Join(err, s.markAsUnavailable(fallbackCtx))
+		markErr := s.markAsUnavailable(fallbackCtx)
+		if markErr == nil {
+			if s.events != nil {
+				s.events.NodeUnavailable(fallbackCtx, err)
+			}
+			err = xerr.Wrap(err, xerr.WithType(errdefs.ErrNodeUnavailable))
+		}
+		err = xerr.Join(err, markErr)
 	case target == models.NodeStatusRunning && curr == models.NodeStatusStopped:
 		err = s.startNode(ctx)
 	case target == models.NodeStatusStopped && curr == models.NodeStatusRunning:
@@ -75,9 +86,66 @@ func (s *syncer) SyncNodeState(ctx context.Context) (err error) {
 		return err
 	}
 
+	// node state was unknown, now it's running again
+	if prev == models.NodeStatusUnknown && target == models.NodeStatusRunning &&
+		s.events != nil {
+		s.events.NodeRecovered(ctx)
+	}
+
+	// push pending configs to running node. config push errors
+	// (invalid config most likely) don't mark node unavailable
+	if target == models.NodeStatusRunning {
+		if err = s.syncNodeConfig(ctx); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// reload node configs. node keeps its status and users,
+// only node settings (client config template) are updated.
+func (s *syncer) ReloadNodeConfig(ctx context.Context) error {
+	if s == nil || s.storage == nil || s.client == nil {
+		return errdefs.NilCall()
+	}
+
+	nodeSettings, err := s.client.Reload(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.storage.SetNodeSettings(ctx, nodeSettings); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *syncer) syncNodeConfig(ctx context.Context) error {
+	cfg, err := s.storage.GetPendingNodeConfig(ctx)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	nodeSettings, err := s.client.PushConfig(ctx, *cfg)
+	if errors.Is(err, errdefs.ErrInvaildPayload) {
+		// node keeps its configs, don't push rejected ones on every sync
+		return xerr.Join(err, s.storage.SetFailedNodeConfigVersion(ctx, cfg.Version))
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.storage.DoTx(ctx, func(ctx context.Context) error {
+		if err := s.storage.SetNodeSettings(ctx, nodeSettings); err != nil {
+			return err
+		}
+		if err := s.storage.SetCurrentNodeConfigVersion(ctx, cfg.Version); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
 func (s *syncer) fetchNodeStatus(ctx context.Context) (
 	curr, prev, target models.NodeStatus, err error,
 ) {
@@ -142,26 +210,39 @@ func (s *syncer) startNode(ctx context.Context) (err error) {
 	return nil
 }
 
-func (s *syncer) getUsers(ctx context.Context) (users []models.User, err error) {
-	return s.storage.ListUsers(ctx)
+func (s *syncer) getUsers(ctx context.Context) (users []models.UserSyncStatus, err error) {
+	return s.storage.ListUserSyncs(ctx)
 }
 
-func (s *syncer) getEnabledUsers(users []models.User) []models.UserProfile {
+// node is started with enabled users, their previous
+// identities kept during grace period and their devices
+func (s *syncer) getEnabledUsers(users []models.UserSyncStatus) []models.UserProfile {
 	enabled := make([]models.UserProfile, 0, len(users))
 	for _, u := range users {
-		if u.TargetStatus == models.UserStatusEnabled {
-			enabled = append(enabled, u.Profile)
+		if u.User.TargetStatus != models.UserStatusEnabled {
+			continue
+		}
+		profile := u.User.Profile
+		enabled = append(enabled, profile)
+		if u.TargetPrevVlessUUID != "" {
+			enabled = append(enabled, profile.PrevIdentity(u.TargetPrevVlessUUID))
+		}
+		for _, d := range u.TargetDevices {
+			enabled = append(enabled, profile.DeviceIdentity(d))
 		}
 	}
 	return enabled
 }
 
-func (s *syncer) getUsersPatch(users []models.User) []models.UserStatusPatch {
+func (s *syncer) getUsersPatch(users []models.UserSyncStatus) []models.UserStatusPatch {
 	patch := make([]models.UserStatusPatch, 0, len(users))
 	for _, u := range users {
 		patch = append(patch, models.UserStatusPatch{
-			UserID: u.Profile.ID,
-			Status: u.TargetStatus,
+			UserID:        u.User.Profile.ID,
+			Status:        u.User.TargetStatus,
+			VlessUUID:     u.User.Profile.VlessUUID,
+			PrevVlessUUID: u.TargetPrevVlessUUID,
+			Devices:       u.TargetDevices,
 		})
 	}
 	return patch
@@ -203,12 +284,20 @@ func (s *syncer) syncNodeUsers(ctx context.Context, updateNodeStatus bool) error
 		return nil
 	}
 
-	usersUpdate, prePatch, postPatch := s.buildUserUpdate(pending)
+	usersReset, usersUpdate, prePatch, postPatch := s.buildUserUpdate(pending)
 
 	if err := s.applyNodeStatePatch(ctx, prePatch); err != nil {
 		return err
 	}
 
+	// node keeps existing user on add of user with the same email,
+	// so outdated user identities are removed before update
+	if len(usersReset.Remove) != 0 {
+		if err := s.client.UpdateUsers(ctx, usersReset); err != nil {
+			return err
+		}
+	}
+
 	if err := s.client.UpdateUsers(ctx, usersUpdate); err != nil {
 		return err
 	}
@@ -224,8 +313,10 @@ func (s *syncer) getPendingSyncs(ctx context.Context) ([]models.UserSyncStatus,
 	return s.storage.FindPendingSyncs(ctx)
 }
 
+// build node users update. reset contains outdated user identities
+// (replaced by credentials rotation) to remove before update.
 func (s *syncer) buildUserUpdate(syncs []models.UserSyncStatus) (
-	update models.NodeUsersUpdate, prePatch, postPatch []models.UserStatusPatch,
+	reset, update models.NodeUsersUpdate, prePatch, postPatch []models.UserStatusPatch,
 ) {
 	prePatch = make([]models.UserStatusPatch, 0, len(syncs))
 	postPatch = make([]models.UserStatusPatch, 0, len(syncs))
@@ -233,24 +324,65 @@ func (s *syncer) buildUserUpdate(syncs []models.UserSyncStatus) (
 	update.Remove = make([]models.UserProfile, 0, len(syncs))
 
 	for _, u := range syncs {
+		profile := u.User.Profile
 		switch u.User.TargetStatus {
 		case models.UserStatusEnabled:
-			update.Add = append(update.Add, u.User.Profile)
+			update.Add = append(update.Add, profile)
+			if u.CurrentVlessUUID != "" && u.CurrentVlessUUID != profile.VlessUUID {
+				outdated := profile
+				outdated.VlessUUID = u.CurrentVlessUUID
+				reset.Remove = append(reset.Remove, outdated)
+			}
 		case models.UserStatusDisabled:
-			update.Remove = append(update.Remove, u.User.Profile)
+			update.Remove = append(update.Remove, profile)
+		}
+		if u.CurrentPrevVlessUUID != u.TargetPrevVlessUUID {
+			if u.CurrentPrevVlessUUID != "" {
+				reset.Remove = append(reset.Remove,
+					profile.PrevIdentity(u.CurrentPrevVlessUUID))
+			}
+			if u.TargetPrevVlessUUID != "" {
+				update.Add = append(update.Add,
+					profile.PrevIdentity(u.TargetPrevVlessUUID))
+			}
+		}
+		for _, d := range missingDevices(u.CurrentDevices, u.TargetDevices) {
+			reset.Remove = append(reset.Remove, profile.DeviceIdentity(d))
+		}
+		for _, d := range missingDevices(u.TargetDevices, u.CurrentDevices) {
+			update.Add = append(update.Add, profile.DeviceIdentity(d))
 		}
 		prePatch = append(prePatch, models.UserStatusPatch{
-			UserID: u.User.Profile.ID,
-			Status: models.UserStatusUnknown,
+			UserID:        profile.ID,
+			Status:        models.UserStatusUnknown,
+			VlessUUID:     u.CurrentVlessUUID,
+			PrevVlessUUID: u.CurrentPrevVlessUUID,
+			Devices:       u.CurrentDevices,
 		})
 		postPatch = append(postPatch, models.UserStatusPatch{
-			UserID: u.User.Profile.ID,
-			Status: u.User.TargetStatus,
+			UserID:        profile.ID,
+			Status:        u.User.TargetStatus,
+			VlessUUID:     profile.VlessUUID,
+			PrevVlessUUID: u.TargetPrevVlessUUID,
+			Devices:       u.TargetDevices,
 		})
 	}
 	return
 }
 
+// devices from src missing in dst
+func missingDevices(src, dst []models.UserDevice) []models.UserDevice {
+	var missing []models.UserDevice
+	for _, d := range src {
+		if !slices.ContainsFunc(dst, func(v models.UserDevice) bool {
+			return v.ID == d.ID && v.VlessUUID == d.VlessUUID
+		}) {
+			missing = append(missing, d)
+		}
+	}
+	return missing
+}
+
 func (s *syncer) applyNodeStatePatch(ctx context.Context,
 	patch []models.UserStatusPatch,
 ) error {
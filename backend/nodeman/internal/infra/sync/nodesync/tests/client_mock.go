@@ -5,6 +5,7 @@ import (
 	"math/rand/v2"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/nodesync"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
@@ -13,6 +14,11 @@ import (
 type ClientMock struct {
 	Status models.NodeStatus
 	Users  map[models.UserProfile]struct{}
+	Config models.NodeXRayConfig
+	// configs are rejected as invalid
+	RejectConfig bool
+	// config push attempts
+	ConfigPushes int
 }
 
 func NewClientMock() *ClientMock {
@@ -49,17 +55,57 @@ func (c *ClientMock) Stop(ctx context.Context) error {
 	return nil
 }
 
+func (c *ClientMock) Reload(ctx context.Context) (*models.NodeSettings, error) {
+	return &models.NodeSettings{}, nil
+}
+
+func (c *ClientMock) PushConfig(ctx context.Context,
+	cfg models.NodeXRayConfig,
+) (*models.NodeSettings, error) {
+	c.ConfigPushes++
+	if err := c.ValidateConfig(ctx, cfg); err != nil {
+		return nil, err
+	}
+	c.Config = cfg
+	return &models.NodeSettings{}, nil
+}
+
+func (c *ClientMock) ValidateConfig(ctx context.Context,
+	cfg models.NodeXRayConfig,
+) error {
+	if c.RejectConfig {
+		return errdefs.PayloadErr(xerr.New("invalid config"))
+	}
+	return nil
+}
+
 func (c *ClientMock) UpdateUsers(ctx context.Context,
 	upd models.NodeUsersUpdate,
 ) error {
 	if c.Status != models.NodeStatusRunning {
 		return xerr.New("node not running")
 	}
+	// like xray, users are identified by email: existing user
+	// is kept on add, user with any identity is removed
 	for _, u := range upd.Add {
-		c.Users[u] = struct{}{}
+		if c.findUser(u) == nil {
+			c.Users[u] = struct{}{}
+		}
 	}
 	for _, u := range upd.Remove {
-		delete(c.Users, u)
+		if existing := c.findUser(u); existing != nil {
+			delete(c.Users, *existing)
+		}
+	}
+	return nil
+}
+
+// find user with the same email (id and name)
+func (c *ClientMock) findUser(user models.UserProfile) *models.UserProfile {
+	for u := range c.Users {
+		if u.ID == user.ID && u.Name == user.Name {
+			return &u
+		}
 	}
 	return nil
 }
@@ -106,6 +152,31 @@ func (c *UnstableClientMock) Stop(ctx context.Context) error {
 	return c.BaseClient.Stop(ctx)
 }
 
+func (c *UnstableClientMock) Reload(ctx context.Context) (*models.NodeSettings, error) {
+	if c.rand.Float32() < c.Instability {
+		return nil, xerr.New("random client fail")
+	}
+	return c.BaseClient.Reload(ctx)
+}
+
+func (c *UnstableClientMock) PushConfig(ctx context.Context,
+	cfg models.NodeXRayConfig,
+) (*models.NodeSettings, error) {
+	if c.rand.Float32() < c.Instability {
+		return nil, xerr.New("random client fail")
+	}
+	return c.BaseClient.PushConfig(ctx, cfg)
+}
+
+func (c *UnstableClientMock) ValidateConfig(ctx context.Context,
+	cfg models.NodeXRayConfig,
+) error {
+	if c.rand.Float32() < c.Instability {
+		return xerr.New("random client fail")
+	}
+	return c.BaseClient.ValidateConfig(ctx, cfg)
+}
+
 func (c *UnstableClientMock) UpdateUsers(ctx context.Context,
 	upd models.NodeUsersUpdate,
 ) error {
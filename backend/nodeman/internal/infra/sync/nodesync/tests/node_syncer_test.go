@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/nodesync"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"github.com/stretchr/testify/require"
@@ -94,6 +95,148 @@ func TestNodeSync_UnstableStorage_UnstableNode(t *testing.T) {
 	}
 }
 
+func TestNodeSync_PushConfig(t *testing.T) {
+	client := NewClientMock()
+	storage := NewStorage(10)
+
+	// config pushed while node is stopped waits for node start
+	storage.targetStatus = models.NodeStatusStopped
+	storage.targetConfigVersion = 1
+	require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+	require.Equal(t, models.ConfigVersion(0), storage.currentConfigVersion)
+
+	storage.targetStatus = models.NodeStatusRunning
+	require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+	checkFullConsistency(t, client, storage)
+
+	// next config version
+	storage.targetConfigVersion = 2
+	require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+	checkFullConsistency(t, client, storage)
+
+	// rejected config is recorded and not pushed again
+	client.RejectConfig = true
+	client.ConfigPushes = 0
+	storage.targetConfigVersion = 3
+	require.Error(t, nodesync.SyncState(context.TODO(), client, storage))
+	require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+	require.Equal(t, 1, client.ConfigPushes)
+	require.Equal(t, models.ConfigVersion(3), storage.failedConfigVersion)
+	require.Equal(t, models.ConfigVersion(2), storage.currentConfigVersion)
+
+	// next pushed version is applied
+	client.RejectConfig = false
+	storage.targetConfigVersion = 4
+	require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+	checkFullConsistency(t, client, storage)
+}
+
+func TestNodeSync_RotateCredentials(t *testing.T) {
+	client := NewClientMock()
+	storage := NewStorage(3)
+	for i := range storage.users {
+		storage.users[i].TargetStatus = models.UserStatusEnabled
+	}
+	sync := func() {
+		require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+		checkFullConsistency(t, client, storage)
+	}
+	sync()
+
+	// old identity is replaced
+	old := storage.users[0].Profile
+	storage.RotateCredentials(0, false)
+	sync()
+	require.NotContains(t, client.Users, old)
+	require.Contains(t, client.Users, storage.users[0].Profile)
+
+	// old identity is kept during grace period
+	old = storage.users[1].Profile
+	storage.RotateCredentials(1, true)
+	sync()
+	require.Contains(t, client.Users, storage.users[1].Profile)
+	require.Contains(t, client.Users, old.PrevIdentity(old.VlessUUID))
+	require.Len(t, client.Users, 4)
+
+	// and removed after it
+	storage.usersPrevUUID[1] = ""
+	sync()
+	require.NotContains(t, client.Users, old.PrevIdentity(old.VlessUUID))
+	require.Len(t, client.Users, 3)
+}
+
+func TestNodeSync_RestartDuringRotation(t *testing.T) {
+	client := NewClientMock()
+	storage := NewStorage(3)
+	for i := range storage.users {
+		storage.users[i].TargetStatus = models.UserStatusEnabled
+	}
+	sync := func() {
+		require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+		checkFullConsistency(t, client, storage)
+	}
+	sync()
+
+	old := storage.users[1].Profile
+	storage.RotateCredentials(1, true)
+	storage.AddDevice(2)
+	sync()
+
+	// node restarted during grace period keeps previous identity
+	// and user devices
+	storage.targetStatus = models.NodeStatusStopped
+	sync()
+	storage.targetStatus = models.NodeStatusRunning
+	sync()
+	require.Contains(t, client.Users, storage.users[1].Profile)
+	require.Contains(t, client.Users, old.PrevIdentity(old.VlessUUID))
+	require.Contains(t, client.Users,
+		storage.users[2].Profile.DeviceIdentity(storage.usersDevices[2][0]))
+	require.Len(t, client.Users, 5)
+
+	// and nothing is left to sync after start
+	pending, err := storage.FindPendingSyncs(context.TODO())
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestNodeSync_Devices(t *testing.T) {
+	client := NewClientMock()
+	storage := NewStorage(2)
+	for i := range storage.users {
+		storage.users[i].TargetStatus = models.UserStatusEnabled
+	}
+	sync := func() {
+		require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+		checkFullConsistency(t, client, storage)
+	}
+	sync()
+
+	// devices are added along with user
+	storage.AddDevice(0)
+	storage.AddDevice(0)
+	sync()
+	require.Len(t, client.Users, 4)
+
+	// deleted device is removed, others are kept
+	removed := storage.usersDevices[0][0]
+	storage.DeleteDevice(0, 0)
+	sync()
+	_, ok := client.Users[storage.users[0].Profile.DeviceIdentity(removed)]
+	require.False(t, ok)
+	require.Len(t, client.Users, 3)
+
+	// disabled user devices are removed
+	storage.users[0].TargetStatus = models.UserStatusDisabled
+	sync()
+	require.Len(t, client.Users, 1)
+
+	// and restored on enable
+	storage.users[0].TargetStatus = models.UserStatusEnabled
+	sync()
+	require.Len(t, client.Users, 3)
+}
+
 func checkFullConsistency(t *testing.T, c *ClientMock, s *storage) {
 	// check state is ok. only node required to be running matters
 	if s.targetStatus != models.NodeStatusRunning {
@@ -109,6 +252,36 @@ func checkFullConsistency(t *testing.T, c *ClientMock, s *storage) {
 		require.Equal(t, u.TargetStatus, s.currentUserStatus[i],
 			"user %s (%d) check", u.Profile.Name, u.Profile.ID)
 	}
+
+	// node users are exactly enabled users, their previous
+	// identities and devices
+	nodeUsers := 0
+	for i, u := range s.users {
+		if u.TargetStatus != models.UserStatusEnabled {
+			continue
+		}
+		require.Equal(t, u.Profile.VlessUUID, s.currentUserUUID[i],
+			"stored user %s identity check", u.Profile.Name)
+		_, ok := c.Users[u.Profile]
+		require.True(t, ok, "user %s identity check", u.Profile.Name)
+		nodeUsers++
+		if prev := s.currentUserPrevUUID[i]; prev != "" {
+			_, ok = c.Users[u.Profile.PrevIdentity(prev)]
+			require.True(t, ok, "user %s previous identity check", u.Profile.Name)
+			nodeUsers++
+		}
+		for _, d := range s.currentUserDevices[i] {
+			_, ok = c.Users[u.Profile.DeviceIdentity(d)]
+			require.True(t, ok, "user %s device %d check", u.Profile.Name, d.ID)
+			nodeUsers++
+		}
+	}
+	require.Len(t, c.Users, nodeUsers, "node users check")
+
+	require.Equal(t, s.targetConfigVersion, s.currentConfigVersion,
+		"stored config version check")
+	require.Equal(t, s.targetConfigVersion, c.Config.Version,
+		"node config version check")
 }
 
 func checkStorageConsistency(t *testing.T, c *ClientMock, s *storage) {
@@ -127,3 +300,54 @@ func checkStorageConsistency(t *testing.T, c *ClientMock, s *storage) {
 		}
 	}
 }
+
+type eventsMock struct {
+	events []string
+}
+
+func (e *eventsMock) NodeUnavailable(context.Context, error) {
+	e.events = append(e.events, "unavailable")
+}
+
+func (e *eventsMock) NodeRecovered(context.Context) {
+	e.events = append(e.events, "recovered")
+}
+
+func TestNodeSync_Events(t *testing.T) {
+	client := NewUnstableClientMock()
+	storage := NewStorage(10)
+	events := &eventsMock{}
+	sync := func() error {
+		return nodesync.SyncState(context.TODO(), client, storage,
+			nodesync.WithEvents(events))
+	}
+
+	// node starts in unknown state
+	require.NoError(t, sync())
+	require.Equal(t, []string{"recovered"}, events.events)
+	events.events = nil
+
+	// unavailable is reported once
+	client.Instability = 1
+	require.Error(t, sync())
+	require.Error(t, sync())
+	require.Equal(t, []string{"unavailable"}, events.events)
+
+	client.Instability = 0
+	require.NoError(t, sync())
+	require.NoError(t, sync())
+	require.Equal(t, []string{"unavailable", "recovered"}, events.events)
+	checkFullConsistency(t, client.BaseClient, storage)
+}
+
+func TestNodeSync_Unavailable(t *testing.T) {
+	client := NewUnstableClientMock()
+	storage := NewStorage(10)
+	require.NoError(t, nodesync.SyncState(context.TODO(), client, storage))
+
+	// unavailable node error is typed without events too
+	client.Instability = 1
+	err := nodesync.SyncState(context.TODO(), client, storage)
+	require.ErrorIs(t, err, errdefs.ErrNodeUnavailable)
+	require.Equal(t, models.NodeStatusUnknown, storage.currentStatus)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand/v2"
+	"slices"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/nodesync"
@@ -15,6 +16,18 @@ type storage struct {
 	targetStatus      models.NodeStatus
 	users             []models.User
 	currentUserStatus []models.UserStatus
+	// user identities, previous ones are kept during grace period
+	usersPrevUUID       []string
+	currentUserUUID     []string
+	currentUserPrevUUID []string
+	// user devices identities
+	usersDevices       [][]models.UserDevice
+	currentUserDevices [][]models.UserDevice
+	nextDeviceID       models.DeviceID
+
+	currentConfigVersion models.ConfigVersion
+	targetConfigVersion  models.ConfigVersion
+	failedConfigVersion  models.ConfigVersion
 
 	rand        *rand.Rand
 	Instability float32
@@ -30,8 +43,9 @@ func NewStorage(nUsers int) *storage {
 	for i := range nUsers {
 		u := models.User{
 			Profile: models.UserProfile{
-				ID:   models.UserID(i),
-				Name: fmt.Sprintf("user %d", i),
+				ID:        models.UserID(i),
+				Name:      fmt.Sprintf("user %d", i),
+				VlessUUID: fmt.Sprintf("uuid %d", i),
 			},
 			TargetStatus: models.UserStatusDisabled,
 		}
@@ -43,11 +57,17 @@ func NewStorage(nUsers int) *storage {
 	}
 
 	return &storage{
-		currentStatus:     models.NodeStatusUnknown,
-		targetStatus:      models.NodeStatusRunning,
-		users:             users,
-		currentUserStatus: usersStatus,
-		rand:              rand.New(rand.NewPCG(0, 0)), // #nosec
+		currentStatus:       models.NodeStatusUnknown,
+		targetStatus:        models.NodeStatusRunning,
+		users:               users,
+		currentUserStatus:   usersStatus,
+		usersPrevUUID:       make([]string, nUsers),
+		currentUserUUID:     make([]string, nUsers),
+		currentUserPrevUUID: make([]string, nUsers),
+		usersDevices:        make([][]models.UserDevice, nUsers),
+		currentUserDevices:  make([][]models.UserDevice, nUsers),
+		nextDeviceID:        1,
+		rand:                rand.New(rand.NewPCG(0, 0)), // #nosec
 	}
 }
 
@@ -70,12 +90,27 @@ func (s *storage) FindPendingSyncs(ctx context.Context) (
 	pending = make([]models.UserSyncStatus, 0, len(s.users))
 	err = s.do(ctx, func(s *storage) error {
 		for i, u := range s.users {
-			if u.TargetStatus == s.currentUserStatus[i] {
+			enabled := u.TargetStatus == models.UserStatusEnabled
+			targetPrevUUID := ""
+			var targetDevices []models.UserDevice
+			if enabled {
+				targetPrevUUID = s.usersPrevUUID[i]
+				targetDevices = s.usersDevices[i]
+			}
+			if u.TargetStatus == s.currentUserStatus[i] &&
+				(!enabled || u.Profile.VlessUUID == s.currentUserUUID[i]) &&
+				targetPrevUUID == s.currentUserPrevUUID[i] &&
+				sameDevices(targetDevices, s.currentUserDevices[i]) {
 				continue
 			}
 			pending = append(pending, models.UserSyncStatus{
-				User:          u,
-				CurrentStatus: s.currentUserStatus[i],
+				User:                 u,
+				CurrentStatus:        s.currentUserStatus[i],
+				CurrentVlessUUID:     s.currentUserUUID[i],
+				TargetPrevVlessUUID:  targetPrevUUID,
+				CurrentPrevVlessUUID: s.currentUserPrevUUID[i],
+				TargetDevices:        targetDevices,
+				CurrentDevices:       s.currentUserDevices[i],
 			})
 		}
 		return nil
@@ -84,11 +119,41 @@ func (s *storage) FindPendingSyncs(ctx context.Context) (
 	return
 }
 
-func (s *storage) ListUsers(ctx context.Context) (
-	users []models.User, err error,
+func (s *storage) GetPendingNodeConfig(ctx context.Context) (
+	cfg *models.NodeXRayConfig, err error,
+) {
+	err = s.do(ctx, func(s *storage) error {
+		if s.currentConfigVersion == s.targetConfigVersion ||
+			s.failedConfigVersion == s.targetConfigVersion {
+			return nil
+		}
+		cfg = &models.NodeXRayConfig{
+			Version:      s.targetConfigVersion,
+			ServerConfig: fmt.Sprintf("server config %d", s.targetConfigVersion),
+			ClientConfig: fmt.Sprintf("client config %d", s.targetConfigVersion),
+		}
+		return nil
+	})
+	return
+}
+
+func (s *storage) ListUserSyncs(ctx context.Context) (
+	users []models.UserSyncStatus, err error,
 ) {
 	err = s.do(ctx, func(s *storage) error {
-		users = append(users, s.users...)
+		for i, u := range s.users {
+			targetPrevUUID := ""
+			var targetDevices []models.UserDevice
+			if u.TargetStatus == models.UserStatusEnabled {
+				targetPrevUUID = s.usersPrevUUID[i]
+				targetDevices = s.usersDevices[i]
+			}
+			users = append(users, models.UserSyncStatus{
+				User:                u,
+				TargetPrevVlessUUID: targetPrevUUID,
+				TargetDevices:       targetDevices,
+			})
+		}
 		return nil
 	})
 	return
@@ -100,28 +165,49 @@ func (s *storage) SetNodeUsers(ctx context.Context, patch []models.UserStatusPat
 	return s.do(ctx, func(s *storage) error {
 		for i := range s.currentUserStatus {
 			s.currentUserStatus[i] = models.UserStatusDisabled
+			s.currentUserUUID[i] = ""
+			s.currentUserPrevUUID[i] = ""
+			s.currentUserDevices[i] = nil
 		}
-		for _, p := range patch {
-			s.currentUserStatus[p.UserID] = p.Status
-		}
-
+		s.applyUsersPatch(patch)
 		return nil
 	})
 }
 
 func (s *storage) UpdateNodeUsers(ctx context.Context, patch []models.UserStatusPatch) error {
 	return s.do(ctx, func(s *storage) error {
-		for _, u := range patch {
-			s.currentUserStatus[u.UserID] = u.Status
-		}
+		s.applyUsersPatch(patch)
 		return nil
 	})
 }
 
+func (s *storage) applyUsersPatch(patch []models.UserStatusPatch) {
+	for _, p := range patch {
+		s.currentUserStatus[p.UserID] = p.Status
+		s.currentUserUUID[p.UserID] = p.VlessUUID
+		s.currentUserPrevUUID[p.UserID] = p.PrevVlessUUID
+		s.currentUserDevices[p.UserID] = p.Devices
+	}
+}
+
 func (s *storage) SetNodeSettings(ctx context.Context, _ *models.NodeSettings) error {
 	return nil
 }
 
+func (s *storage) SetCurrentNodeConfigVersion(ctx context.Context, v models.ConfigVersion) error {
+	return s.do(ctx, func(s *storage) error {
+		s.currentConfigVersion = v
+		return nil
+	})
+}
+
+func (s *storage) SetFailedNodeConfigVersion(ctx context.Context, v models.ConfigVersion) error {
+	return s.do(ctx, func(s *storage) error {
+		s.failedConfigVersion = v
+		return nil
+	})
+}
+
 func (s *storage) SetCurrentNodeStatus(ctx context.Context, st models.NodeStatus) error {
 	return s.do(ctx, func(s *storage) error {
 		s.currentStatus = st
@@ -142,17 +228,68 @@ func (s *storage) RandomExternalOperation() {
 		u := s.users[userIdx]
 		u.TargetStatus = (models.UserStatusEnabled + models.UserStatusDisabled) - u.TargetStatus
 		s.users[userIdx] = u
+	case s.rand.IntN(2) == 0:
+		// rotate user credentials, sometimes with grace period
+		userIdx := s.rand.IntN(len(s.users))
+		s.RotateCredentials(userIdx, s.rand.IntN(2) == 0)
+	case s.rand.IntN(2) == 0:
+		// add or delete user device
+		userIdx := s.rand.IntN(len(s.users))
+		if n := len(s.usersDevices[userIdx]); n != 0 && s.rand.IntN(2) == 0 {
+			s.DeleteDevice(userIdx, s.rand.IntN(n))
+		} else {
+			s.AddDevice(userIdx)
+		}
 	default:
 		// add new user
 		s.users = append(s.users, models.User{
 			Profile: models.UserProfile{
-				ID:   models.UserID(len(s.users)),
-				Name: fmt.Sprintf("user %d", len(s.users)),
+				ID:        models.UserID(len(s.users)),
+				Name:      fmt.Sprintf("user %d", len(s.users)),
+				VlessUUID: fmt.Sprintf("uuid %d", len(s.users)),
 			},
 			TargetStatus: models.UserStatusEnabled,
 		})
 		s.currentUserStatus = append(s.currentUserStatus, models.UserStatusUnknown)
+		s.usersPrevUUID = append(s.usersPrevUUID, "")
+		s.currentUserUUID = append(s.currentUserUUID, "")
+		s.currentUserPrevUUID = append(s.currentUserPrevUUID, "")
+		s.usersDevices = append(s.usersDevices, nil)
+		s.currentUserDevices = append(s.currentUserDevices, nil)
+	}
+}
+
+func (s *storage) AddDevice(userIdx int) {
+	id := s.nextDeviceID
+	s.nextDeviceID++
+	s.usersDevices[userIdx] = append(slices.Clone(s.usersDevices[userIdx]),
+		models.UserDevice{
+			ID:        id,
+			UserID:    models.UserID(userIdx),
+			VlessUUID: fmt.Sprintf("device uuid %d", id),
+		})
+}
+
+func (s *storage) DeleteDevice(userIdx int, deviceIdx int) {
+	s.usersDevices[userIdx] = slices.Delete(
+		slices.Clone(s.usersDevices[userIdx]), deviceIdx, deviceIdx+1)
+}
+
+func sameDevices(a, b []models.UserDevice) bool {
+	return slices.EqualFunc(a, b, func(a, b models.UserDevice) bool {
+		return a.ID == b.ID && a.VlessUUID == b.VlessUUID
+	})
+}
+
+// replace user vless uuid, keep previous one if grace is set
+func (s *storage) RotateCredentials(userIdx int, grace bool) {
+	u := s.users[userIdx]
+	s.usersPrevUUID[userIdx] = ""
+	if grace {
+		s.usersPrevUUID[userIdx] = u.Profile.VlessUUID
 	}
+	u.Profile.VlessUUID += "'"
+	s.users[userIdx] = u
 }
 
 // //////////////////////////////////////////////////////////////////////////////
@@ -183,6 +320,15 @@ func copyStorage(from *storage, to *storage) {
 	to.targetStatus = from.targetStatus
 	to.users = append([]models.User{}, from.users...)
 	to.currentUserStatus = append([]models.UserStatus{}, from.currentUserStatus...)
+	to.usersPrevUUID = append([]string{}, from.usersPrevUUID...)
+	to.currentUserUUID = append([]string{}, from.currentUserUUID...)
+	to.currentUserPrevUUID = append([]string{}, from.currentUserPrevUUID...)
+	to.usersDevices = append([][]models.UserDevice{}, from.usersDevices...)
+	to.currentUserDevices = append([][]models.UserDevice{}, from.currentUserDevices...)
+	to.nextDeviceID = from.nextDeviceID
+	to.currentConfigVersion = from.currentConfigVersion
+	to.targetConfigVersion = from.targetConfigVersion
+	to.failedConfigVersion = from.failedConfigVersion
 	to.rand = rand.New(rand.NewPCG(0, 0)) // #nosec
 	to.Instability = from.Instability
 }
@@ -0,0 +1,69 @@
+package poolsync
+
+import (
+	"context"
+	"sync"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/poolop"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/nodesync"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Notifier = poolop.Notifier
+
+// nodes reported as unavailable, only they are
+// reported as recovered when running again
+type unavailableNodes struct {
+	nodes map[models.NodeID]bool
+	mu    sync.Mutex
+}
+
+func newUnavailableNodes() *unavailableNodes {
+	return &unavailableNodes{nodes: make(map[models.NodeID]bool)}
+}
+
+// set node availability, returns if it was changed
+func (u *unavailableNodes) set(id models.NodeID, unavailable bool) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.nodes[id] == unavailable {
+		return false
+	}
+	if unavailable {
+		u.nodes[id] = true
+	} else {
+		delete(u.nodes, id)
+	}
+	return true
+}
+
+// node availability events of single node
+type nodeEvents struct {
+	notifier    Notifier
+	unavailable *unavailableNodes
+	nodeID      models.NodeID
+}
+
+var _ nodesync.Events = (*nodeEvents)(nil)
+
+func (e *nodeEvents) NodeUnavailable(ctx context.Context, cause error) {
+	if !e.unavailable.set(e.nodeID, true) {
+		return
+	}
+	e.notifier.Notify(ctx, models.WebhookEvent{
+		Type:   models.WebhookEventNodeUnavailable,
+		NodeID: e.nodeID,
+		Error:  cause.Error(),
+	})
+}
+
+// new nodes and nodes failed to start are not recovered
+func (e *nodeEvents) NodeRecovered(ctx context.Context) {
+	if !e.unavailable.set(e.nodeID, false) {
+		return
+	}
+	e.notifier.Notify(ctx, models.WebhookEvent{
+		Type:   models.WebhookEventNodeRecovered,
+		NodeID: e.nodeID,
+	})
+}
@@ -0,0 +1,37 @@
+package poolsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierMock struct {
+	events []models.WebhookEventType
+}
+
+func (n *notifierMock) Notify(_ context.Context, e models.WebhookEvent) {
+	n.events = append(n.events, e.Type)
+}
+
+func TestNodeEvents(t *testing.T) {
+	n := notifierMock{}
+	e := nodeEvents{notifier: &n, unavailable: newUnavailableNodes(), nodeID: 1}
+
+	// new node first sync
+	e.NodeRecovered(t.Context())
+	require.Empty(t, n.events)
+
+	e.NodeUnavailable(t.Context(), xerr.New("connection refused"))
+	e.NodeUnavailable(t.Context(), xerr.New("connection refused"))
+	e.NodeRecovered(t.Context())
+	// retried sync of recovered node
+	e.NodeRecovered(t.Context())
+	require.Equal(t, []models.WebhookEventType{
+		models.WebhookEventNodeUnavailable,
+		models.WebhookEventNodeRecovered,
+	}, n.events)
+}
@@ -32,10 +32,24 @@ func (n *nodeStorage) GetNodeStatus(ctx context.Context) (
 	return
 }
 
-func (n *nodeStorage) ListUsers(ctx context.Context) (
-	[]models.User, error,
+func (n *nodeStorage) GetPendingNodeConfig(ctx context.Context) (
+	*models.NodeXRayConfig, error,
 ) {
-	return n.base.ListUsers(ctx)
+	node, err := n.base.GetNode(ctx, n.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node.TargetConfigVersion == node.CurrentConfigVersion ||
+		node.TargetConfigVersion == node.FailedConfigVersion {
+		return nil, nil
+	}
+	return n.base.GetNodeConfig(ctx, n.nodeID, node.TargetConfigVersion)
+}
+
+func (n *nodeStorage) ListUserSyncs(ctx context.Context) (
+	[]models.UserSyncStatus, error,
+) {
+	return n.base.ListNodeUserSyncs(ctx, n.nodeID)
 }
 
 func (n *nodeStorage) SetNodeSettings(ctx context.Context,
@@ -50,6 +64,18 @@ func (n *nodeStorage) SetCurrentNodeStatus(ctx context.Context,
 	return n.base.SetCurrentNodeStatus(ctx, n.nodeID, s)
 }
 
+func (n *nodeStorage) SetCurrentNodeConfigVersion(ctx context.Context,
+	v models.ConfigVersion,
+) error {
+	return n.base.SetCurrentNodeConfigVersion(ctx, n.nodeID, v)
+}
+
+func (n *nodeStorage) SetFailedNodeConfigVersion(ctx context.Context,
+	v models.ConfigVersion,
+) error {
+	return n.base.SetFailedNodeConfigVersion(ctx, n.nodeID, v)
+}
+
 func (n *nodeStorage) SetNodeUsers(ctx context.Context,
 	patch []models.UserStatusPatch,
 ) error {
@@ -13,6 +13,9 @@ import (
 type nodeOp struct {
 	storage Storage
 	client  Client
+	// optional
+	notifier    Notifier
+	unavailable *unavailableNodes
 }
 
 var _ poolop.NodeOp = (*nodeOp)(nil)
@@ -26,7 +29,38 @@ func (op *nodeOp) Exec(ctx context.Context, node models.Node, log *zap.Logger) e
 	if err != nil {
 		return err
 	}
-	if err := nodesync.SyncState(ctx, nodeClient, nodeStorage); err != nil {
+	var opts []nodesync.Option
+	if op.notifier != nil {
+		opts = append(opts, nodesync.WithEvents(&nodeEvents{
+			notifier:    op.notifier,
+			unavailable: op.unavailable,
+			nodeID:      node.ID,
+		}))
+	}
+	if err := nodesync.SyncState(ctx, nodeClient, nodeStorage, opts...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// node config reload op impl
+type nodeReloadOp struct {
+	storage Storage
+	client  Client
+}
+
+var _ poolop.NodeOp = (*nodeReloadOp)(nil)
+
+func (op *nodeReloadOp) Exec(ctx context.Context, node models.Node, log *zap.Logger) error {
+	nodeStorage := &nodeStorage{
+		base:   op.storage,
+		nodeID: node.ID,
+	}
+	nodeClient, err := op.client.GetNodeClient(node.Config.ConnectionInfo)
+	if err != nil {
+		return err
+	}
+	if err := nodesync.ReloadConfig(ctx, nodeClient, nodeStorage); err != nil {
 		return err
 	}
 	return nil
@@ -10,7 +10,10 @@ import (
 type TxFn = func(context.Context) error
 
 type UsersStorage interface {
-	ListUsers(ctx context.Context) ([]models.User, error)
+	// target state of all node users,
+	// users not entitled to node are disabled
+	ListNodeUserSyncs(ctx context.Context, id models.NodeID) (
+		[]models.UserSyncStatus, error)
 }
 
 type StatesStorage interface {
@@ -26,6 +29,15 @@ type StatesStorage interface {
 		id models.NodeID) error
 }
 
+type ConfigStorage interface {
+	GetNodeConfig(ctx context.Context, id models.NodeID,
+		v models.ConfigVersion) (*models.NodeXRayConfig, error)
+	SetCurrentNodeConfigVersion(ctx context.Context, id models.NodeID,
+		v models.ConfigVersion) error
+	SetFailedNodeConfigVersion(ctx context.Context, id models.NodeID,
+		v models.ConfigVersion) error
+}
+
 type SyncsStorage interface {
 	FindPendingSyncs(ctx context.Context, id models.NodeID) (
 		[]models.UserSyncStatus, error)
@@ -41,6 +53,7 @@ type Storage interface {
 	UsersStorage
 	StatesStorage
 	SyncsStorage
+	ConfigStorage
 	// call multiple operations as tx
 	DoTx(ctx context.Context, fn TxFn) error
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/poolop"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/sync/nodesync"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/syncman"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/service/nodes"
@@ -13,14 +14,37 @@ import (
 )
 
 type Syncer struct {
-	op *poolop.PoolOp
+	op       *poolop.PoolOp
+	reloadOp *poolop.PoolOp
+	client   Client
+	storage  Storage
 }
 
 var _ users.Syncer = (*Syncer)(nil)
 var _ nodes.Syncer = (*Syncer)(nil)
 var _ syncman.PoolSyncer = (*Syncer)(nil)
 
-func New(client Client, storage Storage, log *zap.Logger) (*Syncer, error) {
+type options struct {
+	metrics  poolop.Metrics
+	notifier Notifier
+}
+
+type Option func(o *options)
+
+func WithMetrics(m poolop.Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithNotifier reports node availability changes and sync errors
+func WithNotifier(n Notifier) Option {
+	return func(o *options) {
+		o.notifier = n
+	}
+}
+
+func New(client Client, storage Storage, log *zap.Logger, opts ...Option) (*Syncer, error) {
 	if client == nil {
 		return nil, errdefs.NilArg("client")
 	}
@@ -30,25 +54,55 @@ func New(client Client, storage Storage, log *zap.Logger) (*Syncer, error) {
 	if log == nil {
 		return nil, errdefs.NilArg("log")
 	}
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	op, err := poolop.New(
 		storage,
-		&nodeOp{storage: storage, client: client},
+		&nodeOp{
+			storage:     storage,
+			client:      client,
+			notifier:    o.notifier,
+			unavailable: newUnavailableNodes(),
+		},
 		log,
+		poolop.WithMetrics("sync", o.metrics),
+		poolop.WithNotifier(o.notifier),
 	)
 	if err != nil {
 		return nil, err
 	}
+	reloadOp, err := poolop.New(
+		storage,
+		&nodeReloadOp{storage: storage, client: client},
+		log,
+		poolop.WithMetrics("reload", o.metrics),
+		poolop.WithNotifier(o.notifier),
+	)
+	if err != nil {
+		op.Close()
+		return nil, err
+	}
 	return &Syncer{
-		op: op,
+		op:       op,
+		reloadOp: reloadOp,
+		client:   client,
+		storage:  storage,
 	}, nil
 }
 
 func (s *Syncer) Close() {
-	if s == nil || s.op == nil {
+	if s == nil {
 		return
 	}
-	s.op.Close()
+	if s.op != nil {
+		s.op.Close()
+	}
+	if s.reloadOp != nil {
+		s.reloadOp.Close()
+	}
 }
 
 func (s *Syncer) SyncPoolState(ctx context.Context) (
@@ -62,3 +116,24 @@ func (s *Syncer) SyncNodeState(ctx context.Context,
 ) error {
 	return s.op.ExecNode(ctx, id)
 }
+
+func (s *Syncer) ReloadNodeConfig(ctx context.Context,
+	id models.NodeID,
+) error {
+	return s.reloadOp.ExecNode(ctx, id)
+}
+
+// ValidateNodeConfig checks config on node without applying it
+func (s *Syncer) ValidateNodeConfig(ctx context.Context,
+	id models.NodeID, cfg models.NodeXRayConfig,
+) error {
+	node, err := s.storage.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+	nodeClient, err := s.client.GetNodeClient(node.Config.ConnectionInfo)
+	if err != nil {
+		return err
+	}
+	return nodesync.ValidateConfig(ctx, nodeClient, cfg)
+}
@@ -5,10 +5,14 @@ import (
 	"text/template"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/common/xraysecret"
 )
 
 func RenderTemplate(tmpl string, data any) ([]byte, error) {
-	t, err := template.New("inline").Parse(tmpl)
+	t, err := template.New("inline").
+		Funcs(xraysecret.TemplateFuncs()).
+		Option("missingkey=error").
+		Parse(tmpl)
 	if err != nil {
 		return nil, xerr.WrapWithStack(err)
 	}
@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
+// sealed secrets are prefixed to tell them from
+// plaintext ones stored before encryption was added
+const sealedPrefix = "enc1:"
+
+// encrypts totp secrets stored in db, aes-gcm
+// with key derived from configured passphrase
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func NewCipher(passphrase string) (*Cipher, error) {
+	if passphrase == "" {
+		return nil, xerr.New("empty totp cipher passphrase")
+	}
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+func (c *Cipher) Seal(secret string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", xerr.WrapWithStack(err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// plaintext secrets are returned as is
+func (c *Cipher) Open(stored string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return stored, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", xerr.WrapWithStack(err)
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", xerr.New("sealed totp secret too short")
+	}
+	secret, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", xerr.WrapWithInfo(err, "open totp secret")
+	}
+	return string(secret), nil
+}
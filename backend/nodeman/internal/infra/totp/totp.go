@@ -0,0 +1,110 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
+// rfc 6238 defaults, supported by all authenticator apps
+const (
+	period     = 30 * time.Second
+	digits     = 6
+	secretSize = 20
+	// accepted clock drift in periods
+	skew = 1
+)
+
+const (
+	recoveryCodeSize  = 10
+	RecoveryCodeCount = 10
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generate new base32 encoded shared secret
+func NewSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", xerr.WrapWithStack(err)
+	}
+	return b32.EncodeToString(b), nil
+}
+
+// otpauth uri for authenticator app, usually shown as qr code
+func ProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(digits))
+	v.Set("period", fmt.Sprint(int(period.Seconds())))
+	return "otpauth://totp/" + label + "?" + v.Encode()
+}
+
+// check code for the given time, codes of adjacent periods are
+// accepted too to tolerate clock drift. matched time step is returned,
+// caller rejects steps not greater than last used one to prevent replay
+func Validate(secret, code string, now time.Time) (int64, bool) {
+	key, err := b32.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+	step := now.Unix() / int64(period.Seconds())
+	for d := int64(-skew); d <= skew; d++ {
+		expected := generate(key, uint64(step+d))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step + d, true
+		}
+	}
+	return 0, false
+}
+
+func generate(key []byte, counter uint64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, rfc 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+// generate one-time recovery codes and their hashes to store
+func NewRecoveryCodes() ([]string, [][]byte, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([][]byte, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, xerr.WrapWithStack(err)
+		}
+		codes[i] = strings.ToLower(b32.EncodeToString(b))[:recoveryCodeSize]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// codes are random, so plain sha256 is enough
+func HashRecoveryCode(code string) []byte {
+	h := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return h[:]
+}
@@ -0,0 +1,98 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	// rfc 6238 sha1 test vectors, truncated to 6 digits
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.code, generate(key, uint64(tt.unix/30)))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).
+		EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	valid := func(secret, code string, now time.Time) bool {
+		_, ok := Validate(secret, code, now)
+		return ok
+	}
+
+	step, ok := Validate(secret, "081804", now)
+	require.True(t, ok)
+	require.Equal(t, int64(1111111109/30), step)
+	// clock drift, matched step is the code one
+	step, ok = Validate(secret, "081804", now.Add(30*time.Second))
+	require.True(t, ok)
+	require.Equal(t, int64(1111111109/30), step)
+	require.False(t, valid(secret, "081804", now.Add(5*time.Minute)))
+	require.False(t, valid(secret, "000000", now))
+	require.False(t, valid(secret, "81804", now))
+	require.False(t, valid("not base32!", "081804", now))
+
+	generated, err := NewSecret()
+	require.NoError(t, err)
+	require.False(t, valid(generated, "081804", now))
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("xrayman", "admin", "SECRET")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/xrayman:admin?"))
+	require.Contains(t, uri, "secret=SECRET")
+	require.Contains(t, uri, "issuer=xrayman")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+	require.Len(t, hashes, RecoveryCodeCount)
+	for i, code := range codes {
+		require.Len(t, code, recoveryCodeSize)
+		require.Equal(t, hashes[i], HashRecoveryCode(code))
+		require.Equal(t, hashes[i], HashRecoveryCode(" "+strings.ToUpper(code)))
+	}
+}
+
+func TestCipher(t *testing.T) {
+	c, err := NewCipher("passphrase")
+	require.NoError(t, err)
+
+	sealed, err := c.Seal("SECRET")
+	require.NoError(t, err)
+	require.NotContains(t, sealed, "SECRET")
+	secret, err := c.Open(sealed)
+	require.NoError(t, err)
+	require.Equal(t, "SECRET", secret)
+
+	// stored before encryption
+	secret, err = c.Open("PLAIN")
+	require.NoError(t, err)
+	require.Equal(t, "PLAIN", secret)
+
+	other, err := NewCipher("other")
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	require.Error(t, err)
+
+	_, err = NewCipher("")
+	require.Error(t, err)
+}
@@ -0,0 +1,188 @@
+// Package usercsv reads users for bulk import and writes users export as CSV.
+// Columns are matched by header names, so export can be imported as is:
+// unknown columns are ignored on read.
+package usercsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+const (
+	colID                = "id"
+	colName              = "name"
+	colDisplayName       = "display_name"
+	colVlessUUID         = "vless_uuid"
+	colSubToken          = "sub_token"
+	colStatus            = "status"
+	colQuotaLimit        = "quota_limit"
+	colQuotaPeriod       = "quota_period"
+	colExpiresAt         = "expires_at"
+	colIPLimit           = "ip_limit"
+	colEmail             = "email"
+	colUploadTotal       = "upload_total"
+	colDownloadTotal     = "download_total"
+	colUploadLastMonth   = "upload_last_month"
+	colDownloadLastMonth = "download_last_month"
+	colUploadPeriod      = "upload_period"
+	colDownloadPeriod    = "download_period"
+)
+
+var exportHeader = []string{
+	colID, colName, colDisplayName, colVlessUUID, colSubToken,
+	colStatus, colQuotaLimit, colQuotaPeriod, colExpiresAt, colIPLimit, colEmail,
+	colUploadTotal, colDownloadTotal, colUploadLastMonth, colDownloadLastMonth,
+	colUploadPeriod, colDownloadPeriod,
+}
+
+var statusNames = map[models.UserStatus]string{
+	models.UserStatusEnabled:  "enabled",
+	models.UserStatusDisabled: "disabled",
+}
+
+var periodNames = map[models.TrafficQuotaPeriod]string{
+	models.TrafficQuotaPeriodOneOff:  "one_off",
+	models.TrafficQuotaPeriodMonthly: "monthly",
+}
+
+// Read parses users to import. header row is required,
+// empty values mean defaults, only display name is required
+func Read(r io.Reader) ([]models.ImportUser, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, xerr.New("empty csv, header row is required")
+	}
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols[colDisplayName]; !ok {
+		return nil, xerr.Newf("no %s column in csv header", colDisplayName)
+	}
+
+	var users []models.ImportUser
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return users, nil
+		}
+		if err != nil {
+			return nil, xerr.WrapWithStack(err)
+		}
+		line, _ := cr.FieldPos(0)
+		user, err := readUser(record, cols)
+		if err != nil {
+			return nil, xerr.Newf("line %d: %v", line, err)
+		}
+		users = append(users, user)
+	}
+}
+
+func readUser(record []string, cols map[string]int) (models.ImportUser, error) {
+	get := func(col string) string {
+		if i, ok := cols[col]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	u := models.ImportUser{
+		DisplayName: get(colDisplayName),
+		VlessUUID:   get(colVlessUUID),
+		Email:       get(colEmail),
+	}
+	var ok bool
+	if v := get(colStatus); v != "" {
+		if u.Status, ok = parseEnum(statusNames, v); !ok {
+			return u, invalidValue(colStatus, v)
+		}
+	}
+	if v := get(colQuotaPeriod); v != "" {
+		if u.Quota.Period, ok = parseEnum(periodNames, v); !ok {
+			return u, invalidValue(colQuotaPeriod, v)
+		}
+	}
+	var err error
+	if v := get(colQuotaLimit); v != "" {
+		if u.Quota.Limit, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return u, invalidValue(colQuotaLimit, v)
+		}
+	}
+	if v := get(colExpiresAt); v != "" {
+		if u.ExpiresAt, err = time.Parse(time.RFC3339, v); err != nil {
+			return u, invalidValue(colExpiresAt, v)
+		}
+	}
+	if v := get(colIPLimit); v != "" {
+		if u.IPLimit, err = strconv.Atoi(v); err != nil {
+			return u, invalidValue(colIPLimit, v)
+		}
+	}
+	return u, nil
+}
+
+func parseEnum[T comparable](names map[T]string, v string) (T, bool) {
+	for e, name := range names {
+		if strings.EqualFold(name, v) {
+			return e, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func invalidValue(col, v string) error {
+	return xerr.Newf("invalid %s: %q", col, v)
+}
+
+// Write writes users export with traffic stats
+func Write(w io.Writer, users []models.UserView) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	for _, v := range users {
+		u := v.User
+		expiresAt := ""
+		if !u.ExpiresAt.IsZero() {
+			expiresAt = u.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			strconv.Itoa(u.Profile.ID),
+			u.Profile.Name,
+			u.Profile.DisplayName,
+			u.Profile.VlessUUID,
+			u.Profile.SubToken,
+			statusNames[u.TargetStatus],
+			strconv.FormatInt(u.Quota.Limit, 10),
+			periodNames[u.Quota.Period],
+			expiresAt,
+			strconv.Itoa(u.IPLimit),
+			u.Profile.Email,
+			strconv.FormatInt(v.Traffic.Total.Upload, 10),
+			strconv.FormatInt(v.Traffic.Total.Download, 10),
+			strconv.FormatInt(v.Traffic.LastMonth.Upload, 10),
+			strconv.FormatInt(v.Traffic.LastMonth.Download, 10),
+			strconv.FormatInt(v.Traffic.CurrentPeriod.Upload, 10),
+			strconv.FormatInt(v.Traffic.CurrentPeriod.Download, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return xerr.WrapWithStack(err)
+		}
+	}
+	cw.Flush()
+	return xerr.WrapWithStack(cw.Error())
+}
@@ -0,0 +1,94 @@
+package usercsv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	in := `display_name,status,vless_uuid,quota_limit,quota_period,expires_at,ip_limit
+John Doe,,,,,,
+Jane,Disabled,0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12,1000,monthly,2030-01-02T03:04:05Z,3
+`
+	users, err := Read(strings.NewReader(in))
+	require.NoError(t, err)
+	require.Equal(t, []models.ImportUser{
+		{DisplayName: "John Doe"},
+		{
+			DisplayName: "Jane",
+			VlessUUID:   "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12",
+			Status:      models.UserStatusDisabled,
+			Quota: models.TrafficQuota{
+				Limit:  1000,
+				Period: models.TrafficQuotaPeriodMonthly,
+			},
+			ExpiresAt: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+			IPLimit:   3,
+		},
+	}, users)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":        "",
+		"no name":      "status\nenabled\n",
+		"status":       "display_name,status\njohn,unknown\n",
+		"quota limit":  "display_name,quota_limit\njohn,10GB\n",
+		"quota period": "display_name,quota_period\njohn,weekly\n",
+		"expires at":   "display_name,expires_at\njohn,tomorrow\n",
+		"ip limit":     "display_name,ip_limit\njohn,x\n",
+		"fields count": "display_name,ip_limit\njohn\n",
+	}
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(in))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	users := []models.UserView{{
+		User: models.User{
+			Profile: models.UserProfile{
+				ID:          7,
+				Name:        "john-doe",
+				DisplayName: "John, Doe",
+				VlessUUID:   "uuid",
+				SubToken:    "token",
+				Email:       "john@example.com",
+			},
+			TargetStatus: models.UserStatusEnabled,
+			Quota: models.TrafficQuota{
+				Limit:  100,
+				Period: models.TrafficQuotaPeriodOneOff,
+			},
+			ExpiresAt: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+			IPLimit:   2,
+		},
+		Traffic: models.UserTraffic{
+			Total: models.TrafficStats{Upload: 1, Download: 2},
+		},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, users))
+
+	// export is accepted by import, stats are ignored
+	imported, err := Read(&buf)
+	require.NoError(t, err)
+	require.Equal(t, []models.ImportUser{{
+		DisplayName: "John, Doe",
+		VlessUUID:   "uuid",
+		Status:      models.UserStatusEnabled,
+		Quota:       users[0].User.Quota,
+		ExpiresAt:   users[0].User.ExpiresAt,
+		IPLimit:     2,
+		Email:       "john@example.com",
+	}}, imported)
+}
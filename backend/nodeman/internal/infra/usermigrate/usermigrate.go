@@ -0,0 +1,425 @@
+// Package usermigrate reads users of other xray setups to import them
+// into nodeman: hand-written xray server configs, 3x-ui inbounds and
+// marzban users exports.
+//
+// Users keep their uuids, so clients keep working without re-import:
+// vmess ids and trojan passwords are the same as vless uuid on nodes.
+// The same user in several inbounds is merged by email, its credentials
+// differing from the first ones become user devices. Client emails
+// become users display names and are kept as users emails on nodes.
+// Marzban has no client emails, its usernames become display names.
+//
+// Clients which can't be migrated as is don't fail the migration,
+// they are reported in result: shadowsocks and non-uuid credentials
+// are replaced by generated ones, settings nodeman has no analogue
+// for are approximated.
+package usermigrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/google/uuid"
+)
+
+// Source is a kind of migrated setup
+type Source string
+
+const (
+	// xray server config, inbounds[].settings.clients
+	SourceXray Source = "xray"
+	// 3x-ui inbounds list api response, or inbounds table
+	// dump made by sqlite3 -json x-ui.db "select * from inbounds"
+	Source3XUI Source = "3x-ui"
+	// marzban users list api response
+	SourceMarzban Source = "marzban"
+)
+
+// max devices of user, extra credentials are skipped
+const maxUserDevices = 16
+
+// Result is users read from migrated setup
+type Result struct {
+	Users []models.ImportUser
+	// clients or their credentials not imported
+	Skipped []string
+	// clients imported with changed credentials or settings
+	Warnings []string
+}
+
+// Read parses users of given source
+func Read(r io.Reader, src Source) (*Result, error) {
+	return read(r, src, time.Now())
+}
+
+// read parses users, expirations counted from first use start at now
+func read(r io.Reader, src Source, now time.Time) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+
+	c := collector{now: now.UTC()}
+	switch src {
+	case SourceXray:
+		err = readXray(data, &c)
+	case Source3XUI:
+		err = read3XUI(data, &c)
+	case SourceMarzban:
+		err = readMarzban(data, &c)
+	default:
+		return nil, xerr.Newf("unsupported source %q", src)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(c.res.Users) == 0 {
+		return nil, xerr.Newf("no users found in %s data", src)
+	}
+	for _, u := range c.res.Users {
+		if u.VlessUUID == "" {
+			c.warn("user %q: no credentials kept, new ones are generated", u.DisplayName)
+		}
+	}
+	return &c.res, nil
+}
+
+// xray server config
+
+type xrayConfig struct {
+	Inbounds []xrayInbound `json:"inbounds"`
+}
+
+type xrayInbound struct {
+	Tag      string `json:"tag"`
+	Protocol string `json:"protocol"`
+	Settings struct {
+		Clients []xrayClient `json:"clients"`
+	} `json:"settings"`
+}
+
+type xrayClient struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+func readXray(data []byte, c *collector) error {
+	var cfg xrayConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return xerr.Newf("invalid xray config: %v", err)
+	}
+	for _, in := range cfg.Inbounds {
+		if !hasClients(in.Protocol) {
+			continue
+		}
+		for i, client := range in.Settings.Clients {
+			c.add(models.ImportUser{
+				DisplayName: clientName(in.Tag, i, client.Email),
+			}, client.Email, credential{
+				label:  inboundLabel(in.Tag, in.Protocol),
+				secret: clientSecret(in.Protocol, client),
+				kept:   in.Protocol != protocolShadowsocks,
+			})
+		}
+	}
+	return nil
+}
+
+// 3x-ui inbounds, settings are stored as json string
+
+type xuiInbound struct {
+	Tag      string `json:"tag"`
+	Protocol string `json:"protocol"`
+	Settings string `json:"settings"`
+}
+
+type xuiSettings struct {
+	Clients []xuiClient `json:"clients"`
+}
+
+type xuiClient struct {
+	xrayClient
+	Enable *bool `json:"enable"`
+	// traffic limit, bytes
+	TotalGB int64 `json:"totalGB"`
+	// unix ms, negative means expiration after first use
+	ExpiryTime int64 `json:"expiryTime"`
+	LimitIP    int   `json:"limitIp"`
+	// traffic reset period, days
+	Reset int `json:"reset"`
+}
+
+// 3x-ui reset period matching nodeman monthly one
+const xuiMonthDays = 30
+
+func read3XUI(data []byte, c *collector) error {
+	var inbounds []xuiInbound
+	if err := unmarshalList(data, "obj", &inbounds); err != nil {
+		return xerr.Newf("invalid 3x-ui inbounds: %v", err)
+	}
+	for _, in := range inbounds {
+		if !hasClients(in.Protocol) {
+			continue
+		}
+		var settings xuiSettings
+		if err := json.Unmarshal([]byte(in.Settings), &settings); err != nil {
+			c.skip("inbound %q: invalid settings: %v", in.Tag, err)
+			continue
+		}
+		for i, client := range settings.Clients {
+			u := models.ImportUser{
+				DisplayName: clientName(in.Tag, i, client.Email),
+				IPLimit:     client.LimitIP,
+			}
+			if client.Enable != nil && !*client.Enable {
+				u.Status = models.UserStatusDisabled
+			}
+			if client.TotalGB > 0 {
+				u.Quota.Limit = client.TotalGB
+				// nodeman has monthly reset period only
+				if client.Reset > 0 {
+					u.Quota.Period = models.TrafficQuotaPeriodMonthly
+				}
+				if client.Reset > 0 && client.Reset != xuiMonthDays {
+					c.warn("user %q: traffic reset every %d days "+
+						"becomes monthly", u.DisplayName, client.Reset)
+				}
+			}
+			switch {
+			case client.ExpiryTime > 0:
+				u.ExpiresAt = time.UnixMilli(client.ExpiryTime).UTC()
+			case client.ExpiryTime < 0:
+				u.ExpiresAt = c.expiresFromNow(u.DisplayName,
+					time.Duration(-client.ExpiryTime)*time.Millisecond)
+			}
+			c.add(u, client.Email, credential{
+				label:  inboundLabel(in.Tag, in.Protocol),
+				secret: clientSecret(in.Protocol, client.xrayClient),
+				kept:   in.Protocol != protocolShadowsocks,
+			})
+		}
+	}
+	return nil
+}
+
+// marzban users
+
+type marzbanUser struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	// unix s
+	Expire *int64 `json:"expire"`
+	// s, expiration after first use of on hold user
+	OnHoldExpireDuration *int64 `json:"on_hold_expire_duration"`
+	// bytes
+	DataLimit         *int64 `json:"data_limit"`
+	DataLimitStrategy string `json:"data_limit_reset_strategy"`
+	Proxies           struct {
+		Vless       *xrayClient `json:"vless"`
+		Vmess       *xrayClient `json:"vmess"`
+		Trojan      *xrayClient `json:"trojan"`
+		Shadowsocks *xrayClient `json:"shadowsocks"`
+	} `json:"proxies"`
+}
+
+func readMarzban(data []byte, c *collector) error {
+	var users []marzbanUser
+	if err := unmarshalList(data, "users", &users); err != nil {
+		return xerr.Newf("invalid marzban users: %v", err)
+	}
+	for _, mu := range users {
+		u := models.ImportUser{
+			DisplayName: mu.Username,
+		}
+		// limited and expired users are handled by nodeman itself
+		if mu.Status == "disabled" {
+			u.Status = models.UserStatusDisabled
+		}
+		if mu.DataLimit != nil && *mu.DataLimit > 0 {
+			u.Quota.Limit = *mu.DataLimit
+			switch mu.DataLimitStrategy {
+			case "month":
+				u.Quota.Period = models.TrafficQuotaPeriodMonthly
+			case "", "no_reset":
+			default:
+				c.warn("user %q: %s traffic reset becomes one-off quota",
+					u.DisplayName, mu.DataLimitStrategy)
+			}
+		}
+		switch {
+		case mu.Expire != nil && *mu.Expire > 0:
+			u.ExpiresAt = time.Unix(*mu.Expire, 0).UTC()
+		case mu.Status == "on_hold" && mu.OnHoldExpireDuration != nil &&
+			*mu.OnHoldExpireDuration > 0:
+			u.ExpiresAt = c.expiresFromNow(u.DisplayName,
+				time.Duration(*mu.OnHoldExpireDuration)*time.Second)
+		}
+
+		// each proxy has own credentials, first one is user
+		// credentials, differing others become devices
+		var creds []credential
+		p := mu.Proxies
+		if p.Vless != nil {
+			creds = append(creds, credential{label: "vless", secret: p.Vless.ID, kept: true})
+		}
+		if p.Vmess != nil {
+			creds = append(creds, credential{label: "vmess", secret: p.Vmess.ID, kept: true})
+		}
+		if p.Trojan != nil {
+			creds = append(creds, credential{label: "trojan", secret: p.Trojan.Password, kept: true})
+		}
+		if p.Shadowsocks != nil {
+			creds = append(creds, credential{label: "shadowsocks", secret: p.Shadowsocks.Password})
+		}
+		c.add(u, "", creds...)
+	}
+	return nil
+}
+
+const protocolShadowsocks = "shadowsocks"
+
+func hasClients(protocol string) bool {
+	switch protocol {
+	case "vless", "vmess", "trojan", protocolShadowsocks:
+		return true
+	default:
+		return false
+	}
+}
+
+func clientSecret(protocol string, client xrayClient) string {
+	if protocol == "trojan" || protocol == protocolShadowsocks {
+		return client.Password
+	}
+	return client.ID
+}
+
+// device label of inbound credentials
+func inboundLabel(tag, protocol string) string {
+	if tag != "" {
+		return tag
+	}
+	return protocol
+}
+
+// clients without email are named by inbound
+func clientName(tag string, i int, email string) string {
+	if email != "" {
+		return email
+	}
+	return fmt.Sprintf("%s client %d", tag, i+1)
+}
+
+// unmarshalList parses either list itself or api response with list in key
+func unmarshalList(data []byte, key string, v any) error {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		return json.Unmarshal(data, v)
+	}
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	list, ok := resp[key]
+	if !ok {
+		return xerr.Newf("no %q list", key)
+	}
+	return json.Unmarshal(list, v)
+}
+
+// client credentials in one of migrated inbounds or proxies
+type credential struct {
+	// device label if credentials become device
+	label  string
+	secret string
+	// false if nodes can't keep secret, e.g. shadowsocks
+	// keys derived from vless uuid on nodes
+	kept bool
+}
+
+// collector merges the same user from several inbounds
+type collector struct {
+	res    Result
+	now    time.Time
+	byName map[string]int
+	// user name by any of its credentials
+	byUUID map[string]string
+}
+
+func (c *collector) skip(format string, args ...any) {
+	c.res.Skipped = append(c.res.Skipped, fmt.Sprintf(format, args...))
+}
+
+func (c *collector) warn(format string, args ...any) {
+	c.res.Warnings = append(c.res.Warnings, fmt.Sprintf(format, args...))
+}
+
+// nodeman has no expiration after first use, it starts now
+func (c *collector) expiresFromNow(name string, d time.Duration) time.Time {
+	c.warn("user %q: expiration after first use in %s starts now", name, d)
+	return c.now.Add(d)
+}
+
+// add adds user with given credentials, or merges
+// credentials into already added user of the same name
+func (c *collector) add(u models.ImportUser, email string, creds ...credential) {
+	if c.byName == nil {
+		c.byName = make(map[string]int)
+		c.byUUID = make(map[string]string)
+	}
+
+	i, ok := c.byName[u.DisplayName]
+	if !ok {
+		// nodes generate "<id>-<name>" emails, they can't be kept
+		if _, _, generated := models.ParseLegacyUserKey(email); generated {
+			c.warn("user %q: email looks like nodeman one, not kept", u.DisplayName)
+			email = ""
+		}
+		u.Email = email
+		i = len(c.res.Users)
+		c.byName[u.DisplayName] = i
+		c.res.Users = append(c.res.Users, u)
+	}
+	user := &c.res.Users[i]
+
+	for _, cred := range creds {
+		c.addCredential(user, cred)
+	}
+}
+
+func (c *collector) addCredential(u *models.ImportUser, cred credential) {
+	id, err := uuid.Parse(cred.secret)
+	if !cred.kept || err != nil {
+		c.skip("user %q: %s credentials can't be kept", u.DisplayName, cred.label)
+		return
+	}
+	secret := id.String()
+	if name, ok := c.byUUID[secret]; ok {
+		if name != u.DisplayName {
+			c.skip("user %q: %s credentials are used by user %q",
+				u.DisplayName, cred.label, name)
+		}
+		return
+	}
+
+	switch {
+	case u.VlessUUID == "":
+		u.VlessUUID = secret
+	case len(u.Devices) >= maxUserDevices:
+		c.skip("user %q: %s credentials exceed %d devices",
+			u.DisplayName, cred.label, maxUserDevices)
+		return
+	default:
+		u.Devices = append(u.Devices, models.ImportDevice{
+			Label:     cred.label,
+			VlessUUID: secret,
+		})
+	}
+	c.byUUID[secret] = u.DisplayName
+}
@@ -0,0 +1,247 @@
+package usermigrate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestRead_Xray(t *testing.T) {
+	in := `{
+	"inbounds": [
+		{
+			"tag": "vless-in",
+			"protocol": "vless",
+			"settings": {"clients": [
+				{"id": "0A9F1C3E-2F4B-4C8D-9E1A-6B7C8D9E0F12", "email": "alice@example.com", "flow": "xtls-rprx-vision"},
+				{"id": "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9"}
+			]}
+		},
+		{
+			"tag": "trojan-in",
+			"protocol": "trojan",
+			"settings": {"clients": [
+				{"password": "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12", "email": "alice@example.com"},
+				{"password": "2c3d4e5f-6071-4829-93a4-b5c6d7e8f9a0", "email": "bob"},
+				{"password": "3d4e5f60-7182-4930-a4b5-c6d7e8f9a0b1", "email": "alice@example.com"},
+				{"password": "not-uuid", "email": "dave"},
+				{"password": "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9", "email": "eve"}
+			]}
+		},
+		{
+			"tag": "ss-in",
+			"protocol": "shadowsocks",
+			"settings": {"method": "2022-blake3-aes-128-gcm", "clients": [
+				{"password": "c2VjcmV0", "email": "carol"}
+			]}
+		},
+		{"tag": "api", "protocol": "dokodemo-door", "settings": {"address": "127.0.0.1"}}
+	]
+}`
+	res, err := read(strings.NewReader(in), SourceXray, testNow)
+	require.NoError(t, err)
+	require.Equal(t, []models.ImportUser{
+		{
+			DisplayName: "alice@example.com",
+			Email:       "alice@example.com",
+			VlessUUID:   "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12",
+			Devices: []models.ImportDevice{
+				{Label: "trojan-in", VlessUUID: "3d4e5f60-7182-4930-a4b5-c6d7e8f9a0b1"},
+			},
+		},
+		{DisplayName: "vless-in client 2", VlessUUID: "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9"},
+		{DisplayName: "bob", Email: "bob", VlessUUID: "2c3d4e5f-6071-4829-93a4-b5c6d7e8f9a0"},
+		{DisplayName: "dave", Email: "dave"},
+		{DisplayName: "eve", Email: "eve"},
+		{DisplayName: "carol", Email: "carol"},
+	}, res.Users)
+	require.Equal(t, []string{
+		`user "dave": trojan-in credentials can't be kept`,
+		`user "eve": trojan-in credentials are used by user "vless-in client 2"`,
+		`user "carol": ss-in credentials can't be kept`,
+	}, res.Skipped)
+	require.Equal(t, []string{
+		`user "dave": no credentials kept, new ones are generated`,
+		`user "eve": no credentials kept, new ones are generated`,
+		`user "carol": no credentials kept, new ones are generated`,
+	}, res.Warnings)
+}
+
+func TestRead_3XUI(t *testing.T) {
+	settings := `{\"clients\": [` +
+		`{\"id\": \"0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12\", \"email\": \"alice\", \"enable\": true,` +
+		` \"totalGB\": 1000, \"expiryTime\": 1893553445000, \"limitIp\": 2, \"reset\": 30},` +
+		`{\"id\": \"1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9\", \"email\": \"bob\", \"enable\": false,` +
+		` \"totalGB\": 500, \"expiryTime\": -86400000, \"reset\": 7}` +
+		`]}`
+	want := &Result{
+		Users: []models.ImportUser{
+			{
+				DisplayName: "alice",
+				Email:       "alice",
+				VlessUUID:   "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12",
+				Quota: models.TrafficQuota{
+					Limit:  1000,
+					Period: models.TrafficQuotaPeriodMonthly,
+				},
+				ExpiresAt: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+				IPLimit:   2,
+			},
+			{
+				DisplayName: "bob",
+				Email:       "bob",
+				VlessUUID:   "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9",
+				Status:      models.UserStatusDisabled,
+				Quota: models.TrafficQuota{
+					Limit:  500,
+					Period: models.TrafficQuotaPeriodMonthly,
+				},
+				ExpiresAt: testNow.Add(24 * time.Hour),
+			},
+		},
+		Warnings: []string{
+			`user "bob": traffic reset every 7 days becomes monthly`,
+			`user "bob": expiration after first use in 24h0m0s starts now`,
+		},
+	}
+
+	tests := map[string]string{
+		"api response": `{"success": true, "obj": [{"id": 1, "enable": true, "tag": "in-443",` +
+			` "protocol": "vless", "settings": "` + settings + `"}]}`,
+		"db dump": `[{"id": 1, "enable": 1, "tag": "in-443",` +
+			` "protocol": "vless", "settings": "` + settings + `"}]`,
+	}
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := read(strings.NewReader(in), Source3XUI, testNow)
+			require.NoError(t, err)
+			require.Equal(t, want, res)
+		})
+	}
+}
+
+func TestRead_Marzban(t *testing.T) {
+	in := `{"total": 4, "users": [
+	{
+		"username": "alice", "status": "active", "expire": 1893553445,
+		"data_limit": 1000, "data_limit_reset_strategy": "month",
+		"proxies": {"vless": {"id": "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12", "flow": ""},
+			"vmess": {"id": "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9"},
+			"trojan": {"password": "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12"}}
+	},
+	{
+		"username": "bob", "status": "disabled", "expire": null,
+		"data_limit": 500, "data_limit_reset_strategy": "week",
+		"proxies": {"trojan": {"password": "2c3d4e5f-6071-4829-93a4-b5c6d7e8f9a0"}}
+	},
+	{
+		"username": "carol", "status": "limited", "expire": 0, "data_limit": null,
+		"proxies": {"shadowsocks": {"password": "secret"}}
+	},
+	{
+		"username": "dave", "status": "on_hold", "expire": null,
+		"on_hold_expire_duration": 3600,
+		"proxies": {"vless": {"id": "3d4e5f60-7182-4930-a4b5-c6d7e8f9a0b1"}}
+	}
+]}`
+	res, err := read(strings.NewReader(in), SourceMarzban, testNow)
+	require.NoError(t, err)
+	require.Equal(t, []models.ImportUser{
+		{
+			DisplayName: "alice",
+			VlessUUID:   "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12",
+			Devices: []models.ImportDevice{
+				{Label: "vmess", VlessUUID: "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9"},
+			},
+			Quota: models.TrafficQuota{
+				Limit:  1000,
+				Period: models.TrafficQuotaPeriodMonthly,
+			},
+			ExpiresAt: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			DisplayName: "bob",
+			VlessUUID:   "2c3d4e5f-6071-4829-93a4-b5c6d7e8f9a0",
+			Status:      models.UserStatusDisabled,
+			Quota:       models.TrafficQuota{Limit: 500},
+		},
+		{DisplayName: "carol"},
+		{
+			DisplayName: "dave",
+			VlessUUID:   "3d4e5f60-7182-4930-a4b5-c6d7e8f9a0b1",
+			ExpiresAt:   testNow.Add(time.Hour),
+		},
+	}, res.Users)
+	require.Equal(t, []string{
+		`user "carol": shadowsocks credentials can't be kept`,
+	}, res.Skipped)
+	require.Equal(t, []string{
+		`user "bob": week traffic reset becomes one-off quota`,
+		`user "dave": expiration after first use in 1h0m0s starts now`,
+		`user "carol": no credentials kept, new ones are generated`,
+	}, res.Warnings)
+}
+
+func TestRead_Devices(t *testing.T) {
+	var clients []string
+	for i := range maxUserDevices + 2 {
+		clients = append(clients, fmt.Sprintf(
+			`{"id": "0a9f1c3e-2f4b-4c8d-9e1a-%012x", "email": "alice"}`, i))
+	}
+	in := `{"inbounds": [{"protocol": "vless", "settings": {"clients": [` +
+		strings.Join(clients, ",") + `]}}]}`
+
+	res, err := read(strings.NewReader(in), SourceXray, testNow)
+	require.NoError(t, err)
+	require.Len(t, res.Users, 1)
+	require.Len(t, res.Users[0].Devices, maxUserDevices)
+	require.Equal(t, "vless", res.Users[0].Devices[0].Label)
+	require.Equal(t, []string{
+		`user "alice": vless credentials exceed 16 devices`,
+	}, res.Skipped)
+}
+
+func TestRead_GeneratedEmail(t *testing.T) {
+	in := `{"inbounds": [{"tag": "in", "protocol": "vless", "settings": {"clients": [` +
+		`{"id": "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12", "email": "3-alice"}]}}]}`
+	res, err := read(strings.NewReader(in), SourceXray, testNow)
+	require.NoError(t, err)
+	require.Equal(t, []models.ImportUser{{
+		DisplayName: "3-alice",
+		VlessUUID:   "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12",
+	}}, res.Users)
+	require.Equal(t, []string{
+		`user "3-alice": email looks like nodeman one, not kept`,
+	}, res.Warnings)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	const uuid1 = "0a9f1c3e-2f4b-4c8d-9e1a-6b7c8d9e0f12"
+	xray := func(clients string) string {
+		return `{"inbounds": [{"tag": "in", "protocol": "vless", "settings": {"clients": [` +
+			clients + `]}}]}`
+	}
+	tests := map[string]struct {
+		src Source
+		in  string
+	}{
+		"source":         {"v2ray", xray(`{"id": "` + uuid1 + `"}`)},
+		"xray json":      {SourceXray, `{"inbounds": [`},
+		"no users":       {SourceXray, xray(``)},
+		"3x-ui list":     {Source3XUI, `{"success": false, "msg": "unauthorized"}`},
+		"3x-ui settings": {Source3XUI, `[{"tag": "in", "protocol": "vless", "settings": "{"}]`},
+		"marzban list":   {SourceMarzban, `{"detail": "Not authenticated"}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := read(strings.NewReader(tt.in), tt.src, testNow)
+			require.Error(t, err)
+		})
+	}
+}
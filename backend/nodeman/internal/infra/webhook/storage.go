@@ -0,0 +1,12 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	// get all webhooks with secrets
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+}
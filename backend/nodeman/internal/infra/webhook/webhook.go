@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/supervisor"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	EventHeader     = "X-Xrayman-Event"
+	DeliveryHeader  = "X-Xrayman-Delivery"
+	SignatureHeader = "X-Xrayman-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+const (
+	defaultAttempts       = 5
+	defaultBackoff        = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultRequestTimeout = 10 * time.Second
+	// all attempts of one delivery
+	defaultDeliveryTimeout = 10 * time.Minute
+)
+
+const secretSize = 32
+
+type config struct {
+	attempts        int
+	backoff         time.Duration
+	maxBackoff      time.Duration
+	deliveryTimeout time.Duration
+	client          *http.Client
+}
+
+type Option func(c *config)
+
+// WithRetry sets delivery attempts and backoff,
+// backoff is doubled after every failed attempt up to maxBackoff
+func WithRetry(attempts int, backoff, maxBackoff time.Duration) Option {
+	return func(c *config) {
+		c.attempts = attempts
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+func WithClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// Dispatcher delivers events to subscribed webhooks in background
+type Dispatcher struct {
+	storage Storage
+	cfg     config
+	sv      *supervisor.Supervisor
+	log     *zap.Logger
+}
+
+// event payload, signed with webhook secret
+type payload struct {
+	ID     string    `json:"id"`
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	NodeID int       `json:"node_id,omitempty"`
+	UserID int       `json:"user_id,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+func New(storage Storage, log *zap.Logger, opts ...Option) (*Dispatcher, error) {
+	if storage == nil {
+		return nil, errdefs.NilArg("storage")
+	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
+	cfg := config{
+		attempts:        defaultAttempts,
+		backoff:         defaultBackoff,
+		maxBackoff:      defaultMaxBackoff,
+		deliveryTimeout: defaultDeliveryTimeout,
+		client:          &http.Client{Timeout: defaultRequestTimeout},
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return &Dispatcher{
+		storage: storage,
+		cfg:     cfg,
+		sv:      supervisor.New(),
+		log:     log,
+	}, nil
+}
+
+// stop pending deliveries
+func (d *Dispatcher) Close() {
+	if d == nil || d.sv == nil {
+		return
+	}
+	d.sv.Close()
+}
+
+// send event to subscribed webhooks, never blocks caller
+func (d *Dispatcher) Notify(_ context.Context, e models.WebhookEvent) {
+	if d == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	d.sv.Go(func(ctx context.Context) {
+		if err := d.dispatch(ctx, e); err != nil {
+			d.log.Warn("webhook event dispatch",
+				zap.Stringer("event", e.Type),
+				zap.Error(err))
+		}
+	}, d.cfg.deliveryTimeout)
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, e models.WebhookEvent) error {
+	webhooks, err := d.storage.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload{
+		ID:     uuid.NewString(),
+		Event:  e.Type.String(),
+		Time:   e.Time,
+		NodeID: e.NodeID,
+		UserID: e.UserID,
+		Error:  e.Error,
+	})
+	if err != nil {
+		return xerr.WrapWithStack(err)
+	}
+	for _, w := range webhooks {
+		if !w.Accepts(e.Type) {
+			continue
+		}
+		d.sv.Go(func(ctx context.Context) {
+			if err := d.deliver(ctx, w, e.Type, body); err != nil {
+				d.log.Warn("webhook delivery failed",
+					zap.Int("webhook", w.ID),
+					zap.Stringer("event", e.Type),
+					zap.Error(err))
+			}
+		}, d.cfg.deliveryTimeout)
+	}
+	return nil
+}
+
+// post payload until endpoint accepts it or attempts are over
+func (d *Dispatcher) deliver(ctx context.Context, w models.Webhook,
+	t models.WebhookEventType, body []byte,
+) error {
+	delivery := uuid.NewString()
+	backoff := d.cfg.backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		if retry, err = d.post(ctx, w, t, delivery, body); err == nil || !retry {
+			return err
+		}
+		if attempt >= d.cfg.attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return xerr.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, d.cfg.maxBackoff)
+	}
+}
+
+// post payload once, report if failure is worth retrying
+func (d *Dispatcher) post(ctx context.Context, w models.Webhook,
+	t models.WebhookEventType, delivery string, body []byte,
+) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, xerr.WrapWithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, t.String())
+	req.Header.Set(DeliveryHeader, delivery)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+
+	resp, err := d.cfg.client.Do(req)
+	if err != nil {
+		return true, xerr.WrapWithStack(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// client errors won't be fixed by retry, except throttling
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, xerr.Newf("webhook response status %d", resp.StatusCode)
+}
+
+// hmac-sha256 of payload, receiver compares it with own one
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// generate new webhook secret
+func NewSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", xerr.WrapWithStack(err)
+	}
+	return hex.EncodeToString(b), nil
+}
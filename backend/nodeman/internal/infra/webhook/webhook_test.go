@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type testStorage struct {
+	webhooks []models.Webhook
+}
+
+func (s testStorage) ListWebhooks(context.Context) ([]models.Webhook, error) {
+	return s.webhooks, nil
+}
+
+func TestDispatcher(t *testing.T) {
+	const secret = "secret"
+	var calls atomic.Int32
+	received := make(chan map[string]any, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail first attempts to check retry
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, Sign(secret, body), r.Header.Get(SignatureHeader))
+		require.Equal(t, "user.created", r.Header.Get(EventHeader))
+		require.NotEmpty(t, r.Header.Get(DeliveryHeader))
+
+		var p map[string]any
+		require.NoError(t, json.Unmarshal(body, &p))
+		received <- p
+	}))
+	defer srv.Close()
+
+	var skipped atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skipped.Add(1)
+	}))
+	defer other.Close()
+
+	storage := testStorage{webhooks: []models.Webhook{
+		{ID: 1, URL: srv.URL, Secret: secret},
+		{ID: 2, URL: other.URL, Secret: secret, Events: []models.WebhookEventType{
+			models.WebhookEventNodeUnavailable,
+		}},
+	}}
+	d, err := New(storage, zaptest.NewLogger(t),
+		WithRetry(5, time.Millisecond, 10*time.Millisecond))
+	require.NoError(t, err)
+	defer d.Close()
+
+	d.Notify(t.Context(), models.WebhookEvent{
+		Type:   models.WebhookEventUserCreated,
+		UserID: 7,
+	})
+
+	select {
+	case p := <-received:
+		require.Equal(t, "user.created", p["event"])
+		require.EqualValues(t, 7, p["user_id"])
+		require.NotContains(t, p, "node_id")
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+	require.EqualValues(t, 3, calls.Load())
+	require.Zero(t, skipped.Load())
+}
+
+func TestDispatcherNoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	d, err := New(testStorage{}, zaptest.NewLogger(t),
+		WithRetry(5, time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+	defer d.Close()
+
+	w := models.Webhook{ID: 1, URL: srv.URL, Secret: "secret"}
+	err = d.deliver(t.Context(), w, models.WebhookEventUserDeleted, []byte("{}"))
+	require.Error(t, err)
+	require.EqualValues(t, 1, calls.Load())
+}
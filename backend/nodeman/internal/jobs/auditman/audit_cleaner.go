@@ -0,0 +1,10 @@
+package auditman
+
+import (
+	"context"
+)
+
+//go:generate mockgen -source=audit_cleaner.go -destination=./mocks/mock_audit_cleaner.go -package=mocks AuditCleaner
+type AuditCleaner interface {
+	CleanupAuditEvents(ctx context.Context) error
+}
@@ -0,0 +1,72 @@
+package auditman
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/job"
+	"go.uber.org/zap"
+)
+
+type AuditMan struct {
+	job *job.Job
+}
+
+type options struct {
+	log *zap.Logger
+}
+
+type Option func(o *options)
+
+func WithLogger(log *zap.Logger) Option {
+	return func(o *options) {
+		if log != nil {
+			o.log = log
+		}
+	}
+}
+
+func New(cleaner AuditCleaner, interval time.Duration, opts ...Option) (*AuditMan, error) {
+	if cleaner == nil {
+		return nil, errdefs.NilArg("cleaner")
+	}
+	if interval == 0 {
+		return nil, errdefs.NilArg("interval")
+	}
+	cfg := options{
+		log: zap.NewNop(),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	jobFn := func(ctx context.Context) error {
+		return cleaner.CleanupAuditEvents(ctx)
+	}
+	job, err := job.NewJob(jobFn, interval, "cleanup audit events", cfg.log)
+	if err != nil {
+		return nil, err
+	}
+
+	// init default options
+	m := &AuditMan{
+		job: job,
+	}
+
+	return m, nil
+}
+
+func (m *AuditMan) Run() error {
+	if m == nil || m.job == nil {
+		return errdefs.NilCall()
+	}
+	return m.job.Run()
+}
+
+func (m *AuditMan) Stop() {
+	if m == nil || m.job == nil {
+		return
+	}
+	m.job.Stop()
+}
@@ -0,0 +1,72 @@
+package expireman
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/job"
+	"go.uber.org/zap"
+)
+
+type ExpireMan struct {
+	job *job.Job
+}
+
+type options struct {
+	log *zap.Logger
+}
+
+type Option func(o *options)
+
+func WithLogger(log *zap.Logger) Option {
+	return func(o *options) {
+		if log != nil {
+			o.log = log
+		}
+	}
+}
+
+func New(expirer UsersExpirer, interval time.Duration, opts ...Option) (*ExpireMan, error) {
+	if expirer == nil {
+		return nil, errdefs.NilArg("expirer")
+	}
+	if interval == 0 {
+		return nil, errdefs.NilArg("interval")
+	}
+	cfg := options{
+		log: zap.NewNop(),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	jobFn := func(ctx context.Context) error {
+		return expirer.DisableExpiredUsers(ctx)
+	}
+	job, err := job.NewJob(jobFn, interval, "disable expired users", cfg.log)
+	if err != nil {
+		return nil, err
+	}
+
+	// init default options
+	m := &ExpireMan{
+		job: job,
+	}
+
+	return m, nil
+}
+
+func (m *ExpireMan) Run() error {
+	if m == nil || m.job == nil {
+		return errdefs.NilCall()
+	}
+	return m.job.Run()
+}
+
+func (m *ExpireMan) Stop() {
+	if m == nil || m.job == nil {
+		return
+	}
+	m.job.Stop()
+}
@@ -0,0 +1,62 @@
+package expireman
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/expireman/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExpireMan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := zaptest.NewLogger(t)
+
+	// job keeps running after failed attempt
+	calls := make(chan struct{}, 1)
+	called := func() {
+		select {
+		case calls <- struct{}{}:
+		default:
+		}
+	}
+	expirer := mocks.NewMockUsersExpirer(ctrl)
+	gomock.InOrder(
+		expirer.EXPECT().
+			DisableExpiredUsers(gomock.Any()).
+			DoAndReturn(func(context.Context) error {
+				called()
+				return xerr.New("storage failure")
+			}),
+		expirer.EXPECT().
+			DisableExpiredUsers(gomock.Any()).
+			DoAndReturn(func(context.Context) error {
+				called()
+				return nil
+			}).
+			AnyTimes(),
+	)
+
+	m, err := New(expirer, 10*time.Millisecond, WithLogger(log))
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- m.Run() }()
+	<-calls
+	<-calls
+	m.Stop()
+	require.NoError(t, <-done)
+}
+
+func TestExpireMan_NilArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	_, err := New(nil, time.Second)
+	require.Error(t, err)
+	_, err = New(mocks.NewMockUsersExpirer(ctrl), 0)
+	require.Error(t, err)
+}
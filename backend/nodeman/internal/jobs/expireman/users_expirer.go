@@ -0,0 +1,10 @@
+package expireman
+
+import (
+	"context"
+)
+
+//go:generate mockgen -source=users_expirer.go -destination=./mocks/mock_users_expirer.go -package=mocks UsersExpirer
+type UsersExpirer interface {
+	DisableExpiredUsers(ctx context.Context) error
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/common/xerrgroup"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/job"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
@@ -73,10 +73,11 @@ func (m *StatsMan) Run() error {
 	if m == nil || m.updateStatsJob == nil || m.updateDailyJob == nil {
 		return errdefs.NilCall()
 	}
-	return xerr.Join(
-		m.updateStatsJob.Run(),
-		m.updateDailyJob.Run(),
-	)
+	// both jobs block until stopped, run them side by side
+	g, _ := xerrgroup.WithContext(context.Background())
+	g.Go(m.updateStatsJob.Run)
+	g.Go(m.updateDailyJob.Run)
+	return g.Wait()
 }
 
 func (m *StatsMan) Stop() {
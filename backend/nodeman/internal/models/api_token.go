@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+type ApiTokenID = int
+
+type ApiTokenScope int
+
+const (
+	ApiTokenScopeUsersRead ApiTokenScope = iota + 1
+	ApiTokenScopeUsersWrite
+	ApiTokenScopeNodesRead
+	ApiTokenScopeNodesWrite
+	ApiTokenScopeStatsRead
+)
+
+// named revocable credential for automation,
+// token secret is shown once on creation
+type ApiToken struct {
+	ID        ApiTokenID
+	Name      string
+	Scopes    []ApiTokenScope
+	CreatedAt time.Time
+}
+
+type NewApiTokenParams struct {
+	Name   string
+	Scopes []ApiTokenScope
+}
+
+type NewApiTokenResult struct {
+	Token  ApiToken
+	Secret string
+}
+
+type ListApiTokensResult struct {
+	Tokens []ApiToken
+}
+
+type RevokeApiTokenParams struct {
+	ID ApiTokenID
+}
+
+// scope name as used in api security requirements
+func (s ApiTokenScope) String() string {
+	switch s {
+	case ApiTokenScopeUsersRead:
+		return "users:read"
+	case ApiTokenScopeUsersWrite:
+		return "users:write"
+	case ApiTokenScopeNodesRead:
+		return "nodes:read"
+	case ApiTokenScopeNodesWrite:
+		return "nodes:write"
+	case ApiTokenScopeStatsRead:
+		return "stats:read"
+	default:
+		return "unknown"
+	}
+}
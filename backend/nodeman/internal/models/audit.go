@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+type AuditEventID = int
+
+type AuditActorKind int
+
+const (
+	AuditActorAdmin AuditActorKind = iota + 1
+	AuditActorApiToken
+	AuditActorAnonymous
+)
+
+// who made the call, id is admin or api token id
+type AuditActor struct {
+	Kind AuditActorKind
+	ID   int
+	Name string
+}
+
+// record of mutating api call
+type AuditEvent struct {
+	ID        AuditEventID
+	Time      time.Time
+	Actor     AuditActor
+	Operation string
+	// ids of changed users, nodes, groups etc.
+	TargetIDs []int
+	// json encoded request parameters, secrets redacted
+	Params    string
+	ClientIP  string
+	RequestID string
+	Success   bool
+	Error     string
+	// json encoded target fields changed by call,
+	// {"Field": {"Before": ..., "After": ...}}, secrets redacted
+	Changes string
+}
+
+// zero fields mean no filter
+type AuditFilter struct {
+	Operation string
+	ActorName string
+	TargetID  int
+	From      time.Time
+	To        time.Time
+}
+
+// events are returned newest first,
+// next page starts before last returned event
+type ListAuditEventsParams struct {
+	Filter   AuditFilter
+	BeforeID AuditEventID
+	Limit    int
+}
+
+type ListAuditEventsResult struct {
+	Events []AuditEvent
+	// zero if no more events
+	NextBeforeID AuditEventID
+}
@@ -2,16 +2,149 @@ package models
 
 import "time"
 
+type AdminID = int
+
+type AdminRole int
+
+const (
+	// full access, including settings and admin accounts
+	AdminRoleOwner AdminRole = iota + 1
+	// manage users, nodes and groups
+	AdminRoleOperator
+	// read-only access to users, subscriptions and nodes
+	AdminRoleSupport
+)
+
+// default admin password is set on bootstrap, it's
+// always owner and can't be deleted
+const (
+	DefaultAdminID   AdminID = 0
+	DefaultAdminName         = "admin"
+)
+
+type Admin struct {
+	ID   AdminID
+	Name string
+	Role AdminRole
+}
+
 type Auth struct {
+	Admin        Admin
 	PasswordHash []byte
+	TOTP         AdminTOTP
+}
+
+// second factor, secret is set on enrolment,
+// login requires code only when enabled.
+// secret is stored sealed by service
+type AdminTOTP struct {
+	Secret  string
+	Enabled bool
 }
 
 type AuthParams struct {
+	Login    string
 	Password string
+	// totp or recovery code, required if totp enabled
+	OTP string
+	// failed logins are counted per login and client ip
+	ClientIP string
 }
 
 type AuthResult struct {
 	AccessToken string
 	TokenType   string
 	ExpiresIn   time.Duration
+	// renews access token, shown once and rotated on every refresh
+	RefreshToken     string
+	RefreshExpiresIn time.Duration
+}
+
+type NewAdminParams struct {
+	Name     string
+	Password string
+	Role     AdminRole
+}
+
+type SetAdminRoleParams struct {
+	ID   AdminID
+	Role AdminRole
+}
+
+type SetAdminPasswordParams struct {
+	ID       AdminID
+	Password string
+}
+
+type DeleteAdminParams struct {
+	ID AdminID
+}
+
+type ListAdminsResult struct {
+	Admins []Admin
+}
+
+type TOTPSetupResult struct {
+	Secret string
+	// otpauth uri to show as qr code
+	URI string
+}
+
+type EnableTOTPParams struct {
+	AdminID AdminID
+	Code    string
+	// failed codes are counted like failed logins
+	ClientIP string
+}
+
+type EnableTOTPResult struct {
+	RecoveryCodes []string
+}
+
+type DisableTOTPParams struct {
+	AdminID AdminID
+	// totp or recovery code
+	Code string
+	// failed codes are counted like failed logins
+	ClientIP string
+}
+
+type ResetAdminTOTPParams struct {
+	ID AdminID
+}
+
+type AuthLockoutKey struct {
+	Login    string
+	ClientIP string
+}
+
+// login is locked after repeated failures,
+// every next failure makes lockout longer
+type AuthLockout struct {
+	Key         AuthLockoutKey
+	Failures    int
+	LockedUntil time.Time
+}
+
+type ListAuthLockoutsResult struct {
+	Lockouts []AuthLockout
+}
+
+type UnlockAuthParams struct {
+	Login    string
+	ClientIP string
+}
+
+// role name as used in api security requirements
+func (r AdminRole) String() string {
+	switch r {
+	case AdminRoleOwner:
+		return "owner"
+	case AdminRoleOperator:
+		return "operator"
+	case AdminRoleSupport:
+		return "support"
+	default:
+		return "unknown"
+	}
 }
@@ -6,6 +6,15 @@ type AppLink struct {
 	URL       string
 }
 
+// action applied to users exceeded ip limit
+type IPLimitPolicy int
+
+const (
+	IPLimitPolicyUnknown IPLimitPolicy = iota + 1
+	IPLimitPolicyWarn
+	IPLimitPolicyDisable
+)
+
 type Settings struct {
 	SubscrTitle    string
 	UpdateInterval int
@@ -16,4 +25,12 @@ type Settings struct {
 
 	AppLinks      []AppLink
 	CustomHeaders []SubHeader
+
+	// unset policy means warn
+	IPLimitPolicy IPLimitPolicy
+	// announce message for users warned about ip limit
+	IPLimitMessage string
+
+	// audit events are kept for days, zero means forever
+	AuditRetentionDays int
 }
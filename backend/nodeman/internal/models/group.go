@@ -0,0 +1,13 @@
+package models
+
+type NodeGroupID = int
+
+// NodeGroup restricts access to its nodes: user reaches node with groups
+// only if user is assigned to one of them. nodes without groups
+// are available to all users.
+type NodeGroup struct {
+	ID      NodeGroupID
+	Name    string
+	NodeIDs []NodeID
+	UserIDs []UserID
+}
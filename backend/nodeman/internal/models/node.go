@@ -62,6 +62,15 @@ type NodeSettings struct {
 
 type NodeID = int
 
+// pushed xray configs version, 0 means node local configs
+type ConfigVersion = int64
+
+type NodeXRayConfig struct {
+	Version      ConfigVersion
+	ServerConfig string
+	ClientConfig string
+}
+
 type NodeConfig struct {
 	Settings       NodeSettings
 	ConnectionInfo NodeConnectionInfo
@@ -72,6 +81,11 @@ type Node struct {
 	Config        NodeConfig
 	CurrentStatus NodeStatus
 	TargetStatus  NodeStatus
+
+	CurrentConfigVersion ConfigVersion
+	TargetConfigVersion  ConfigVersion
+	// last version rejected by node, not pushed again
+	FailedConfigVersion ConfigVersion
 }
 
 func (s NodeStatus) String() string {
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"time"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+)
+
 type NewNodeParams struct {
 	Endpoint  string
 	AccessKey AccessKey
@@ -17,6 +23,17 @@ type StopNodeParams struct {
 	ID NodeID
 }
 
+type ReloadNodeParams struct {
+	ID NodeID
+}
+
+// IDs are required, config is validated on every node
+type PushNodeConfigParams struct {
+	IDs          []NodeID
+	ServerConfig string
+	ClientConfig string
+}
+
 type ListNodeResult struct {
 	Nodes []Node
 }
@@ -25,13 +42,79 @@ type DeleteNodeParams struct {
 	ID NodeID
 }
 
+type NewNodeGroupParams struct {
+	Name string
+}
+
+type NewNodeGroupResult struct {
+	Group NodeGroup
+}
+
+type DeleteNodeGroupParams struct {
+	ID NodeGroupID
+}
+
+type ListNodeGroupsResult struct {
+	Groups []NodeGroup
+}
+
+// replaces all node groups, empty GroupIDs makes node available to all users
+type SetNodeGroupsParams struct {
+	ID       NodeID
+	GroupIDs []NodeGroupID
+}
+
 type NewUserParams struct {
 	DisplayName string
+	ExpiresAt   time.Time
 }
 
+// key is user subscription token,
+// or "<id>-<name>" if legacy links are allowed
 type GetUserParams struct {
-	ID   UserID
-	Name string
+	Key string
+}
+
+type RotateUserSubTokenParams struct {
+	ID UserID
+}
+
+type RotateUserSubTokenResult struct {
+	SubToken string
+}
+
+type RotateUserCredentialsParams struct {
+	ID UserID
+	// time previous credentials keep working, zero means none
+	GracePeriod time.Duration
+}
+
+type RotateUserCredentialsResult struct {
+	VlessUUID string
+	// previous credentials stop working at, zero if already
+	GraceUntil time.Time
+}
+
+type NewUserDeviceParams struct {
+	UserID UserID
+	Label  string
+}
+
+type ListUserDevicesParams struct {
+	UserID UserID
+}
+
+type ListUserDevicesResult struct {
+	Devices []UserDevice
+}
+
+type SetUserDeviceLabelParams struct {
+	ID    DeviceID
+	Label string
+}
+
+type DeleteUserDeviceParams struct {
+	ID DeviceID
 }
 
 type EnableUserParams struct {
@@ -46,16 +129,133 @@ type ListUsersResult struct {
 	Users []UserView
 }
 
+// user of bulk import, zero fields get defaults
+type ImportUser struct {
+	DisplayName string
+	// existing user credentials, empty means generated one
+	VlessUUID string
+	// zero means enabled
+	Status UserStatus
+	// zero period means one-off quota
+	Quota     TrafficQuota
+	ExpiresAt time.Time
+	IPLimit   int
+	// user email on nodes kept from migrated setup,
+	// empty means generated one
+	Email string
+	// extra credentials of user, e.g. migrated
+	// ones of other protocols, imported as devices
+	Devices []ImportDevice
+}
+
+// device of imported user
+type ImportDevice struct {
+	Label     string
+	VlessUUID string
+}
+
+type ImportUsersParams struct {
+	Users []ImportUser
+}
+
+type ImportUsersResult struct {
+	Users []User
+}
+
+type UsersExportFormat int
+
+const (
+	UsersExportFormatJSON UsersExportFormat = iota + 1
+	UsersExportFormatCSV
+)
+
 type DeleteUserParams struct {
 	ID UserID
 }
 
+type SetUserQuotaParams struct {
+	ID    UserID
+	Quota TrafficQuota
+}
+
+type SetUserExpirationParams struct {
+	ID        UserID
+	ExpiresAt time.Time
+}
+
+type SetUserIPLimitParams struct {
+	ID      UserID
+	IPLimit int
+}
+
+type IPLimitViolationsParams struct {
+	ID UserID
+}
+
+type IPLimitViolationsResult struct {
+	Violations []IPLimitViolation
+}
+
+// replaces all user groups
+type SetUserGroupsParams struct {
+	ID       UserID
+	GroupIDs []NodeGroupID
+}
+
+// traffic history days range, both ends included
+type TrafficHistoryRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func (r TrafficHistoryRange) Validate() error {
+	if r.To.Before(r.From) {
+		return xerr.Newf("traffic history range: %s is after %s",
+			r.From.Format(time.DateOnly), r.To.Format(time.DateOnly))
+	}
+	return nil
+}
+
+type UserTrafficHistoryParams struct {
+	ID    UserID
+	Range TrafficHistoryRange
+}
+
+type UserTrafficHistoryResult struct {
+	Days []DailyTraffic
+}
+
+type UserNodesTrafficHistoryParams struct {
+	ID    UserID
+	Range TrafficHistoryRange
+}
+
+type UserNodesTrafficHistoryResult struct {
+	Nodes []NodeTrafficHistory
+}
+
+type NodeTrafficHistoryParams struct {
+	ID    NodeID
+	Range TrafficHistoryRange
+}
+
+type NodeTrafficHistoryResult struct {
+	Days []DailyTraffic
+}
+
+// key is user subscription token,
+// or "<id>-<name>" if legacy links are allowed
 type UserSubParams struct {
-	ID   UserID
-	Name string
+	Key string
+	// zero format is detected by client user agent
+	Format    SubFormat
+	UserAgent string
+	// zero device means user own credentials
+	DeviceID DeviceID
 }
 
 type UserSubResult struct {
-	Headers       SubHeaders
-	ClientConfigs SubContent
+	Headers SubHeaders
+	Format  SubFormat
+	Content []byte
 }
@@ -1,14 +1,33 @@
 package models
 
-import (
-	"time"
+import "time"
 
-	"github.com/gofrs/uuid"
-)
+type SessionID = int
 
+// admin login, access tokens are short-lived and
+// renewed by refresh token until session is revoked or expired
 type Session struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	ExpiresAt time.Time
-	Revoked   bool
+	ID          SessionID
+	AdminID     AdminID
+	ClientIP    string
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+	ExpiresAt   time.Time
+	// session of request which lists sessions
+	Current bool
+}
+
+type RefreshParams struct {
+	RefreshToken string
+	ClientIP     string
+}
+
+type ListSessionsResult struct {
+	Sessions []Session
+}
+
+// admin can revoke only own sessions
+type RevokeSessionParams struct {
+	AdminID AdminID
+	ID      SessionID
 }
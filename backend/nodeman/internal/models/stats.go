@@ -1,11 +1,35 @@
 package models
 
+import "time"
+
 type UserStats struct {
 	ID       UserID
 	Uplink   int64
 	Downlink int64
+	// source ips currently connected to node
+	OnlineIPs []string
+}
+
+// DeviceStats is user device traffic, included to user one
+type DeviceStats struct {
+	ID       DeviceID
+	UserID   UserID
+	Uplink   int64
+	Downlink int64
 }
 
 type NodeStats struct {
-	Users []UserStats
+	Users   []UserStats
+	Devices []DeviceStats
+}
+
+// DailyTraffic is traffic used during the day
+type DailyTraffic struct {
+	Day     time.Time
+	Traffic TrafficStats
+}
+
+type NodeTrafficHistory struct {
+	NodeID NodeID
+	Days   []DailyTraffic
 }
@@ -12,3 +12,32 @@ type SubHeader struct {
 }
 
 type SubHeaders = []SubHeader
+
+// subscription format, defines client apps able to import it
+type SubFormat int
+
+const (
+	// json array of xray client configs
+	SubFormatXray SubFormat = iota + 1
+	// base64 encoded list of vless://, vmess://, trojan:// share links
+	SubFormatLinks
+	// clash meta (mihomo) yaml profile
+	SubFormatClash
+	// sing-box json profile
+	SubFormatSingBox
+)
+
+func (f SubFormat) String() string {
+	switch f {
+	case SubFormatXray:
+		return "xray"
+	case SubFormatLinks:
+		return "links"
+	case SubFormatClash:
+		return "clash"
+	case SubFormatSingBox:
+		return "sing-box"
+	default:
+		return "unknown"
+	}
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type UserID = int
@@ -12,14 +14,76 @@ type UserProfile struct {
 	DisplayName string
 	Name        string
 	VlessUUID   string
+	// random secret identifying user in public links
+	SubToken string
+	// user email on nodes kept from migrated setup,
+	// empty for generated "<id>-<name>" one
+	Email string
 }
 
 func (u UserProfile) VlessEmail() string {
+	if u.Email != "" {
+		return u.Email
+	}
 	return fmt.Sprintf("%d-%s", u.ID, u.Name)
 }
 
+// suffix of user name in previous identity email,
+// so both identities could be on node at the same time
+const prevIdentityNameSuffix = "-prev"
+
+// previous user identity kept on nodes during
+// credentials rotation grace period
+func (u UserProfile) PrevIdentity(vlessUUID string) UserProfile {
+	u.Name += prevIdentityNameSuffix
+	u.VlessUUID = vlessUUID
+	// kept email belongs to current identity only
+	u.Email = ""
+	return u
+}
+
+// user device identity on nodes, device email is derived from
+// generated user one as "<user id>-<user name>.<device id>",
+// kept user email is never used, so node could parse device stats
+func (u UserProfile) DeviceIdentity(d UserDevice) UserProfile {
+	u.Name = fmt.Sprintf("%s.%d", u.Name, d.ID)
+	u.VlessUUID = d.VlessUUID
+	u.Email = ""
+	return u
+}
+
 func (u UserProfile) SubscriptionURL() string {
-	return fmt.Sprintf("/sub/%d-%s", u.ID, u.Name)
+	return SubscriptionURL(u.SubToken)
+}
+
+func SubscriptionURL(subToken string) string {
+	return "/sub/" + subToken
+}
+
+// parse legacy "<id>-<name>" user key of public links,
+// replaced by subscription tokens
+func ParseLegacyUserKey(key string) (UserID, string, bool) {
+	idStr, name, ok := strings.Cut(key, "-")
+	if !ok || name == "" {
+		return 0, "", false
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return 0, "", false
+	}
+	return id, name, true
+}
+
+type DeviceID = int
+
+// user device with own credentials, user traffic includes devices one
+type UserDevice struct {
+	ID        DeviceID
+	UserID    UserID
+	Label     string
+	VlessUUID string
+	CreatedAt time.Time
+	Traffic   TrafficStats
 }
 
 type UserStatus int
@@ -30,19 +94,60 @@ const (
 	UserStatusEnabled
 )
 
+// traffic quota reset period
+type TrafficQuotaPeriod int
+
+const (
+	TrafficQuotaPeriodOneOff TrafficQuotaPeriod = iota + 1
+	TrafficQuotaPeriodMonthly
+)
+
+// user traffic limit, zero Limit means unlimited traffic
+type TrafficQuota struct {
+	Limit  int64
+	Period TrafficQuotaPeriod
+}
+
 type User struct {
 	Profile      UserProfile
 	TargetStatus UserStatus
+	Quota        TrafficQuota
+	// zero ExpiresAt means user never expires
+	ExpiresAt time.Time
+	// max simultaneous source ips, zero means unlimited
+	IPLimit int
+}
+
+// user online ips count exceeded user ip limit
+type IPLimitViolation struct {
+	UserID    UserID
+	IPCount   int
+	IPLimit   int
+	CreatedAt time.Time
 }
 
 type UserSyncStatus struct {
 	User          User
 	CurrentStatus UserStatus
+	// user identity on node, differs from
+	// user one after credentials rotation
+	CurrentVlessUUID string
+	// previous user identity required on node
+	// during credentials rotation grace period, empty if none
+	TargetPrevVlessUUID  string
+	CurrentPrevVlessUUID string
+	// user devices required on node and pushed to it,
+	// only device ids and vless uuids are set
+	TargetDevices  []UserDevice
+	CurrentDevices []UserDevice
 }
 
 type UserStatusPatch struct {
-	UserID UserID
-	Status UserStatus
+	UserID        UserID
+	Status        UserStatus
+	VlessUUID     string
+	PrevVlessUUID string
+	Devices       []UserDevice
 }
 
 type NodeUsersUpdate struct {
@@ -58,6 +163,8 @@ type TrafficStats struct {
 type UserTraffic struct {
 	Total     TrafficStats
 	LastMonth TrafficStats
+	// traffic used in the current quota period
+	CurrentPeriod TrafficStats
 }
 
 type UserView struct {
@@ -79,3 +186,7 @@ func (s UserStatus) String() string {
 func (s UserStatus) StringInt() string {
 	return strconv.Itoa(int(s))
 }
+
+func (q TrafficQuota) Unlimited() bool {
+	return q.Limit <= 0
+}
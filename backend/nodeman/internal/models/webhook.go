@@ -0,0 +1,94 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type WebhookEventType int
+
+const (
+	WebhookEventNodeUnavailable WebhookEventType = iota + 1
+	WebhookEventNodeRecovered
+	WebhookEventUserCreated
+	WebhookEventUserEnabled
+	WebhookEventUserDisabled
+	WebhookEventUserDeleted
+	WebhookEventUserQuotaReached
+	WebhookEventUserExpired
+	WebhookEventSyncError
+	WebhookEventUserIPLimitExceeded
+)
+
+// lifecycle event delivered to webhooks,
+// zero ids mean event isn't related to node or user
+type WebhookEvent struct {
+	Type   WebhookEventType
+	Time   time.Time
+	NodeID NodeID
+	UserID UserID
+	Error  string
+}
+
+type WebhookID = int
+
+// endpoint receiving events, payloads are signed with secret
+type Webhook struct {
+	ID  WebhookID
+	URL string
+	// empty means all events
+	Events    []WebhookEventType
+	Secret    string
+	CreatedAt time.Time
+}
+
+// check if webhook is subscribed to event
+func (w *Webhook) Accepts(t WebhookEventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, t)
+}
+
+type NewWebhookParams struct {
+	URL    string
+	Events []WebhookEventType
+}
+
+// secret is shown once on creation
+type NewWebhookResult struct {
+	Webhook Webhook
+}
+
+type ListWebhooksResult struct {
+	Webhooks []Webhook
+}
+
+type DeleteWebhookParams struct {
+	ID WebhookID
+}
+
+// event name as sent in payload
+func (t WebhookEventType) String() string {
+	switch t {
+	case WebhookEventNodeUnavailable:
+		return "node.unavailable"
+	case WebhookEventNodeRecovered:
+		return "node.recovered"
+	case WebhookEventUserCreated:
+		return "user.created"
+	case WebhookEventUserEnabled:
+		return "user.enabled"
+	case WebhookEventUserDisabled:
+		return "user.disabled"
+	case WebhookEventUserDeleted:
+		return "user.deleted"
+	case WebhookEventUserQuotaReached:
+		return "user.quota_reached"
+	case WebhookEventUserExpired:
+		return "user.expired"
+	case WebhookEventSyncError:
+		return "sync.error"
+	case WebhookEventUserIPLimitExceeded:
+		return "user.ip_limit_exceeded"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/auditman"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+type Service struct {
+	storage Storage
+}
+
+var _ handler.AuditService = (*Service)(nil)
+var _ auditman.AuditCleaner = (*Service)(nil)
+
+func New(storage Storage) (*Service, error) {
+	if storage == nil {
+		return nil, errdefs.NilArg("storage")
+	}
+	return &Service{
+		storage: storage,
+	}, nil
+}
+
+func (s *Service) ListAuditEvents(ctx context.Context, p models.ListAuditEventsParams) (
+	*models.ListAuditEventsResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if p.Limit <= 0 {
+		p.Limit = defaultAuditPageSize
+	}
+	p.Limit = min(p.Limit, maxAuditPageSize)
+
+	events, err := s.storage.ListAuditEvents(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	res := &models.ListAuditEventsResult{
+		Events: events,
+	}
+	// full page means there could be more events
+	if len(events) == p.Limit {
+		res.NextBeforeID = events[len(events)-1].ID
+	}
+	return res, nil
+}
+
+// delete events older than retention period from settings
+func (s *Service) CleanupAuditEvents(ctx context.Context) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	settings, err := s.storage.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+	if settings.AuditRetentionDays <= 0 {
+		return nil
+	}
+	retention := time.Duration(settings.AuditRetentionDays) * 24 * time.Hour
+	return s.storage.DeleteAuditEventsBefore(ctx, time.Now().Add(-retention))
+}
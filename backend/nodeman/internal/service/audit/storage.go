@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	// get audit events newest first
+	ListAuditEvents(ctx context.Context, p models.ListAuditEventsParams) (
+		[]models.AuditEvent, error)
+	// delete audit events created before the given time
+	DeleteAuditEventsBefore(ctx context.Context, before time.Time) error
+	// get settings with audit retention
+	GetSettings(ctx context.Context) (*models.Settings, error)
+}
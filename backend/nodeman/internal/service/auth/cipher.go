@@ -0,0 +1,7 @@
+package auth
+
+// seals totp secrets before they are stored
+type SecretCipher interface {
+	Seal(secret string) (string, error)
+	Open(stored string) (string, error)
+}
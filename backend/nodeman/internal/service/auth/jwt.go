@@ -5,5 +5,5 @@ import (
 )
 
 type JWT interface {
-	GenerateToken(subject string) (*models.AuthResult, error)
+	GenerateToken(subject string, sessionID string) (*models.AuthResult, error)
 }
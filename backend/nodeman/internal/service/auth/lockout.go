@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Lockout interface {
+	// return lock end if key is locked
+	Locked(key models.AuthLockoutKey) (time.Time, bool)
+	// register failed login, return lock end if key became locked
+	Fail(key models.AuthLockoutKey) (time.Time, bool)
+	// forget failures
+	Reset(key models.AuthLockoutKey)
+	// get active lockouts
+	List() []models.AuthLockout
+}
@@ -2,38 +2,104 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/refreshtoken"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/totp"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// shown in authenticator apps
+const totpIssuer = "xrayman"
+
+// audit operation for lockout events
+const lockoutOperation = "AuthLockout"
+
+// compared on unknown login, so response time doesn't tell
+// whether login exists
+var dummyPasswordHash = []byte("$2a$10$/OKCXYTU4Qwwk4BVkjvaculoCmj.s/yBlqgfATR.RaFHfJBeCU0xS")
+
 type Service struct {
-	storage Storage
-	jwt     JWT
+	storage    Storage
+	jwt        JWT
+	lockout    Lockout
+	cipher     SecretCipher
+	refreshTTL time.Duration
+	log        *zap.Logger
 }
 
-const adminTokenSubject = "admin"
+var _ handler.AuthService = (*Service)(nil)
 
-func New(storage Storage, jwt JWT) (*Service, error) {
+func New(storage Storage, jwt JWT, lockout Lockout, cipher SecretCipher,
+	refreshTTL time.Duration, log *zap.Logger,
+) (*Service, error) {
 	if storage == nil {
 		return nil, errdefs.NilArg("storage")
 	}
 	if jwt == nil {
 		return nil, errdefs.NilArg("jwt")
 	}
+	if lockout == nil {
+		return nil, errdefs.NilArg("lockout")
+	}
+	if cipher == nil {
+		return nil, errdefs.NilArg("cipher")
+	}
+	if log == nil {
+		return nil, errdefs.NilArg("log")
+	}
 	return &Service{
-		storage: storage,
-		jwt:     jwt,
+		storage:    storage,
+		jwt:        jwt,
+		lockout:    lockout,
+		cipher:     cipher,
+		refreshTTL: refreshTTL,
+		log:        log,
 	}, nil
 }
 
+// authenticate admin, empty login means default admin.
+// every login starts new session, token subject is admin id,
+// role is checked on every request.
+// repeated failures lock login for client ip
 func (s *Service) Auth(ctx context.Context, p models.AuthParams) (*models.AuthResult, error) {
 	if s == nil {
 		return nil, errdefs.NilCall()
 	}
-	auth, err := s.storage.GetAuth(ctx)
+	if p.Login == "" {
+		p.Login = models.DefaultAdminName
+	}
+	key := models.AuthLockoutKey{Login: p.Login, ClientIP: p.ClientIP}
+	if until, locked := s.lockout.Locked(key); locked {
+		return nil, errdefs.LockedOut(until)
+	}
+
+	token, err := s.auth(ctx, p)
+	if errors.Is(err, errdefs.ErrAccessDenied) {
+		s.registerFailure(ctx, key)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.lockout.Reset(key)
+	return token, nil
+}
+
+func (s *Service) auth(ctx context.Context, p models.AuthParams) (*models.AuthResult, error) {
+	auth, err := s.storage.GetAdminAuth(ctx, p.Login)
+	if errors.Is(err, errdefs.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(p.Password))
+		return nil, errdefs.AccessDenied()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -42,17 +108,161 @@ func (s *Service) Auth(ctx context.Context, p models.AuthParams) (*models.AuthRe
 	); err != nil {
 		return nil, errdefs.AccessDenied()
 	}
-	token, err := s.jwt.GenerateToken(adminTokenSubject)
+	if auth.TOTP.Enabled {
+		if err := s.checkSecondFactor(ctx, auth, p.OTP); err != nil {
+			return nil, err
+		}
+	}
+	return s.newSession(ctx, auth.Admin.ID, p.ClientIP)
+}
+
+func (s *Service) newSession(ctx context.Context,
+	adminID models.AdminID, clientIP string,
+) (*models.AuthResult, error) {
+	refreshToken, refreshHash, err := refreshtoken.New()
+	if err != nil {
+		return nil, err
+	}
+	session := models.Session{
+		AdminID:   adminID,
+		ClientIP:  clientIP,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.storage.NewSession(ctx, &session, refreshHash); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(&session, refreshToken)
+}
+
+func (s *Service) issueTokens(session *models.Session, refreshToken string) (
+	*models.AuthResult, error,
+) {
+	token, err := s.jwt.GenerateToken(
+		strconv.Itoa(session.AdminID),
+		strconv.Itoa(session.ID))
 	if err != nil {
 		return nil, err
 	}
+	token.RefreshToken = refreshToken
+	token.RefreshExpiresIn = s.refreshTTL
 	return token, nil
 }
 
+// exchange refresh token for new access and refresh tokens,
+// used refresh token isn't valid anymore
+func (s *Service) Refresh(ctx context.Context, p models.RefreshParams) (
+	*models.AuthResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	refreshToken, refreshHash, err := refreshtoken.New()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.storage.RotateSession(ctx,
+		refreshtoken.Hash(p.RefreshToken), refreshHash,
+		p.ClientIP, time.Now().Add(s.refreshTTL))
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return nil, errdefs.AccessDenied()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(session, refreshToken)
+}
+
+// list active sessions of admin, current is the session of request
+func (s *Service) ListSessions(ctx context.Context,
+	adminID models.AdminID, current models.SessionID,
+) (*models.ListSessionsResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	sessions, err := s.storage.ListSessions(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+	return &models.ListSessionsResult{
+		Sessions: sessions,
+	}, nil
+}
+
+// revoke own session, logout is revoke of current session
+func (s *Service) RevokeSession(ctx context.Context, p models.RevokeSessionParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	return s.storage.RevokeSession(ctx, p.AdminID, p.ID)
+}
+
+// lockout is logged and recorded to audit log
+func (s *Service) registerFailure(ctx context.Context, key models.AuthLockoutKey) {
+	until, locked := s.lockout.Fail(key)
+	if !locked {
+		return
+	}
+	s.log.Warn("admin login locked out",
+		zap.String("login", key.Login),
+		zap.String("clientIP", key.ClientIP),
+		zap.Time("until", until))
+
+	params, _ := json.Marshal(map[string]any{"LockedUntil": until})
+	event := models.AuditEvent{
+		Actor: models.AuditActor{
+			Kind: models.AuditActorAnonymous,
+			Name: key.Login,
+		},
+		Operation: lockoutOperation,
+		Params:    string(params),
+		ClientIP:  key.ClientIP,
+		Success:   true,
+	}
+	// lockout must be recorded even if client is gone
+	if err := s.storage.RecordAuditEvent(context.WithoutCancel(ctx), &event); err != nil {
+		s.log.Error("record lockout audit event", zap.Error(err))
+	}
+}
+
+func (s *Service) ListAuthLockouts(ctx context.Context) (*models.ListAuthLockoutsResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	return &models.ListAuthLockoutsResult{
+		Lockouts: s.lockout.List(),
+	}, nil
+}
+
+func (s *Service) UnlockAuth(ctx context.Context, p models.UnlockAuthParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	s.lockout.Reset(models.AuthLockoutKey{Login: p.Login, ClientIP: p.ClientIP})
+	s.log.Info("admin login unlocked",
+		zap.String("login", p.Login),
+		zap.String("clientIP", p.ClientIP))
+	return nil
+}
+
+// set default admin password, all sessions are revoked
+// if password is changed
 func (s *Service) Update(ctx context.Context, password string) error {
-	pwdHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	auth, err := s.storage.GetAdminAuth(ctx, models.DefaultAdminName)
+	if err != nil && !errors.Is(err, errdefs.ErrNotFound) {
+		return err
+	}
+	if auth != nil && bcrypt.CompareHashAndPassword(auth.PasswordHash,
+		[]byte(password),
+	) == nil {
+		return nil
+	}
+
+	pwdHash, err := hashPassword(password)
 	if err != nil {
-		return xerr.WrapWithStack(err)
+		return err
 	}
 
 	if err := s.storage.SetAuth(ctx, &models.Auth{
@@ -60,5 +270,234 @@ func (s *Service) Update(ctx context.Context, password string) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.storage.RevokeAllSessions(ctx); err != nil {
+		return err
+	}
 	return nil
 }
+
+func (s *Service) NewAdmin(ctx context.Context, p models.NewAdminParams) (*models.Admin, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	pwdHash, err := hashPassword(p.Password)
+	if err != nil {
+		return nil, err
+	}
+	admin := models.Admin{
+		Name: p.Name,
+		Role: p.Role,
+	}
+	if err := s.storage.NewAdmin(ctx, &admin, pwdHash); err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+func (s *Service) ListAdmins(ctx context.Context) (*models.ListAdminsResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	admins, err := s.storage.ListAdmins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListAdminsResult{
+		Admins: admins,
+	}, nil
+}
+
+func (s *Service) SetAdminRole(ctx context.Context, p models.SetAdminRoleParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if p.ID == models.DefaultAdminID && p.Role != models.AdminRoleOwner {
+		return errdefs.PayloadErr(xerr.New("default admin role can't be changed"))
+	}
+	return s.storage.SetAdminRole(ctx, p.ID, p.Role)
+}
+
+func (s *Service) SetAdminPassword(ctx context.Context, p models.SetAdminPasswordParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	pwdHash, err := hashPassword(p.Password)
+	if err != nil {
+		return err
+	}
+	if err := s.storage.SetAdminPassword(ctx, p.ID, pwdHash); err != nil {
+		return err
+	}
+	return s.storage.RevokeAdminSessions(ctx, p.ID)
+}
+
+func (s *Service) DeleteAdmin(ctx context.Context, p models.DeleteAdminParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if p.ID == models.DefaultAdminID {
+		return errdefs.PayloadErr(xerr.New("default admin can't be deleted"))
+	}
+	if err := s.storage.DeleteAdmin(ctx, p.ID); err != nil {
+		return err
+	}
+	return s.storage.RevokeAdminSessions(ctx, p.ID)
+}
+
+// start totp enrolment, secret isn't required for login
+// until it's confirmed by EnableTOTP
+func (s *Service) SetupTOTP(ctx context.Context, admin models.Admin) (
+	*models.TOTPSetupResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	auth, err := s.storage.GetAdminAuthByID(ctx, admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	if auth.TOTP.Enabled {
+		return nil, errdefs.PayloadErr(xerr.New("totp already enabled"))
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.cipher.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.SetAdminTOTPSecret(ctx, admin.ID, sealed); err != nil {
+		return nil, err
+	}
+	return &models.TOTPSetupResult{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(totpIssuer, admin.Name, secret),
+	}, nil
+}
+
+// confirm enrolment with code from authenticator app,
+// recovery codes are returned once. failed codes
+// lock admin out like failed logins
+func (s *Service) EnableTOTP(ctx context.Context, p models.EnableTOTPParams) (
+	*models.EnableTOTPResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	auth, err := s.storage.GetAdminAuthByID(ctx, p.AdminID)
+	if err != nil {
+		return nil, err
+	}
+	if auth.TOTP.Enabled {
+		return nil, errdefs.PayloadErr(xerr.New("totp already enabled"))
+	}
+	if auth.TOTP.Secret == "" {
+		return nil, errdefs.PayloadErr(xerr.New("totp setup not started"))
+	}
+	key := models.AuthLockoutKey{Login: auth.Admin.Name, ClientIP: p.ClientIP}
+	if until, locked := s.lockout.Locked(key); locked {
+		return nil, errdefs.LockedOut(until)
+	}
+	err = s.checkTOTPCode(ctx, auth, p.Code)
+	if errors.Is(err, errdefs.ErrAccessDenied) {
+		s.registerFailure(ctx, key)
+		return nil, errdefs.PayloadErr(xerr.New("invalid totp code"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.lockout.Reset(key)
+	codes, hashes, err := totp.NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.EnableAdminTOTP(ctx, p.AdminID, hashes); err != nil {
+		return nil, err
+	}
+	return &models.EnableTOTPResult{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// disable own totp, requires valid second factor,
+// failed codes lock admin out like failed logins
+func (s *Service) DisableTOTP(ctx context.Context, p models.DisableTOTPParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	auth, err := s.storage.GetAdminAuthByID(ctx, p.AdminID)
+	if err != nil {
+		return err
+	}
+	if auth.TOTP.Enabled {
+		key := models.AuthLockoutKey{Login: auth.Admin.Name, ClientIP: p.ClientIP}
+		if until, locked := s.lockout.Locked(key); locked {
+			return errdefs.LockedOut(until)
+		}
+		err := s.checkSecondFactor(ctx, auth, p.Code)
+		if errors.Is(err, errdefs.ErrAccessDenied) {
+			s.registerFailure(ctx, key)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		s.lockout.Reset(key)
+	}
+	return s.storage.DisableAdminTOTP(ctx, p.AdminID)
+}
+
+// disable totp of admin who lost authenticator and recovery codes
+func (s *Service) ResetAdminTOTP(ctx context.Context, p models.ResetAdminTOTPParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	return s.storage.DisableAdminTOTP(ctx, p.ID)
+}
+
+// accept totp code or unused recovery code
+func (s *Service) checkSecondFactor(ctx context.Context,
+	auth *models.Auth, code string,
+) error {
+	if code == "" {
+		return errdefs.OTPRequired()
+	}
+	err := s.checkTOTPCode(ctx, auth, code)
+	if !errors.Is(err, errdefs.ErrAccessDenied) {
+		return err
+	}
+	err = s.storage.UseRecoveryCode(ctx, auth.Admin.ID, totp.HashRecoveryCode(code))
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return errdefs.AccessDenied()
+	}
+	return err
+}
+
+// accept totp code once, codes of already used
+// time steps are rejected as replayed
+func (s *Service) checkTOTPCode(ctx context.Context,
+	auth *models.Auth, code string,
+) error {
+	secret, err := s.cipher.Open(auth.TOTP.Secret)
+	if err != nil {
+		return err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return errdefs.AccessDenied()
+	}
+	err = s.storage.UseAdminTOTPStep(ctx, auth.Admin.ID, step)
+	if errors.Is(err, errdefs.ErrNotFound) {
+		return errdefs.AccessDenied()
+	}
+	return err
+}
+
+func hashPassword(password string) ([]byte, error) {
+	pwdHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, xerr.WrapWithStack(err)
+	}
+	return pwdHash, nil
+}
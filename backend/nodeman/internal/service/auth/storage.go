@@ -2,11 +2,49 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
 
 type Storage interface {
+	// get default admin auth
 	GetAuth(ctx context.Context) (*models.Auth, error)
+	// set default admin password
 	SetAuth(ctx context.Context, auth *models.Auth) error
+	// get admin auth by name, return ErrNotFound if not exists
+	GetAdminAuth(ctx context.Context, name string) (*models.Auth, error)
+	// add new admin, assign AdminID to admin
+	NewAdmin(ctx context.Context, admin *models.Admin, passwordHash []byte) error
+	ListAdmins(ctx context.Context) ([]models.Admin, error)
+	SetAdminRole(ctx context.Context, id models.AdminID, role models.AdminRole) error
+	SetAdminPassword(ctx context.Context, id models.AdminID, passwordHash []byte) error
+	DeleteAdmin(ctx context.Context, id models.AdminID) error
+	// get admin auth by id, return ErrNotFound if not exists
+	GetAdminAuthByID(ctx context.Context, id models.AdminID) (*models.Auth, error)
+	// set not yet enabled totp secret, ignored if totp already enabled
+	SetAdminTOTPSecret(ctx context.Context, id models.AdminID, secret string) error
+	// enable totp and replace recovery codes
+	EnableAdminTOTP(ctx context.Context, id models.AdminID, recoveryCodeHashes [][]byte) error
+	// remove totp secret and recovery codes
+	DisableAdminTOTP(ctx context.Context, id models.AdminID) error
+	// record accepted totp time step, return ErrNotFound
+	// if step isn't greater than last recorded one
+	UseAdminTOTPStep(ctx context.Context, id models.AdminID, step int64) error
+	// mark recovery code used, return ErrNotFound if not exists or already used
+	UseRecoveryCode(ctx context.Context, id models.AdminID, codeHash []byte) error
+	// append audit event
+	RecordAuditEvent(ctx context.Context, e *models.AuditEvent) error
+	// add new session, assign SessionID to session
+	NewSession(ctx context.Context, session *models.Session, refreshHash []byte) error
+	// replace refresh token of active session, return ErrNotFound
+	// if token is unknown, already rotated, revoked or expired
+	RotateSession(ctx context.Context, refreshHash, newRefreshHash []byte,
+		clientIP string, expiresAt time.Time) (*models.Session, error)
+	// get active admin sessions
+	ListSessions(ctx context.Context, adminID models.AdminID) ([]models.Session, error)
+	// revoke admin session, ignored if not exists
+	RevokeSession(ctx context.Context, adminID models.AdminID, id models.SessionID) error
+	RevokeAdminSessions(ctx context.Context, adminID models.AdminID) error
+	RevokeAllSessions(ctx context.Context) error
 }
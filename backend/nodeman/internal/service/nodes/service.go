@@ -2,8 +2,12 @@ package nodes
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/XRay-Addons/xrayman/common/jsonval"
+	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/supervisor"
@@ -99,6 +103,71 @@ func (s *Service) StopNode(ctx context.Context, p models.StopNodeParams) error {
 	return nil
 }
 
+// ReloadNode makes node re-read its configs and stores
+// new node settings, runs synchronously to report reload errors
+func (s *Service) ReloadNode(ctx context.Context, p models.ReloadNodeParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if err := s.poolSyncer.ReloadNodeConfig(ctx, p.ID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PushNodeConfig validates configs on nodes, stores new configs
+// version for them and requests their sync, nodes apply configs on sync
+func (s *Service) PushNodeConfig(ctx context.Context, p models.PushNodeConfigParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+
+	if len(p.IDs) == 0 {
+		return errdefs.PayloadErr(xerr.New("node ids are required"))
+	}
+	if err := jsonval.ValidateJsonData([]byte(p.ServerConfig)); err != nil {
+		return errdefs.PayloadErr(xerr.WrapWithInfo(err, "server config"))
+	}
+	if err := jsonval.ValidateJsonData([]byte(p.ClientConfig)); err != nil {
+		return errdefs.PayloadErr(xerr.WrapWithInfo(err, "client config"))
+	}
+
+	cfg := models.NodeXRayConfig{
+		ServerConfig: p.ServerConfig,
+		ClientConfig: p.ClientConfig,
+	}
+
+	// rejected config is reported to admin right now,
+	// unavailable nodes validate config on sync
+	for _, id := range p.IDs {
+		err := s.poolSyncer.ValidateNodeConfig(ctx, id, cfg)
+		if errors.Is(err, errdefs.ErrInvaildPayload) || errors.Is(err, errdefs.ErrNotFound) {
+			return xerr.WrapWithInfo(err, fmt.Sprintf("node %d", id))
+		}
+		if err != nil {
+			s.logger.Warn("node config not validated",
+				zap.Int("node_id", id), zap.Error(err))
+		}
+	}
+
+	if err := s.storage.DoTx(ctx, func(ctx context.Context) error {
+		for _, id := range p.IDs {
+			if _, err := s.storage.PushNodeConfig(ctx, id, cfg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, id := range p.IDs {
+		s.requestNodeSync(id)
+	}
+
+	return nil
+}
+
 func (s *Service) ListNodes(ctx context.Context) (
 	*models.ListNodeResult, error,
 ) {
@@ -111,6 +180,24 @@ func (s *Service) ListNodes(ctx context.Context) (
 	}, nil
 }
 
+func (s *Service) GetNodeTrafficHistory(ctx context.Context,
+	p models.NodeTrafficHistoryParams,
+) (*models.NodeTrafficHistoryResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if err := p.Range.Validate(); err != nil {
+		return nil, errdefs.PayloadErr(err)
+	}
+	days, err := s.storage.GetNodeTrafficHistory(ctx, p.ID, p.Range)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NodeTrafficHistoryResult{
+		Days: days,
+	}, nil
+}
+
 func (s *Service) DeleteNode(ctx context.Context, p models.DeleteNodeParams) error {
 	// mark node stopped and deleting
 	if err := s.storage.DoTx(ctx, func(ctx context.Context) error {
@@ -132,6 +219,75 @@ func (s *Service) DeleteNode(ctx context.Context, p models.DeleteNodeParams) err
 	return nil
 }
 
+func (s *Service) NewNodeGroup(ctx context.Context, p models.NewNodeGroupParams) (
+	*models.NewNodeGroupResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	group := models.NodeGroup{
+		Name:    p.Name,
+		NodeIDs: []models.NodeID{},
+		UserIDs: []models.UserID{},
+	}
+	if err := s.storage.NewNodeGroup(ctx, &group); err != nil {
+		return nil, err
+	}
+	return &models.NewNodeGroupResult{Group: group}, nil
+}
+
+func (s *Service) ListNodeGroups(ctx context.Context) (
+	*models.ListNodeGroupsResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	groups, err := s.storage.ListNodeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListNodeGroupsResult{Groups: groups}, nil
+}
+
+// DeleteNodeGroup deletes group, its nodes become
+// available to users according to remaining groups
+func (s *Service) DeleteNodeGroup(ctx context.Context, p models.DeleteNodeGroupParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+
+	var nodeIDs []models.NodeID
+	if err := s.storage.DoTx(ctx, func(ctx context.Context) (err error) {
+		if nodeIDs, err = s.storage.ListNodeGroupNodes(ctx, p.ID); err != nil {
+			return err
+		}
+		return s.storage.DeleteNodeGroup(ctx, p.ID)
+	}); err != nil {
+		return err
+	}
+
+	for _, id := range nodeIDs {
+		s.requestNodeSync(id)
+	}
+
+	return nil
+}
+
+// SetNodeGroups replaces node groups, node sync
+// adds or removes users according to new groups
+func (s *Service) SetNodeGroups(ctx context.Context, p models.SetNodeGroupsParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if err := s.storage.SetNodeGroups(ctx, p.ID, p.GroupIDs); err != nil {
+		return err
+	}
+
+	s.requestNodeSync(p.ID)
+
+	return nil
+}
+
 func (s *Service) setNodeStatus(ctx context.Context,
 	id models.NodeID, status models.NodeStatus,
 ) error {
@@ -16,6 +16,24 @@ type Storage interface {
 	// change node target status
 	SetTargetNodeStatus(ctx context.Context, id models.NodeID,
 		status models.NodeStatus) error
+	// store new node config version, node applies it on sync
+	PushNodeConfig(ctx context.Context, id models.NodeID,
+		cfg models.NodeXRayConfig) (models.ConfigVersion, error)
+	// add new node group, assign NodeGroupID to group
+	NewNodeGroup(ctx context.Context, group *models.NodeGroup) error
+	// get all node groups with their nodes and users
+	ListNodeGroups(ctx context.Context) ([]models.NodeGroup, error)
+	// get nodes of node group
+	ListNodeGroupNodes(ctx context.Context,
+		id models.NodeGroupID) ([]models.NodeID, error)
+	// delete node group with its nodes and users assignment
+	DeleteNodeGroup(ctx context.Context, id models.NodeGroupID) error
+	// replace node groups
+	SetNodeGroups(ctx context.Context, id models.NodeID,
+		groups []models.NodeGroupID) error
+	// get node daily traffic
+	GetNodeTrafficHistory(ctx context.Context, id models.NodeID,
+		r models.TrafficHistoryRange) ([]models.DailyTraffic, error)
 	// delete node
 	DeleteNode(ctx context.Context,
 		id models.NodeID) error
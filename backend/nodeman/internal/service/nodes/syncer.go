@@ -8,4 +8,7 @@ import (
 
 type Syncer interface {
 	SyncNodeState(ctx context.Context, id models.NodeID) error
+	ReloadNodeConfig(ctx context.Context, id models.NodeID) error
+	ValidateNodeConfig(ctx context.Context, id models.NodeID,
+		cfg models.NodeXRayConfig) error
 }
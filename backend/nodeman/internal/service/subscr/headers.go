@@ -3,7 +3,10 @@ package subscr
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
@@ -16,20 +19,46 @@ const (
 	AnnounceHeader              = "announce"
 	RoutingHeader               = "routing"
 	TrafficStatsHeader          = "subscription-userinfo"
-	TrafficStatsFmt             = "upload=%d; download=%d; total=0; expire=0"
+	TrafficStatsFmt             = "upload=%d; download=%d; total=%d; expire=%d"
+	ContentDispositionHeader    = "content-disposition"
+	ContentDispositionFmt       = "attachment; filename*=UTF-8''%s"
 )
 
+// headers understood by client apps of the format, other
+// headers are dropped. all headers are sent for formats not listed,
+// custom headers are always sent
+var formatHeaders = map[models.SubFormat][]string{
+	models.SubFormatClash: {
+		ProfileTitleHeader, ContentDispositionHeader, ProfileUpdateIntervalHeader,
+		WebPageHeader, TrafficStatsHeader,
+	},
+	models.SubFormatSingBox: {
+		ProfileTitleHeader, ProfileUpdateIntervalHeader, TrafficStatsHeader,
+	},
+}
+
+// users exceeded ip limit see ip limit message during this period
+const ipLimitWarnPeriod = 24 * time.Hour
+
 func createClientHeaders(ctx context.Context,
-	u *models.UserView, settings *models.Settings,
+	u *models.UserView, settings *models.Settings, ipWarned bool,
+	format models.SubFormat,
 ) models.SubHeaders {
 	var headers []models.SubHeader
 
-	// title
+	// title, clash takes profile name from file name
 	if settings.SubscrTitle != "" {
+		title := replacePlaceholders(settings.SubscrTitle, u)
 		headers = append(headers, models.SubHeader{
 			Key:   ProfileTitleHeader,
-			Value: replacePlaceholders(settings.SubscrTitle, u),
+			Value: title,
 		})
+		if format == models.SubFormatClash {
+			headers = append(headers, models.SubHeader{
+				Key:   ContentDispositionHeader,
+				Value: fmt.Sprintf(ContentDispositionFmt, url.PathEscape(title)),
+			})
+		}
 	}
 	// update interval
 	if settings.UpdateInterval != 0 {
@@ -52,11 +81,15 @@ func createClientHeaders(ctx context.Context,
 			Value: replacePlaceholders(settings.UserPage, u),
 		})
 	}
-	// announce header
-	if settings.UsersMessage != "" {
+	// announce header, ip limit warning replaces common message
+	announce := settings.UsersMessage
+	if ipWarned && settings.IPLimitMessage != "" {
+		announce = settings.IPLimitMessage
+	}
+	if announce != "" {
 		headers = append(headers, models.SubHeader{
 			Key:   AnnounceHeader,
-			Value: replacePlaceholders(settings.UsersMessage, u),
+			Value: replacePlaceholders(announce, u),
 		})
 	}
 	// routing header
@@ -66,14 +99,31 @@ func createClientHeaders(ctx context.Context,
 			Value: settings.Routing,
 		})
 	}
-	// custom headers
-	headers = append(headers, settings.CustomHeaders...)
-	// traffic stats header
+	// traffic stats header, limited users see
+	// traffic used in the current quota period
 	ts := u.Traffic.Total
+	if !u.User.Quota.Unlimited() {
+		ts = u.Traffic.CurrentPeriod
+	}
+	// expire is unix time, 0 for users without expiration
+	var expire int64
+	if !u.User.ExpiresAt.IsZero() {
+		expire = u.User.ExpiresAt.Unix()
+	}
 	headers = append(headers, models.SubHeader{
 		Key:   TrafficStatsHeader,
-		Value: fmt.Sprintf(TrafficStatsFmt, ts.Upload, ts.Download),
+		Value: fmt.Sprintf(TrafficStatsFmt, ts.Upload, ts.Download, u.User.Quota.Limit, expire),
 	})
 
+	// drop headers format clients don't understand
+	if keep, ok := formatHeaders[format]; ok {
+		headers = slices.DeleteFunc(headers, func(h models.SubHeader) bool {
+			return !slices.Contains(keep, h.Key)
+		})
+	}
+
+	// custom headers
+	headers = append(headers, settings.CustomHeaders...)
+
 	return headers
 }
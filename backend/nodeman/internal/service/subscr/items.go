@@ -2,6 +2,7 @@ package subscr
 
 import (
 	"github.com/XRay-Addons/xrayman/common/jsonval"
+	"github.com/XRay-Addons/xrayman/common/xraysecret"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/template"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"github.com/go-faster/jx"
@@ -31,9 +32,12 @@ func createNodeClientCfgs(user models.User,
 ) ([]models.ClientConfigItem, error) {
 	nodeConfigs := make([]models.ClientConfigItem, 0, len(cfgTemplate.Template))
 	for _, item := range cfgTemplate.Template {
+		// uuid var is set even for templates without vless users,
+		// shadowsocks keys are derived from it
 		tmpl, err := template.RenderTemplate(item.String(), map[string]string{
 			cfgTemplate.VlessEmailField: user.Profile.VlessEmail(),
 			cfgTemplate.VlessUUIDField:  user.Profile.VlessUUID,
+			xraysecret.UUIDVar:          user.Profile.VlessUUID,
 		})
 		if err != nil {
 			return nil, err
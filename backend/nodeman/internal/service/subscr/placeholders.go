@@ -12,6 +12,8 @@ const (
 	UserIDPlaceholder          = "UserID"
 	UserNamePlaceholder        = "UserName"
 	UserDisplayNamePlaceholder = "DisplayName"
+	UserIPLimitPlaceholder     = "IPLimit"
+	UserSubTokenPlaceholder    = "SubToken"
 )
 
 func replacePlaceholders(s string, u *models.UserView) string {
@@ -19,6 +21,8 @@ func replacePlaceholders(s string, u *models.UserView) string {
 		UserIDPlaceholder:          fmt.Sprintf("%v", u.User.Profile.ID),
 		UserNamePlaceholder:        u.User.Profile.Name,
 		UserDisplayNamePlaceholder: u.User.Profile.DisplayName,
+		UserIPLimitPlaceholder:     fmt.Sprintf("%v", u.User.IPLimit),
+		UserSubTokenPlaceholder:    u.User.Profile.SubToken,
 	})
 }
 
@@ -27,6 +31,8 @@ func listPlaceholders() []string {
 		makePlaceholder(UserIDPlaceholder),
 		makePlaceholder(UserNamePlaceholder),
 		makePlaceholder(UserDisplayNamePlaceholder),
+		makePlaceholder(UserIPLimitPlaceholder),
+		makePlaceholder(UserSubTokenPlaceholder),
 	}
 }
 
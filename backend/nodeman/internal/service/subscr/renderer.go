@@ -0,0 +1,21 @@
+package subscr
+
+import (
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/subformat"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+// Renderer converts rendered node templates (xray client configs)
+// into subscription content of some format
+type Renderer interface {
+	Render(cfgs []models.ClientConfigItem) ([]byte, error)
+}
+
+func defaultRenderers() map[models.SubFormat]Renderer {
+	return map[models.SubFormat]Renderer{
+		models.SubFormatXray:    subformat.Xray{},
+		models.SubFormatLinks:   subformat.Links{},
+		models.SubFormatClash:   subformat.Clash{},
+		models.SubFormatSingBox: subformat.SingBox{},
+	}
+}
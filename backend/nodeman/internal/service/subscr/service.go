@@ -2,11 +2,14 @@ package subscr
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/common/xerrgroup"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/subformat"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"go.uber.org/zap"
 )
@@ -21,9 +24,28 @@ func WithLogger(l *zap.Logger) option {
 	}
 }
 
+// accept legacy "<id>-<name>" user keys along with subscription tokens
+func WithLegacyLinks(allow bool) option {
+	return func(s *Service) {
+		s.legacyLinks = allow
+	}
+}
+
+// set renderer for format, replaces default one
+func WithRenderer(f models.SubFormat, r Renderer) option {
+	return func(s *Service) {
+		if r != nil {
+			s.renderers[f] = r
+		}
+	}
+}
+
 type Service struct {
-	storage Storage
-	log     *zap.Logger
+	storage   Storage
+	renderers map[models.SubFormat]Renderer
+	// accept legacy user keys
+	legacyLinks bool
+	log         *zap.Logger
 }
 
 var _ handler.SubscrService = (*Service)(nil)
@@ -33,8 +55,9 @@ func New(storage Storage, opts ...option) (*Service, error) {
 		return nil, errdefs.NilArg("storage")
 	}
 	s := &Service{
-		storage: storage,
-		log:     zap.NewNop(),
+		storage:   storage,
+		renderers: defaultRenderers(),
+		log:       zap.NewNop(),
 	}
 	for _, o := range opts {
 		o(s)
@@ -49,18 +72,24 @@ func (s *Service) GetUserSub(ctx context.Context,
 		return nil, errdefs.NilCall()
 	}
 
+	// resolve user key
+	id, name, err := s.findUser(ctx, p.Key)
+	if err != nil {
+		return nil, err
+	}
+
 	g, ctx := xerrgroup.WithContext(ctx)
 	// find user
 	var user *models.UserView
 	g.Go(func() (err error) {
-		user, err = s.storage.GetUserView(ctx, p.ID, p.Name)
+		user, err = s.storage.GetUserView(ctx, id, name)
 		return
 	})
 
 	// get active nodes for user
 	var userNodes []models.Node
 	g.Go(func() (err error) {
-		userNodes, err = s.storage.GetUserNodes(ctx, p.ID)
+		userNodes, err = s.storage.GetUserNodes(ctx, id)
 		return
 	})
 
@@ -71,6 +100,23 @@ func (s *Service) GetUserSub(ctx context.Context,
 		return
 	})
 
+	// check user recently exceeded ip limit
+	var ipWarned bool
+	g.Go(func() (err error) {
+		since := time.Now().Add(-ipLimitWarnPeriod)
+		ipWarned, err = s.storage.HasIPLimitViolation(ctx, id, since)
+		return
+	})
+
+	// get requested user device
+	var device *models.UserDevice
+	if p.DeviceID != 0 {
+		g.Go(func() (err error) {
+			device, err = s.storage.GetUserDevice(ctx, id, p.DeviceID)
+			return
+		})
+	}
+
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
@@ -80,18 +126,49 @@ func (s *Service) GetUserSub(ctx context.Context,
 		return nil, xerr.WrapWithStack(errdefs.ErrNotFound)
 	}
 
+	// explicitly requested format wins over user agent
+	format := p.Format
+	if format == 0 {
+		format = subformat.Detect(p.UserAgent)
+	}
+	renderer, ok := s.renderers[format]
+	if !ok {
+		return nil, errdefs.PayloadErr(xerr.Newf("unsupported subscription format: %v", format))
+	}
+
 	// get subscription content
-	clientCfgs := createClientCfgs(user, userNodes, s.log)
+	// device configs use device credentials, other user data is kept
+	cfgUser := *user
+	if device != nil {
+		cfgUser.User.Profile = user.User.Profile.DeviceIdentity(*device)
+	}
+	clientCfgs := createClientCfgs(&cfgUser, userNodes, s.log)
+	content, err := renderer.Render(clientCfgs)
+	if err != nil {
+		return nil, err
+	}
 
 	// get subscription headers
-	clientHeaders := createClientHeaders(ctx, user, settings)
+	clientHeaders := createClientHeaders(ctx, user, settings, ipWarned, format)
 
 	return &models.UserSubResult{
-		Headers:       clientHeaders,
-		ClientConfigs: clientCfgs,
+		Headers: clientHeaders,
+		Format:  format,
+		Content: content,
 	}, nil
 }
 
+// resolve user key of public links to user id and name
+func (s *Service) findUser(ctx context.Context, key string) (models.UserID, string, error) {
+	id, name, err := s.storage.FindUserBySubToken(ctx, key)
+	if errors.Is(err, errdefs.ErrNotFound) && s.legacyLinks {
+		if id, name, ok := models.ParseLegacyUserKey(key); ok {
+			return id, name, nil
+		}
+	}
+	return id, name, err
+}
+
 func (s Service) SubHeadersPlaceholders() []string {
 	return listPlaceholders()
 }
@@ -2,6 +2,7 @@ package subscr
 
 import (
 	"context"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
@@ -9,6 +10,13 @@ import (
 type Storage interface {
 	GetUserNodes(ctx context.Context, id models.UserID) ([]models.Node, error)
 	GetUserView(ctx context.Context, id models.UserID, name string) (*models.UserView, error)
+	// find user by subscription token, return ErrNotFound if not exists
+	FindUserBySubToken(ctx context.Context, token string) (models.UserID, string, error)
+	// get user device, return ErrNotFound if not exists
+	GetUserDevice(ctx context.Context, userID models.UserID,
+		id models.DeviceID) (*models.UserDevice, error)
 
 	GetSettings(ctx context.Context) (*models.Settings, error)
+	// check user has ip limit violations since the given time
+	HasIPLimitViolation(ctx context.Context, id models.UserID, since time.Time) (bool, error)
 }
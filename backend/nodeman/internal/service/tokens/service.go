@@ -0,0 +1,73 @@
+package tokens
+
+import (
+	"context"
+	"slices"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/apitoken"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Service struct {
+	storage Storage
+}
+
+var _ handler.TokensService = (*Service)(nil)
+
+func New(storage Storage) (*Service, error) {
+	if storage == nil {
+		return nil, errdefs.NilArg("storage")
+	}
+	return &Service{
+		storage: storage,
+	}, nil
+}
+
+// create api token, only token hash is stored,
+// so secret is returned to caller once
+func (s *Service) NewApiToken(ctx context.Context, p models.NewApiTokenParams) (
+	*models.NewApiTokenResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	secret, hash, err := apitoken.New()
+	if err != nil {
+		return nil, err
+	}
+	scopes := slices.Clone(p.Scopes)
+	slices.Sort(scopes)
+	token := models.ApiToken{
+		Name:   p.Name,
+		Scopes: slices.Compact(scopes),
+	}
+	if err := s.storage.NewApiToken(ctx, &token, hash); err != nil {
+		return nil, err
+	}
+	return &models.NewApiTokenResult{
+		Token:  token,
+		Secret: secret,
+	}, nil
+}
+
+func (s *Service) ListApiTokens(ctx context.Context) (*models.ListApiTokensResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	tokens, err := s.storage.ListApiTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListApiTokensResult{
+		Tokens: tokens,
+	}, nil
+}
+
+func (s *Service) RevokeApiToken(ctx context.Context, p models.RevokeApiTokenParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	return s.storage.RevokeApiToken(ctx, p.ID)
+}
@@ -0,0 +1,16 @@
+package tokens
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	// add new api token, assign ApiTokenID and creation time to token
+	NewApiToken(ctx context.Context, token *models.ApiToken, tokenHash []byte) error
+	// get active api tokens
+	ListApiTokens(ctx context.Context) ([]models.ApiToken, error)
+	// revoke api token, revoked token can't be used anymore
+	RevokeApiToken(ctx context.Context, id models.ApiTokenID) error
+}
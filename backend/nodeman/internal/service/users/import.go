@@ -0,0 +1,287 @@
+package users
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+	"github.com/google/uuid"
+)
+
+// max users in one import
+const maxImportUsers = 10000
+
+// max user display name length in characters
+const maxDisplayNameLen = 128
+
+// max kept user email length in characters
+const maxEmailLen = 128
+
+// imported user with its devices
+type importedUser struct {
+	user    models.User
+	devices []models.UserDevice
+}
+
+// ImportUsers creates users in bulk. all users are validated up front
+// and created in one transaction, then nodes are synced once in background
+func (s *Service) ImportUsers(ctx context.Context, p models.ImportUsersParams) (
+	*models.ImportUsersResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	imported, err := makeImportedUsers(p.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.DoTx(ctx, func(ctx context.Context) error {
+		if err := s.checkImportedClashes(ctx, imported); err != nil {
+			return err
+		}
+		for i := range imported {
+			u := &imported[i]
+			if err := s.storage.NewUser(ctx, &u.user); err != nil {
+				return err
+			}
+			for j := range u.devices {
+				u.devices[j].UserID = u.user.Profile.ID
+				if err := s.storage.NewUserDevice(ctx, &u.devices[j]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	users := make([]models.User, 0, len(imported))
+	for _, u := range imported {
+		s.notifyUser(ctx, models.WebhookEventUserCreated, u.user.Profile.ID)
+		users = append(users, u.user)
+	}
+
+	// sync nodes. errors is not a problem, it will updates in background
+	s.requestNodesSync()
+
+	return &models.ImportUsersResult{
+		Users: users,
+	}, nil
+}
+
+// imported credentials and emails must not clash with existing ones
+func (s *Service) checkImportedClashes(ctx context.Context,
+	imported []importedUser,
+) error {
+	uuids := make(map[string]int, len(imported))
+	emails := make(map[string]int)
+	for i, u := range imported {
+		uuids[u.user.Profile.VlessUUID] = i
+		for _, d := range u.devices {
+			uuids[d.VlessUUID] = i
+		}
+		if u.user.Profile.Email != "" {
+			emails[u.user.Profile.Email] = i
+		}
+	}
+
+	existing, err := s.storage.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if i, ok := uuids[e.Profile.VlessUUID]; ok {
+			return errdefs.PayloadErr(xerr.Newf(
+				"user %d: vless uuid is used by user %d", i+1, e.Profile.ID))
+		}
+		if i, ok := emails[e.Profile.Email]; ok {
+			return errdefs.PayloadErr(xerr.Newf(
+				"user %d: email is used by user %d", i+1, e.Profile.ID))
+		}
+	}
+
+	devices, err := s.storage.ListDevicesVlessUUIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if i, ok := uuids[d]; ok {
+			return errdefs.PayloadErr(xerr.Newf(
+				"user %d: vless uuid is used by existing device", i+1))
+		}
+	}
+	return nil
+}
+
+// validate all imported users, all errors are reported at once
+func makeImportedUsers(imported []models.ImportUser) ([]importedUser, error) {
+	if len(imported) == 0 {
+		return nil, errdefs.PayloadErr(xerr.New("no users to import"))
+	}
+	if len(imported) > maxImportUsers {
+		return nil, errdefs.PayloadErr(xerr.Newf(
+			"can't import more than %d users at once", maxImportUsers))
+	}
+
+	users := make([]importedUser, 0, len(imported))
+	uuids := make(map[string]int, len(imported))
+	emails := make(map[string]int)
+	var errs []error
+	for i, u := range imported {
+		user, err := makeImportedUser(u)
+		if err != nil {
+			errs = append(errs, xerr.Newf("user %d: %v", i+1, err))
+			continue
+		}
+		if err := checkImportedDuplicates(user, i, uuids, emails); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		users = append(users, user)
+	}
+	if err := xerr.Join(errs...); err != nil {
+		return nil, errdefs.PayloadErr(err)
+	}
+	return users, nil
+}
+
+// imported users must not share credentials and emails,
+// credentials and email of valid user are added to maps
+func checkImportedDuplicates(u importedUser, i int,
+	uuids, emails map[string]int,
+) error {
+	credentials := []string{u.user.Profile.VlessUUID}
+	for _, d := range u.devices {
+		credentials = append(credentials, d.VlessUUID)
+	}
+	seen := make(map[string]struct{}, len(credentials))
+	for _, c := range credentials {
+		if prev, ok := uuids[c]; ok {
+			return xerr.Newf("user %d: vless uuid is used by user %d", i+1, prev+1)
+		}
+		if _, ok := seen[c]; ok {
+			return xerr.Newf("user %d: vless uuid %q is used twice", i+1, c)
+		}
+		seen[c] = struct{}{}
+	}
+	email := u.user.Profile.Email
+	if prev, ok := emails[email]; ok && email != "" {
+		return xerr.Newf("user %d: email is used by user %d", i+1, prev+1)
+	}
+
+	for _, c := range credentials {
+		uuids[c] = i
+	}
+	if email != "" {
+		emails[email] = i
+	}
+	return nil
+}
+
+func makeImportedUser(u models.ImportUser) (importedUser, error) {
+	var imported importedUser
+	user := &imported.user
+	if u.DisplayName == "" {
+		return imported, xerr.New("empty display name")
+	}
+	if utf8.RuneCountInString(u.DisplayName) > maxDisplayNameLen {
+		return imported, xerr.Newf(
+			"display name is longer than %d characters", maxDisplayNameLen)
+	}
+
+	vlessUUID := u.VlessUUID
+	if vlessUUID == "" {
+		var err error
+		if vlessUUID, err = generateVlessUUID(); err != nil {
+			return imported, err
+		}
+	} else {
+		var err error
+		if vlessUUID, err = parseImportedUUID(vlessUUID); err != nil {
+			return imported, err
+		}
+	}
+	if err := validateImportedEmail(u.Email); err != nil {
+		return imported, err
+	}
+	if len(u.Devices) > maxUserDevices {
+		return imported, xerr.Newf(
+			"user can't have more than %d devices", maxUserDevices)
+	}
+	for _, d := range u.Devices {
+		if err := validateDeviceLabel(d.Label); err != nil {
+			return imported, err
+		}
+		deviceUUID, err := parseImportedUUID(d.VlessUUID)
+		if err != nil {
+			return imported, err
+		}
+		imported.devices = append(imported.devices, models.UserDevice{
+			Label:     d.Label,
+			VlessUUID: deviceUUID,
+		})
+	}
+
+	switch u.Status {
+	case 0:
+		u.Status = models.UserStatusEnabled
+	case models.UserStatusEnabled, models.UserStatusDisabled:
+	default:
+		return imported, xerr.Newf("unexpected status %v", u.Status)
+	}
+
+	switch u.Quota.Period {
+	case 0:
+		u.Quota.Period = models.TrafficQuotaPeriodOneOff
+	case models.TrafficQuotaPeriodOneOff, models.TrafficQuotaPeriodMonthly:
+	default:
+		return imported, xerr.Newf("unexpected quota period %v", u.Quota.Period)
+	}
+	if u.Quota.Limit < 0 {
+		return imported, xerr.New("negative quota limit")
+	}
+	if u.IPLimit < 0 {
+		return imported, xerr.New("negative ip limit")
+	}
+
+	user.Profile.DisplayName = u.DisplayName
+	user.Profile.Name = makeSlugName(u.DisplayName)
+	user.Profile.VlessUUID = vlessUUID
+	user.Profile.Email = u.Email
+	user.TargetStatus = u.Status
+	user.Quota = u.Quota
+	user.ExpiresAt = u.ExpiresAt
+	user.IPLimit = u.IPLimit
+	return imported, nil
+}
+
+func parseImportedUUID(s string) (string, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return "", xerr.Newf("invalid vless uuid %q", s)
+	}
+	return id.String(), nil
+}
+
+// kept email identifies user traffic on nodes, so it must
+// not look like generated "<id>-<name>" emails of other users
+func validateImportedEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(email) > maxEmailLen {
+		return xerr.Newf("email is longer than %d characters", maxEmailLen)
+	}
+	if strings.TrimSpace(email) != email {
+		return xerr.Newf("email %q has surrounding spaces", email)
+	}
+	if _, _, ok := models.ParseLegacyUserKey(email); ok {
+		return xerr.Newf("email %q looks like generated one", email)
+	}
+	return nil
+}
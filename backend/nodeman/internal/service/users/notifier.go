@@ -0,0 +1,12 @@
+package users
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Notifier interface {
+	// send lifecycle event, never blocks
+	Notify(ctx context.Context, e models.WebhookEvent)
+}
@@ -2,18 +2,33 @@ package users
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/XRay-Addons/xrayman/common/xerr"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/supervisor"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/jobs/expireman"
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 	"go.uber.org/zap"
 )
 
+type Option func(s *Service)
+
+// accept legacy "<id>-<name>" user keys along with subscription tokens
+func WithLegacyLinks(allow bool) Option {
+	return func(s *Service) {
+		s.legacyLinks = allow
+	}
+}
+
 type Service struct {
 	storage    Storage
 	poolSyncer Syncer
+	notifier   Notifier
+
+	legacyLinks bool
 
 	syncTimeout time.Duration
 	sv          *supervisor.Supervisor
@@ -21,12 +36,24 @@ type Service struct {
 	logger *zap.Logger
 }
 
+// max ip limit violations returned for user
+const maxIPLimitViolations = 100
+
+// max time previous user credentials keep working after rotation
+const maxCredentialsGracePeriod = 7 * 24 * time.Hour
+
+// max devices with own credentials per user
+const maxUserDevices = 16
+
 var _ handler.UsersService = (*Service)(nil)
+var _ expireman.UsersExpirer = (*Service)(nil)
 
 func New(poolSyncer Syncer,
 	storage Storage,
+	notifier Notifier,
 	syncTimeout time.Duration,
 	logger *zap.Logger,
+	opts ...Option,
 ) (*Service, error) {
 	if poolSyncer == nil {
 		return nil, errdefs.NilArg("poolSyncer")
@@ -34,17 +61,25 @@ func New(poolSyncer Syncer,
 	if storage == nil {
 		return nil, errdefs.NilArg("storage")
 	}
+	if notifier == nil {
+		return nil, errdefs.NilArg("notifier")
+	}
 	if logger == nil {
 		return nil, errdefs.NilArg("logger")
 	}
 
-	return &Service{
+	s := &Service{
 		storage:     storage,
 		poolSyncer:  poolSyncer,
+		notifier:    notifier,
 		syncTimeout: syncTimeout,
 		sv:          supervisor.New(),
 		logger:      logger,
-	}, nil
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
 }
 
 func (s *Service) Close() {
@@ -79,6 +114,8 @@ func (s *Service) NewUser(ctx context.Context, p models.NewUserParams) (
 	user.Profile.Name = name
 	user.Profile.VlessUUID = vlessUUID
 	user.TargetStatus = models.UserStatusEnabled
+	user.Quota.Period = models.TrafficQuotaPeriodOneOff
+	user.ExpiresAt = p.ExpiresAt
 
 	// sync all nodes on user add to return valid configuration
 	// to new user and avoid situation when nodes are temporary
@@ -96,6 +133,8 @@ func (s *Service) NewUser(ctx context.Context, p models.NewUserParams) (
 		return nil, err
 	}
 
+	s.notifyUser(ctx, models.WebhookEventUserCreated, user.Profile.ID)
+
 	return &user, nil
 }
 
@@ -106,8 +145,12 @@ func (s *Service) GetUserView(ctx context.Context, p models.GetUserParams) (
 		return nil, errdefs.NilCall()
 	}
 
-	// find user with given id
-	userView, err := s.storage.GetUserView(ctx, p.ID, p.Name)
+	// find user with given key
+	id, name, err := s.findUser(ctx, p.Key)
+	if err != nil {
+		return nil, err
+	}
+	userView, err := s.storage.GetUserView(ctx, id, name)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +158,155 @@ func (s *Service) GetUserView(ctx context.Context, p models.GetUserParams) (
 	return userView, nil
 }
 
+// resolve user key of public links to user id and name
+func (s *Service) findUser(ctx context.Context, key string) (models.UserID, string, error) {
+	id, name, err := s.storage.FindUserBySubToken(ctx, key)
+	if errors.Is(err, errdefs.ErrNotFound) && s.legacyLinks {
+		if id, name, ok := models.ParseLegacyUserKey(key); ok {
+			return id, name, nil
+		}
+	}
+	return id, name, err
+}
+
+// replace user vless uuid, previous one keeps working during grace period.
+// nodes remove previous identity and add new one on sync
+func (s *Service) RotateUserCredentials(ctx context.Context,
+	p models.RotateUserCredentialsParams,
+) (*models.RotateUserCredentialsResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if p.GracePeriod < 0 || p.GracePeriod > maxCredentialsGracePeriod {
+		return nil, errdefs.PayloadErr(xerr.Newf(
+			"grace period should be in [0, %s]", maxCredentialsGracePeriod))
+	}
+	vlessUUID, err := generateVlessUUID()
+	if err != nil {
+		return nil, err
+	}
+	var graceUntil time.Time
+	if p.GracePeriod > 0 {
+		graceUntil = time.Now().Add(p.GracePeriod)
+	}
+
+	if err := s.storage.RotateUserCredentials(ctx,
+		p.ID, vlessUUID, graceUntil,
+	); err != nil {
+		return nil, err
+	}
+
+	// sync nodes. errors is not a problem, it will updates in background
+	s.requestNodesSync()
+
+	return &models.RotateUserCredentialsResult{
+		VlessUUID:  vlessUUID,
+		GraceUntil: graceUntil,
+	}, nil
+}
+
+// replace user subscription token, links with old token stop working
+func (s *Service) RotateUserSubToken(ctx context.Context,
+	p models.RotateUserSubTokenParams,
+) (*models.RotateUserSubTokenResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	token, err := s.storage.RotateUserSubToken(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RotateUserSubTokenResult{
+		SubToken: token,
+	}, nil
+}
+
+// add device with its own credentials to user,
+// nodes add device identity on sync
+func (s *Service) NewUserDevice(ctx context.Context,
+	p models.NewUserDeviceParams,
+) (*models.UserDevice, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if err := validateDeviceLabel(p.Label); err != nil {
+		return nil, err
+	}
+	vlessUUID, err := generateVlessUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	device := models.UserDevice{
+		UserID:    p.UserID,
+		Label:     p.Label,
+		VlessUUID: vlessUUID,
+	}
+	if err := s.storage.DoTx(ctx, func(ctx context.Context) error {
+		count, err := s.storage.CountUserDevices(ctx, p.UserID)
+		if err != nil {
+			return err
+		}
+		if count >= maxUserDevices {
+			return errdefs.PayloadErr(xerr.Newf(
+				"user can't have more than %d devices", maxUserDevices))
+		}
+		return s.storage.NewUserDevice(ctx, &device)
+	}); err != nil {
+		return nil, err
+	}
+
+	// sync nodes. errors is not a problem, it will updates in background
+	s.requestNodesSync()
+
+	return &device, nil
+}
+
+func (s *Service) ListUserDevices(ctx context.Context,
+	p models.ListUserDevicesParams,
+) (*models.ListUserDevicesResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	devices, err := s.storage.ListUserDevices(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListUserDevicesResult{
+		Devices: devices,
+	}, nil
+}
+
+func (s *Service) SetUserDeviceLabel(ctx context.Context,
+	p models.SetUserDeviceLabelParams,
+) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if err := validateDeviceLabel(p.Label); err != nil {
+		return err
+	}
+	// label is not pushed to nodes, so nodes sync is not required here
+	return s.storage.SetUserDeviceLabel(ctx, p.ID, p.Label)
+}
+
+// delete device, nodes remove device identity on sync
+func (s *Service) DeleteUserDevice(ctx context.Context,
+	p models.DeleteUserDeviceParams,
+) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if err := s.storage.DeleteUserDevice(ctx, p.ID); err != nil {
+		return err
+	}
+
+	// sync nodes. errors is not a problem, it will updates in background
+	s.requestNodesSync()
+
+	return nil
+}
+
 func (s *Service) ListUsers(ctx context.Context) (
 	*models.ListUsersResult, error,
 ) {
@@ -130,10 +322,47 @@ func (s *Service) ListUsers(ctx context.Context) (
 	}, nil
 }
 
+func (s *Service) GetUserTrafficHistory(ctx context.Context,
+	p models.UserTrafficHistoryParams,
+) (*models.UserTrafficHistoryResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if err := p.Range.Validate(); err != nil {
+		return nil, errdefs.PayloadErr(err)
+	}
+	days, err := s.storage.GetUserTrafficHistory(ctx, p.ID, p.Range)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserTrafficHistoryResult{
+		Days: days,
+	}, nil
+}
+
+func (s *Service) GetUserNodesTrafficHistory(ctx context.Context,
+	p models.UserNodesTrafficHistoryParams,
+) (*models.UserNodesTrafficHistoryResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if err := p.Range.Validate(); err != nil {
+		return nil, errdefs.PayloadErr(err)
+	}
+	nodes, err := s.storage.GetUserNodesTrafficHistory(ctx, p.ID, p.Range)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserNodesTrafficHistoryResult{
+		Nodes: nodes,
+	}, nil
+}
+
 func (s *Service) EnableUser(ctx context.Context, p models.EnableUserParams) error {
 	if err := s.setUserStatus(ctx, p.ID, models.UserStatusEnabled); err != nil {
 		return err
 	}
+	s.notifyUser(ctx, models.WebhookEventUserEnabled, p.ID)
 	return nil
 }
 
@@ -141,6 +370,116 @@ func (s *Service) DisableUser(ctx context.Context, p models.DisableUserParams) e
 	if err := s.setUserStatus(ctx, p.ID, models.UserStatusDisabled); err != nil {
 		return err
 	}
+	s.notifyUser(ctx, models.WebhookEventUserDisabled, p.ID)
+	return nil
+}
+
+func (s *Service) SetUserQuota(ctx context.Context, p models.SetUserQuotaParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	// quota overrun is checked on the next stats update,
+	// nodes sync is required only if user disabled on quota is enabled
+	enabled, err := s.storage.SetUserQuota(ctx, p.ID, p.Quota, time.Now())
+	if err != nil {
+		return err
+	}
+	if enabled {
+		s.logger.Info("user quota changed, enabled",
+			zap.Int("id", p.ID),
+			zap.Int64("quota", p.Quota.Limit))
+		s.notifyUser(ctx, models.WebhookEventUserEnabled, p.ID)
+		s.requestNodesSync()
+	}
+	return nil
+}
+
+func (s *Service) SetUserIPLimit(ctx context.Context, p models.SetUserIPLimitParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	// ip limit is checked on the next stats update,
+	// so nodes sync is not required here
+	if err := s.storage.SetUserIPLimit(ctx, p.ID, p.IPLimit); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) GetIPLimitViolations(ctx context.Context,
+	p models.IPLimitViolationsParams,
+) (*models.IPLimitViolationsResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	violations, err := s.storage.ListIPLimitViolations(ctx, p.ID, maxIPLimitViolations)
+	if err != nil {
+		return nil, err
+	}
+	return &models.IPLimitViolationsResult{
+		Violations: violations,
+	}, nil
+}
+
+func (s *Service) SetUserExpiration(ctx context.Context, p models.SetUserExpirationParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	// expired users are disabled by background job,
+	// nodes sync is required only if user expired before is enabled
+	enabled, err := s.storage.SetUserExpiration(ctx, p.ID, p.ExpiresAt, time.Now())
+	if err != nil {
+		return err
+	}
+	if enabled {
+		s.logger.Info("user expiration extended, enabled",
+			zap.Int("id", p.ID),
+			zap.Time("expiresAt", p.ExpiresAt))
+		s.notifyUser(ctx, models.WebhookEventUserEnabled, p.ID)
+		s.requestNodesSync()
+	}
+	return nil
+}
+
+// disable all enabled users with passed expiration time
+func (s *Service) DisableExpiredUsers(ctx context.Context) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	expired, err := s.storage.DisableExpiredUsers(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	for _, u := range expired {
+		s.logger.Info("user expired, disabled",
+			zap.Int("id", u.Profile.ID),
+			zap.Time("expiresAt", u.ExpiresAt))
+		s.notifyUser(ctx, models.WebhookEventUserExpired, u.Profile.ID)
+	}
+
+	// sync nodes. errors is not a problem, it will updates in background
+	s.requestNodesSync()
+
+	return nil
+}
+
+// SetUserGroups replaces user node groups,
+// nodes sync adds or removes user according to new groups
+func (s *Service) SetUserGroups(ctx context.Context, p models.SetUserGroupsParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	if err := s.storage.SetUserGroups(ctx, p.ID, p.GroupIDs); err != nil {
+		return err
+	}
+
+	// sync nodes. errors is not a problem, it will updates in background
+	s.requestNodesSync()
+
 	return nil
 }
 
@@ -159,6 +498,7 @@ func (s *Service) DeleteUser(ctx context.Context, p models.DeleteUserParams) err
 		return err
 	}
 
+	s.notifyUser(ctx, models.WebhookEventUserDeleted, p.ID)
 	s.requestNodesSync()
 
 	return nil
@@ -181,6 +521,15 @@ func (s *Service) setUserStatus(ctx context.Context,
 	return nil
 }
 
+func (s *Service) notifyUser(ctx context.Context,
+	t models.WebhookEventType, id models.UserID,
+) {
+	s.notifier.Notify(ctx, models.WebhookEvent{
+		Type:   t,
+		UserID: id,
+	})
+}
+
 // sync all nodes, return nil if at least one node synced ok
 func (s *Service) syncAllNodes(ctx context.Context) error {
 	syncResults, err := s.poolSyncer.SyncPoolState(ctx)
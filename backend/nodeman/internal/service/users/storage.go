@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
 )
@@ -13,11 +14,63 @@ type Storage interface {
 	NewUser(ctx context.Context, user *models.User) error
 	// get user by id, return ErrNotFound if not exists
 	GetUserView(ctx context.Context, id models.UserID, name string) (*models.UserView, error)
+	// find user by subscription token, return ErrNotFound if not exists
+	FindUserBySubToken(ctx context.Context, token string) (models.UserID, string, error)
+	// replace user subscription token with random one, return new token
+	RotateUserSubToken(ctx context.Context, id models.UserID) (string, error)
+	// replace user vless uuid, previous one is kept
+	// on nodes until prevExpiresAt, zero time means not kept
+	RotateUserCredentials(ctx context.Context, id models.UserID,
+		vlessUUID string, prevExpiresAt time.Time) error
+	// add device to user, assign DeviceID to device,
+	// return ErrNotFound if user not exists
+	NewUserDevice(ctx context.Context, device *models.UserDevice) error
+	// get user devices count
+	CountUserDevices(ctx context.Context, userID models.UserID) (int, error)
+	// get vless uuids of all users devices
+	ListDevicesVlessUUIDs(ctx context.Context) ([]string, error)
+	// get user devices with their traffic
+	ListUserDevices(ctx context.Context, userID models.UserID) (
+		[]models.UserDevice, error)
+	// change device label, return ErrNotFound if not exists
+	SetUserDeviceLabel(ctx context.Context, id models.DeviceID,
+		label string) error
+	// delete device, return ErrNotFound if not exists
+	DeleteUserDevice(ctx context.Context, id models.DeviceID) error
+	// get all users without traffic
+	ListUsers(ctx context.Context) ([]models.User, error)
 	// get all users
 	ListUserViews(ctx context.Context) ([]models.UserView, error)
 	// change user target status
 	SetTargetUserStatus(ctx context.Context, id models.UserID,
 		status models.UserStatus) error
+	// change user traffic quota, user disabled on quota is enabled
+	// if its usage is under new quota, returns whether user was enabled
+	SetUserQuota(ctx context.Context, id models.UserID,
+		quota models.TrafficQuota, now time.Time) (bool, error)
+	// change user ip limit, zero means unlimited
+	SetUserIPLimit(ctx context.Context, id models.UserID,
+		ipLimit int) error
+	// get last user ip limit violations
+	ListIPLimitViolations(ctx context.Context, id models.UserID,
+		maxCount int) ([]models.IPLimitViolation, error)
+	// change user expiration time, zero time means never.
+	// user disabled on expiration is enabled if not expired at now,
+	// returns whether user was enabled
+	SetUserExpiration(ctx context.Context, id models.UserID,
+		expiresAt time.Time, now time.Time) (bool, error)
+	// replace user node groups
+	SetUserGroups(ctx context.Context, id models.UserID,
+		groups []models.NodeGroupID) error
+	// get user daily traffic
+	GetUserTrafficHistory(ctx context.Context, id models.UserID,
+		r models.TrafficHistoryRange) ([]models.DailyTraffic, error)
+	// get user daily traffic per node
+	GetUserNodesTrafficHistory(ctx context.Context, id models.UserID,
+		r models.TrafficHistoryRange) ([]models.NodeTrafficHistory, error)
+	// disable enabled users expired at the given time, return them
+	DisableExpiredUsers(ctx context.Context, now time.Time) (
+		[]models.User, error)
 	// delete user
 	DeleteUser(ctx context.Context,
 		id models.UserID) error
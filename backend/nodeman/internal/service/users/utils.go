@@ -1,7 +1,10 @@
 package users
 
 import (
+	"unicode/utf8"
+
 	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
 	"github.com/google/uuid"
 	"github.com/gosimple/slug"
 )
@@ -17,3 +20,17 @@ func generateVlessUUID() (string, error) {
 func makeSlugName(name string) string {
 	return slug.Make(name)
 }
+
+// max device label length in characters
+const maxDeviceLabelLen = 64
+
+func validateDeviceLabel(label string) error {
+	if label == "" {
+		return errdefs.PayloadErr(xerr.Newf("empty device label"))
+	}
+	if utf8.RuneCountInString(label) > maxDeviceLabelLen {
+		return errdefs.PayloadErr(xerr.Newf(
+			"device label is longer than %d characters", maxDeviceLabelLen))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"context"
+	"net/url"
+	"slices"
+
+	"github.com/XRay-Addons/xrayman/common/xerr"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/errdefs"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/http/handler"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/infra/webhook"
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Service struct {
+	storage Storage
+}
+
+var _ handler.WebhooksService = (*Service)(nil)
+
+func New(storage Storage) (*Service, error) {
+	if storage == nil {
+		return nil, errdefs.NilArg("storage")
+	}
+	return &Service{
+		storage: storage,
+	}, nil
+}
+
+// create webhook with random signing secret,
+// secret is returned to caller once
+func (s *Service) NewWebhook(ctx context.Context, p models.NewWebhookParams) (
+	*models.NewWebhookResult, error,
+) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	if err := checkURL(p.URL); err != nil {
+		return nil, err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	events := slices.Clone(p.Events)
+	slices.Sort(events)
+	hook := models.Webhook{
+		URL:    p.URL,
+		Events: slices.Compact(events),
+		Secret: secret,
+	}
+	if err := s.storage.NewWebhook(ctx, &hook); err != nil {
+		return nil, err
+	}
+	return &models.NewWebhookResult{
+		Webhook: hook,
+	}, nil
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) (*models.ListWebhooksResult, error) {
+	if s == nil {
+		return nil, errdefs.NilCall()
+	}
+	hooks, err := s.storage.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return &models.ListWebhooksResult{
+		Webhooks: hooks,
+	}, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, p models.DeleteWebhookParams) error {
+	if s == nil {
+		return errdefs.NilCall()
+	}
+	return s.storage.DeleteWebhook(ctx, p.ID)
+}
+
+func checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errdefs.PayloadErr(xerr.Newf("invalid webhook url: %v", err))
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errdefs.PayloadErr(xerr.New("webhook url must be absolute http(s) url"))
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/XRay-Addons/xrayman/nodeman/internal/models"
+)
+
+type Storage interface {
+	// add new webhook, assign WebhookID and creation time to webhook
+	NewWebhook(ctx context.Context, webhook *models.Webhook) error
+	// get all webhooks
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	// delete webhook, no more events are sent to it
+	DeleteWebhook(ctx context.Context, id models.WebhookID) error
+}
@@ -1,4 +1,4 @@
+// user subscription token, or legacy "<id>-<name>" pair
 export type UserID = {
-  id: number;
-  name: string;
+  key: string;
 };
@@ -4,7 +4,7 @@ import { MakePageUrl } from "@/runtime/utils/paths";
 
 export const ProfileURL = {
   async make(user: User): Promise<string> {
-    return MakePageUrl(`${user.Profile.ID}-${user.Profile.Name}`);
+    return MakePageUrl(user.Profile.SubToken);
   },
   async set(user: User) {
     history.pushState(null, "", await this.make(user));
@@ -16,10 +16,10 @@ export const ProfileURL = {
   async parse(): Promise<UserID | null> {
     const prefix = MakePageUrl(`./`);
     const path = window.location.href;
-    const match = path.match(new RegExp(`${prefix}(\\d+)-(.+)$`));
+    const match = path.match(new RegExp(`${prefix}([^/?#]+)$`));
     if (!match) return null;
-    const [, idStr, name] = match;
-    return { id: Number(idStr), name };
+    const [, key] = match;
+    return { key };
   },
 };
 
@@ -27,7 +27,7 @@ const STORAGE_KEY = "user";
 
 export const ProfileStorage = {
   set(user: User): void {
-    const data: UserID = { id: user.Profile.ID, name: user.Profile.Name };
+    const data: UserID = { key: user.Profile.SubToken };
     localStorage.setItem(STORAGE_KEY, JSON.stringify(data));
   },
   reset() {
@@ -38,12 +38,15 @@ export const ProfileStorage = {
       const raw = localStorage.getItem(STORAGE_KEY);
       if (!raw) return null;
 
-      const data = JSON.parse(raw) as UserID;
-      if (typeof data.id !== "number" || typeof data.name !== "string") {
-        return null;
+      const data = JSON.parse(raw) as UserID & { id?: number; name?: string };
+      if (typeof data.key === "string") {
+        return { key: data.key };
       }
-
-      return data;
+      // stored before subscription tokens
+      if (typeof data.id === "number" && typeof data.name === "string") {
+        return { key: `${data.id}-${data.name}` };
+      }
+      return null;
     } catch {
       return null;
     }
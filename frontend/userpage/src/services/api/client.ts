@@ -9,9 +9,9 @@ export async function newUser(displayName: string): Promise<ApiResult<User>> {
   );
 }
 
-export async function getUser(id: number, name: string): Promise<ApiResult<UserView>> {
+export async function getUser(key: string): Promise<ApiResult<UserView>> {
   return handleAPI(
-    () => _getUser({ path: { ID: id, Name: name } }),
+    () => _getUser({ path: { Key: key } }),
     (data) => data,
   );
 }